@@ -0,0 +1,65 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// chainSyncFunc returns a ChainSync callback that applies roll forwards and
+// roll backwards to a tracker before passing the data along to next
+func chainSyncFunc(
+	observe func(block *chainsync.Block) error,
+	rollback func(slot uint64),
+	reset func(),
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	return func(ctx context.Context, data []byte) error {
+		var response chainsync.ResponsePraos
+		if err := json.Unmarshal(data, &response); err != nil {
+			return fmt.Errorf("failed to decode chainsync response: %w", err)
+		}
+
+		if response.Method == chainsync.NextBlockMethod {
+			result, err := response.NextBlockResult()
+			if err != nil {
+				return fmt.Errorf("failed to decode chainsync response: %w", err)
+			}
+			switch result.Direction {
+			case chainsync.RollForwardString:
+				if err := observe(result.Block); err != nil {
+					return err
+				}
+			case chainsync.RollBackwardString:
+				if result.Point != nil {
+					if ps, ok := result.Point.PointStruct(); ok {
+						rollback(ps.Slot)
+					} else {
+						reset()
+					}
+				}
+			}
+		}
+
+		if next != nil {
+			return next(ctx, data)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// UnresolvedScript is reported in place of the script hash when the script a
+// redeemer applies to cannot be determined from the block alone e.g. spends
+// without a ScriptResolver
+const UnresolvedScript = "unresolved"
+
+// ScriptResolver returns the hash of the script locking the given input; ok is
+// false if the input is unknown.  Spent inputs are not included in blocks, so
+// a resolver, e.g. backed by a utxo index, is required to attribute spends
+type ScriptResolver func(in chainsync.TxIn) (scriptHash string, ok bool)
+
+// ScriptExUnits summarizes the execution units consumed by a single script
+type ScriptExUnits struct {
+	ScriptHash string `json:"scriptHash"`
+	Redeemers  int    `json:"redeemers"`
+	Memory     uint64 `json:"memory"`
+	CPU        uint64 `json:"cpu"`
+}
+
+// BlockExUnits summarizes the execution units consumed within a block
+type BlockExUnits struct {
+	ID        string          `json:"id"`
+	Slot      uint64          `json:"slot"`
+	Height    uint64          `json:"height"`
+	Redeemers int             `json:"redeemers"`
+	Memory    uint64          `json:"memory"`
+	CPU       uint64          `json:"cpu"`
+	Scripts   []ScriptExUnits `json:"scripts,omitempty"` // ordered by cpu
+}
+
+// BlockExecutionUnits computes the execution units consumed by the block, in
+// total and per script hash.  resolve may be nil
+func BlockExecutionUnits(
+	block *chainsync.Block,
+	resolve ScriptResolver,
+) (BlockExUnits, error) {
+	summary := BlockExUnits{
+		ID:     block.ID,
+		Slot:   block.Slot,
+		Height: block.Height,
+	}
+
+	scripts := map[string]*ScriptExUnits{}
+	for _, tx := range block.Transactions {
+		redeemers, err := tx.DecodeRedeemers()
+		if err != nil {
+			return BlockExUnits{}, err
+		}
+
+		for _, redeemer := range redeemers {
+			hash := redeemerScript(tx, redeemer.Validator, resolve)
+			script, ok := scripts[hash]
+			if !ok {
+				script = &ScriptExUnits{ScriptHash: hash}
+				scripts[hash] = script
+			}
+			units := redeemer.ExecutionUnits
+			script.add(1, units.Memory, units.CPU)
+
+			summary.Redeemers++
+			summary.Memory += units.Memory
+			summary.CPU += units.CPU
+		}
+	}
+	summary.Scripts = sortScripts(scripts)
+	return summary, nil
+}
+
+func (s *ScriptExUnits) add(redeemers int, memory, cpu uint64) {
+	s.Redeemers += redeemers
+	s.Memory += memory
+	s.CPU += cpu
+}
+
+func sortScripts(scripts map[string]*ScriptExUnits) []ScriptExUnits {
+	var sorted []ScriptExUnits
+	for _, script := range scripts {
+		sorted = append(sorted, *script)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CPU != sorted[j].CPU {
+			return sorted[i].CPU > sorted[j].CPU
+		}
+		return sorted[i].ScriptHash < sorted[j].ScriptHash
+	})
+	return sorted
+}
+
+// redeemerScript returns the hash of the script the redeemer is supplied to.
+// Redeemer indexes refer to the ledger's sorted order of the relevant items
+func redeemerScript(
+	tx chainsync.Tx,
+	validator chainsync.RedeemerValidator,
+	resolve ScriptResolver,
+) string {
+	index := validator.Index
+	switch validator.Purpose {
+	case chainsync.RedeemerPurposeSpend:
+		inputs := append([]chainsync.TxIn(nil), tx.Inputs...)
+		sort.Slice(inputs, func(i, j int) bool {
+			if a, b := inputs[i].Transaction.ID, inputs[j].Transaction.ID; a != b {
+				return a < b
+			}
+			return inputs[i].Index < inputs[j].Index
+		})
+		if resolve != nil && index >= 0 && index < len(inputs) {
+			if hash, ok := resolve(inputs[index]); ok {
+				return hash
+			}
+		}
+
+	case chainsync.RedeemerPurposeMint:
+		var policies []string
+		for policy := range tx.Mint {
+			if policy != "ada" {
+				policies = append(policies, policy)
+			}
+		}
+		sort.Strings(policies)
+		if index >= 0 && index < len(policies) {
+			return policies[index]
+		}
+
+	case chainsync.RedeemerPurposeWithdraw:
+		var accounts [][]byte
+		for address := range tx.Withdrawals {
+			if account, err := address.Bytes(); err == nil {
+				accounts = append(accounts, account)
+			}
+		}
+		sort.Slice(accounts, func(i, j int) bool {
+			return string(accounts[i]) < string(accounts[j])
+		})
+		if index >= 0 && index < len(accounts) {
+			return hex.EncodeToString(accounts[index][1:])
+		}
+
+	case chainsync.RedeemerPurposePublish:
+		if index >= 0 && index < len(tx.Certificates) {
+			certificate := tx.Certificates[index]
+			credential, err := jsonparser.GetString(certificate, "credential")
+			if err == nil {
+				return credential
+			}
+		}
+	}
+	return UnresolvedScript
+}
+
+// ExUnitsOptions configures an ExUnitsTracker
+type ExUnitsOptions struct {
+	resolve ScriptResolver
+	window  int
+}
+
+// ExUnitsOption provides the functional options pattern for ExUnitsTracker
+type ExUnitsOption func(*ExUnitsOptions)
+
+// WithExUnitsWindow sets the number of recent blocks over which execution
+// units are aggregated; defaults to DefaultWindow
+func WithExUnitsWindow(n int) ExUnitsOption {
+	return func(opts *ExUnitsOptions) {
+		opts.window = n
+	}
+}
+
+// WithScriptResolver allows spends to be attributed to the script locking the
+// spent input
+func WithScriptResolver(resolve ScriptResolver) ExUnitsOption {
+	return func(opts *ExUnitsOptions) {
+		opts.resolve = resolve
+	}
+}
+
+// ExUnitsTracker aggregates execution units per script over a sliding window
+// of recent blocks.  ExUnitsTracker is safe for concurrent use and implements
+// expvar.Var so it may be published directly e.g.
+//
+//	expvar.Publish("ogmigo_exunits", tracker)
+type ExUnitsTracker struct {
+	mutex   sync.Mutex
+	window  int
+	resolve ScriptResolver
+	blocks  []BlockExUnits
+	scripts map[string]*ScriptExUnits
+}
+
+// NewExUnitsTracker returns a new tracker
+func NewExUnitsTracker(opts ...ExUnitsOption) *ExUnitsTracker {
+	options := ExUnitsOptions{
+		window: DefaultWindow,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.window <= 0 {
+		options.window = DefaultWindow
+	}
+
+	return &ExUnitsTracker{
+		window:  options.window,
+		resolve: options.resolve,
+		scripts: map[string]*ScriptExUnits{},
+	}
+}
+
+// Observe records a block rolled forward and returns its summary
+func (t *ExUnitsTracker) Observe(block *chainsync.Block) (BlockExUnits, error) {
+	if block == nil {
+		return BlockExUnits{}, nil
+	}
+
+	summary, err := BlockExecutionUnits(block, t.resolve)
+	if err != nil {
+		return BlockExUnits{}, fmt.Errorf(
+			"failed to observe block %v: %w",
+			block.ID,
+			err,
+		)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.blocks = append(t.blocks, summary)
+	t.apply(summary, 1)
+	if n := len(t.blocks) - t.window; n > 0 {
+		for _, b := range t.blocks[:n] {
+			t.apply(b, -1)
+		}
+		t.blocks = append(t.blocks[:0], t.blocks[n:]...)
+	}
+	return summary, nil
+}
+
+// apply adds, or with sign -1 removes, the block summary from the totals.
+// apply assumes the caller holds the mutex
+func (t *ExUnitsTracker) apply(summary BlockExUnits, sign int) {
+	for _, s := range summary.Scripts {
+		script, ok := t.scripts[s.ScriptHash]
+		if !ok {
+			script = &ScriptExUnits{ScriptHash: s.ScriptHash}
+			t.scripts[s.ScriptHash] = script
+		}
+		if sign > 0 {
+			script.add(s.Redeemers, s.Memory, s.CPU)
+			continue
+		}
+
+		script.Redeemers -= s.Redeemers
+		script.Memory -= s.Memory
+		script.CPU -= s.CPU
+		if script.Redeemers <= 0 {
+			delete(t.scripts, s.ScriptHash)
+		}
+	}
+}
+
+// Rollback discards any observed blocks after the given slot
+func (t *ExUnitsTracker) Rollback(slot uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	i := len(t.blocks)
+	for i > 0 && t.blocks[i-1].Slot > slot {
+		i--
+		t.apply(t.blocks[i], -1)
+	}
+	t.blocks = t.blocks[:i]
+}
+
+// Reset discards all observed blocks
+func (t *ExUnitsTracker) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.blocks = nil
+	t.scripts = map[string]*ScriptExUnits{}
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that observes each
+// block before passing the data along to next, if provided
+func (t *ExUnitsTracker) ChainSyncFunc(
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	observe := func(block *chainsync.Block) error {
+		_, err := t.Observe(block)
+		return err
+	}
+	return chainSyncFunc(observe, t.Rollback, t.Reset, next)
+}
+
+// Script returns the totals for a single script within the window
+func (t *ExUnitsTracker) Script(scriptHash string) (ScriptExUnits, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	script, ok := t.scripts[scriptHash]
+	if !ok {
+		return ScriptExUnits{}, false
+	}
+	return *script, true
+}
+
+// Scripts returns the totals for every script within the window, ordered by
+// cpu consumed
+func (t *ExUnitsTracker) Scripts() []ScriptExUnits {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return sortScripts(t.scripts)
+}
+
+// Blocks returns the summaries of the blocks within the window, oldest first
+func (t *ExUnitsTracker) Blocks() []BlockExUnits {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]BlockExUnits(nil), t.blocks...)
+}
+
+// Len returns the number of blocks currently within the window
+func (t *ExUnitsTracker) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.blocks)
+}
+
+// String implements expvar.Var
+func (t *ExUnitsTracker) String() string {
+	t.mutex.Lock()
+	var (
+		total   ScriptExUnits
+		scripts = sortScripts(t.scripts)
+		blocks  = len(t.blocks)
+	)
+	t.mutex.Unlock()
+
+	for _, script := range scripts {
+		total.add(script.Redeemers, script.Memory, script.CPU)
+	}
+	data, err := json.Marshal(struct {
+		Blocks    int             `json:"blocks"`
+		Redeemers int             `json:"redeemers"`
+		Memory    uint64          `json:"memory"`
+		CPU       uint64          `json:"cpu"`
+		Scripts   []ScriptExUnits `json:"scripts"`
+	}{
+		Blocks:    blocks,
+		Redeemers: total.Redeemers,
+		Memory:    total.Memory,
+		CPU:       total.CPU,
+		Scripts:   scripts,
+	})
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/tj/assert"
+)
+
+var (
+	policyA = strings.Repeat("a", 56)
+	policyB = strings.Repeat("b", 56)
+	scriptC = strings.Repeat("c", 56)
+	scriptD = strings.Repeat("d", 56)
+)
+
+func rewardAccount(t *testing.T, scriptHash string) chainsync.RewardAccount {
+	hash, err := hex.DecodeString(scriptHash)
+	assert.Nil(t, err)
+	data, err := bech32.ConvertBits(append([]byte{0xf1}, hash...), 8, 5, true)
+	assert.Nil(t, err)
+	address, err := bech32.Encode("stake", data)
+	assert.Nil(t, err)
+	return chainsync.RewardAccount(address)
+}
+
+func redeemers(t *testing.T, redeemers ...chainsync.Redeemer) json.RawMessage {
+	data, err := json.Marshal(redeemers)
+	assert.Nil(t, err)
+	return data
+}
+
+func redeemer(purpose string, index int, memory, cpu uint64) chainsync.Redeemer {
+	return chainsync.Redeemer{
+		Validator:      chainsync.RedeemerValidator{Purpose: purpose, Index: index},
+		Redeemer:       "d87980",
+		ExecutionUnits: chainsync.ExUnits{Memory: memory, CPU: cpu},
+	}
+}
+
+func exUnitsBlock(t *testing.T, slot uint64) *chainsync.Block {
+	txIn := func(id string, index int) chainsync.TxIn {
+		return chainsync.TxIn{Transaction: chainsync.TxInID{ID: id}, Index: index}
+	}
+	return &chainsync.Block{
+		ID:   "block",
+		Slot: slot,
+		Transactions: []chainsync.Tx{
+			{
+				ID:     "tx1",
+				Inputs: []chainsync.TxIn{txIn("b", 0), txIn("a", 1)},
+				Mint: shared.MintValue{
+					policyB: {"": {}},
+					policyA: {"": {}},
+				},
+				Redeemers: redeemers(t,
+					redeemer(chainsync.RedeemerPurposeSpend, 0, 10, 100), // a#1
+					redeemer(chainsync.RedeemerPurposeSpend, 1, 1, 1),    // b#0
+					redeemer(chainsync.RedeemerPurposeMint, 1, 20, 200),  // policyB
+				),
+			},
+			{
+				ID: "tx2",
+				Withdrawals: chainsync.Withdrawals{
+					rewardAccount(t, scriptD): {},
+				},
+				Redeemers: redeemers(t,
+					redeemer(chainsync.RedeemerPurposeWithdraw, 0, 5, 50),
+				),
+			},
+			{ID: "tx3"},
+		},
+	}
+}
+
+func TestBlockExecutionUnits(t *testing.T) {
+	resolve := func(in chainsync.TxIn) (string, bool) {
+		if in.String() == "a#1" {
+			return scriptC, true
+		}
+		return "", false
+	}
+
+	summary, err := BlockExecutionUnits(exUnitsBlock(t, 1), resolve)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, summary.Redeemers)
+	assert.EqualValues(t, 36, summary.Memory)
+	assert.EqualValues(t, 351, summary.CPU)
+	assert.Equal(t, []ScriptExUnits{
+		{ScriptHash: policyB, Redeemers: 1, Memory: 20, CPU: 200},
+		{ScriptHash: scriptC, Redeemers: 1, Memory: 10, CPU: 100},
+		{ScriptHash: scriptD, Redeemers: 1, Memory: 5, CPU: 50},
+		{ScriptHash: UnresolvedScript, Redeemers: 1, Memory: 1, CPU: 1},
+	}, summary.Scripts)
+
+	_, err = BlockExecutionUnits(&chainsync.Block{
+		Transactions: []chainsync.Tx{{Redeemers: json.RawMessage(`{}`)}},
+	}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestExUnitsTracker(t *testing.T) {
+	tracker := NewExUnitsTracker(WithExUnitsWindow(2))
+	for slot := uint64(1); slot <= 3; slot++ {
+		_, err := tracker.Observe(exUnitsBlock(t, slot))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, tracker.Len())
+
+	script, ok := tracker.Script(policyB)
+	assert.True(t, ok)
+	assert.Equal(t, 2, script.Redeemers)
+	assert.EqualValues(t, 400, script.CPU)
+
+	unresolved, ok := tracker.Script(UnresolvedScript)
+	assert.True(t, ok)
+	assert.Equal(t, 4, unresolved.Redeemers) // spends without a resolver
+
+	tracker.Rollback(2)
+	assert.Equal(t, 1, tracker.Len())
+	script, _ = tracker.Script(policyB)
+	assert.EqualValues(t, 200, script.CPU)
+
+	var metrics struct {
+		Blocks int
+		CPU    uint64
+	}
+	assert.Nil(t, json.Unmarshal([]byte(tracker.String()), &metrics))
+	assert.Equal(t, 1, metrics.Blocks)
+	assert.EqualValues(t, 351, metrics.CPU)
+
+	tracker.Reset()
+	assert.Len(t, tracker.Scripts(), 0)
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analytics provides accessories that aggregate chain data as it is
+// streamed via ChainSync
+package analytics
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/statequery"
+	"github.com/btcsuite/btcutil/bech32"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DefaultWindow is the number of recent blocks tracked by default
+const DefaultWindow = 2160
+
+// IssuerStats summarizes the blocks produced by a single pool within the window
+type IssuerStats struct {
+	PoolID   string  `json:"poolId"`
+	Blocks   int     `json:"blocks"`
+	Share    float64 `json:"share"`              // fraction of blocks in the window
+	Stake    float64 `json:"stake,omitempty"`    // fraction of the live stake
+	Expected float64 `json:"expected,omitempty"` // blocks expected given the stake
+	Ratio    float64 `json:"ratio,omitempty"`    // actual / expected
+}
+
+// IssuerOptions configures an IssuerTracker
+type IssuerOptions struct {
+	window int
+}
+
+// IssuerOption provides the functional options pattern for IssuerTracker
+type IssuerOption func(*IssuerOptions)
+
+// WithWindow sets the number of recent blocks over which production is measured
+func WithWindow(n int) IssuerOption {
+	return func(opts *IssuerOptions) {
+		opts.window = n
+	}
+}
+
+type issuedBlock struct {
+	slot   uint64
+	poolID string
+}
+
+// IssuerTracker aggregates block production per pool over a sliding window of
+// recent blocks.  IssuerTracker is safe for concurrent use and implements
+// expvar.Var so it may be published directly e.g.
+//
+//	expvar.Publish("ogmigo_issuers", tracker)
+type IssuerTracker struct {
+	mutex  sync.Mutex
+	window int
+	blocks []issuedBlock
+	counts map[string]int
+	stake  map[string]float64
+}
+
+// NewIssuerTracker returns a new tracker
+func NewIssuerTracker(opts ...IssuerOption) *IssuerTracker {
+	options := IssuerOptions{
+		window: DefaultWindow,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.window <= 0 {
+		options.window = DefaultWindow
+	}
+
+	return &IssuerTracker{
+		window: options.window,
+		counts: map[string]int{},
+		stake:  map[string]float64{},
+	}
+}
+
+// PoolID returns the bech32 pool id for the block issuer's verification key
+func PoolID(verificationKey string) (string, error) {
+	key, err := hex.DecodeString(verificationKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode verification key: %w", err)
+	}
+
+	hash, err := blake2b.New(28, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create hash: %w", err)
+	}
+	hash.Write(key)
+
+	data, err := bech32.ConvertBits(hash.Sum(nil), 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert bits: %w", err)
+	}
+	return bech32.Encode("pool", data)
+}
+
+// Observe records a block rolled forward.  Blocks without an issuer, such as
+// those from the byron era, are ignored.
+func (t *IssuerTracker) Observe(block *chainsync.Block) error {
+	if block == nil || block.Issuer.VerificationKey == "" {
+		return nil
+	}
+
+	poolID, err := PoolID(block.Issuer.VerificationKey)
+	if err != nil {
+		return fmt.Errorf("failed to observe block %v: %w", block.ID, err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.blocks = append(t.blocks, issuedBlock{slot: block.Slot, poolID: poolID})
+	t.counts[poolID]++
+	if n := len(t.blocks) - t.window; n > 0 {
+		for _, b := range t.blocks[:n] {
+			t.decrement(b.poolID)
+		}
+		t.blocks = append(t.blocks[:0], t.blocks[n:]...)
+	}
+
+	return nil
+}
+
+// Rollback discards any observed blocks after the given slot
+func (t *IssuerTracker) Rollback(slot uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	i := len(t.blocks)
+	for i > 0 && t.blocks[i-1].slot > slot {
+		i--
+		t.decrement(t.blocks[i].poolID)
+	}
+	t.blocks = t.blocks[:i]
+}
+
+func (t *IssuerTracker) decrement(poolID string) {
+	if t.counts[poolID]--; t.counts[poolID] <= 0 {
+		delete(t.counts, poolID)
+	}
+}
+
+// SetStakeDistribution updates the stake used to compute expected production
+func (t *IssuerTracker) SetStakeDistribution(
+	distribution statequery.StakeDistribution,
+) error {
+	stake := make(map[string]float64, len(distribution))
+	for poolID, pool := range distribution {
+		fraction, err := pool.Fraction()
+		if err != nil {
+			return fmt.Errorf("failed to set stake for pool %v: %w", poolID, err)
+		}
+		stake[poolID], _ = fraction.Float64()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stake = stake
+	return nil
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that observes each
+// block before passing the data along to next, if provided
+func (t *IssuerTracker) ChainSyncFunc(
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	return chainSyncFunc(t.Observe, t.Rollback, t.Reset, next)
+}
+
+// Reset discards all observed blocks
+func (t *IssuerTracker) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.blocks = nil
+	t.counts = map[string]int{}
+}
+
+// Pool returns the stats for a single pool
+func (t *IssuerTracker) Pool(poolID string) (IssuerStats, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, produced := t.counts[poolID]
+	_, staked := t.stake[poolID]
+	if !produced && !staked {
+		return IssuerStats{}, false
+	}
+	return t.stats(poolID), true
+}
+
+// Snapshot returns stats for every pool that either produced a block within the
+// window or holds stake, ordered by blocks produced
+func (t *IssuerTracker) Snapshot() []IssuerStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	seen := map[string]struct{}{}
+	var stats []IssuerStats
+	for poolID := range t.counts {
+		seen[poolID] = struct{}{}
+		stats = append(stats, t.stats(poolID))
+	}
+	for poolID := range t.stake {
+		if _, ok := seen[poolID]; !ok {
+			stats = append(stats, t.stats(poolID))
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Blocks != stats[j].Blocks {
+			return stats[i].Blocks > stats[j].Blocks
+		}
+		return stats[i].PoolID < stats[j].PoolID
+	})
+	return stats
+}
+
+// Len returns the number of blocks currently within the window
+func (t *IssuerTracker) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.blocks)
+}
+
+// String implements expvar.Var
+func (t *IssuerTracker) String() string {
+	data, err := json.Marshal(t.Snapshot())
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// stats assumes the caller holds the mutex
+func (t *IssuerTracker) stats(poolID string) IssuerStats {
+	var (
+		blocks = t.counts[poolID]
+		total  = len(t.blocks)
+		stake  = t.stake[poolID]
+		stats  = IssuerStats{
+			PoolID: poolID,
+			Blocks: blocks,
+			Stake:  stake,
+		}
+	)
+	if total > 0 {
+		stats.Share = float64(blocks) / float64(total)
+		stats.Expected = stake * float64(total)
+	}
+	if stats.Expected > 0 {
+		stats.Ratio = float64(blocks) / stats.Expected
+	}
+	return stats
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/statequery"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/tj/assert"
+)
+
+const (
+	keyA = "0000000000000000000000000000000000000000000000000000000000000001"
+	keyB = "0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+func block(slot uint64, key string) *chainsync.Block {
+	return &chainsync.Block{
+		Slot:   slot,
+		Issuer: chainsync.BlockIssuer{VerificationKey: key},
+	}
+}
+
+func mustPoolID(t *testing.T, key string) string {
+	poolID, err := PoolID(key)
+	assert.Nil(t, err)
+	return poolID
+}
+
+func TestPoolID(t *testing.T) {
+	poolID := mustPoolID(t, keyA)
+
+	hrp, data, err := bech32.Decode(poolID)
+	assert.Nil(t, err)
+	assert.Equal(t, "pool", hrp)
+
+	decoded, err := bech32.ConvertBits(data, 5, 8, false)
+	assert.Nil(t, err)
+	assert.Len(t, decoded, 28)
+
+	_, err = PoolID("zz")
+	assert.NotNil(t, err)
+}
+
+func TestIssuerTracker_Window(t *testing.T) {
+	tracker := NewIssuerTracker(WithWindow(3))
+	assert.Nil(t, tracker.Observe(block(1, keyA)))
+	assert.Nil(t, tracker.Observe(block(2, keyA)))
+	assert.Nil(t, tracker.Observe(block(3, keyB)))
+	assert.Nil(t, tracker.Observe(block(4, keyB)))
+	assert.Nil(t, tracker.Observe(block(5, "")))
+
+	assert.Equal(t, 3, tracker.Len())
+
+	stats := tracker.Snapshot()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, mustPoolID(t, keyB), stats[0].PoolID)
+	assert.Equal(t, 2, stats[0].Blocks)
+	assert.Equal(t, 1, stats[1].Blocks)
+}
+
+func TestIssuerTracker_Rollback(t *testing.T) {
+	tracker := NewIssuerTracker()
+	assert.Nil(t, tracker.Observe(block(1, keyA)))
+	assert.Nil(t, tracker.Observe(block(2, keyB)))
+	assert.Nil(t, tracker.Observe(block(3, keyB)))
+
+	tracker.Rollback(1)
+	assert.Equal(t, 1, tracker.Len())
+
+	_, ok := tracker.Pool(mustPoolID(t, keyB))
+	assert.False(t, ok)
+}
+
+func TestIssuerTracker_Expected(t *testing.T) {
+	var (
+		poolA   = mustPoolID(t, keyA)
+		poolB   = mustPoolID(t, keyB)
+		tracker = NewIssuerTracker()
+	)
+	err := tracker.SetStakeDistribution(statequery.StakeDistribution{
+		poolA: {Stake: "1/4"},
+		poolB: {Stake: "3/4"},
+	})
+	assert.Nil(t, err)
+
+	for slot := uint64(0); slot < 4; slot++ {
+		assert.Nil(t, tracker.Observe(block(slot, keyA)))
+	}
+
+	stats, ok := tracker.Pool(poolA)
+	assert.True(t, ok)
+	assert.Equal(t, 4, stats.Blocks)
+	assert.Equal(t, 1.0, stats.Share)
+	assert.Equal(t, 1.0, stats.Expected)
+	assert.Equal(t, 4.0, stats.Ratio)
+
+	stats, ok = tracker.Pool(poolB)
+	assert.True(t, ok)
+	assert.Equal(t, 0, stats.Blocks)
+	assert.Equal(t, 3.0, stats.Expected)
+
+	err = tracker.SetStakeDistribution(statequery.StakeDistribution{
+		poolA: {Stake: "bogus"},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestIssuerTracker_ChainSyncFunc(t *testing.T) {
+	var (
+		tracker = NewIssuerTracker()
+		called  int
+		fn      = tracker.ChainSyncFunc(func(context.Context, []byte) error {
+			called++
+			return nil
+		})
+		forward = chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result: chainsync.ResultNextBlockPraos{
+				Direction: chainsync.RollForwardString,
+				Block:     block(10, keyA),
+			},
+		}
+	)
+
+	data, err := json.Marshal(forward)
+	assert.Nil(t, err)
+	assert.Nil(t, fn(context.Background(), data))
+	assert.Equal(t, 1, called)
+	assert.Equal(t, 1, tracker.Len())
+
+	point := chainsync.PointStruct{Slot: 5, ID: "id"}.Point()
+	backward := chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &point,
+		},
+	}
+	data, err = json.Marshal(backward)
+	assert.Nil(t, err)
+	assert.Nil(t, fn(context.Background(), data))
+	assert.Equal(t, 2, called)
+	assert.Equal(t, 0, tracker.Len())
+}
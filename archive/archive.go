@@ -0,0 +1,261 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive provides rotating, optionally compressed writers suitable
+// for ogmigo.WithFrameArchive
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// OpenFunc opens the named segment of an archive
+type OpenFunc func(name string) (io.WriteCloser, error)
+
+// Options configures a Writer
+type Options struct {
+	compress bool
+	maxAge   time.Duration
+	maxSize  int64
+	now      func() time.Time
+	prefix   string
+}
+
+// Option provides the functional options pattern for Writer
+type Option func(*Options)
+
+// WithGzip compresses each segment with gzip
+func WithGzip() Option {
+	return func(opts *Options) {
+		opts.compress = true
+	}
+}
+
+// WithMaxAge rotates to a new segment once the current one is older than d
+func WithMaxAge(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.maxAge = d
+	}
+}
+
+// WithMaxSize rotates to a new segment once n uncompressed bytes are written
+func WithMaxSize(n int64) Option {
+	return func(opts *Options) {
+		opts.maxSize = n
+	}
+}
+
+// WithPrefix sets the prefix of segment names; defaults to frames
+func WithPrefix(prefix string) Option {
+	return func(opts *Options) {
+		opts.prefix = prefix
+	}
+}
+
+// Writer writes to a sequence of segments, rotating by size and age.  Each
+// call to Write is written to a single segment, so frames are never split.
+// Writer is safe for concurrent use
+type Writer struct {
+	options Options
+	open    OpenFunc
+
+	mutex   sync.Mutex
+	seq     int
+	segment io.WriteCloser // underlying segment
+	gz      *gzip.Writer   // optional compression of segment
+	started time.Time
+	written int64
+	err     error
+}
+
+// New returns a Writer whose segments are opened with open
+func New(open OpenFunc, opts ...Option) *Writer {
+	options := Options{
+		now:    time.Now,
+		prefix: "frames",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Writer{
+		options: options,
+		open:    open,
+	}
+}
+
+// Write implements io.Writer
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.segment != nil && w.expired(len(p)) {
+		if err := w.closeSegment(); err != nil {
+			return 0, err
+		}
+	}
+	if w.segment == nil {
+		if err := w.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	var writer io.Writer = w.segment
+	if w.gz != nil {
+		writer = w.gz
+	}
+	n, err := writer.Write(p)
+	w.written += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return n, nil
+}
+
+// Close closes the current segment; subsequent writes fail
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err != nil {
+		return nil
+	}
+	w.err = io.ErrClosedPipe
+	if w.segment == nil {
+		return nil
+	}
+	return w.closeSegment()
+}
+
+// expired returns true if writing n more bytes requires a new segment
+func (w *Writer) expired(n int) bool {
+	if w.options.maxSize > 0 && w.written > 0 &&
+		w.written+int64(n) > w.options.maxSize {
+		return true
+	}
+	if w.options.maxAge > 0 &&
+		w.options.now().Sub(w.started) >= w.options.maxAge {
+		return true
+	}
+	return false
+}
+
+func (w *Writer) openSegment() error {
+	w.seq++
+	w.started = w.options.now()
+	w.written = 0
+
+	name := fmt.Sprintf(
+		"%v-%v-%06d.jsonl",
+		w.options.prefix,
+		w.started.UTC().Format("20060102T150405Z"),
+		w.seq,
+	)
+	if w.options.compress {
+		name += ".gz"
+	}
+
+	segment, err := w.open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open archive segment, %v: %w", name, err)
+	}
+	w.segment = segment
+	if w.options.compress {
+		w.gz = gzip.NewWriter(segment)
+	}
+	return nil
+}
+
+func (w *Writer) closeSegment() error {
+	segment, gz := w.segment, w.gz
+	w.segment, w.gz = nil, nil
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			_ = segment.Close()
+			return fmt.Errorf("failed to close archive segment: %w", err)
+		}
+	}
+	if err := segment.Close(); err != nil {
+		return fmt.Errorf("failed to close archive segment: %w", err)
+	}
+	return nil
+}
+
+// Dir returns an OpenFunc that creates segments as files within dir
+func Dir(dir string) (OpenFunc, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir, %v: %w", dir, err)
+	}
+	return func(name string) (io.WriteCloser, error) {
+		return os.OpenFile(
+			filepath.Join(dir, name),
+			os.O_CREATE|os.O_WRONLY|os.O_EXCL,
+			0o644,
+		)
+	}, nil
+}
+
+// S3API is the subset of the s3 client used by S3; satisfied by *s3.S3
+type S3API interface {
+	PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+}
+
+// S3 returns an OpenFunc that buffers each segment in memory and uploads it
+// to bucket under prefix once the segment is closed
+func S3(api S3API, bucket, prefix string) OpenFunc {
+	return func(name string) (io.WriteCloser, error) {
+		return &s3Segment{
+			api:    api,
+			bucket: bucket,
+			key:    prefix + name,
+		}, nil
+	}
+}
+
+type s3Segment struct {
+	api    S3API
+	bucket string
+	key    string
+	buf    bytes.Buffer
+}
+
+func (s *s3Segment) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+func (s *s3Segment) Close() error {
+	_, err := s.api.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+		Body:   bytes.NewReader(s.buf.Bytes()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload archive segment, %v: %w", s.key, err)
+	}
+	return nil
+}
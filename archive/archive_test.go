@@ -0,0 +1,116 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/tj/assert"
+)
+
+func TestWriter_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	open, err := Dir(dir)
+	assert.Nil(t, err)
+
+	w := New(open, WithMaxSize(10))
+	for _, frame := range []string{"{\"a\":1}\n", "{\"b\":2}\n", "{\"c\":3}\n"} {
+		_, err := w.Write([]byte(frame))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+
+	names, err := filepath.Glob(filepath.Join(dir, "frames-*.jsonl"))
+	assert.Nil(t, err)
+	assert.Len(t, names, 3)
+
+	sort.Strings(names)
+	data, err := os.ReadFile(names[1])
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"b\":2}\n", string(data))
+
+	_, err = w.Write([]byte("{}"))
+	assert.NotNil(t, err)
+}
+
+func TestWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	open, err := Dir(dir)
+	assert.Nil(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := New(open, WithMaxAge(time.Hour), WithGzip(), WithPrefix("test"))
+	w.options.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("{}\n"))
+		assert.Nil(t, err)
+		now = now.Add(30 * time.Minute)
+	}
+	assert.Nil(t, w.Close())
+
+	names, err := filepath.Glob(filepath.Join(dir, "test-*.jsonl.gz"))
+	assert.Nil(t, err)
+	assert.Len(t, names, 2)
+
+	sort.Strings(names)
+	f, err := os.Open(names[0])
+	assert.Nil(t, err)
+	//nolint:errcheck
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	data, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "{}\n{}\n", string(data))
+}
+
+type mockS3 struct {
+	objects map[string][]byte
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestS3(t *testing.T) {
+	api := &mockS3{objects: map[string][]byte{}}
+	w := New(S3(api, "bucket", "ogmios/"))
+	_, err := w.Write([]byte("{}\n"))
+	assert.Nil(t, err)
+	assert.Len(t, api.objects, 0)
+	assert.Nil(t, w.Close())
+
+	assert.Len(t, api.objects, 1)
+	for key, data := range api.objects {
+		assert.True(t, bytes.HasPrefix([]byte(key), []byte("bucket/ogmios/frames-")))
+		assert.Equal(t, "{}\n", string(data))
+	}
+}
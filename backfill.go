@@ -0,0 +1,375 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+	"golang.org/x/sync/errgroup"
+)
+
+// Shard is a contiguous range of the chain synced independently by Backfill
+type Shard struct {
+	ID     string          // ID uniquely identifies the shard
+	From   chainsync.Point // From is the point the shard intersects at
+	ToSlot uint64          // ToSlot is the inclusive end; 0 syncs to the tip
+}
+
+// ShardsFromPoints splits the chain into shards bounded by the given points.
+// The final shard syncs to the tip
+func ShardsFromPoints(points ...chainsync.Point) []Shard {
+	points = append(chainsync.Points(nil), points...)
+	sort.Sort(sort.Reverse(chainsync.Points(points)))
+
+	var shards []Shard
+	for i, point := range points {
+		shard := Shard{
+			ID:   strconv.Itoa(i),
+			From: point,
+		}
+		if i+1 < len(points) {
+			if ps, ok := points[i+1].PointStruct(); ok {
+				shard.ToSlot = ps.Slot
+			}
+		}
+		shards = append(shards, shard)
+	}
+	return shards
+}
+
+// ShardProgress records how far a shard has been synced
+type ShardProgress struct {
+	Point    chainsync.Point `json:"point"`
+	Complete bool            `json:"complete,omitempty"`
+}
+
+// BackfillProgress persists shard progress to allow Backfill to resume
+type BackfillProgress interface {
+	// Load the progress for the shard; ok is false if none has been saved
+	Load(ctx context.Context, shard string) (ShardProgress, bool, error)
+	// Save the progress for the shard
+	Save(ctx context.Context, shard string, progress ShardProgress) error
+}
+
+// BackfillFunc receives each json encoded chainsync.Response for a shard
+type BackfillFunc func(ctx context.Context, shard Shard, data []byte) error
+
+// BackfillFlushFunc is invoked before progress is saved; data delivered to
+// BackfillFunc must be durable once it returns
+type BackfillFlushFunc func(ctx context.Context, shard Shard) error
+
+// BackfillOptions configuration parameters
+type BackfillOptions struct {
+	concurrency int               // number of shards synced concurrently
+	flush       BackfillFlushFunc // flush prior to saving progress
+	progress    BackfillProgress  // persists shard progress
+}
+
+// BackfillOption provides functional options for Backfill
+type BackfillOption func(opts *BackfillOptions)
+
+// WithConcurrency sets the number of shards synced at once; defaults to 4
+func WithConcurrency(n int) BackfillOption {
+	return func(opts *BackfillOptions) {
+		opts.concurrency = n
+	}
+}
+
+// WithFlush specifies a func invoked before each progress checkpoint
+func WithFlush(fn BackfillFlushFunc) BackfillOption {
+	return func(opts *BackfillOptions) {
+		opts.flush = fn
+	}
+}
+
+// WithProgress specifies where shard progress is persisted; defaults to none
+func WithProgress(progress BackfillProgress) BackfillOption {
+	return func(opts *BackfillOptions) {
+		opts.progress = progress
+	}
+}
+
+func buildBackfillOptions(opts ...BackfillOption) BackfillOptions {
+	var options BackfillOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.concurrency <= 0 {
+		options.concurrency = 4
+	}
+	if options.progress == nil {
+		options.progress = nopProgress{}
+	}
+	return options
+}
+
+// errShardComplete signals the shard has reached its end
+var errShardComplete = errors.New("shard complete")
+
+// Backfill performs a sharded historical sync, invoking the callback for
+// every message within each shard.  Progress is checkpointed every
+// WithInterval messages and once each shard completes, so an interrupted
+// Backfill resumes from its last checkpoint.  Delivery is at least once;
+// messages after the last checkpoint may be delivered again on resume.
+func (c *Client) Backfill(
+	ctx context.Context,
+	shards []Shard,
+	callback BackfillFunc,
+	opts ...BackfillOption,
+) error {
+	options := buildBackfillOptions(opts...)
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(options.concurrency)
+	for _, shard := range shards {
+		group.Go(func() error {
+			if err := c.backfillShard(ctx, shard, callback, options); err != nil {
+				return fmt.Errorf("backfill shard %v failed: %w", shard.ID, err)
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+func (c *Client) backfillShard(
+	ctx context.Context,
+	shard Shard,
+	callback BackfillFunc,
+	options BackfillOptions,
+) error {
+	progress, ok, err := options.progress.Load(ctx, shard.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load progress: %w", err)
+	}
+	if ok && progress.Complete {
+		return nil
+	}
+
+	from := shard.From
+	if ok {
+		from = progress.Point
+	}
+	logger := c.options.logger.With(KV("shard", shard.ID))
+	logger.Info("backfill shard started", KV("from", from.String()))
+
+	var (
+		last    chainsync.Point
+		pending bool
+		n       uint64
+	)
+	checkpoint := func(ctx context.Context, complete bool) error {
+		if !pending && !complete {
+			return nil
+		}
+		if options.flush != nil {
+			if err := options.flush(ctx, shard); err != nil {
+				return fmt.Errorf("failed to flush: %w", err)
+			}
+		}
+		if !pending {
+			last = from
+		}
+		progress := ShardProgress{Point: last, Complete: complete}
+		if err := options.progress.Save(ctx, shard.ID, progress); err != nil {
+			return fmt.Errorf("failed to save progress: %w", err)
+		}
+		pending = false
+		return nil
+	}
+
+	var fn ChainSyncFunc = func(ctx context.Context, data []byte) error {
+		point, slot, tip, ok := getBackfillPoint(data)
+		if !ok {
+			return nil // findIntersection
+		}
+		if shard.ToSlot > 0 && slot > shard.ToSlot {
+			return errShardComplete
+		}
+		if err := callback(ctx, shard, data); err != nil {
+			return err
+		}
+		last, pending = point, true
+
+		end := shard.ToSlot
+		if end == 0 {
+			end = tip
+		}
+		if end > 0 && slot >= end {
+			return errShardComplete
+		}
+		if n++; n%c.options.saveInterval == 0 {
+			return checkpoint(ctx, false)
+		}
+		return nil
+	}
+
+	chainSync, err := c.ChainSync(ctx, fn, WithPoints(from))
+	if err != nil {
+		return err
+	}
+	<-chainSync.Done()
+	err = chainSync.Close()
+
+	switch {
+	case errors.Is(err, errShardComplete):
+		logger.Info("backfill shard complete")
+		return checkpoint(context.Background(), true)
+	case err == nil && ctx.Err() != nil:
+		if err := checkpoint(context.Background(), false); err != nil {
+			return err
+		}
+		return ctx.Err()
+	case err != nil:
+		return err
+	default:
+		return checkpoint(context.Background(), false)
+	}
+}
+
+// getBackfillPoint extracts the point and slot of a nextBlock response along
+// with the slot of the current tip
+func getBackfillPoint(data []byte) (chainsync.Point, uint64, uint64, bool) {
+	method, _ := jsonparser.GetString(data, "method")
+	if method != chainsync.NextBlockMethod {
+		return chainsync.Point{}, 0, 0, false
+	}
+
+	tip, _ := jsonparser.GetInt(data, "result", "tip", "slot")
+	direction, _ := jsonparser.GetString(data, "result", "direction")
+	switch direction {
+	case chainsync.RollForwardString:
+		slot, err := jsonparser.GetInt(data, "result", "block", "slot")
+		if err != nil {
+			return chainsync.Point{}, 0, 0, false
+		}
+		id, _ := jsonparser.GetString(data, "result", "block", "id")
+		point := chainsync.PointStruct{ID: id, Slot: uint64(slot)}.Point()
+		return point, uint64(slot), uint64(tip), true
+
+	case chainsync.RollBackwardString:
+		raw, dataType, _, err := jsonparser.Get(data, "result", "point")
+		if err != nil {
+			return chainsync.Point{}, 0, 0, false
+		}
+		if dataType == jsonparser.String {
+			return chainsync.Origin, 0, uint64(tip), true
+		}
+		var point chainsync.Point
+		if err := json.Unmarshal(raw, &point); err != nil {
+			return chainsync.Point{}, 0, 0, false
+		}
+		var slot uint64
+		if ps, ok := point.PointStruct(); ok {
+			slot = ps.Slot
+		}
+		return point, slot, uint64(tip), true
+	}
+	return chainsync.Point{}, 0, 0, false
+}
+
+type nopProgress struct{}
+
+func (nopProgress) Load(context.Context, string) (ShardProgress, bool, error) {
+	return ShardProgress{}, false, nil
+}
+
+func (nopProgress) Save(context.Context, string, ShardProgress) error {
+	return nil
+}
+
+// FileProgress persists shard progress as a json file
+type FileProgress struct {
+	mutex  sync.Mutex
+	path   string
+	shards map[string]ShardProgress
+}
+
+// NewFileProgress returns a BackfillProgress backed by the file at path.
+// Existing progress, if any, is loaded so Backfill may resume
+func NewFileProgress(path string) (*FileProgress, error) {
+	shards := map[string]ShardProgress{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read progress, %v: %w", path, err)
+	default:
+		if err := json.Unmarshal(data, &shards); err != nil {
+			return nil, fmt.Errorf("failed to decode progress, %v: %w", path, err)
+		}
+	}
+
+	return &FileProgress{
+		path:   path,
+		shards: shards,
+	}, nil
+}
+
+// Load implements BackfillProgress
+func (f *FileProgress) Load(
+	_ context.Context,
+	shard string,
+) (ShardProgress, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	progress, ok := f.shards[shard]
+	return progress, ok, nil
+}
+
+// Save implements BackfillProgress; the file is replaced atomically
+func (f *FileProgress) Save(
+	_ context.Context,
+	shard string,
+	progress ShardProgress,
+) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.shards[shard] = progress
+	data, err := json.MarshalIndent(f.shards, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".progress-*")
+	if err != nil {
+		return fmt.Errorf("failed to save progress: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to save progress: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save progress: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to save progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestShardsFromPoints(t *testing.T) {
+	p10 := chainsync.PointStruct{ID: "block10", Slot: 10}.Point()
+	p20 := chainsync.PointStruct{ID: "block20", Slot: 20}.Point()
+
+	shards := ShardsFromPoints(p20, chainsync.Origin, p10)
+	assert.Len(t, shards, 3)
+	assert.Equal(t, chainsync.Origin, shards[0].From)
+	assert.EqualValues(t, 10, shards[0].ToSlot)
+	assert.Equal(t, p10, shards[1].From)
+	assert.EqualValues(t, 20, shards[1].ToSlot)
+	assert.Equal(t, p20, shards[2].From)
+	assert.EqualValues(t, 0, shards[2].ToSlot)
+}
+
+func TestFileProgress(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		path  = filepath.Join(t.TempDir(), "progress.json")
+		point = chainsync.PointStruct{ID: "block10", Slot: 10}.Point()
+	)
+
+	progress, err := NewFileProgress(path)
+	assert.Nil(t, err)
+	_, ok, err := progress.Load(ctx, "0")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	err = progress.Save(ctx, "0", ShardProgress{Point: point, Complete: true})
+	assert.Nil(t, err)
+
+	progress, err = NewFileProgress(path)
+	assert.Nil(t, err)
+	got, ok, err := progress.Load(ctx, "0")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, got.Complete)
+	assert.Equal(t, point.String(), got.Point.String())
+}
+
+func TestClient_Backfill(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		endpoint = chainSyncServer(t, 30)
+		client   = New(WithEndpoint(endpoint), WithLogger(NopLogger), WithInterval(5))
+		p10      = chainsync.PointStruct{ID: "block10", Slot: 10}.Point()
+		p20      = chainsync.PointStruct{ID: "block20", Slot: 20}.Point()
+		shards   = ShardsFromPoints(chainsync.Origin, p10, p20)
+		mutex    sync.Mutex
+		slots    = map[uint64]int{}
+		flushes  int
+	)
+
+	progress, err := NewFileProgress(filepath.Join(t.TempDir(), "progress.json"))
+	assert.Nil(t, err)
+
+	callback := func(_ context.Context, shard Shard, data []byte) error {
+		point, slot, _, ok := getBackfillPoint(data)
+		assert.True(t, ok)
+		if _, ok := point.PointStruct(); ok && slot > 0 {
+			mutex.Lock()
+			slots[slot]++
+			mutex.Unlock()
+		}
+		return nil
+	}
+	flush := func(context.Context, Shard) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		flushes++
+		return nil
+	}
+
+	err = client.Backfill(ctx, shards, callback,
+		WithProgress(progress),
+		WithFlush(flush),
+		WithConcurrency(2),
+	)
+	assert.Nil(t, err)
+
+	for slot := uint64(1); slot <= 30; slot++ {
+		// shard boundaries are delivered by both shards as the rollback point
+		want := 1
+		if slot == 10 || slot == 20 {
+			want = 2
+		}
+		assert.Equal(t, want, slots[slot], "slot %v", slot)
+	}
+	assert.True(t, flushes >= len(shards))
+
+	for _, shard := range shards {
+		got, ok, err := progress.Load(ctx, shard.ID)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.True(t, got.Complete)
+	}
+
+	// resuming a completed backfill delivers nothing
+	slots = map[uint64]int{}
+	err = client.Backfill(ctx, shards, callback, WithProgress(progress))
+	assert.Nil(t, err)
+	assert.Len(t, slots, 0)
+}
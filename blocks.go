@@ -0,0 +1,89 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// BlocksChan streams typed roll forward and roll backward events from the
+// given points, or origin if none are given, via a channel buffered to
+// WithPipeline.  The channel is closed once ctx is cancelled or chain sync
+// stops; if chain sync failed, the final event carries the error in Err
+func (c *Client) BlocksChan(
+	ctx context.Context,
+	points ...chainsync.Point,
+) (<-chan chainsync.BlockEvent, error) {
+	decode := func(data []byte) (chainsync.BlockEvent, bool, error) {
+		return chainsync.DecodeBlockEvent(data, c.options.codec.Unmarshal)
+	}
+	return c.blocksChan(ctx, decode, points...)
+}
+
+// HeadersChan is BlocksChan, but each Block carries only the header; the
+// transactions are neither decoded nor delivered
+func (c *Client) HeadersChan(
+	ctx context.Context,
+	points ...chainsync.Point,
+) (<-chan chainsync.BlockEvent, error) {
+	return c.blocksChan(ctx, chainsync.NewHeaderEvent, points...)
+}
+
+func (c *Client) blocksChan(
+	ctx context.Context,
+	decode func(data []byte) (chainsync.BlockEvent, bool, error),
+	points ...chainsync.Point,
+) (<-chan chainsync.BlockEvent, error) {
+	ch := make(chan chainsync.BlockEvent, c.options.pipeline)
+
+	var callback ChainSyncFunc = func(ctx context.Context, data []byte) error {
+		event, ok, err := decode(data)
+		if err != nil || !ok {
+			return err
+		}
+
+		select {
+		case ch <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	chainSync, err := c.ChainSync(ctx, callback, WithPoints(points...))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(ch)
+
+		<-chainSync.Done()
+		err := chainSync.Close()
+		if err == nil || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case ch <- chainsync.BlockEvent{Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return ch, nil
+}
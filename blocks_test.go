@@ -0,0 +1,136 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestClient_BlocksChan(t *testing.T) {
+	endpoint := chainSyncServer(t, 5)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := client.BlocksChan(ctx)
+	assert.Nil(t, err)
+
+	event := <-events
+	assert.Equal(t, chainsync.RollBackwardEvent, event.Type)
+	assert.Equal(t, chainsync.Origin, event.Point)
+
+	for slot := uint64(1); slot <= 5; slot++ {
+		event := <-events
+		assert.Nil(t, event.Err)
+		assert.Equal(t, chainsync.RollForwardEvent, event.Type)
+		assert.Equal(t, slot, event.Block.Slot)
+
+		ps, ok := event.Point.PointStruct()
+		assert.True(t, ok)
+		assert.Equal(t, slot, ps.Slot)
+		assert.EqualValues(t, 5, event.Tip.Slot)
+	}
+
+	cancel()
+	for event := range events {
+		t.Fatalf("got %v; want closed channel", event)
+	}
+}
+
+func TestClient_BlocksChanError(t *testing.T) {
+	client := New(WithEndpoint("ws://127.0.0.1:1"), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := client.BlocksChan(ctx)
+	assert.Nil(t, err)
+
+	event, ok := <-events
+	assert.True(t, ok)
+	assert.NotNil(t, event.Err)
+
+	_, ok = <-events
+	assert.False(t, ok)
+}
+
+func TestClient_Headers(t *testing.T) {
+	endpoint := chainSyncServer(t, 3)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var slots []uint64
+	for event, err := range client.Headers(ctx) {
+		assert.Nil(t, err)
+		if event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+		if len(slots) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+}
+
+func TestStripTransactions(t *testing.T) {
+	var (
+		data = []byte(`{"method":"nextBlock","result":{"direction":"forward",` +
+			`"block":{"id":"abc","transactions":[{"id":"tx"}],"slot":1}}}`)
+		original = string(data)
+		got      []byte
+	)
+	fn := stripTransactions(func(_ context.Context, data []byte) error {
+		got = data
+		return nil
+	})
+	assert.Nil(t, fn(context.Background(), data))
+	assert.JSONEq(t,
+		`{"method":"nextBlock","result":{"direction":"forward","block":{"id":"abc","slot":1}}}`,
+		string(got),
+	)
+	assert.Equal(t, original, string(data))
+}
+
+func TestRequireBlockCBOR(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		called int
+		fn     = requireBlockCBOR(func(context.Context, []byte) error {
+			called++
+			return nil
+		})
+	)
+
+	data := []byte(`{"method":"nextBlock","result":{"direction":"forward","block":{"id":"abc","cbor":"82"}}}`)
+	assert.Nil(t, fn(ctx, data))
+
+	data = []byte(`{"method":"nextBlock","result":{"direction":"backward","point":"origin"}}`)
+	assert.Nil(t, fn(ctx, data))
+	assert.Equal(t, 2, called)
+
+	data = []byte(`{"method":"nextBlock","result":{"direction":"forward","block":{"id":"abc"}}}`)
+	err := fn(ctx, data)
+	assert.True(t, errors.Is(err, chainsync.ErrNoBlockCBOR))
+	assert.Equal(t, 2, called)
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrCircuitOpen is returned, wrapped, by queries to an endpoint whose circuit
+// breaker has tripped as configured via WithCircuitBreaker
+var ErrCircuitOpen = errors.New("ogmigo: circuit open")
+
+// defaultCooldown is how long a tripped circuit fails fast unless overridden
+const defaultCooldown = 30 * time.Second
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker trips after threshold consecutive failures of an endpoint and fails
+// fast until cooldown elapses.  A single probe is then admitted; its success
+// closes the circuit, its failure reopens it for another cooldown
+type breaker struct {
+	endpoint  string
+	threshold int
+	cooldown  time.Duration
+	logger    Logger
+	now       func() time.Time
+
+	mutex    sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(endpoint string, options Options) *breaker {
+	return &breaker{
+		endpoint:  endpoint,
+		threshold: options.breakerThreshold,
+		cooldown:  options.breakerCooldown,
+		logger:    options.logger,
+		now:       time.Now,
+	}
+}
+
+// allow returns an error wrapping ErrCircuitOpen if the request must fail fast
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if wait := b.cooldown - b.now().Sub(b.openedAt); wait > 0 {
+			return fmt.Errorf("%w: %v, retry in %v", ErrCircuitOpen, b.endpoint,
+				wait.Round(time.Millisecond))
+		}
+		b.state = breakerHalfOpen
+		fallthrough
+
+	case breakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w: %v, awaiting probe", ErrCircuitOpen, b.endpoint)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record the outcome of a request admitted by allow.  Requests abandoned by
+// the caller neither trip nor close the circuit
+func (b *breaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	probe := b.state == breakerHalfOpen
+	if probe {
+		b.probing = false
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// abandoned
+
+	case isEndpointFailure(err):
+		b.failures++
+		if probe || b.failures >= b.threshold {
+			if b.state != breakerOpen {
+				b.logger.Warn("ogmios circuit breaker opened",
+					KV("endpoint", b.endpoint),
+					Int64("failures", int64(b.failures)),
+					Err(err),
+				)
+			}
+			b.state, b.openedAt = breakerOpen, b.now()
+		}
+
+	default:
+		if b.state != breakerClosed {
+			b.logger.Info("ogmios circuit breaker closed", KV("endpoint", b.endpoint))
+		}
+		b.state, b.failures = breakerClosed, 0
+	}
+}
+
+// isEndpointFailure returns true for failures indicating the endpoint itself
+// is unhealthy, as opposed to errors reported by a responsive ogmios
+func isEndpointFailure(err error) bool {
+	var (
+		timeoutErr *QueryTimeoutError
+		closeErr   *websocket.CloseError
+		opErr      *net.OpError
+		pongErr    *PongTimeoutError
+	)
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &timeoutErr), errors.As(err, &closeErr),
+		errors.As(err, &opErr), errors.As(err, &pongErr):
+		return true
+	case errors.Is(err, websocket.ErrBadHandshake):
+		return true
+	default:
+		return false
+	}
+}
+
+// breaker returns the circuit breaker of the active endpoint, or nil if
+// WithCircuitBreaker was not specified
+func (c *Client) breaker() *breaker {
+	return c.breakers[c.endpoint()]
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		now     = time.Unix(0, 0)
+		failure = &net.OpError{Op: "dial", Err: errors.New("refused")}
+		b       = newBreaker("ws://ogmios", buildOptions(
+			WithCircuitBreaker(2, time.Minute),
+			WithLogger(NopLogger),
+		))
+	)
+	b.now = func() time.Time { return now }
+
+	// responsive ogmios errors do not count
+	assert.Nil(t, b.allow())
+	b.record(ctx, &RPCError{Code: -32602})
+	assert.Nil(t, b.allow())
+	b.record(ctx, failure)
+	assert.Nil(t, b.allow())
+	b.record(ctx, failure)
+
+	err := b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// a single probe is admitted once the cooldown elapses
+	now = now.Add(time.Minute)
+	assert.Nil(t, b.allow())
+	assert.True(t, errors.Is(b.allow(), ErrCircuitOpen))
+	b.record(ctx, failure)
+	assert.True(t, errors.Is(b.allow(), ErrCircuitOpen))
+
+	// an abandoned probe neither trips nor closes the circuit
+	now = now.Add(time.Minute)
+	assert.Nil(t, b.allow())
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	b.record(cancelled, context.Canceled)
+	assert.Nil(t, b.allow())
+	b.record(ctx, nil)
+
+	assert.Equal(t, breakerClosed, b.state)
+	assert.Nil(t, b.allow())
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	client := New(
+		WithEndpoint("ws://127.0.0.1:1"),
+		WithLogger(NopLogger),
+		WithCircuitBreaker(2, time.Hour),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.Request(ctx, "queryNetwork/tip", nil)
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	_, err := client.Request(ctx, "queryNetwork/tip", nil)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	client = New(WithEndpoint("ws://127.0.0.1:1"), WithLogger(NopLogger))
+	assert.Nil(t, client.breaker())
+}
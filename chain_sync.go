@@ -22,22 +22,27 @@ import (
 	"io"
 	"net"
 	"os"
-	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
 	"github.com/gorilla/websocket"
 	"golang.org/x/sync/errgroup"
 )
 
 // ChainSync provides control over a given ChainSync connection
 type ChainSync struct {
-	cancel context.CancelFunc
-	errs   chan error
-	done   chan struct{}
-	err    error
-	logger Logger
+	cancel    context.CancelFunc
+	drain     chan struct{} // closed to begin a graceful shutdown
+	drainOnce sync.Once
+	errs      chan error
+	done      chan struct{}
+	err       error
+	logger    Logger
+	meter     *progressMeter
+	state     *syncState
 }
 
 // Done indicates the ChainSync has terminated prematurely
@@ -61,15 +66,57 @@ func (c *ChainSync) Close() error {
 	return c.err
 }
 
+// CloseWithContext shuts the ChainSync down gracefully: no further blocks are
+// requested, in-flight callbacks complete, the point of the final message
+// delivered is saved to the store, and the connection is closed with a close
+// frame.  Should ctx expire first, the ChainSync is closed as per Close and the
+// ctx error is returned unless chain sync failed
+func (c *ChainSync) CloseWithContext(ctx context.Context) error {
+	c.drainOnce.Do(func() { close(c.drain) })
+
+	select {
+	case <-c.done:
+		return c.Close()
+	case <-ctx.Done():
+		c.cancel()
+		<-c.done
+		if err := c.Close(); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+}
+
+// errStopConditionMet signals WithStopAtSlot or WithStopWhenTipReached is met
+var errStopConditionMet = errors.New("chainsync stop condition met")
+
+// errDrained signals a graceful shutdown via CloseWithContext has completed
+var errDrained = errors.New("chainsync drained")
+
 // ChainSyncFunc callback containing json encoded chainsync.Response
 type ChainSyncFunc func(ctx context.Context, data []byte) error
 
 // ChainSyncOptions configuration parameters
 type ChainSyncOptions struct {
-	minSlot   uint64           // minSlot to begin invoking ChainSyncFunc; 0 for always invoke func
-	points    chainsync.Points // points to attempt initial intersection
-	reconnect bool             // reconnect to ogmios if connection drops
-	store     Store            // store of points
+	adaptive     *adaptivePipeline // adaptive pipeline bounds; nil for fixed
+	concurrency  int               // concurrency of callbacks when ordering is relaxed
+	fallback     *fallbackOptions  // fallback when points are not found; nil for none
+	blockCBOR    bool              // fail if a block arrives without cbor
+	headersOnly  bool              // strip transactions before delivery
+	interceptors []Interceptor     // interceptors wrapping the callback
+	minSlot      uint64            // minSlot to begin invoking ChainSyncFunc; 0 for always invoke func
+	ordering     OrderingMode      // ordering guarantees for callback delivery
+	points       chainsync.Points  // points to attempt initial intersection
+	prepare      PrepareFunc       // prepares messages when workers > 0
+	reconnect    bool              // reconnect to ogmios if connection drops
+	report       *progressReport   // periodic progress reports; nil for none
+	state        *ChainSyncState   // state to resume from
+	stateStore   *stateStore       // persists state; nil for none
+	stopAtSlot   uint64            // stop once this slot is reached; 0 for never
+	stopAtTip    bool              // stop once the tip is reached
+	store        Store             // store of points
+	watchdog     *watchdog         // monitors lag and stalls; nil for none
+	workers      int               // workers preparing messages; 0 for none
 }
 
 func buildChainSyncOptions(opts ...ChainSyncOption) ChainSyncOptions {
@@ -86,6 +133,35 @@ func buildChainSyncOptions(opts ...ChainSyncOption) ChainSyncOptions {
 // ChainSyncOption provides functional options for ChainSync
 type ChainSyncOption func(opts *ChainSyncOptions)
 
+// WithCallbackConcurrency sets the number of callbacks that may run at once
+// when used with OrderingRelaxed; defaults to 1
+func WithCallbackConcurrency(n int) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.concurrency = n
+	}
+}
+
+// WithBlockCBOR requires every block to carry its serialized cbor, exposed as
+// Block.CBOR.  The ogmios v6 protocol has no per-request flag for this; the
+// cbor is only included when the ogmios server is configured to include it,
+// so WithBlockCBOR fails chain sync with ErrNoBlockCBOR on the first block
+// without it rather than silently delivering incomplete blocks
+func WithBlockCBOR() ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.blockCBOR = true
+	}
+}
+
+// WithHeadersOnly removes the transactions from each block before the
+// message reaches the callback, so callbacks that decode the message decode
+// only the block header.  Ogmios still sends complete blocks; this saves the
+// cost of decoding transaction bodies, not bandwidth
+func WithHeadersOnly() ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.headersOnly = true
+	}
+}
+
 // WithMinSlot ignores any activity prior to the specified slot
 func WithMinSlot(slot uint64) ChainSyncOption {
 	return func(opts *ChainSyncOptions) {
@@ -93,6 +169,14 @@ func WithMinSlot(slot uint64) ChainSyncOption {
 	}
 }
 
+// WithOrdering specifies the ordering guarantees for callback delivery;
+// defaults to OrderingStrict
+func WithOrdering(mode OrderingMode) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.ordering = mode
+	}
+}
+
 // WithPoints allows starting from an optional point
 func WithPoints(points ...chainsync.Point) ChainSyncOption {
 	return func(opts *ChainSyncOptions) {
@@ -107,6 +191,24 @@ func WithReconnect(enabled bool) ChainSyncOption {
 	}
 }
 
+// WithStopAtSlot stops ChainSync once the block at slot, or the first block
+// beyond it, is reached.  Blocks beyond slot are not delivered.  ChainSync then
+// saves its final point and Close returns nil
+func WithStopAtSlot(slot uint64) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.stopAtSlot = slot
+	}
+}
+
+// WithStopWhenTipReached stops ChainSync once the block at the tip, as of
+// that message, has been delivered.  ChainSync then saves its final point and
+// Close returns nil
+func WithStopWhenTipReached() ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.stopAtTip = true
+	}
+}
+
 // WithStore specifies store to persist points to; defaults to no persistence
 func WithStore(store Store) ChainSyncOption {
 	return func(opts *ChainSyncOptions) {
@@ -123,31 +225,79 @@ func (c *Client) ChainSync(
 	opts ...ChainSyncOption,
 ) (*ChainSync, error) {
 	options := buildChainSyncOptions(opts...)
+	if options.stateStore != nil && options.state == nil {
+		saved, ok, err := options.stateStore.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			options.state = &saved
+		}
+	}
+	callback = wrapCallback(callback, options)
+	state := newSyncState(options, c.failoverEnabled())
+	if options.stateStore != nil {
+		options.store = options.stateStore.wrap(options.store, state)
+	}
 
 	done := make(chan struct{})
+	drain := make(chan struct{})
 	errs := make(chan error, 1)
+	meter := newProgressMeter()
 	ctx, cancel := context.WithCancel(ctx)
 
+	if report := options.report; report != nil {
+		go meter.report(ctx, done, report.interval, report.fn)
+	}
+
 	go func() {
 		defer close(done)
 
 		var (
-			timeout = 10 * time.Second
-			err     error
+			timeout  = 10 * time.Second
+			err      error
+			progress uint64 // state sequence as of the last failure
+			failures int    // consecutive failures without progress
 		)
 		for {
-			err = c.doChainSync(ctx, callback, options)
+			err = c.doChainSync(ctx, callback, options, state, meter, drain)
+			if errors.Is(err, errStopConditionMet) || errors.Is(err, errDrained) {
+				err = nil
+				break
+			}
+			if errors.Is(err, errFellBehind) {
+				continue // watchLag has already failed over
+			}
+			if err != nil && c.failoverEnabled() && isTemporaryError(err) {
+				// fail over immediately unless every endpoint has failed in turn
+				if sequence := state.snapshot().Sequence; sequence != progress {
+					progress, failures = sequence, 0
+				}
+				if failures++; failures < len(c.options.endpoints) {
+					next := int(atomic.LoadInt64(&c.active)) + 1
+					c.failover(next, err.Error())
+					continue
+				}
+				failures = 0
+			}
+			var we *WatchdogError
+			if errors.As(err, &we) {
+				c.options.logger.Warn("watchdog forced reconnect", Err(err))
+				continue
+			}
 			if err != nil && isTemporaryError(err) {
-				if options.reconnect {
-					c.options.logger.Info(
+				if options.reconnect || c.failoverEnabled() {
+					c.options.logger.Warn(
 						"websocket connection error: will retry",
-						KV("delay", timeout.Round(time.Millisecond).String()),
-						KV("err", err.Error()),
+						Duration("delay", timeout.Round(time.Millisecond)),
+						Err(err),
 					)
 
 					select {
 					case <-ctx.Done():
 						return
+					case <-drain:
+						err = nil
 					case <-time.After(timeout):
 						continue
 					}
@@ -156,14 +306,20 @@ func (c *Client) ChainSync(
 
 			break
 		}
+		if err != nil && ctx.Err() == nil {
+			c.options.logger.Error("ogmigo chainsync failed", Err(err))
+		}
 		errs <- err
 	}()
 
 	return &ChainSync{
 		cancel: cancel,
+		drain:  drain,
 		errs:   errs,
 		done:   done,
 		logger: c.logger,
+		meter:  meter,
+		state:  state,
 	}, nil
 }
 
@@ -171,22 +327,82 @@ func (c *Client) doChainSync(
 	ctx context.Context,
 	callback ChainSyncFunc,
 	options ChainSyncOptions,
+	state *syncState,
+	meter *progressMeter,
+	drain <-chan struct{},
 ) error {
-	conn, _, err := websocket.DefaultDialer.Dial(c.options.endpoint, nil)
+	select {
+	case <-drain:
+		return errDrained
+	default:
+	}
+
+	protocol, err := c.Protocol(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := c.dial(ctx)
 	if err != nil {
 		return fmt.Errorf(
 			"failed to connect to ogmios, %v: %w",
-			c.options.endpoint,
+			c.endpoint(),
 			err,
 		)
 	}
+	keepalive := startKeepAlive(conn, c.options.keepAlive, c.options.keepTimeout)
+	defer keepalive.stop()
 
-	init, err := getInit(ctx, options.store, options.points...)
-	if err != nil {
-		return fmt.Errorf("failed to create init message: %w", err)
+	store, points := options.store, options.points
+	if resume, ok := state.points(); ok {
+		store, points = nopStore{}, resume
+	}
+	next := []byte(`{"jsonrpc":"2.0","method":"nextBlock","id":{}}`)
+	if protocol == ProtocolV5 {
+		next = requestNextV5
+	}
+
+	// with a fallback, negotiate the intersection before pipelining requests
+	// and deliver the successful response as the first message
+	var init, intersection []byte
+	if options.fallback != nil {
+		candidates, err := getIntersectPoints(ctx, store, points...)
+		if err != nil {
+			return fmt.Errorf("failed to create init message: %w", err)
+		}
+		intersection, err = c.intersect(
+			ctx, conn, protocol, candidates, options.fallback,
+		)
+		if err != nil {
+			_ = conn.Close()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	} else {
+		if init, err = getInit(ctx, store, points...); err != nil {
+			return fmt.Errorf("failed to create init message: %w", err)
+		}
+		if protocol == ProtocolV5 {
+			if init, err = encodeInitV5(init); err != nil {
+				return fmt.Errorf("failed to create init message: %w", err)
+			}
+		}
 	}
+	state.connected()
 
 	group, ctx := errgroup.WithContext(ctx)
+	var tip uint64 // atomic; slot of the tip as last reported
+	if c.failoverEnabled() {
+		group.Go(func() error { return c.watchLag(ctx, &tip) })
+	}
+	if options.watchdog != nil {
+		connected := time.Now()
+		group.Go(func() error {
+			return options.watchdog.watch(ctx, meter, connected)
+		})
+	}
 	group.Go(func() error {
 		c.options.logger.Info("ogmigo chainsync started")
 		defer c.options.logger.Info("ogmigo chainsync stopped")
@@ -205,9 +421,31 @@ func (c *Client) doChainSync(
 		return nil
 	})
 
+	// on drain, stop requesting blocks and unblock the reader so it may wind
+	// down gracefully
+	var draining int32 // atomic; 1 once drain has begun
+	group.Go(func() error {
+		select {
+		case <-ctx.Done():
+		case <-drain:
+			atomic.StoreInt32(&draining, 1)
+			_ = conn.SetReadDeadline(time.Now())
+		}
+		return nil
+	})
+
 	// prime the pump
-	ch := make(chan struct{}, 64)
-	for range c.options.pipeline {
+	var (
+		adaptive *adaptivePipeline
+		depth    = c.options.pipeline
+		capacity = 64
+	)
+	if options.adaptive != nil {
+		adaptive, depth = options.adaptive.reset()
+		capacity = max(capacity, adaptive.max)
+	}
+	ch := make(chan struct{}, capacity)
+	for range depth {
 		select {
 		case ch <- struct{}{}:
 		default:
@@ -215,21 +453,24 @@ func (c *Client) doChainSync(
 	}
 
 	group.Go(func() error {
-		if err := conn.WriteMessage(websocket.TextMessage, init); err != nil {
-			var oe *net.OpError
-			if ok := errors.As(err, &oe); ok {
-				if v := atomic.LoadInt64(&connState); v > 0 {
-					return nil // connection closed
+		if init != nil {
+			if err := conn.WriteMessage(websocket.TextMessage, init); err != nil {
+				var oe *net.OpError
+				if ok := errors.As(err, &oe); ok {
+					if v := atomic.LoadInt64(&connState); v > 0 {
+						return nil // connection closed
+					}
 				}
+				return fmt.Errorf("failed to write FindIntersect: %w", err)
 			}
-			return fmt.Errorf("failed to write FindIntersect: %w", err)
 		}
 
-		next := []byte(`{"jsonrpc":"2.0","method":"nextBlock","id":{}}`)
 		for {
 			select {
 			case <-ctx.Done():
 				return nil
+			case <-drain:
+				return nil
 			case <-ch:
 				if err := conn.WriteMessage(websocket.TextMessage, next); err != nil {
 					return fmt.Errorf("failed to write RequestNext: %w", err)
@@ -239,10 +480,59 @@ func (c *Client) doChainSync(
 	})
 
 	group.Go(func() error {
+		dispatcher := newDispatcher(callback, options)
+		//nolint:errcheck
+		defer dispatcher.close()
+
 		checkSlot := options.minSlot > 0
 		last := newCircular(3)
+		var delivered []byte // most recent message dispatched
+
+		// stop winds down once a stop condition has been met, saving the
+		// point of the final message delivered
+		stop := func() error {
+			if err := dispatcher.barrier(); err != nil {
+				return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+			}
+			if point, ok := getPoint(delivered); ok {
+				if err := options.store.Save(context.Background(), point); err != nil {
+					return fmt.Errorf("chainsync client failed: %w", err)
+				}
+			}
+			c.options.logger.Info("ogmigo chainsync stop condition reached")
+			return errStopConditionMet
+		}
+
+		// drained completes a graceful shutdown; messages read once draining
+		// has begun are discarded
+		drained := func() error {
+			if err := dispatcher.barrier(); err != nil {
+				return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+			}
+			if point, ok := getPoint(delivered); ok {
+				if err := options.store.Save(context.Background(), point); err != nil {
+					return fmt.Errorf("chainsync client failed: %w", err)
+				}
+			}
+			message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			deadline := time.Now().Add(time.Second)
+			_ = conn.WriteControl(websocket.CloseMessage, message, deadline)
+			c.options.logger.Info("ogmigo chainsync drained")
+			return errDrained
+		}
+
+		var work time.Duration // time spent delivering the prior message
 		for n := uint64(1); ; n++ {
-			messageType, data, err := conn.ReadMessage()
+			started := time.Now()
+			messageType, data, err := websocket.TextMessage, intersection, error(nil)
+			if data == nil {
+				messageType, data, err = keepalive.readMessage()
+			}
+			intersection = nil
+			wait := time.Since(started)
+			if atomic.LoadInt32(&draining) == 1 {
+				return drained()
+			}
 			if err != nil {
 				if errors.Is(err, io.EOF) {
 					return nil
@@ -255,27 +545,52 @@ func (c *Client) doChainSync(
 				}
 				return fmt.Errorf("failed to read message from ogmios: %w", err)
 			}
+			c.options.archive.write(data) // as received, prior to any conversion
+			if protocol == ProtocolV5 && messageType == websocket.TextMessage {
+				if data, err = decodeV5(data); err != nil {
+					return fmt.Errorf("failed to read message from ogmios: %w", err)
+				}
+			}
 
 			select {
 			case <-ctx.Done():
+				if err := dispatcher.barrier(); err != nil {
+					return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+				}
 				if point, ok := getPoint(last.list()...); ok {
 					if err := options.store.Save(context.Background(), point); err != nil {
 						return fmt.Errorf("chainsync client failed: %w", err)
 					}
 				}
 				return nil
-			case ch <- struct{}{}:
-				// request the next message
 			default:
-				// pump is full
+			}
+
+			// request the next message(s)
+			requests := 1
+			if adaptive != nil {
+				requests = 0
+				if messageType == websocket.TextMessage && isNextBlockResponse(data) {
+					requests = adaptive.next(wait, work, dispatcher.saturated())
+				}
+			}
+			for range requests {
+				select {
+				case ch <- struct{}{}:
+				default:
+					// pump is full
+				}
 			}
 
 			switch messageType {
 			case websocket.BinaryMessage:
-				c.options.logger.Info("skipping unexpected binary message")
+				c.options.logger.Warn("skipping unexpected binary message")
 				continue
 
 			case websocket.CloseMessage:
+				if err := dispatcher.barrier(); err != nil {
+					return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+				}
 				if point, ok := getPoint(last.list()...); ok {
 					if err := options.store.Save(context.Background(), point); err != nil {
 						return fmt.Errorf("chainsync client failed: %w", err)
@@ -283,18 +598,6 @@ func (c *Client) doChainSync(
 				}
 				return nil
 
-			case websocket.PingMessage:
-				if err := conn.WriteMessage(websocket.PongMessage, nil); err != nil {
-					return fmt.Errorf(
-						"failed to respond with pong to ogmios: %w",
-						err,
-					)
-				}
-				continue
-
-			case websocket.PongMessage:
-				continue
-
 			case websocket.TextMessage:
 				// ok
 			}
@@ -311,12 +614,42 @@ func (c *Client) doChainSync(
 				}
 			}
 
-			if err := callback(ctx, data); err != nil {
-				return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+			_, slot, tipSlot, isNextBlock := getBackfillPoint(data)
+			if isNextBlock {
+				atomic.StoreUint64(&tip, tipSlot)
+			}
+			if isNextBlock && options.stopAtSlot > 0 && slot > options.stopAtSlot {
+				return stop()
+			}
+
+			messages, err := state.observe(data)
+			if err != nil {
+				return fmt.Errorf("chainsync stopped: %w", err)
+			}
+			started = time.Now()
+			for _, message := range messages {
+				if err := dispatcher.dispatch(ctx, message); err != nil {
+					return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+				}
+				delivered = message
+			}
+			work = time.Since(started)
+			if isNextBlock {
+				meter.observe(slot, tipSlot, isRollForward(data))
+			}
+
+			if isNextBlock {
+				if (options.stopAtSlot > 0 && slot >= options.stopAtSlot) ||
+					(options.stopAtTip && slot >= tipSlot) {
+					return stop()
+				}
 			}
 
 			// periodically save points to the store to allow graceful recovery
 			if n%c.options.saveInterval == 0 {
+				if err := dispatcher.barrier(); err != nil {
+					return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+				}
 				if point, ok := getPoint(last.prefix(data)...); ok {
 					if err := options.store.Save(ctx, point); err != nil {
 						return fmt.Errorf("chainsync client failed: %w", err)
@@ -326,7 +659,11 @@ func (c *Client) doChainSync(
 			last.add(data)
 		}
 	})
-	return group.Wait()
+	err = group.Wait()
+	if kaErr := keepalive.failed(); kaErr != nil {
+		return kaErr
+	}
+	return err
 }
 
 func getInit(
@@ -334,6 +671,23 @@ func getInit(
 	store Store,
 	pp ...chainsync.Point,
 ) (data []byte, err error) {
+	points, err := getIntersectPoints(ctx, store, pp...)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) > maxIntersectPoints {
+		points = points[0:maxIntersectPoints]
+	}
+	return encodeInit(points)
+}
+
+// getIntersectPoints returns every point from which an intersection may be
+// attempted, preferring those of the store, ordered most recent first
+func getIntersectPoints(
+	ctx context.Context,
+	store Store,
+	pp ...chainsync.Point,
+) (chainsync.Points, error) {
 	points, err := store.Load(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve points from store: %w", err)
@@ -341,14 +695,14 @@ func getInit(
 	if len(points) == 0 {
 		points = append(points, pp...)
 	}
+	points = points.Dedup()
 	if len(points) == 0 {
 		points = append(points, chainsync.Origin)
 	}
-	sort.Sort(points)
-	if len(points) > 5 {
-		points = points[0:5]
-	}
+	return points, nil
+}
 
+func encodeInit(points chainsync.Points) ([]byte, error) {
 	init := Map{
 		"jsonrpc": "2.0",
 		"method":  "findIntersection",
@@ -368,14 +722,16 @@ func getPoint(data ...[]byte) (chainsync.Point, bool) {
 
 		var response chainsync.ResponsePraos
 		if err := json.Unmarshal(d, &response); err == nil {
-			if response.Method == chainsync.NextBlockMethod {
-				nbr := response.MustNextBlockResult()
+			if nbr, err := response.NextBlockResult(); err == nil {
 				switch nbr.Direction {
 				case chainsync.RollForwardString:
-					ps := nbr.Block.PointStruct()
-					return ps.Point(), true
+					if nbr.Block != nil {
+						return nbr.Block.PointStruct().Point(), true
+					}
 				case chainsync.RollBackwardString:
-					return *nbr.Point, true
+					if nbr.Point != nil {
+						return *nbr.Point, true
+					}
 				}
 			}
 		}
@@ -408,3 +764,43 @@ func isTemporaryError(err error) bool {
 
 	return false
 }
+
+// wrapCallback applies the interceptors and delivery options to callback
+func wrapCallback(callback ChainSyncFunc, options ChainSyncOptions) ChainSyncFunc {
+	callback = intercept(callback, options.interceptors)
+	if options.headersOnly {
+		callback = stripTransactions(callback)
+	}
+	if options.blockCBOR {
+		callback = requireBlockCBOR(callback)
+	}
+	return callback
+}
+
+// stripTransactions removes the transactions from roll forward messages
+// before invoking next.  Delete works in place, so a copy is modified as the
+// original is retained to track points
+func stripTransactions(next ChainSyncFunc) ChainSyncFunc {
+	return func(ctx context.Context, data []byte) error {
+		if isRollForward(data) {
+			data = jsonparser.Delete(
+				append([]byte(nil), data...),
+				"result", "block", "transactions",
+			)
+		}
+		return next(ctx, data)
+	}
+}
+
+// requireBlockCBOR fails roll forward messages whose block lacks its cbor
+func requireBlockCBOR(next ChainSyncFunc) ChainSyncFunc {
+	return func(ctx context.Context, data []byte) error {
+		if isRollForward(data) {
+			if s, _ := jsonparser.GetString(data, "result", "block", "cbor"); s == "" {
+				id, _ := jsonparser.GetString(data, "result", "block", "id")
+				return fmt.Errorf("block %v: %w", id, chainsync.ErrNoBlockCBOR)
+			}
+		}
+		return next(ctx, data)
+	}
+}
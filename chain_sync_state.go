@@ -0,0 +1,346 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+const (
+	// chainSyncStateVersion is the current version of ChainSyncState
+	chainSyncStateVersion = 1
+
+	// stateWindow is the number of recent blocks retained for deduplication
+	stateWindow = 100
+)
+
+// ChainSyncState is the serializable state of a ChainSync, allowing a new
+// ChainSync to resume exactly where another left off, e.g. to hand off a live
+// pipeline during a blue/green deploy.  See ChainSync.State and WithState
+type ChainSyncState struct {
+	Version  int                     `json:"version"`
+	Cursor   *chainsync.Point        `json:"cursor,omitempty"`  // point of the last message delivered
+	Sequence uint64                  `json:"sequence"`          // number of messages delivered
+	Window   []chainsync.PointStruct `json:"window,omitempty"`  // recent blocks delivered, oldest first
+	MinSlot  uint64                  `json:"minSlot,omitempty"` // filter applied via WithMinSlot
+}
+
+// ParseChainSyncState decodes state exported via ChainSync.State
+func ParseChainSyncState(data []byte) (ChainSyncState, error) {
+	var state ChainSyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return ChainSyncState{}, fmt.Errorf(
+			"failed to decode chainsync state: %w",
+			err,
+		)
+	}
+	if state.Version != chainSyncStateVersion {
+		return ChainSyncState{}, fmt.Errorf(
+			"failed to decode chainsync state: unsupported version, %v",
+			state.Version,
+		)
+	}
+	return state, nil
+}
+
+// WithState resumes from state exported by ChainSync.State.  The intersection
+// is negotiated from the state's cursor, taking precedence over WithPoints and
+// WithStore, and blocks replayed within the window are not delivered again.
+// The same applies on each reconnect, so WithState(ChainSyncState{}) may be
+// used to start a new ChainSync that reconnects without duplicates
+func WithState(state ChainSyncState) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.state = &state
+	}
+}
+
+// WithStateStore persists the ChainSyncState, including the window used to
+// suppress replayed blocks, under key in store each time ChainSync saves a
+// point.  On start, state found under key is resumed as if passed to
+// WithState, which takes precedence if also specified
+func WithStateStore(store kv.Store, key string) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.stateStore = &stateStore{store: store, key: key}
+	}
+}
+
+// stateStore persists ChainSyncState to a kv.Store
+type stateStore struct {
+	store kv.Store
+	key   string
+}
+
+// load returns the persisted state; ok is false if none has been saved
+func (s *stateStore) load(ctx context.Context) (ChainSyncState, bool, error) {
+	data, ok, err := s.store.Get(ctx, s.key)
+	if err != nil {
+		return ChainSyncState{}, false, fmt.Errorf(
+			"failed to load chainsync state, %v: %w",
+			s.key,
+			err,
+		)
+	}
+	if !ok {
+		return ChainSyncState{}, false, nil
+	}
+	state, err := ParseChainSyncState(data)
+	if err != nil {
+		return ChainSyncState{}, false, err
+	}
+	return state, true, nil
+}
+
+// wrap returns a Store that saves the state alongside each point
+func (s *stateStore) wrap(store Store, state *syncState) Store {
+	return &stateSaver{Store: store, stateStore: s, state: state}
+}
+
+type stateSaver struct {
+	Store
+	*stateStore
+	state *syncState
+}
+
+// Save implements Store
+func (s *stateSaver) Save(ctx context.Context, point chainsync.Point) error {
+	if err := s.Store.Save(ctx, point); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s.state.snapshot())
+	if err != nil {
+		return fmt.Errorf("failed to encode chainsync state: %w", err)
+	}
+	if err := s.store.Set(ctx, s.key, data); err != nil {
+		return fmt.Errorf("failed to save chainsync state, %v: %w", s.key, err)
+	}
+	return nil
+}
+
+// syncState tracks the messages delivered by a ChainSync
+type syncState struct {
+	mutex  sync.Mutex
+	state  ChainSyncState
+	resume bool // resume from the cursor on (re)connect
+
+	// replay is true while ogmios replays blocks already delivered
+	replay  bool
+	matched chainsync.Point // last replayed point that matched the window
+}
+
+// newSyncState returns the state for a ChainSync; if resume is true, each
+// connection resumes from the cursor
+func newSyncState(options ChainSyncOptions, resume bool) *syncState {
+	s := &syncState{
+		state: ChainSyncState{
+			Version: chainSyncStateVersion,
+			MinSlot: options.minSlot,
+		},
+		resume: resume,
+	}
+	if options.state != nil {
+		s.resume = true
+		s.state.Cursor = options.state.Cursor
+		s.state.Sequence = options.state.Sequence
+		s.state.Window = append(s.state.Window, options.state.Window...)
+		if options.minSlot == 0 {
+			s.state.MinSlot = options.state.MinSlot
+		}
+	}
+	return s
+}
+
+// snapshot returns a copy of the current state
+func (s *syncState) snapshot() ChainSyncState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.state
+	state.Window = append([]chainsync.PointStruct(nil), s.state.Window...)
+	return state
+}
+
+// points returns the points to intersect from when resuming; ok is false if
+// there is nothing to resume from
+func (s *syncState) points() (chainsync.Points, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.resume || s.state.Cursor == nil {
+		return nil, false
+	}
+
+	points := chainsync.Points{*s.state.Cursor}
+	for i := len(s.state.Window) - 1; i >= 0 && len(points) < 5; i-- {
+		points = append(points, s.state.Window[i].Point())
+	}
+	return points, true
+}
+
+// connected marks the start of a new connection; the first rollback is the
+// intersection, after which ogmios may replay blocks already delivered
+func (s *syncState) connected() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.replay = s.resume && s.state.Cursor != nil
+	s.matched = chainsync.Point{}
+}
+
+// observe returns the messages to deliver in place of data; replayed blocks
+// are suppressed, and a rollback is synthesized if the chain forked while
+// disconnected
+func (s *syncState) observe(data []byte) ([][]byte, error) {
+	point, slot, _, ok := getBackfillPoint(data)
+	if !ok {
+		return [][]byte{data}, nil
+	}
+	direction, _ := jsonparser.GetString(data, "result", "direction")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.replay {
+		switch direction {
+		case chainsync.RollBackwardString:
+			if s.isCursor(point) {
+				s.matched = point
+				s.replay = false
+				return nil, nil // intersected at the cursor
+			}
+			if _, found := s.find(point); found {
+				s.matched = point
+				return nil, nil // replaying from within the window
+			}
+			s.replay = false // intersected before the window
+
+		case chainsync.RollForwardString:
+			if _, found := s.find(point); found {
+				s.matched = point
+				if s.isCursor(point) {
+					s.replay = false
+				}
+				return nil, nil
+			}
+
+			// the chain forked while disconnected
+			s.replay = false
+			if ps, ok := s.matched.PointStruct(); ok {
+				rollback, err := rollBackward(s.matched, data)
+				if err != nil {
+					return nil, err
+				}
+				s.record(chainsync.RollBackwardString, s.matched, ps.Slot)
+				s.record(direction, point, slot)
+				return [][]byte{rollback, data}, nil
+			}
+		}
+	}
+
+	s.record(direction, point, slot)
+	return [][]byte{data}, nil
+}
+
+// record updates the state for a delivered message; record assumes the
+// caller holds the mutex
+func (s *syncState) record(
+	direction string,
+	point chainsync.Point,
+	slot uint64,
+) {
+	s.state.Cursor = &point
+	s.state.Sequence++
+
+	switch direction {
+	case chainsync.RollForwardString:
+		ps, _ := point.PointStruct()
+		s.state.Window = append(s.state.Window, chainsync.PointStruct{
+			ID:   ps.ID,
+			Slot: ps.Slot,
+		})
+		if n := len(s.state.Window) - stateWindow; n > 0 {
+			s.state.Window = append(s.state.Window[:0], s.state.Window[n:]...)
+		}
+
+	case chainsync.RollBackwardString:
+		i := len(s.state.Window)
+		for i > 0 && s.state.Window[i-1].Slot > slot {
+			i--
+		}
+		s.state.Window = s.state.Window[:i]
+	}
+}
+
+// find returns the index of point within the window
+func (s *syncState) find(point chainsync.Point) (int, bool) {
+	ps, ok := point.PointStruct()
+	if !ok {
+		return 0, false
+	}
+	for i := len(s.state.Window) - 1; i >= 0; i-- {
+		if w := s.state.Window[i]; w.Slot == ps.Slot && w.ID == ps.ID {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *syncState) isCursor(point chainsync.Point) bool {
+	if s.state.Cursor == nil {
+		return false
+	}
+	a, aok := s.state.Cursor.PointStruct()
+	b, bok := point.PointStruct()
+	if !aok || !bok {
+		return !aok && !bok // both origin
+	}
+	return a.Slot == b.Slot && a.ID == b.ID
+}
+
+// rollBackward synthesizes a nextBlock roll backward to point, with the tip
+// taken from the nextBlock response data
+func rollBackward(point chainsync.Point, data []byte) ([]byte, error) {
+	var tip *chainsync.PointStruct
+	if raw, _, _, err := jsonparser.Get(data, "result", "tip"); err == nil {
+		_ = json.Unmarshal(raw, &tip)
+	}
+
+	response := chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &point,
+			Tip:       tip,
+		},
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rollback: %w", err)
+	}
+	return data, nil
+}
+
+// State returns the current state of the ChainSync, suitable for WithState.
+// For an exact handoff, call State after Close
+func (c *ChainSync) State() ChainSyncState {
+	return c.state.snapshot()
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestChainSync_StateHandoff(t *testing.T) {
+	endpoint := chainSyncServer(t, 10)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var events []string
+	callback := func(_ context.Context, data []byte) error {
+		event, ok, err := chainsync.NewBlockEvent(data)
+		if ok {
+			events = append(events, fmt.Sprintf("%v:%v", event.Type, event.Point))
+		}
+		return err
+	}
+
+	run := func(opts ...ChainSyncOption) ChainSyncState {
+		chainSync, err := client.ChainSync(ctx, callback, opts...)
+		assert.Nil(t, err)
+		<-chainSync.Done()
+		assert.Nil(t, chainSync.Close())
+		return chainSync.State()
+	}
+
+	state := run(WithState(ChainSyncState{}), WithStopAtSlot(4))
+	assert.EqualValues(t, 5, state.Sequence) // rollback to origin + 4 blocks
+	assert.Len(t, state.Window, 4)
+
+	data, err := json.Marshal(state)
+	assert.Nil(t, err)
+	state, err = ParseChainSyncState(data)
+	assert.Nil(t, err)
+
+	state = run(WithState(state), WithStopWhenTipReached())
+	assert.EqualValues(t, 11, state.Sequence)
+
+	var want []string
+	want = append(want, "backward:origin")
+	for slot := 1; slot <= 10; slot++ {
+		want = append(want, fmt.Sprintf("forward:slot=%v id=block%v block=%v", slot, slot, slot))
+	}
+	assert.Equal(t, want, events)
+
+	_, err = ParseChainSyncState([]byte(`{"version":99}`))
+	assert.NotNil(t, err)
+}
+
+func TestWithStateStore(t *testing.T) {
+	endpoint := chainSyncServer(t, 10)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var slots []uint64
+	callback := func(_ context.Context, data []byte) error {
+		event, ok, err := chainsync.NewBlockEvent(data)
+		if ok && event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+		return err
+	}
+
+	store := kv.NewMemory()
+	run := func(opts ...ChainSyncOption) {
+		opts = append(opts, WithStateStore(store, "state"))
+		chainSync, err := client.ChainSync(ctx, callback, opts...)
+		assert.Nil(t, err)
+		<-chainSync.Done()
+		assert.Nil(t, chainSync.Close())
+	}
+
+	run(WithStopAtSlot(4))
+	data, ok, err := store.Get(ctx, "state")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	state, err := ParseChainSyncState(data)
+	assert.Nil(t, err)
+	assert.Len(t, state.Window, 4)
+
+	// the persisted window suppresses blocks already delivered
+	run(WithStopWhenTipReached())
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, slots)
+
+	assert.Nil(t, store.Set(ctx, "state", []byte(`{"version":99}`)))
+	_, err = client.ChainSync(ctx, callback, WithStateStore(store, "state"))
+	assert.NotNil(t, err)
+}
+
+func TestSyncState_Replay(t *testing.T) {
+	point := func(slot uint64, id string) chainsync.Point {
+		return chainsync.PointStruct{Slot: slot, ID: id}.Point()
+	}
+	forward := func(slot uint64, id string) []byte {
+		data, _ := json.Marshal(chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result: chainsync.ResultNextBlockPraos{
+				Direction: chainsync.RollForwardString,
+				Block:     &chainsync.Block{Slot: slot, ID: id},
+				Tip:       &chainsync.PointStruct{Slot: 10, ID: "tip"},
+			},
+		})
+		return data
+	}
+	backward := func(slot uint64, id string) []byte {
+		p := point(slot, id)
+		data, _ := rollBackward(p, forward(10, "tip"))
+		return data
+	}
+
+	cursor := point(3, "c")
+	state := newSyncState(ChainSyncOptions{state: &ChainSyncState{
+		Cursor: &cursor,
+		Window: []chainsync.PointStruct{{Slot: 1, ID: "a"}, {Slot: 2, ID: "b"}, {Slot: 3, ID: "c"}},
+	}}, false)
+
+	points, ok := state.points()
+	assert.True(t, ok)
+	assert.Len(t, points, 4)
+
+	// intersect within the window then replay up to the cursor
+	state.connected()
+	for _, data := range [][]byte{backward(1, "a"), forward(2, "b"), forward(3, "c")} {
+		messages, err := state.observe(data)
+		assert.Nil(t, err)
+		assert.Len(t, messages, 0)
+	}
+	messages, err := state.observe(forward(4, "d"))
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+
+	// intersect within the window, then the chain forks before the cursor
+	state.connected()
+	for _, data := range [][]byte{backward(2, "b"), forward(3, "c")} {
+		messages, err := state.observe(data)
+		assert.Nil(t, err)
+		assert.Len(t, messages, 0)
+	}
+	messages, err = state.observe(forward(4, "x"))
+	assert.Nil(t, err)
+	assert.Len(t, messages, 2)
+
+	event, ok, err := chainsync.NewBlockEvent(messages[0])
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, chainsync.RollBackwardEvent, event.Type)
+	assert.Equal(t, point(3, "c"), event.Point)
+
+	snapshot := state.snapshot()
+	assert.Equal(t, "x", snapshot.Window[len(snapshot.Window)-1].ID)
+	assert.Len(t, snapshot.Window, 4)
+}
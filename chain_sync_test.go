@@ -18,9 +18,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -29,6 +34,7 @@ import (
 	"golang.org/x/text/message"
 
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/gorilla/websocket"
 	"github.com/tj/assert"
 )
 
@@ -64,7 +70,7 @@ func TestClient_ChainSync(t *testing.T) {
 		read += int64(len(data))
 		if v := atomic.AddInt64(&counter, 1); v%1e3 == 0 {
 			var blockNo uint64
-			nbr := response.MustNextBlockResult()
+			nbr, _ := response.NextBlockResult()
 			if nbr.Direction == chainsync.RollForwardString {
 				blockNo = nbr.Block.Height
 			}
@@ -150,4 +156,236 @@ func Test_getInit(t *testing.T) {
 		want := `{"id":{"step":"INIT"},"jsonrpc":"2.0","method":"findIntersection","params":{"points":[{"id":"hash","slot":456}]}}`
 		assert.EqualValues(t, string(points), want)
 	})
+
+	t.Run("dedup", func(t *testing.T) {
+		store := mockStore{}
+		points, err := getInit(ctx, store,
+			p1.Point(), p1.Point(), p1.Point(), p1.Point(), p1.Point(),
+			p2.Point(), chainsync.Point{},
+		)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		want := `{"id":{"step":"INIT"},"jsonrpc":"2.0","method":"findIntersection","params":{"points":[{"id":"hash","slot":654},{"id":"hash","slot":456}]}}`
+		assert.EqualValues(t, string(points), want)
+	})
+}
+
+// chainSyncServer serves a simple chain of n blocks, one per slot starting at
+// slot 1, via the ogmios chainsync protocol
+func chainSyncServer(t *testing.T, n int) string {
+	t.Helper()
+
+	block := func(slot uint64) *chainsync.Block {
+		return &chainsync.Block{
+			Type:   "praos",
+			Era:    "babbage",
+			ID:     fmt.Sprintf("block%v", slot),
+			Height: slot,
+			Slot:   slot,
+		}
+	}
+	tip := chainsync.PointStruct{
+		ID:   fmt.Sprintf("block%v", n),
+		Slot: uint64(n),
+	}
+
+	upgrader := websocket.Upgrader{}
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		var (
+			slot       uint64 // last slot delivered
+			rolledBack bool
+		)
+		for {
+			var request struct {
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+				ID     json.RawMessage `json:"id"`
+			}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			response := Map{"jsonrpc": "2.0", "method": request.Method, "id": request.ID}
+			switch request.Method {
+			case chainsync.FindIntersectionMethod:
+				var params struct{ Points chainsync.Points }
+				_ = json.Unmarshal(request.Params, &params)
+				intersection := chainsync.Origin
+				if len(params.Points) > 0 {
+					intersection = params.Points[0]
+				}
+				if ps, ok := intersection.PointStruct(); ok {
+					slot = ps.Slot
+				}
+				response["result"] = chainsync.ResultFindIntersectionPraos{
+					Intersection: &intersection,
+					Tip:          &tip,
+				}
+
+			case "queryNetwork/tip":
+				response["result"] = tip
+
+			case chainsync.NextBlockMethod:
+				switch {
+				case !rolledBack:
+					rolledBack = true
+					point := chainsync.Origin
+					if slot > 0 {
+						point = block(slot).PointStruct().Point()
+					}
+					response["result"] = chainsync.ResultNextBlockPraos{
+						Direction: chainsync.RollBackwardString,
+						Tip:       &tip,
+						Point:     &point,
+					}
+				case slot >= uint64(n):
+					continue // await the next block forever
+				default:
+					slot++
+					response["result"] = chainsync.ResultNextBlockPraos{
+						Direction: chainsync.RollForwardString,
+						Tip:       &tip,
+						Block:     block(slot),
+					}
+				}
+			}
+
+			if err := conn.WriteJSON(response); err != nil {
+				return
+			}
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+type pointStore struct {
+	point chainsync.Point
+}
+
+func (p *pointStore) Save(_ context.Context, point chainsync.Point) error {
+	p.point = point
+	return nil
+}
+
+func (p *pointStore) Load(context.Context) (chainsync.Points, error) {
+	return nil, nil
+}
+
+func TestClient_ChainSyncStopConditions(t *testing.T) {
+	testCases := map[string]struct {
+		Option ChainSyncOption
+		Want   uint64
+	}{
+		"slot": {Option: WithStopAtSlot(3), Want: 3},
+		"tip":  {Option: WithStopWhenTipReached(), Want: 5},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			endpoint := chainSyncServer(t, 5)
+			client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+			var (
+				store = &pointStore{}
+				slots []uint64
+			)
+			callback := func(_ context.Context, data []byte) error {
+				event, ok, err := chainsync.NewBlockEvent(data)
+				if ok && event.Type == chainsync.RollForwardEvent {
+					slots = append(slots, event.Block.Slot)
+				}
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			chainSync, err := client.ChainSync(ctx, callback, tc.Option, WithStore(store))
+			assert.Nil(t, err)
+
+			select {
+			case <-chainSync.Done():
+			case <-ctx.Done():
+				t.Fatalf("got timeout; want chainsync to stop")
+			}
+			assert.Nil(t, chainSync.Close())
+			assert.Len(t, slots, int(tc.Want))
+			assert.Equal(t, tc.Want, slots[len(slots)-1])
+
+			ps, ok := store.point.PointStruct()
+			assert.True(t, ok)
+			assert.Equal(t, tc.Want, ps.Slot)
+		})
+	}
+}
+
+func TestChainSync_CloseWithContext(t *testing.T) {
+	endpoint := chainSyncServer(t, 5)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	var (
+		store   = &pointStore{}
+		slots   []uint64
+		reached = make(chan struct{})
+	)
+	callback := func(_ context.Context, data []byte) error {
+		event, ok, err := chainsync.NewBlockEvent(data)
+		if ok && event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+			if event.Block.Slot == 3 {
+				close(reached)
+				time.Sleep(50 * time.Millisecond) // in flight as the drain begins
+			}
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chainSync, err := client.ChainSync(ctx, callback, WithStore(store))
+	assert.Nil(t, err)
+
+	<-reached
+	assert.Nil(t, chainSync.CloseWithContext(ctx))
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+
+	ps, ok := store.point.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 3, ps.Slot)
+}
+
+func TestChainSync_CloseWithContextTimeout(t *testing.T) {
+	endpoint := chainSyncServer(t, 5)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	reached := make(chan struct{})
+	var once sync.Once
+	callback := func(ctx context.Context, _ []byte) error {
+		once.Do(func() { close(reached) })
+		<-ctx.Done() // never completes on its own
+		return nil
+	}
+
+	chainSync, err := client.ChainSync(context.Background(), callback)
+	assert.Nil(t, err)
+
+	<-reached
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = chainSync.CloseWithContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
 }
@@ -14,10 +14,23 @@
 
 package ogmigo
 
+import (
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
 // Client provides a client for the chain sync protocol only
 type Client struct {
-	logger  Logger
-	options Options
+	active   int64 // atomic; index of the active endpoint
+	protocol int32 // atomic; detected Protocol + 1, or 0 if not yet known
+	logger   Logger
+	options  Options
+	session  *session            // session is nil unless connections are pooled
+	breakers map[string]*breaker // breakers by endpoint; nil if disabled
+	limiter  *rate.Limiter       // limiter is nil unless WithRateLimit is set
+
+	network atomic.Pointer[NetworkInfo] // network is nil until first queried
 }
 
 // New returns a new Client
@@ -25,8 +38,30 @@ func New(opts ...Option) *Client {
 	options := buildOptions(opts...)
 	logger := options.logger.With(KV("service", "ogmios"))
 
-	return &Client{
+	client := &Client{
 		logger:  logger,
 		options: options,
 	}
+	if options.poolSize > 0 {
+		client.session = newSession(client, options.poolSize)
+	}
+	if options.rateLimit > 0 {
+		client.limiter = rate.NewLimiter(rate.Limit(options.rateLimit), options.rateBurst)
+	}
+	if options.breakerThreshold > 0 {
+		client.breakers = map[string]*breaker{}
+		for _, endpoint := range options.endpoints {
+			client.breakers[endpoint] = newBreaker(endpoint, options)
+		}
+	}
+	return client
+}
+
+// Close releases the persistent connection, if any, held by the client.
+// ChainSync and MonitorMempool connections are closed independently.
+func (c *Client) Close() error {
+	if c.session == nil {
+		return nil
+	}
+	return c.session.close()
 }
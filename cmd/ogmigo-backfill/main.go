@@ -0,0 +1,260 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/sink"
+	sinkkafka "github.com/SundaeSwap-finance/ogmigo/v6/sink/kafka"
+	_ "github.com/lib/pq"
+	"github.com/segmentio/kafka-go"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	BatchSize   int
+	Brokers     cli.StringSlice
+	Concurrency int
+	Dir         string
+	DSN         string
+	Interval    int
+	Ogmios      string
+	Points      cli.StringSlice
+	Progress    string
+	Sink        string
+	Table       string
+	Topic       string
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "sharded historical sync with resume"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint",
+			Value:       "ws://127.0.0.1:1337",
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.StringSliceFlag{
+			Name:        "point",
+			Aliases:     []string{"p"},
+			Usage:       "shard boundary in the form {slot}/{hash}; origin is always included",
+			EnvVars:     []string{"POINT"},
+			Destination: &opts.Points,
+		},
+		&cli.StringFlag{
+			Name:        "progress",
+			Usage:       "file used to persist shard progress",
+			Value:       "backfill-progress.json",
+			EnvVars:     []string{"PROGRESS"},
+			Destination: &opts.Progress,
+		},
+		&cli.IntFlag{
+			Name:        "concurrency",
+			Usage:       "number of shards to sync concurrently",
+			Value:       4,
+			EnvVars:     []string{"CONCURRENCY"},
+			Destination: &opts.Concurrency,
+		},
+		&cli.IntFlag{
+			Name:        "interval",
+			Usage:       "checkpoint progress every interval messages",
+			Value:       2160,
+			EnvVars:     []string{"INTERVAL"},
+			Destination: &opts.Interval,
+		},
+		&cli.StringFlag{
+			Name:        "sink",
+			Usage:       "destination for chainsync messages; file, postgres, or kafka",
+			Value:       "file",
+			EnvVars:     []string{"SINK"},
+			Destination: &opts.Sink,
+		},
+		&cli.StringFlag{
+			Name:        "dir",
+			Usage:       "directory for the file sink",
+			Value:       "backfill",
+			EnvVars:     []string{"DIR"},
+			Destination: &opts.Dir,
+		},
+		&cli.StringFlag{
+			Name:        "dsn",
+			Usage:       "postgres connection string for the postgres sink",
+			EnvVars:     []string{"DSN"},
+			Destination: &opts.DSN,
+		},
+		&cli.StringFlag{
+			Name:        "table",
+			Usage:       "table for the postgres sink",
+			Value:       "chainsync",
+			EnvVars:     []string{"TABLE"},
+			Destination: &opts.Table,
+		},
+		&cli.StringSliceFlag{
+			Name:        "broker",
+			Usage:       "kafka broker address for the kafka sink, e.g. 127.0.0.1:9092",
+			EnvVars:     []string{"BROKER"},
+			Destination: &opts.Brokers,
+		},
+		&cli.StringFlag{
+			Name:        "topic",
+			Usage:       "topic for the kafka sink",
+			Value:       "chainsync",
+			EnvVars:     []string{"TOPIC"},
+			Destination: &opts.Topic,
+		},
+		&cli.IntFlag{
+			Name:        "batch-size",
+			Usage:       "messages produced per batch by the kafka sink",
+			Value:       100,
+			EnvVars:     []string{"BATCH_SIZE"},
+			Destination: &opts.BatchSize,
+		},
+	}
+	app.Action = action
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func newSink(ctx context.Context) (sink.Sink, error) {
+	switch opts.Sink {
+	case "file":
+		return sink.NewFile(opts.Dir)
+	case "postgres":
+		db, err := sql.Open("postgres", opts.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("ogmigo: failed to open postgres: %w", err)
+		}
+		s := sink.NewSQL(db, opts.Table)
+		if err := s.CreateTable(ctx); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "kafka":
+		if len(opts.Brokers.Value()) == 0 {
+			return nil, fmt.Errorf("ogmigo: kafka sink requires --broker")
+		}
+		writer := &kafka.Writer{
+			Addr:         kafka.TCP(opts.Brokers.Value()...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		}
+		s := sinkkafka.New(producer(writer), opts.Topic,
+			sinkkafka.WithBatchSize(opts.BatchSize),
+		)
+		return &kafkaSink{Sink: s, writer: writer}, nil
+	default:
+		return nil, fmt.Errorf("ogmigo: unsupported sink, %v", opts.Sink)
+	}
+}
+
+// producer adapts a kafka-go Writer to the kafka sink
+func producer(writer *kafka.Writer) sinkkafka.Producer {
+	return sinkkafka.ProducerFunc(
+		func(ctx context.Context, messages ...sinkkafka.Message) error {
+			records := make([]kafka.Message, 0, len(messages))
+			for _, m := range messages {
+				records = append(records, kafka.Message{
+					Topic: m.Topic,
+					Key:   m.Key,
+					Value: m.Value,
+				})
+			}
+			return writer.WriteMessages(ctx, records...)
+		},
+	)
+}
+
+// kafkaSink closes the writer once the sink has flushed
+type kafkaSink struct {
+	*sinkkafka.Sink
+	writer *kafka.Writer
+}
+
+func (k *kafkaSink) Close() error {
+	if err := k.Sink.Close(); err != nil {
+		_ = k.writer.Close()
+		return err
+	}
+	return k.writer.Close()
+}
+
+func action(_ *cli.Context) error {
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(opts.Ogmios),
+		ogmigo.WithLogger(ogmigo.DefaultLogger),
+		ogmigo.WithInterval(opts.Interval),
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var (
+		re     = regexp.MustCompile(`^(\d+)/([a-zA-Z0-9]+)$`)
+		points = chainsync.Points{chainsync.Origin}
+	)
+	for _, s := range opts.Points.Value() {
+		match := re.FindStringSubmatch(s)
+		if len(match) != 3 {
+			return fmt.Errorf("ogmigo: failed to parse point, %v", s)
+		}
+		slot, _ := strconv.ParseUint(match[1], 10, 64)
+		points = append(points, chainsync.PointStruct{
+			ID:   match[2],
+			Slot: slot,
+		}.Point())
+	}
+
+	progress, err := ogmigo.NewFileProgress(opts.Progress)
+	if err != nil {
+		return err
+	}
+
+	dst, err := newSink(ctx)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	callback := func(ctx context.Context, shard ogmigo.Shard, data []byte) error {
+		return dst.Write(ctx, shard.ID, data)
+	}
+	flush := func(ctx context.Context, _ ogmigo.Shard) error {
+		return dst.Flush(ctx)
+	}
+
+	shards := ogmigo.ShardsFromPoints(points...)
+	log.Printf("backfilling %v shards", len(shards))
+
+	return client.Backfill(ctx, shards, callback,
+		ogmigo.WithConcurrency(opts.Concurrency),
+		ogmigo.WithFlush(flush),
+		ogmigo.WithProgress(progress),
+	)
+}
@@ -0,0 +1,537 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/compatibility"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	Era      string
+	JSON     bool
+	Ogmios   string
+	Protocol string
+	Run      string
+	Timeout  time.Duration
+}
+
+// invalidTx is deliberately malformed; submission and evaluation are expected
+// to be rejected by ogmios with a structured error
+const invalidTx = "00"
+
+// check verifies a single feature, returning a short detail on success
+type check struct {
+	Feature string
+	Fn      func(ctx context.Context, client *ogmigo.Client) (string, error)
+}
+
+// Result of a single check
+type Result struct {
+	Feature  string        `json:"feature"`
+	Pass     bool          `json:"pass"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report of a conformance run
+type Report struct {
+	Endpoint string   `json:"endpoint"`
+	Version  string   `json:"version,omitempty"`
+	Protocol string   `json:"protocol,omitempty"`
+	Results  []Result `json:"results"`
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "verify ogmios protocol conformance, feature by feature"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint",
+			Value:       "ws://127.0.0.1:1337",
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.StringFlag{
+			Name:        "protocol",
+			Usage:       "ogmios protocol, one of auto, v5 or v6",
+			Value:       "auto",
+			EnvVars:     []string{"PROTOCOL"},
+			Destination: &opts.Protocol,
+		},
+		&cli.DurationFlag{
+			Name:        "timeout",
+			Usage:       "timeout for each check",
+			Value:       30 * time.Second,
+			EnvVars:     []string{"TIMEOUT"},
+			Destination: &opts.Timeout,
+		},
+		&cli.StringFlag{
+			Name:        "run",
+			Usage:       "only run checks whose feature matches this regexp",
+			EnvVars:     []string{"RUN"},
+			Destination: &opts.Run,
+		},
+		&cli.StringFlag{
+			Name:        "era",
+			Usage:       "era used for the genesis configuration check",
+			Value:       "shelley",
+			EnvVars:     []string{"ERA"},
+			Destination: &opts.Era,
+		},
+		&cli.BoolFlag{
+			Name:        "json",
+			Usage:       "print the report as json",
+			EnvVars:     []string{"JSON"},
+			Destination: &opts.JSON,
+		},
+	}
+	app.Action = action
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func action(_ *cli.Context) error {
+	var run *regexp.Regexp
+	if opts.Run != "" {
+		re, err := regexp.Compile(opts.Run)
+		if err != nil {
+			return fmt.Errorf("ogmigo: invalid --run, %v: %w", opts.Run, err)
+		}
+		run = re
+	}
+
+	protocol, err := parseProtocol(opts.Protocol)
+	if err != nil {
+		return err
+	}
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(opts.Ogmios),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+		ogmigo.WithProtocol(protocol),
+	)
+	//nolint:errcheck
+	defer client.Close()
+
+	report := Report{Endpoint: opts.Ogmios}
+	if health, err := health(client); err == nil {
+		report.Version = health.Version
+	}
+	if protocol, err := negotiate(client); err == nil {
+		report.Protocol = protocol.String()
+	}
+
+	failed := 0
+	for _, c := range checks() {
+		if run != nil && !run.MatchString(c.Feature) {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		started := time.Now()
+		detail, err := c.Fn(ctx, client)
+		cancel()
+
+		result := Result{
+			Feature:  c.Feature,
+			Pass:     err == nil,
+			Detail:   detail,
+			Duration: time.Since(started).Round(time.Millisecond),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if err := printReport(report); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("ogmigo: %v of %v checks failed",
+			failed,
+			len(report.Results),
+		)
+	}
+	return nil
+}
+
+func parseProtocol(s string) (ogmigo.Protocol, error) {
+	for _, p := range []ogmigo.Protocol{
+		ogmigo.ProtocolAuto,
+		ogmigo.ProtocolV5,
+		ogmigo.ProtocolV6,
+	} {
+		if strings.EqualFold(s, p.String()) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("ogmigo: invalid --protocol, %v: want auto, v5 or v6", s)
+}
+
+// negotiate returns the protocol spoken by the server, detecting it if
+// --protocol is auto
+func negotiate(client *ogmigo.Client) (ogmigo.Protocol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	return client.Protocol(ctx)
+}
+
+func health(client *ogmigo.Client) (*ogmigo.Health, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	return client.Health(ctx)
+}
+
+func printReport(report Report) error {
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	version, protocol := report.Version, report.Protocol
+	if version == "" {
+		version = "unknown"
+	}
+	if protocol == "" {
+		protocol = "unknown"
+	}
+	fmt.Printf("endpoint: %v\nversion:  %v\nprotocol: %v\n\n",
+		report.Endpoint,
+		version,
+		protocol,
+	)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, r := range report.Results {
+		status, detail := "PASS", r.Detail
+		if !r.Pass {
+			status, detail = "FAIL", r.Error
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", status, r.Feature, r.Duration, detail)
+	}
+	return w.Flush()
+}
+
+func checks() []check {
+	return []check{
+		{"health", checkHealth},
+		{"chainsync/findIntersection", checkFindIntersection},
+		{"chainsync/nextBlock", checkNextBlock},
+		{"queryNetwork/tip", checkTip},
+		{"queryNetwork/tip (v5)", checkTipV5},
+		{"queryNetwork/blockHeight", checkBlockHeight},
+		{"queryNetwork/startTime", checkStartTime},
+		{"queryNetwork/genesisConfiguration", checkGenesisConfig},
+		{"queryLedgerState/epoch", checkEpoch},
+		{"queryLedgerState/eraStart", checkEraStart},
+		{"queryLedgerState/eraSummaries", checkEraSummaries},
+		{"queryLedgerState/protocolParameters", checkProtocolParameters},
+		{"queryLedgerState/protocolParameters (v5)", checkProtocolParametersV5},
+		{"queryLedgerState/liveStakeDistribution", checkStakeDistribution},
+		{"mempool/monitor", checkMempool},
+		{"submitTransaction", checkSubmitTx},
+		{"evaluateTransaction", checkEvaluateTx},
+	}
+}
+
+func checkHealth(ctx context.Context, client *ogmigo.Client) (string, error) {
+	health, err := client.Health(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("version=%v network=%v synced=%v",
+		health.Version,
+		health.Network,
+		health.Synced(),
+	), nil
+}
+
+// syncMessages returns the first n chainsync messages from origin
+func syncMessages(
+	ctx context.Context,
+	client *ogmigo.Client,
+	n int,
+) ([]compatibility.CompatibleResponsePraos, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		messages = make(chan compatibility.CompatibleResponsePraos, n)
+		errs     = make(chan error, 1)
+	)
+	callback := func(_ context.Context, data []byte) error {
+		var response compatibility.CompatibleResponsePraos
+		if err := json.Unmarshal(data, &response); err != nil {
+			select {
+			case errs <- fmt.Errorf("failed to decode message: %w", err):
+			default:
+			}
+			return err
+		}
+		select {
+		case messages <- response:
+		default:
+		}
+		return nil
+	}
+
+	chainSync, err := client.ChainSync(ctx, callback)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer chainSync.Close()
+
+	var responses []compatibility.CompatibleResponsePraos
+	for len(responses) < n {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-errs:
+			return nil, err
+		case <-chainSync.Done():
+			if err := chainSync.Close(); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("chainsync stopped unexpectedly")
+		case response := <-messages:
+			responses = append(responses, response)
+		}
+	}
+	return responses, nil
+}
+
+func checkFindIntersection(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	responses, err := syncMessages(ctx, client, 1)
+	if err != nil {
+		return "", err
+	}
+	result, err := responses[0].FindIntersectResult()
+	if err != nil {
+		return "", err
+	}
+	if result.Intersection == nil {
+		return "", errors.New("no intersection found at origin")
+	}
+	return fmt.Sprintf("method=%v intersection=%v",
+		responses[0].Method,
+		result.Intersection,
+	), nil
+}
+
+func checkNextBlock(ctx context.Context, client *ogmigo.Client) (string, error) {
+	const n = 3
+	responses, err := syncMessages(ctx, client, n+1)
+	if err != nil {
+		return "", err
+	}
+
+	var directions []string
+	for _, response := range responses[1:] {
+		result, err := response.NextBlockResult()
+		if err != nil {
+			return "", err
+		}
+		directions = append(directions, result.Direction)
+		if result.Direction == chainsync.RollForwardString && result.Block == nil {
+			return "", errors.New("roll forward without block")
+		}
+	}
+	return fmt.Sprintf("method=%v directions=%v",
+		responses[1].Method,
+		strings.Join(directions, ","),
+	), nil
+}
+
+func checkTip(ctx context.Context, client *ogmigo.Client) (string, error) {
+	point, err := client.ChainTip(ctx)
+	if err != nil {
+		return "", err
+	}
+	return point.String(), nil
+}
+
+func checkTipV5(ctx context.Context, client *ogmigo.Client) (string, error) {
+	point, err := client.ChainTipV5(ctx)
+	if err != nil {
+		return "", err
+	}
+	return point.String(), nil
+}
+
+func checkBlockHeight(ctx context.Context, client *ogmigo.Client) (string, error) {
+	height, err := client.BlockHeight(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("height=%v", height), nil
+}
+
+func checkStartTime(ctx context.Context, client *ogmigo.Client) (string, error) {
+	return client.StartTime(ctx)
+}
+
+func checkGenesisConfig(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	config, err := client.GenesisConfig(ctx, opts.Era)
+	if err != nil {
+		return "", err
+	}
+	if len(config) == 0 || string(config) == "null" {
+		return "", fmt.Errorf("empty genesis configuration for era, %v", opts.Era)
+	}
+	return fmt.Sprintf("era=%v bytes=%v", opts.Era, len(config)), nil
+}
+
+func checkEpoch(ctx context.Context, client *ogmigo.Client) (string, error) {
+	epoch, err := client.CurrentEpoch(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("epoch=%v", epoch), nil
+}
+
+func checkEraStart(ctx context.Context, client *ogmigo.Client) (string, error) {
+	start, err := client.EraStart(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("slot=%v epoch=%v", start.Slot, start.Epoch), nil
+}
+
+func checkEraSummaries(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	history, err := client.EraSummaries(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(history.Summaries) == 0 {
+		return "", errors.New("no era summaries")
+	}
+	return fmt.Sprintf("eras=%v", len(history.Summaries)), nil
+}
+
+func checkProtocolParameters(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	params, err := client.CurrentProtocolParameters(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("bytes=%v", len(params)), nil
+}
+
+func checkProtocolParametersV5(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	params, err := client.CurrentProtocolParametersV5(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("bytes=%v", len(params)), nil
+}
+
+func checkStakeDistribution(
+	ctx context.Context,
+	client *ogmigo.Client,
+) (string, error) {
+	distribution, err := client.LiveStakeDistribution(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pools=%v", len(distribution)), nil
+}
+
+// checkMempool monitors the mempool briefly; an empty mempool is not a failure
+func checkMempool(ctx context.Context, client *ogmigo.Client) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var txs int
+	callback := func(_ context.Context, data []*chainsync.Tx, _ uint64) error {
+		txs += len(data)
+		return nil
+	}
+	monitor, err := client.MonitorMempool(ctx, callback)
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-monitor.Done():
+	}
+	if err := monitor.Close(); err != nil && !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded) {
+		return "", err
+	}
+	return fmt.Sprintf("txs=%v", txs), nil
+}
+
+// checkSubmitTx expects ogmios to reject a malformed transaction with a
+// structured submission error
+func checkSubmitTx(ctx context.Context, client *ogmigo.Client) (string, error) {
+	response, err := client.SubmitTx(ctx, invalidTx)
+	if err != nil {
+		return "", err
+	}
+	if response.Error == nil {
+		return "", errors.New("malformed transaction was accepted")
+	}
+	return fmt.Sprintf("rejected with code %v", response.Error.Code), nil
+}
+
+// checkEvaluateTx expects ogmios to reject a malformed transaction with a
+// structured evaluation error
+func checkEvaluateTx(ctx context.Context, client *ogmigo.Client) (string, error) {
+	response, err := client.EvaluateTx(ctx, invalidTx)
+	if err != nil {
+		return "", err
+	}
+	if response.Error == nil {
+		return "", errors.New("malformed transaction was evaluated")
+	}
+	return fmt.Sprintf("rejected with code %v", response.Error.Code), nil
+}
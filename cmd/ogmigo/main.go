@@ -16,73 +16,280 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"regexp"
 	"strconv"
-	"sync/atomic"
+	"strings"
+	"syscall"
 
+	"github.com/SundaeSwap-finance/ogmigo/store/badgerstore"
 	"github.com/SundaeSwap-finance/ogmigo/v6"
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
 	"github.com/urfave/cli/v2"
 )
 
 var opts struct {
-	DB     string
 	Ogmios string
-	Points cli.StringSlice
-	Tick   int64
+	Debug  bool
 }
 
 func main() {
 	app := cli.NewApp()
+	app.Usage = "query and interact with an ogmios endpoint"
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{
 			Name:        "ogmios",
 			Usage:       "ogmios websocket endpoint",
-			Value:       "ws://3.21.245.207:1337", // mainnet2
+			Value:       "ws://127.0.0.1:1337",
 			EnvVars:     []string{"OGMIOS"},
 			Destination: &opts.Ogmios,
 		},
-		&cli.StringSliceFlag{
-			Name:        "point",
-			Aliases:     []string{"p"},
-			Usage:       "initial starting point in the form {slot}/{hash} e.g.",
-			EnvVars:     []string{"POINT"},
-			Destination: &opts.Points,
+		&cli.BoolFlag{
+			Name:        "debug",
+			Usage:       "log ogmigo activity to stderr",
+			EnvVars:     []string{"DEBUG"},
+			Destination: &opts.Debug,
 		},
-		&cli.Int64Flag{
-			Name:        "tick",
-			Usage:       "display progress every tick slots",
-			EnvVars:     []string{"TICK"},
-			Value:       5e3,
-			Destination: &opts.Tick,
+	}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "tip",
+			Usage:  "print the tip of the ledger state",
+			Action: tip,
+		},
+		{
+			Name:   "epoch",
+			Usage:  "print the current epoch",
+			Action: epoch,
+		},
+		{
+			Name:   "params",
+			Usage:  "print the current protocol parameters",
+			Action: params,
+		},
+		{
+			Name:  "utxos",
+			Usage: "print the utxos held by one or more addresses",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:     "address",
+					Aliases:  []string{"a"},
+					Usage:    "bech32 address; may be repeated",
+					Required: true,
+				},
+			},
+			Action: utxos,
+		},
+		{
+			Name:   "submit",
+			Usage:  "submit a signed transaction",
+			Flags:  []cli.Flag{cborFileFlag},
+			Action: submit,
+		},
+		{
+			Name:   "evaluate",
+			Usage:  "print the execution units required by a transaction",
+			Flags:  []cli.Flag{cborFileFlag},
+			Action: evaluate,
+		},
+		{
+			Name:  "follow",
+			Usage: "print chainsync events as they arrive",
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "from-slot",
+					Usage: "ignore blocks prior to slot; syncs from origin unless --point is given",
+				},
+				&cli.StringSliceFlag{
+					Name:    "point",
+					Aliases: []string{"p"},
+					Usage:   "starting point in the form {slot}/{hash}; defaults to the tip",
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "print each nextBlock response as json",
+				},
+			},
+			Action: follow,
+		},
+		{
+			Name:  "copy-store",
+			Usage: "copy checkpointed points from one store to another",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "from",
+					Usage:    "source store in the form {kind}:{path}, e.g. badger:/var/lib/points",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "to",
+					Usage:    "destination store in the form {kind}:{path}",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "from-prefix",
+					Usage: "key prefix of the points within the source store",
+					Value: "points",
+				},
+				&cli.StringFlag{
+					Name:  "to-prefix",
+					Usage: "key prefix of the points within the destination store",
+					Value: "points",
+				},
+			},
+			Action: copyStore,
 		},
 	}
-	app.Action = action
-	err := app.Run(os.Args)
-	if err != nil {
+
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	defer stop()
+
+	if err := app.RunContext(ctx, os.Args); err != nil {
 		log.Fatalln(err)
 	}
 }
 
-func action(_ *cli.Context) error {
-	client := ogmigo.New(
+var cborFileFlag = &cli.StringFlag{
+	Name:     "cbor-file",
+	Usage:    "file holding the transaction as hex, raw cbor, or a cardano-cli text envelope",
+	Required: true,
+}
+
+func newClient() *ogmigo.Client {
+	options := []ogmigo.Option{
 		ogmigo.WithEndpoint(opts.Ogmios),
-		ogmigo.WithLogger(ogmigo.DefaultLogger),
-	)
+	}
+	if opts.Debug {
+		options = append(options, ogmigo.WithLogger(ogmigo.DefaultLogger))
+	}
+	return ogmigo.New(options...)
+}
 
-	var (
-		ctx    = context.Background()
-		re     = regexp.MustCompile(`^(\d+)/([a-zA-Z0-9]+)$`)
-		points chainsync.Points
-	)
+// output writes v to stdout as indented json
+func output(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func tip(c *cli.Context) error {
+	point, err := newClient().ChainTip(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to query tip: %w", err)
+	}
+	return output(point)
+}
+
+func epoch(c *cli.Context) error {
+	epoch, err := newClient().CurrentEpoch(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to query epoch: %w", err)
+	}
+	fmt.Println(epoch)
+	return nil
+}
+
+func params(c *cli.Context) error {
+	params, err := newClient().CurrentProtocolParameters(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to query protocol parameters: %w", err)
+	}
+	return output(params)
+}
+
+func utxos(c *cli.Context) error {
+	addresses := c.StringSlice("address")
+	utxos, err := newClient().UtxosByAddress(c.Context, addresses...)
+	if err != nil {
+		return err
+	}
+	if utxos == nil {
+		utxos = []shared.Utxo{}
+	}
+	return output(utxos)
+}
+
+func submit(c *cli.Context) error {
+	data, err := readCBOR(c.String("cbor-file"))
+	if err != nil {
+		return err
+	}
+	client := newClient()
+	response, err := client.SubmitTx(c.Context, data, ogmigo.WithTxValidation())
+	if err != nil {
+		return err
+	}
+	if err := response.Err(); err != nil {
+		return err
+	}
+	fmt.Println(response.ID)
+	return nil
+}
 
-	for _, s := range opts.Points.Value() {
-		match := re.FindStringSubmatch(s)
+func evaluate(c *cli.Context) error {
+	data, err := readCBOR(c.String("cbor-file"))
+	if err != nil {
+		return err
+	}
+	response, err := newClient().EvaluateTx(c.Context, data)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf(
+			"failed to evaluate tx: %v (%v)",
+			response.Error.Message,
+			response.Error.Code,
+		)
+	}
+	return output(response.ExUnits)
+}
+
+// readCBOR returns the hex encoded transaction held by path
+func readCBOR(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cbor file, %v: %w", path, err)
+	}
+
+	var envelope struct {
+		CborHex string `json:"cborHex"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil && envelope.CborHex != "" {
+		return envelope.CborHex, nil
+	}
+
+	if s := strings.TrimSpace(string(raw)); s != "" {
+		if _, err := hex.DecodeString(s); err == nil {
+			return s, nil
+		}
+	}
+	if len(raw) == 0 {
+		return "", fmt.Errorf("cbor file is empty, %v", path)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+var rePoint = regexp.MustCompile(`^(\d+)/([a-zA-Z0-9]+)$`)
+
+func follow(c *cli.Context) error {
+	var points chainsync.Points
+	for _, s := range c.StringSlice("point") {
+		match := rePoint.FindStringSubmatch(s)
 		if len(match) != 3 {
 			return fmt.Errorf("ogmigo: failed to parse point, %v", s)
 		}
@@ -93,43 +300,107 @@ func action(_ *cli.Context) error {
 		}.Point())
 	}
 
-	var counter int64
-	var callback ogmigo.ChainSyncFunc = func(ctx context.Context, data []byte) error {
-		if v := atomic.AddInt64(&counter, 1); v%opts.Tick != 0 {
-			return nil
+	client := newClient()
+	fromSlot := c.Uint64("from-slot")
+	switch {
+	case len(points) > 0:
+	case fromSlot > 0:
+		points = chainsync.Points{chainsync.Origin}
+	default:
+		tip, err := client.ChainTip(c.Context)
+		if err != nil {
+			return fmt.Errorf("failed to query tip: %w", err)
 		}
+		points = chainsync.Points{tip}
+	}
 
+	printJSON := c.Bool("json")
+	var callback ogmigo.ChainSyncFunc = func(_ context.Context, data []byte) error {
 		var response chainsync.ResponsePraos
 		if err := json.Unmarshal(data, &response); err != nil {
 			return err
 		}
-		if response.Result == nil {
-			return nil
+		result, ok := response.Result.(chainsync.ResultNextBlockPraos)
+		if !ok {
+			return nil // findIntersection
 		}
-		intersect := response.Result.(chainsync.ResultFindIntersectionPraos).Intersection
-		if intersect == nil {
+		if printJSON {
+			fmt.Println(string(data))
 			return nil
 		}
 
-		ps, _ := intersect.PointStruct()
-		fmt.Printf("slot=%v id=%v block=%v\n", ps.Slot, ps.ID, ps.Height)
-
+		switch result.Direction {
+		case chainsync.RollForwardString:
+			if b := result.Block; b != nil {
+				fmt.Printf(
+					"forward  slot=%v id=%v block=%v txs=%v\n",
+					b.Slot,
+					b.ID,
+					b.Height,
+					len(b.Transactions),
+				)
+			}
+		case chainsync.RollBackwardString:
+			if result.Point != nil {
+				fmt.Printf("backward %v\n", result.Point)
+			}
+		}
 		return nil
 	}
-	closer, err := client.ChainSync(ctx, callback,
+
+	closer, err := client.ChainSync(c.Context, callback,
 		ogmigo.WithPoints(points...),
+		ogmigo.WithMinSlot(fromSlot),
 		ogmigo.WithReconnect(true),
 	)
+	if err != nil {
+		return err
+	}
 
+	select {
+	case <-c.Context.Done():
+	case <-closer.Done():
+	}
+	if err := closer.Close(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+func copyStore(c *cli.Context) error {
+	src, err := openStore(c.String("from"), c.String("from-prefix"))
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer src.Close()
+
+	dst, err := openStore(c.String("to"), c.String("to-prefix"))
 	if err != nil {
 		return err
 	}
-	defer closer.Close()
+	//nolint:errcheck
+	defer dst.Close()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Kill, os.Interrupt)
+	n, err := ogmigo.CopyStore(c.Context, src, dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("copied %v points\n", n)
+	return dst.Close()
+}
 
-	<-stop
+// openStore opens the store described by spec in the form {kind}:{path}
+func openStore(spec, prefix string) (*badgerstore.Store, error) {
+	kind, path, ok := strings.Cut(spec, ":")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("failed to parse store, %v: want {kind}:{path}", spec)
+	}
 
-	return nil
+	switch kind {
+	case "badger":
+		return badgerstore.Open(path, prefix)
+	default:
+		return nil, fmt.Errorf("unsupported store kind, %v", kind)
+	}
 }
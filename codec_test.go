@@ -0,0 +1,78 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+type countingCodec struct {
+	unmarshals int64
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	return StdJSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	atomic.AddInt64(&c.unmarshals, 1)
+	return StdJSONCodec.Unmarshal(data, v)
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("query", func(t *testing.T) {
+		endpoint, _ := scripted(t,
+			`{"jsonrpc":"2.0","method":"queryNetwork/blockHeight","result":42}`,
+		)
+		codec := &countingCodec{}
+		client := New(WithEndpoint(endpoint), WithJSONCodec(codec))
+
+		height, err := Query[uint64](ctx, client, "queryNetwork/blockHeight", nil)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 42, height)
+		assert.True(t, atomic.LoadInt64(&codec.unmarshals) >= 2)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		endpoint := chainSyncServer(t, 3)
+		codec := &countingCodec{}
+		client := New(
+			WithEndpoint(endpoint),
+			WithJSONCodec(codec),
+			WithLogger(NopLogger),
+		)
+
+		var slots []uint64
+		for event, err := range client.Blocks(ctx) {
+			assert.Nil(t, err)
+			if event.Block != nil {
+				slots = append(slots, event.Block.Slot)
+			}
+			if len(slots) == 3 {
+				break
+			}
+		}
+		assert.Equal(t, []uint64{1, 2, 3}, slots)
+		assert.True(t, atomic.LoadInt64(&codec.unmarshals) >= 4)
+	})
+}
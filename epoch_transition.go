@@ -0,0 +1,111 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// epochPollInterval bounds the time OnEpochTransition sleeps before checking
+// the clock again, e.g. after the host was suspended
+const epochPollInterval = time.Minute
+
+// EpochTransitionFunc is invoked with the new epoch as each epoch begins
+type EpochTransitionFunc func(ctx context.Context, epoch uint64) error
+
+// EpochTransitionOptions configures OnEpochTransition
+type EpochTransitionOptions struct {
+	last    uint64
+	hasLast bool
+}
+
+// EpochTransitionOption provides functional options for OnEpochTransition
+type EpochTransitionOption func(*EpochTransitionOptions)
+
+// WithLastEpoch specifies the last epoch whose transition was handled, e.g. as
+// persisted before a restart.  The callback is first invoked for each epoch
+// after epoch through the current epoch, so no transition is missed while
+// down.  By default, only transitions after OnEpochTransition is called are
+// delivered.
+func WithLastEpoch(epoch uint64) EpochTransitionOption {
+	return func(opts *EpochTransitionOptions) {
+		opts.last = epoch
+		opts.hasLast = true
+	}
+}
+
+// OnEpochTransition invokes fn exactly once for each epoch transition until
+// ctx is cancelled or fn returns an error.  Epoch boundaries are computed from
+// the era summaries and network start time, so no chain sync is required.  If
+// fn fails, the error is returned and epoch was not handled; resume with
+// WithLastEpoch(epoch-1).
+func (c *Client) OnEpochTransition(
+	ctx context.Context,
+	fn EpochTransitionFunc,
+	opts ...EpochTransitionOption,
+) error {
+	var options EpochTransitionOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	history, clock, err := c.eraClock(ctx)
+	if err != nil {
+		return err
+	}
+
+	last, known := options.last, options.hasLast
+	for {
+		slot, err := clock.CurrentSlot()
+		if err != nil {
+			return err
+		}
+		epoch, ok := history.EpochOf(slot)
+		if !ok {
+			// slot is beyond the horizon of the history; refresh it
+			if history, clock, err = c.eraClock(ctx); err != nil {
+				return err
+			}
+			if epoch, ok = history.EpochOf(slot); !ok {
+				return fmt.Errorf("failed to determine epoch of slot %v", slot)
+			}
+		}
+
+		if !known {
+			last, known = epoch, true
+		}
+		for last < epoch {
+			if err := fn(ctx, last+1); err != nil {
+				return err
+			}
+			last++
+		}
+
+		wait := epochPollInterval
+		if next, ok := history.FirstSlotOf(epoch + 1); ok {
+			wait = min(wait, time.Until(clock.SlotToTime(next)))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
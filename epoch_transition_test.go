@@ -0,0 +1,95 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ogmigotest"
+	"github.com/tj/assert"
+)
+
+// epochServer serves an era history of 50ms epochs, 1ms per slot, starting
+// at start
+func epochServer(t *testing.T, start time.Time) *ogmigotest.Server {
+	summaries := json.RawMessage(`[{
+		"start": {"time": {"seconds": 0}, "slot": 0, "epoch": 0},
+		"end": {"time": {"seconds": 1000000}, "slot": 1000000000, "epoch": 20000000},
+		"parameters": {
+			"epochLength": 50,
+			"slotLength": {"milliseconds": 1},
+			"safeZone": 10
+		}
+	}]`)
+	server := ogmigotest.NewServer(
+		ogmigotest.WithResult("queryLedgerState/eraSummaries", summaries),
+		ogmigotest.WithResult(
+			"queryNetwork/startTime",
+			start.UTC().Format(time.RFC3339Nano),
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_OnEpochTransition(t *testing.T) {
+	server := epochServer(t, time.Now().Add(-125*time.Millisecond))
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		done   = errors.New("done")
+		epochs []uint64
+	)
+	err := client.OnEpochTransition(ctx,
+		func(_ context.Context, epoch uint64) error {
+			if epochs = append(epochs, epoch); epoch == 4 {
+				return done
+			}
+			return nil
+		},
+		WithLastEpoch(0),
+	)
+	assert.True(t, errors.Is(err, done))
+	assert.Equal(t, []uint64{1, 2, 3, 4}, epochs)
+}
+
+func TestClient_OnEpochTransition_Cancel(t *testing.T) {
+	server := epochServer(t, time.Now().Add(-125*time.Millisecond))
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var epochs []uint64
+	err := client.OnEpochTransition(ctx,
+		func(_ context.Context, epoch uint64) error {
+			if epochs = append(epochs, epoch); len(epochs) == 2 {
+				cancel()
+			}
+			return nil
+		},
+	)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Len(t, epochs, 2)
+	assert.True(t, epochs[0] >= 3, "got %v; want only new epochs", epochs[0])
+	assert.Equal(t, epochs[0]+1, epochs[1])
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+// EraNames are the names of the eras in the order ogmios reports their
+// summaries
+var EraNames = []string{
+	"byron",
+	"shelley",
+	"allegra",
+	"mary",
+	"alonzo",
+	"babbage",
+	"conway",
+}
+
+// newEraHistory names each summary by its position in the history
+func newEraHistory(summaries []EraSummary) *EraHistory {
+	for i := range summaries {
+		if summaries[i].Era == "" && i < len(EraNames) {
+			summaries[i].Era = EraNames[i]
+		}
+	}
+	return &EraHistory{
+		Summaries: summaries,
+	}
+}
+
+// Contains returns true if slot falls within the era
+func (e EraSummary) Contains(slot uint64) bool {
+	return slot >= e.Start.Slot && slot < e.End.Slot
+}
+
+// EpochOf returns the epoch containing slot, which must fall within the era
+func (e EraSummary) EpochOf(slot uint64) uint64 {
+	if slot < e.Start.Slot || e.Parameters.EpochLength == 0 {
+		return e.Start.Epoch
+	}
+	return e.Start.Epoch + (slot-e.Start.Slot)/e.Parameters.EpochLength
+}
+
+// FirstSlotOf returns the first slot of epoch, which must fall within the era
+func (e EraSummary) FirstSlotOf(epoch uint64) uint64 {
+	if epoch < e.Start.Epoch {
+		return e.Start.Slot
+	}
+	return e.Start.Slot + (epoch-e.Start.Epoch)*e.Parameters.EpochLength
+}
+
+// EraAt returns the summary of the era containing slot.  ok is false for
+// slots at or beyond Horizon, where a hard fork may yet change the era.
+func (h *EraHistory) EraAt(slot uint64) (EraSummary, bool) {
+	if h == nil {
+		return EraSummary{}, false
+	}
+	for _, summary := range h.Summaries {
+		if summary.Contains(slot) {
+			return summary, true
+		}
+	}
+	return EraSummary{}, false
+}
+
+// EpochOf returns the epoch containing slot; ok is false for slots at or
+// beyond Horizon
+func (h *EraHistory) EpochOf(slot uint64) (uint64, bool) {
+	summary, ok := h.EraAt(slot)
+	if !ok {
+		return 0, false
+	}
+	return summary.EpochOf(slot), true
+}
+
+// FirstSlotOf returns the first slot of epoch; ok is false if the epoch does
+// not begin before Horizon
+func (h *EraHistory) FirstSlotOf(epoch uint64) (uint64, bool) {
+	if h == nil {
+		return 0, false
+	}
+	for _, summary := range h.Summaries {
+		if epoch >= summary.Start.Epoch && epoch < summary.End.Epoch {
+			return summary.FirstSlotOf(epoch), true
+		}
+	}
+	return 0, false
+}
+
+// Horizon returns the end of the final era, i.e. the first slot beyond the
+// safe zone.  Conversions for slots before Horizon are guaranteed not to be
+// invalidated by a future hard fork.
+func (h *EraHistory) Horizon() uint64 {
+	if h == nil || len(h.Summaries) == 0 {
+		return 0
+	}
+	return h.Summaries[len(h.Summaries)-1].End.Slot
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func mainnetEraHistory(t *testing.T) *EraHistory {
+	var summaries []EraSummary
+	err := json.Unmarshal([]byte(mainnetEraSummaries), &summaries)
+	assert.Nil(t, err)
+	return newEraHistory(summaries)
+}
+
+func TestEraHistory_EraAt(t *testing.T) {
+	history := mainnetEraHistory(t)
+
+	summary, ok := history.EraAt(0)
+	assert.True(t, ok)
+	assert.Equal(t, "byron", summary.Era)
+	assert.EqualValues(t, 21600, summary.Parameters.EpochLength)
+
+	summary, ok = history.EraAt(4492800)
+	assert.True(t, ok)
+	assert.Equal(t, "shelley", summary.Era)
+	assert.EqualValues(t, 129600, summary.Parameters.SafeZone)
+
+	_, ok = history.EraAt(history.Horizon())
+	assert.False(t, ok)
+	assert.EqualValues(t, 16588800, history.Horizon())
+
+	var empty *EraHistory
+	_, ok = empty.EraAt(0)
+	assert.False(t, ok)
+	assert.EqualValues(t, 0, empty.Horizon())
+}
+
+func TestEraHistory_EpochOf(t *testing.T) {
+	history := mainnetEraHistory(t)
+
+	tests := map[uint64]uint64{
+		0:               0,
+		21599:           0,
+		21600:           1,
+		4492799:         207,
+		4492800:         208,
+		4492800 + 43199: 208,
+		4492800 + 43200: 208,
+		4924800:         209,
+	}
+	for slot, want := range tests {
+		epoch, ok := history.EpochOf(slot)
+		assert.True(t, ok)
+		assert.Equal(t, want, epoch, "slot %v", slot)
+	}
+
+	_, ok := history.EpochOf(16588800)
+	assert.False(t, ok)
+}
+
+func TestEraHistory_FirstSlotOf(t *testing.T) {
+	history := mainnetEraHistory(t)
+
+	tests := map[uint64]uint64{
+		0:   0,
+		1:   21600,
+		208: 4492800,
+		209: 4924800,
+	}
+	for epoch, want := range tests {
+		slot, ok := history.FirstSlotOf(epoch)
+		assert.True(t, ok)
+		assert.Equal(t, want, slot, "epoch %v", epoch)
+
+		got, ok := history.EpochOf(slot)
+		assert.True(t, ok)
+		assert.Equal(t, epoch, got)
+	}
+
+	_, ok := history.FirstSlotOf(236)
+	assert.False(t, ok)
+}
@@ -15,7 +15,11 @@
 package ogmigo
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Error encapsulates errors from ogmios
@@ -34,3 +38,46 @@ type Fault struct {
 	Code   string `json:"code,omitempty"`   // Code identifies error
 	String string `json:"string,omitempty"` // String provides human readable description
 }
+
+// QueryTimeoutError indicates a request exceeded the timeout set via
+// WithQueryTimeout
+type QueryTimeoutError struct {
+	Method   string        // Method is the ogmios method requested
+	Duration time.Duration // Duration of the timeout that was exceeded
+}
+
+// Error implements error interface
+func (e *QueryTimeoutError) Error() string {
+	return fmt.Sprintf("ogmios %v timed out after %v", e.Method, e.Duration)
+}
+
+// Timeout reports the error is a timeout, see net.Error
+func (e *QueryTimeoutError) Timeout() bool { return true }
+
+// Unwrap allows errors.Is(err, context.DeadlineExceeded)
+func (e *QueryTimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+const (
+	// AcquireLedgerStateFailureCode indicates ogmios failed to acquire the ledger state
+	AcquireLedgerStateFailureCode = 2000
+	// AcquiredExpiredCode indicates the previously acquired ledger state is no longer available
+	AcquiredExpiredCode = 2003
+)
+
+// AcquireError indicates ogmios was unable to acquire, or lost, the ledger
+// state required to answer a query.  These errors are transient
+type AcquireError struct {
+	Code    int
+	Message string
+	Data    json.RawMessage
+}
+
+// Error implements error interface
+func (e *AcquireError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// isAcquireFault returns true if the v5 fault relates to acquiring the ledger state
+func isAcquireFault(e Error) bool {
+	return strings.Contains(strings.ToLower(e.Fault.String), "acquire")
+}
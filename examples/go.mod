@@ -0,0 +1,45 @@
+module examples
+
+go 1.24.0
+
+replace github.com/SundaeSwap-finance/ogmigo/store/badgerstore => ../store/badgerstore
+
+replace github.com/SundaeSwap-finance/ogmigo/v6 => ..
+
+require (
+	github.com/SundaeSwap-finance/ogmigo/store/badgerstore v0.0.0-00010101000000-000000000000
+	github.com/SundaeSwap-finance/ogmigo/v6 v6.0.0
+	github.com/dgraph-io/badger/v3 v3.2103.5
+	github.com/urfave/cli/v2 v2.27.7
+)
+
+require (
+	github.com/aws/aws-sdk-go v1.44.197 // indirect
+	github.com/btcsuite/btcutil v1.0.2 // indirect
+	github.com/buger/jsonparser v1.1.2 // indirect
+	github.com/cespare/xxhash v1.1.0 // indirect
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
+	github.com/dgraph-io/ristretto v0.1.1 // indirect
+	github.com/dustin/go-humanize v1.0.0 // indirect
+	github.com/fxamacker/cbor/v2 v2.4.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/glog v1.2.4 // indirect
+	github.com/golang/groupcache v0.0.0-20190702054246-869f871628b6 // indirect
+	github.com/golang/protobuf v1.3.1 // indirect
+	github.com/golang/snappy v0.0.3 // indirect
+	github.com/google/flatbuffers v1.12.1 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/klauspost/compress v1.12.3 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/russross/blackfriday/v2 v2.1.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
+	go.opencensus.io v0.22.5 // indirect
+	golang.org/x/crypto v0.36.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/time v0.5.0 // indirect
+)
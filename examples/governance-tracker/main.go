@@ -0,0 +1,147 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command governance-tracker follows the chain reporting conway governance
+// proposals as they are submitted and tallying the votes cast on each
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	Blocks int
+	Ogmios cli.StringSlice
+}
+
+// tally counts votes by "role/vote" e.g. delegateRepresentative/yes
+type tally map[string]int
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "track governance proposals and votes"
+	app.Flags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint; repeat to fail over between endpoints",
+			Value:       cli.NewStringSlice("ws://127.0.0.1:1337"),
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.IntFlag{
+			Name:        "blocks",
+			Usage:       "exit after this many blocks; 0 runs until interrupted",
+			EnvVars:     []string{"BLOCKS"},
+			Destination: &opts.Blocks,
+		},
+	}
+	app.Action = action
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func action(_ *cli.Context) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoints(opts.Ogmios.Value()...),
+		ogmigo.WithLogger(ogmigo.DefaultLogger),
+	)
+
+	tip, err := client.ChainTip(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query tip: %w", err)
+	}
+
+	var (
+		tallies = map[string]tally{} // proposal id -> tally
+		n       int
+	)
+	defer func() { report(tallies) }()
+
+	for event, err := range client.Blocks(ctx, tip) {
+		if err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case chainsync.RollBackwardEvent:
+			// a production tracker would unwind the votes cast after the point
+			fmt.Printf("rollback to %v\n", event.Point)
+
+		case chainsync.RollForwardEvent:
+			for _, tx := range event.Block.Transactions {
+				if err := observe(event.Block, tx, tallies); err != nil {
+					return err
+				}
+			}
+			if n++; opts.Blocks > 0 && n >= opts.Blocks {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func observe(block *chainsync.Block, tx chainsync.Tx, tallies map[string]tally) error {
+	proposals, err := tx.DecodeProposals()
+	if err != nil {
+		return err
+	}
+	for i, p := range proposals {
+		id := tx.ProposalReference(i).String()
+		var url string
+		if p.Metadata != nil {
+			url = p.Metadata.URL
+		}
+		fmt.Printf("slot=%v proposal=%v action=%v metadata=%v\n", block.Slot, id, p.Action.Type, url)
+		tallies[id] = tally{}
+	}
+
+	votes, err := tx.DecodeVotes()
+	if err != nil {
+		return err
+	}
+	for _, v := range votes {
+		id := v.Proposal.String()
+		if tallies[id] == nil {
+			tallies[id] = tally{} // proposed before we started following
+		}
+		tallies[id][v.Issuer.Role+"/"+v.Vote]++
+	}
+	return nil
+}
+
+func report(tallies map[string]tally) {
+	ids := make([]string, 0, len(tallies))
+	for id := range tallies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		fmt.Printf("proposal=%v votes=%v\n", id, tallies[id])
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command nft-indexer follows the chain and records the mint and burn of
+// every NFT, i.e. any asset minted or burned with a quantity of one,
+// optionally restricted to a set of policies
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+
+	"github.com/SundaeSwap-finance/ogmigo/store/badgerstore"
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	Blocks   int
+	DB       string
+	Ogmios   string
+	Policies cli.StringSlice
+	Workers  int
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "index nft mints and burns"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint",
+			Value:       "ws://127.0.0.1:1337",
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.StringSliceFlag{
+			Name:        "policy",
+			Aliases:     []string{"p"},
+			Usage:       "policy id to index; may be repeated.  defaults to all",
+			EnvVars:     []string{"POLICY"},
+			Destination: &opts.Policies,
+		},
+		&cli.StringFlag{
+			Name:        "db",
+			Usage:       "badger directory used to resume from the last checkpoint",
+			EnvVars:     []string{"DB"},
+			Destination: &opts.DB,
+		},
+		&cli.IntFlag{
+			Name:        "workers",
+			Usage:       "number of workers decoding blocks ahead of the indexer",
+			Value:       4,
+			EnvVars:     []string{"WORKERS"},
+			Destination: &opts.Workers,
+		},
+		&cli.IntFlag{
+			Name:        "blocks",
+			Usage:       "exit after this many blocks; 0 runs until interrupted",
+			EnvVars:     []string{"BLOCKS"},
+			Destination: &opts.Blocks,
+		},
+	}
+	app.Action = action
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func action(_ *cli.Context) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(opts.Ogmios),
+		ogmigo.WithLogger(ogmigo.DefaultLogger),
+	)
+
+	options := []ogmigo.ChainSyncOption{
+		ogmigo.WithReconnect(true),
+		ogmigo.WithWorkers(opts.Workers, nil),
+	}
+	if opts.DB != "" {
+		db, err := badger.Open(badger.DefaultOptions(opts.DB).WithLogger(nil))
+		if err != nil {
+			return fmt.Errorf("failed to open db, %v: %w", opts.DB, err)
+		}
+		//nolint:errcheck
+		defer db.Close()
+		options = append(options, ogmigo.WithStore(badgerstore.New(db, "nft-indexer")))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var blocks int64
+	var callback ogmigo.ChainSyncFunc = func(ctx context.Context, _ []byte) error {
+		response, ok := ogmigo.Prepared[*chainsync.ResponsePraos](ctx)
+		if !ok {
+			return nil
+		}
+		result, err := response.NextBlockResult()
+		if err != nil {
+			return nil // findIntersection
+		}
+
+		switch result.Direction {
+		case chainsync.RollBackwardString:
+			fmt.Printf("rollback to %v\n", result.Point)
+		case chainsync.RollForwardString:
+			index(result.Block)
+			if n := atomic.AddInt64(&blocks, 1); opts.Blocks > 0 && n >= int64(opts.Blocks) {
+				cancel()
+			}
+		}
+		return nil
+	}
+
+	chainSync, err := client.ChainSync(ctx, callback, options...)
+	if err != nil {
+		return err
+	}
+	<-chainSync.Done()
+	return chainSync.Close()
+}
+
+// index reports the nfts minted or burned within the block
+func index(block *chainsync.Block) {
+	var txs []*chainsync.Tx
+	if policies := opts.Policies.Value(); len(policies) > 0 {
+		var (
+			index = chainsync.NewBlockIndex(block)
+			seen  = map[*chainsync.Tx]struct{}{}
+		)
+		for _, policyID := range policies {
+			for _, tx := range index.TransactionsByPolicy(policyID) {
+				if _, ok := seen[tx]; !ok {
+					seen[tx] = struct{}{}
+					txs = append(txs, tx)
+				}
+			}
+		}
+	} else {
+		for i := range block.Transactions {
+			txs = append(txs, &block.Transactions[i])
+		}
+	}
+
+	for _, tx := range txs {
+		for policyID, assets := range tx.Mint {
+			if policyID == shared.AdaPolicy || !wanted(policyID) {
+				continue
+			}
+			for assetName, quantity := range assets {
+				var action string
+				switch quantity.Int64() {
+				case 1:
+					action = "mint"
+				case -1:
+					action = "burn"
+				default:
+					continue // fungible
+				}
+				fmt.Printf("slot=%v tx=%v %v %v.%v (%v)\n",
+					block.Slot,
+					tx.ID,
+					action,
+					policyID,
+					assetName,
+					printable(assetName),
+				)
+			}
+		}
+	}
+}
+
+func wanted(policyID string) bool {
+	policies := opts.Policies.Value()
+	return len(policies) == 0 || slices.Contains(policies, policyID)
+}
+
+// printable returns the asset name decoded from hex if it is plain text
+func printable(assetName string) string {
+	data, err := hex.DecodeString(assetName)
+	if err != nil {
+		return assetName
+	}
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return assetName
+		}
+	}
+	return string(data)
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command submit-and-wait evaluates and submits a signed transaction, then
+// follows the chain until the transaction has been included in a block
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	MaxLag  uint64
+	Ogmios  string
+	Timeout time.Duration
+	Tx      string
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "submit a transaction and wait for it to be included"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint",
+			Value:       "ws://127.0.0.1:1337",
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.StringFlag{
+			Name:        "tx",
+			Usage:       "signed tx as hex cbor, or @file holding hex or a text envelope",
+			Required:    true,
+			EnvVars:     []string{"TX"},
+			Destination: &opts.Tx,
+		},
+		&cli.Uint64Flag{
+			Name:        "max-lag",
+			Usage:       "slots the ledger may trail the network before submitting",
+			Value:       120,
+			Destination: &opts.MaxLag,
+		},
+		&cli.DurationFlag{
+			Name:        "timeout",
+			Usage:       "how long to wait for the transaction to be included",
+			Value:       5 * time.Minute,
+			Destination: &opts.Timeout,
+		},
+	}
+	app.Action = action
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func action(_ *cli.Context) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	cbor, err := readTx(opts.Tx)
+	if err != nil {
+		return err
+	}
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(opts.Ogmios),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+		ogmigo.WithRetry(3, ogmigo.ExponentialBackoff(time.Second, 10*time.Second)),
+	)
+
+	// a node replaying its ledger would judge the tx against stale state
+	status, err := client.SyncStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if !status.Ready(opts.MaxLag) {
+		return fmt.Errorf("node is not ready: %v", status)
+	}
+
+	evaluation, err := client.EvaluateTx(ctx, cbor)
+	if err != nil {
+		return err
+	}
+	if evaluation.Error != nil {
+		return fmt.Errorf("evaluation failed: %v %s", evaluation.Error.Message, evaluation.Error.Data)
+	}
+	for _, units := range evaluation.ExUnits {
+		fmt.Printf("script %v:%v memory=%v cpu=%v\n",
+			units.Validator.Purpose,
+			units.Validator.Index,
+			units.Budget.Memory,
+			units.Budget.Cpu,
+		)
+	}
+
+	// follow from the current tip so the inclusion cannot be missed
+	tip, err := client.ChainTip(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.SubmitTx(ctx, cbor)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("submit failed: %v %s", response.Error.Message, response.Error.Data)
+	}
+	fmt.Printf("submitted %v\n", response.ID)
+
+	for event, err := range client.Blocks(ctx, tip) {
+		if err != nil {
+			return err
+		}
+		if event.Type != chainsync.RollForwardEvent {
+			continue
+		}
+		for _, tx := range event.Block.Transactions {
+			if tx.ID == response.ID {
+				fmt.Printf("included slot=%v block=%v\n", event.Block.Slot, event.Block.ID)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("tx %v not included within %v", response.ID, opts.Timeout)
+}
+
+func readTx(tx string) (string, error) {
+	if !strings.HasPrefix(tx, "@") {
+		return tx, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(tx, "@"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read tx: %w", err)
+	}
+
+	// cardano-cli writes transactions as text envelopes
+	var envelope struct {
+		CborHex string `json:"cborHex"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil {
+		return envelope.CborHex, nil
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command wallet-watcher follows the chain from the current tip and reports
+// every transaction paying to the watched addresses
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/urfave/cli/v2"
+)
+
+var opts struct {
+	Addresses cli.StringSlice
+	Blocks    int
+	Ogmios    string
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "report transactions paying to the watched addresses"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "ogmios",
+			Usage:       "ogmios websocket endpoint",
+			Value:       "ws://127.0.0.1:1337",
+			EnvVars:     []string{"OGMIOS"},
+			Destination: &opts.Ogmios,
+		},
+		&cli.StringSliceFlag{
+			Name:        "address",
+			Aliases:     []string{"a"},
+			Usage:       "bech32 address to watch; may be repeated",
+			EnvVars:     []string{"ADDRESS"},
+			Destination: &opts.Addresses,
+		},
+		&cli.IntFlag{
+			Name:        "blocks",
+			Usage:       "exit after this many blocks; 0 runs until interrupted",
+			EnvVars:     []string{"BLOCKS"},
+			Destination: &opts.Blocks,
+		},
+	}
+	app.Action = action
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func action(_ *cli.Context) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(opts.Ogmios),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+		ogmigo.WithProtocol(ogmigo.ProtocolAuto),
+	)
+
+	tip, err := client.ChainTip(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query tip: %w", err)
+	}
+	fmt.Printf("watching %v address(es) from %v\n", len(opts.Addresses.Value()), tip)
+
+	var n int
+	for event, err := range client.Blocks(ctx, tip) {
+		if err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case chainsync.RollBackwardEvent:
+			fmt.Printf("rollback to %v\n", event.Point)
+
+		case chainsync.RollForwardEvent:
+			block := event.Block
+			index := chainsync.NewBlockIndex(block)
+			for _, address := range opts.Addresses.Value() {
+				for _, tx := range index.TransactionsByAddress(address) {
+					report(block, tx, address)
+				}
+			}
+			if n++; opts.Blocks > 0 && n >= opts.Blocks {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func report(block *chainsync.Block, tx *chainsync.Tx, address string) {
+	for _, output := range tx.Outputs {
+		if output.Address != address {
+			continue
+		}
+		fmt.Printf("slot=%v tx=%v address=%v lovelace=%v assets=%v\n",
+			block.Slot,
+			tx.ID,
+			address,
+			output.Value.AdaLovelace(),
+			output.Value.AssetsExceptAdaCount(),
+		)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: chainsync.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ChainSync_Subscribe_FullMethodName = "/ogmigo.v1.ChainSync/Subscribe"
+)
+
+// ChainSyncClient is the client API for ChainSync service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChainSync fans out the events of a single ogmigo ChainSync to any number of
+// subscribers
+type ChainSyncClient interface {
+	// Subscribe streams events from the hub's current position onwards
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (ChainSync_SubscribeClient, error)
+}
+
+type chainSyncClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChainSyncClient(cc grpc.ClientConnInterface) ChainSyncClient {
+	return &chainSyncClient{cc}
+}
+
+func (c *chainSyncClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (ChainSync_SubscribeClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChainSync_ServiceDesc.Streams[0], ChainSync_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chainSyncSubscribeClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChainSync_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type chainSyncSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *chainSyncSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChainSyncServer is the server API for ChainSync service.
+// All implementations must embed UnimplementedChainSyncServer
+// for forward compatibility
+//
+// ChainSync fans out the events of a single ogmigo ChainSync to any number of
+// subscribers
+type ChainSyncServer interface {
+	// Subscribe streams events from the hub's current position onwards
+	Subscribe(*SubscribeRequest, ChainSync_SubscribeServer) error
+	mustEmbedUnimplementedChainSyncServer()
+}
+
+// UnimplementedChainSyncServer must be embedded to have forward compatible implementations.
+type UnimplementedChainSyncServer struct {
+}
+
+func (UnimplementedChainSyncServer) Subscribe(*SubscribeRequest, ChainSync_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedChainSyncServer) mustEmbedUnimplementedChainSyncServer() {}
+
+// UnsafeChainSyncServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChainSyncServer will
+// result in compilation errors.
+type UnsafeChainSyncServer interface {
+	mustEmbedUnimplementedChainSyncServer()
+}
+
+func RegisterChainSyncServer(s grpc.ServiceRegistrar, srv ChainSyncServer) {
+	s.RegisterService(&ChainSync_ServiceDesc, srv)
+}
+
+func _ChainSync_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChainSyncServer).Subscribe(m, &chainSyncSubscribeServer{ServerStream: stream})
+}
+
+type ChainSync_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type chainSyncSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *chainSyncSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ChainSync_ServiceDesc is the grpc.ServiceDesc for ChainSync service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChainSync_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ogmigo.v1.ChainSync",
+	HandlerType: (*ChainSyncServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _ChainSync_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "chainsync.proto",
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ogmigogrpc exposes a single ogmigo ChainSync as a gRPC service that
+// streams typed block and rollback events to any number of subscribers
+package ogmigogrpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/ext/grpc/pb"
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultBuffer is the number of events buffered per subscriber by default
+const DefaultBuffer = 256
+
+// Options configures the Server
+type Options struct {
+	buffer int
+}
+
+// Option provides functional options for the Server
+type Option func(*Options)
+
+// WithBuffer sets the number of events buffered per subscriber.  Subscribers
+// that fall further behind are disconnected rather than stalling the hub
+func WithBuffer(n int) Option {
+	return func(opts *Options) {
+		opts.buffer = n
+	}
+}
+
+// event is delivered to subscribers; json is only sent when requested
+type event struct {
+	event *pb.Event
+	json  []byte
+}
+
+type subscriber struct {
+	ch          chan event
+	includeJSON bool
+	dropped     chan struct{}
+}
+
+// Server implements pb.ChainSyncServer, fanning out the events of a single
+// ChainSync to every subscriber
+type Server struct {
+	pb.UnimplementedChainSyncServer
+
+	mutex       sync.Mutex
+	buffer      int
+	subscribers map[*subscriber]struct{}
+}
+
+// New returns a new Server
+func New(opts ...Option) *Server {
+	options := Options{
+		buffer: DefaultBuffer,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.buffer <= 0 {
+		options.buffer = DefaultBuffer
+	}
+	return &Server{
+		buffer:      options.buffer,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// Register the ChainSync service with registrar e.g. a *grpc.Server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	pb.RegisterChainSyncServer(registrar, s)
+}
+
+// Run starts a ChainSync with client, publishing to subscribers until ctx is
+// cancelled or the sync fails
+func (s *Server) Run(
+	ctx context.Context,
+	client *ogmigo.Client,
+	opts ...ogmigo.ChainSyncOption,
+) error {
+	chainSync, err := client.ChainSync(ctx, s.ChainSyncFunc(), opts...)
+	if err != nil {
+		return err
+	}
+	<-chainSync.Done()
+	return chainSync.Close()
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that publishes each
+// nextBlock response to subscribers
+func (s *Server) ChainSyncFunc() ogmigo.ChainSyncFunc {
+	return func(_ context.Context, data []byte) error {
+		e, ok, err := toEvent(data)
+		if err != nil || !ok {
+			return err
+		}
+		s.publish(e)
+		return nil
+	}
+}
+
+// Subscribe implements pb.ChainSyncServer
+func (s *Server) Subscribe(
+	req *pb.SubscribeRequest,
+	stream pb.ChainSync_SubscribeServer,
+) error {
+	sub := &subscriber{
+		ch:          make(chan event, s.buffer),
+		includeJSON: req.GetIncludeJson(),
+		dropped:     make(chan struct{}),
+	}
+
+	s.mutex.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mutex.Unlock()
+
+	defer s.unsubscribe(sub)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e := <-sub.ch:
+			if err := stream.Send(sub.message(e)); err != nil {
+				return err
+			}
+		case <-sub.dropped:
+			return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+		}
+	}
+}
+
+// Subscribers returns the number of active subscribers
+func (s *Server) Subscribers() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.subscribers)
+}
+
+func (s *Server) publish(e event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for sub := range s.subscribers {
+		select {
+		case sub.ch <- e:
+		default:
+			close(sub.dropped)
+			delete(s.subscribers, sub)
+		}
+	}
+}
+
+func (s *Server) unsubscribe(sub *subscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.subscribers, sub)
+}
+
+// message returns the event to send; events are shared between subscribers
+// so the json is attached to a copy
+func (sub *subscriber) message(e event) *pb.Event {
+	block := e.event.GetBlock()
+	if !sub.includeJSON || block == nil {
+		return e.event
+	}
+	withJSON := &pb.Block{
+		Id:       block.Id,
+		Slot:     block.Slot,
+		Height:   block.Height,
+		Ancestor: block.Ancestor,
+		Era:      block.Era,
+		TxIds:    block.TxIds,
+		Json:     e.json,
+	}
+	return &pb.Event{
+		Tip:   e.event.Tip,
+		Event: &pb.Event_Block{Block: withJSON},
+	}
+}
+
+// toEvent converts a nextBlock response into an event
+func toEvent(data []byte) (event, bool, error) {
+	method, _ := jsonparser.GetString(data, "method")
+	if method != chainsync.NextBlockMethod {
+		return event{}, false, nil
+	}
+	raw, _, _, err := jsonparser.Get(data, "result")
+	if err != nil {
+		return event{}, false, nil
+	}
+	var result chainsync.ResultNextBlockPraos
+	if err := json.Unmarshal(raw, &result); err != nil {
+		err = fmt.Errorf("failed to decode nextBlock result: %w", err)
+		return event{}, false, err
+	}
+
+	e := &pb.Event{}
+	if result.Tip != nil {
+		e.Tip = &pb.Point{Slot: result.Tip.Slot, Id: result.Tip.ID}
+	}
+
+	switch {
+	case result.Direction == chainsync.RollForwardString && result.Block != nil:
+		b := result.Block
+		block := &pb.Block{
+			Id:       b.ID,
+			Slot:     b.Slot,
+			Height:   b.Height,
+			Ancestor: b.Ancestor,
+			Era:      b.Era,
+		}
+		for _, tx := range b.Transactions {
+			block.TxIds = append(block.TxIds, tx.ID)
+		}
+		e.Event = &pb.Event_Block{Block: block}
+		blockJSON, _, _, _ := jsonparser.Get(raw, "block")
+		return event{event: e, json: blockJSON}, true, nil
+
+	case result.Direction == chainsync.RollBackwardString && result.Point != nil:
+		point := &pb.Point{}
+		if ps, ok := result.Point.PointStruct(); ok {
+			point.Slot, point.Id = ps.Slot, ps.ID
+		}
+		e.Event = &pb.Event_Rollback{Rollback: &pb.Rollback{Point: point}}
+		return event{event: e}, true, nil
+	}
+	return event{}, false, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigogrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/ext/grpc/pb"
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ogmigotest"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func blocks(from, to uint64) []chainsync.Block {
+	var blocks []chainsync.Block
+	for slot := from; slot <= to; slot++ {
+		blocks = append(blocks, chainsync.Block{
+			Type:         "praos",
+			Era:          "babbage",
+			ID:           fmt.Sprintf("%064x", slot),
+			Height:       slot,
+			Slot:         slot,
+			Transactions: []chainsync.Tx{{ID: fmt.Sprintf("tx%v", slot)}},
+		})
+	}
+	return blocks
+}
+
+func dial(t *testing.T, hub *Server) pb.ChainSyncClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	hub.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewChainSyncClient(conn)
+}
+
+func subscribe(
+	ctx context.Context,
+	t *testing.T,
+	hub *Server,
+	client pb.ChainSyncClient,
+	req *pb.SubscribeRequest,
+) pb.ChainSync_SubscribeClient {
+	t.Helper()
+
+	want := hub.Subscribers() + 1
+	stream, err := client.Subscribe(ctx, req)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); hub.Subscribers() < want; {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for subscriber")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return stream
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		ogmios = ogmigotest.NewServer(ogmigotest.WithBlocks(blocks(1, 3)...))
+		hub    = New()
+		client = dial(t, hub)
+		plain  = subscribe(ctx, t, hub, client, &pb.SubscribeRequest{})
+		full   = subscribe(ctx, t, hub, client, &pb.SubscribeRequest{IncludeJson: true})
+	)
+	defer ogmios.Close()
+
+	go hub.Run(ctx, ogmigo.New(ogmigo.WithEndpoint(ogmios.URL)),
+		ogmigo.WithPoints(chainsync.Origin),
+	)
+
+	for _, stream := range []pb.ChainSync_SubscribeClient{plain, full} {
+		var slots []uint64
+		for len(slots) < 3 {
+			event, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			block := event.GetBlock()
+			if block == nil {
+				continue // initial rollback to the intersection
+			}
+			slots = append(slots, block.Slot)
+
+			want := fmt.Sprintf("tx%v", block.Slot)
+			if got := block.TxIds; len(got) != 1 || got[0] != want {
+				t.Fatalf("got %v; want [%v]", got, want)
+			}
+			if got, want := len(block.Json) > 0, stream == full; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if stream == full {
+				var b chainsync.Block
+				if err := json.Unmarshal(block.Json, &b); err != nil || b.ID != block.Id {
+					t.Fatalf("got %v, %v; want nil, %v", err, b.ID, block.Id)
+				}
+			}
+		}
+		if got, want := fmt.Sprint(slots), "[1 2 3]"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	ogmios.Rollback(2)
+	event, err := plain.Recv()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := event.GetRollback().GetPoint().GetSlot(), uint64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestServer_SlowSubscriber(t *testing.T) {
+	var (
+		ctx = context.Background()
+		hub = New(WithBuffer(1))
+		fn  = hub.ChainSyncFunc()
+		sub = &subscriber{
+			ch:      make(chan event, 1),
+			dropped: make(chan struct{}),
+		}
+	)
+	hub.subscribers[sub] = struct{}{}
+
+	for slot := uint64(1); slot <= 2; slot++ {
+		data, err := json.Marshal(chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result: chainsync.ResultNextBlockPraos{
+				Direction: chainsync.RollForwardString,
+				Block:     &chainsync.Block{Slot: slot, ID: "id"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if err := fn(ctx, data); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	select {
+	case <-sub.dropped:
+	default:
+		t.Fatalf("got connected; want dropped")
+	}
+	if got := hub.Subscribers(); got != 0 {
+		t.Fatalf("got %v; want 0", got)
+	}
+}
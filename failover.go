@@ -0,0 +1,172 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/gorilla/websocket"
+)
+
+// lagInterval is how frequently ChainSync compares its tip against the other
+// endpoints provided via WithEndpoints
+const lagInterval = 30 * time.Second
+
+// errFellBehind indicates the active endpoint lags another endpoint
+var errFellBehind = errors.New("ogmios endpoint fell behind")
+
+// endpoint returns the active endpoint
+func (c *Client) endpoint() string {
+	n := int64(len(c.options.endpoints))
+	return c.options.endpoints[atomic.LoadInt64(&c.active)%n]
+}
+
+// failoverEnabled returns true if more than one endpoint was provided
+func (c *Client) failoverEnabled() bool {
+	return len(c.options.endpoints) > 1
+}
+
+// failover makes the endpoint at index active
+func (c *Client) failover(index int, reason string) {
+	previous := c.endpoint()
+	atomic.StoreInt64(&c.active, int64(index%len(c.options.endpoints)))
+	if next := c.endpoint(); next != previous {
+		c.resetProtocol()
+		c.network.Store(nil)
+		c.options.logger.Warn("ogmios endpoint failover",
+			KV("from", previous),
+			KV("to", next),
+			KV("reason", reason),
+		)
+	}
+}
+
+// dial opens a new websocket connection to the active endpoint, failing over
+// to the remaining endpoints in turn if it is unreachable
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, error) {
+	var (
+		n       = len(c.options.endpoints)
+		start   = int(atomic.LoadInt64(&c.active))
+		lastErr error
+	)
+	for i := 0; i < n; i++ {
+		index := (start + i) % n
+		endpoint := c.options.endpoints[index]
+
+		conn, _, err := c.options.dialer.DialContext(ctx, endpoint, nil)
+		if err == nil {
+			if i > 0 {
+				c.failover(index, lastErr.Error())
+			}
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// networkTip returns the slot of the tip of the node behind endpoint
+func (c *Client) networkTip(
+	ctx context.Context,
+	endpoint string,
+) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, lagInterval)
+	defer cancel()
+
+	conn, _, err := c.options.dialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to connect to ogmios, %v: %w",
+			endpoint,
+			err,
+		)
+	}
+	//nolint:errcheck
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetWriteDeadline(deadline)
+	_ = conn.SetReadDeadline(deadline)
+
+	payload := makePayload("queryNetwork/tip", Map{}, nil)
+	if err := conn.WriteJSON(payload); err != nil {
+		return 0, fmt.Errorf("failed to submit request: %w", ioErr(ctx, err))
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to read json response: %w",
+			ioErr(ctx, err),
+		)
+	}
+
+	slot, err := jsonparser.GetInt(data, "result", "slot")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read network tip: %w", err)
+	}
+	return uint64(slot), nil
+}
+
+// watchLag periodically compares tip, the slot of the tip reported to
+// ChainSync, against the other endpoints, failing over to the most advanced
+// endpoint once the active endpoint lags it by more than WithFailoverLag
+func (c *Client) watchLag(ctx context.Context, tip *uint64) error {
+	ticker := time.NewTicker(c.options.lagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current := atomic.LoadUint64(tip)
+		if current == 0 {
+			continue
+		}
+
+		var (
+			active = int(atomic.LoadInt64(&c.active))
+			best   = current
+			index  = -1
+		)
+		for i, endpoint := range c.options.endpoints {
+			if i == active {
+				continue
+			}
+			slot, err := c.networkTip(ctx, endpoint)
+			if err != nil {
+				continue
+			}
+			if slot > best {
+				best, index = slot, i
+			}
+		}
+
+		if index >= 0 && best-current > c.options.failoverLag {
+			c.failover(index, errFellBehind.Error())
+			return errFellBehind
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+// deadEndpoint returns the endpoint of a server that is no longer listening
+func deadEndpoint() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestClient_failoverQuery(t *testing.T) {
+	const success = `{"jsonrpc":"2.0","method":"queryLedgerState/epoch","result":123}`
+
+	var (
+		dead        = deadEndpoint()
+		endpoint, _ = scripted(t, success)
+		client      = New(WithEndpoints(dead, endpoint), WithLogger(NopLogger))
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	epoch, err := client.CurrentEpoch(ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123, epoch)
+	assert.Equal(t, endpoint, client.endpoint())
+}
+
+func TestClient_failoverChainSync(t *testing.T) {
+	const n = 200
+
+	collect := func(events *[]string) ChainSyncFunc {
+		return func(_ context.Context, data []byte) error {
+			event, ok, err := chainsync.NewBlockEvent(data)
+			if ok && event.Type == chainsync.RollForwardEvent {
+				*events = append(*events, event.Block.ID)
+			}
+			return err
+		}
+	}
+	want := func(n int) []string {
+		var ids []string
+		for slot := 1; slot <= n; slot++ {
+			ids = append(ids, fmt.Sprintf("block%v", slot))
+		}
+		return ids
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		var (
+			endpoint = chainSyncServer(t, 10)
+			client   = New(
+				WithEndpoints(deadEndpoint(), endpoint),
+				WithLogger(NopLogger),
+			)
+			events []string
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		chainSync, err := client.ChainSync(ctx, collect(&events),
+			WithStopWhenTipReached(),
+		)
+		assert.Nil(t, err)
+		<-chainSync.Done()
+		assert.Nil(t, chainSync.Close())
+		assert.Equal(t, want(10), events)
+		assert.Equal(t, endpoint, client.endpoint())
+	})
+
+	t.Run("lag", func(t *testing.T) {
+		var (
+			behind = chainSyncServer(t, 3)
+			ahead  = chainSyncServer(t, n)
+			client = New(
+				WithEndpoints(behind, ahead),
+				WithFailoverLag(100),
+				WithLogger(NopLogger),
+			)
+			events []string
+		)
+		client.options.lagInterval = 50 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		chainSync, err := client.ChainSync(ctx, collect(&events),
+			WithStopAtSlot(n),
+		)
+		assert.Nil(t, err)
+		<-chainSync.Done()
+		assert.Nil(t, chainSync.Close())
+		assert.Equal(t, want(n), events) // no gaps or duplicates
+		assert.Equal(t, ahead, client.endpoint())
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+	"github.com/gorilla/websocket"
+)
+
+// ErrIntersectionNotFound indicates that none of the points, nor any
+// fallback, intersected the chain
+var ErrIntersectionNotFound = errors.New("intersection not found")
+
+// Fallback describes an intersection found only after the preferred points
+// were not, e.g. after a deep rollback or against a pruned node
+type Fallback struct {
+	Preferred    chainsync.Point // most recent of the preferred points
+	Intersection chainsync.Point // where the chain was intersected
+	Attempts     int             // findIntersection requests made
+}
+
+// FallbackFunc is notified when ChainSync intersects at a fallback
+type FallbackFunc func(ctx context.Context, fallback Fallback)
+
+// fallbackOptions configures WithIntersectionFallback
+type fallbackOptions struct {
+	origin   bool
+	callback FallbackFunc
+}
+
+// WithIntersectionFallback retries findIntersection with progressively older
+// points, from the store or WithPoints, when the most recent are not found.
+// Once every point has been tried, ChainSync intersects at the origin if
+// origin is true and otherwise fails with ErrIntersectionNotFound.  Callback,
+// if provided, is notified of where ChainSync landed.  By default, ChainSync
+// only attempts the most recent points.
+func WithIntersectionFallback(
+	origin bool,
+	callback FallbackFunc,
+) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.fallback = &fallbackOptions{
+			origin:   origin,
+			callback: callback,
+		}
+	}
+}
+
+// maxIntersectPoints is the number of points sent per findIntersection
+const maxIntersectPoints = 5
+
+// ladder returns the points to attempt in turn, most recent first
+func (f *fallbackOptions) ladder(points chainsync.Points) []chainsync.Points {
+	var (
+		rungs  []chainsync.Points
+		origin bool
+	)
+	for len(points) > 0 {
+		n := min(len(points), maxIntersectPoints)
+		for _, point := range points[:n] {
+			if point.PointType() == chainsync.PointTypeString {
+				origin = true
+			}
+		}
+		rungs = append(rungs, points[:n])
+		points = points[n:]
+	}
+	if f.origin && !origin {
+		rungs = append(rungs, chainsync.Points{chainsync.Origin})
+	}
+	return rungs
+}
+
+// intersect negotiates the intersection by walking down the ladder and
+// returns the findIntersection response, as received, for delivery
+func (c *Client) intersect(
+	ctx context.Context,
+	conn *websocket.Conn,
+	protocol Protocol,
+	points chainsync.Points,
+	fallback *fallbackOptions,
+) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	rungs := fallback.ladder(points)
+	for i, rung := range rungs {
+		init, err := encodeInit(rung)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create init message: %w", err)
+		}
+		if protocol == ProtocolV5 {
+			if init, err = encodeInitV5(init); err != nil {
+				return nil, fmt.Errorf("failed to create init message: %w", err)
+			}
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, init); err != nil {
+			return nil, fmt.Errorf("failed to write FindIntersect: %w", err)
+		}
+
+		raw, response, err := readIntersection(conn, protocol, c.options.archive)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		intersection, ok := getIntersection(response)
+		if !ok {
+			// the response that intersects is archived once delivered
+			c.options.archive.write(raw)
+			c.options.logger.Warn("intersection not found",
+				KV("points", rung.String()),
+				Int64("attempt", int64(i+1)),
+			)
+			continue
+		}
+
+		if i > 0 {
+			c.options.logger.Warn("intersected at fallback",
+				KV("preferred", points[0].String()),
+				KV("intersection", intersection.String()),
+			)
+			if fallback.callback != nil {
+				fallback.callback(ctx, Fallback{
+					Preferred:    points[0],
+					Intersection: intersection,
+					Attempts:     i + 1,
+				})
+			}
+		}
+		return raw, nil
+	}
+	return nil, fmt.Errorf("failed to intersect at %v: %w",
+		points.String(), ErrIntersectionNotFound)
+}
+
+// readIntersection reads the response to findIntersection, archiving and
+// skipping any unexpected binary messages.  Both the message as received and
+// its v6 equivalent are returned.
+func readIntersection(
+	conn *websocket.Conn,
+	protocol Protocol,
+	archive *frameArchive,
+) (raw, response []byte, err error) {
+	for {
+		messageType, data, err := readMessage(conn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read intersection: %w", err)
+		}
+		if messageType != websocket.TextMessage {
+			archive.write(data)
+			continue
+		}
+		response := data
+		if protocol == ProtocolV5 {
+			if response, err = decodeV5(data); err != nil {
+				return nil, nil, fmt.Errorf("failed to read intersection: %w", err)
+			}
+		}
+		return data, response, nil
+	}
+}
+
+// getIntersection returns the intersection of a findIntersection response;
+// ok is false if the intersection was not found
+func getIntersection(data []byte) (chainsync.Point, bool) {
+	raw, dataType, _, err := jsonparser.Get(data, "result", "intersection")
+	if err != nil {
+		return chainsync.Point{}, false
+	}
+	if dataType == jsonparser.String {
+		return chainsync.Origin, true
+	}
+	var point chainsync.Point
+	if err := json.Unmarshal(raw, &point); err != nil {
+		return chainsync.Point{}, false
+	}
+	return point, true
+}
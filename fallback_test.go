@@ -0,0 +1,175 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ogmigotest"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func fallbackServer(t *testing.T) *ogmigotest.Server {
+	var blocks []chainsync.Block
+	for slot := uint64(1); slot <= 5; slot++ {
+		blocks = append(blocks, chainsync.Block{
+			Type:   "praos",
+			Era:    "babbage",
+			ID:     fmt.Sprintf("block%v", slot),
+			Height: slot,
+			Slot:   slot,
+		})
+	}
+	server := ogmigotest.NewServer(ogmigotest.WithBlocks(blocks...))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func nopChainSyncFunc(context.Context, []byte) error { return nil }
+
+// pruned returns points that are not on the chain served by fallbackServer
+func pruned(n int) chainsync.Points {
+	var points chainsync.Points
+	for i := range n {
+		points = append(points, chainsync.PointStruct{
+			ID:   "pruned",
+			Slot: uint64(100 + i),
+		}.Point())
+	}
+	return points
+}
+
+func TestWithIntersectionFallback(t *testing.T) {
+	server := fallbackServer(t)
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	var (
+		fallbacks []Fallback
+		slots     []uint64
+	)
+	callback := func(_ context.Context, data []byte) error {
+		event, ok, err := chainsync.NewBlockEvent(data)
+		if ok && event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+		return err
+	}
+	onFallback := func(_ context.Context, fallback Fallback) {
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	landed := chainsync.PointStruct{ID: "block2", Slot: 2}.Point()
+	points := append(pruned(7), landed)
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithPoints(points...),
+		WithIntersectionFallback(false, onFallback),
+		WithStopAtSlot(4),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+
+	assert.Equal(t, []uint64{3, 4}, slots)
+	assert.Len(t, fallbacks, 1)
+	assert.Equal(t, 2, fallbacks[0].Attempts)
+	assert.Equal(t, landed, fallbacks[0].Intersection)
+	assert.Equal(t, points[6], fallbacks[0].Preferred)
+}
+
+func TestWithIntersectionFallback_Origin(t *testing.T) {
+	server := fallbackServer(t)
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	var fallbacks []Fallback
+	onFallback := func(_ context.Context, fallback Fallback) {
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithPoints(pruned(6)...),
+		WithIntersectionFallback(true, onFallback),
+		WithStopAtSlot(1),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+
+	assert.Len(t, fallbacks, 1)
+	assert.Equal(t, 3, fallbacks[0].Attempts)
+	assert.Equal(t, chainsync.Origin, fallbacks[0].Intersection)
+}
+
+func TestWithIntersectionFallback_NotFound(t *testing.T) {
+	server := fallbackServer(t)
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithPoints(pruned(3)...),
+		WithIntersectionFallback(false, nil),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+
+	err = chainSync.Close()
+	assert.True(t, errors.Is(err, ErrIntersectionNotFound))
+}
+
+func TestWithIntersectionFallback_Archive(t *testing.T) {
+	server := fallbackServer(t)
+
+	var archive lockedBuffer
+	client := New(
+		WithEndpoint(server.URL),
+		WithLogger(NopLogger),
+		WithFrameArchive(&archive),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	landed := chainsync.PointStruct{ID: "block2", Slot: 2}.Point()
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithPoints(append(pruned(7), landed)...),
+		WithIntersectionFallback(false, nil),
+		WithStopAtSlot(4),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+
+	// both the failed and the successful findIntersection are archived
+	var intersections int
+	for _, line := range strings.Split(archive.String(), "\n") {
+		if strings.Contains(line, chainsync.FindIntersectionMethod) {
+			intersections++
+		}
+	}
+	assert.Equal(t, 2, intersections)
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// frameArchive writes each raw inbound frame verbatim to the underlying
+// writer, newline terminated unless the frame already is
+type frameArchive struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	logger Logger
+}
+
+// write archives the frame; failures are logged rather than interrupting the
+// caller.  write is a no-op on a nil archive
+func (a *frameArchive) write(frame []byte) {
+	if a == nil {
+		return
+	}
+
+	data := frame
+	if !bytes.HasSuffix(frame, []byte("\n")) {
+		data = make([]byte, 0, len(frame)+1)
+		data = append(data, frame...)
+		data = append(data, '\n')
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, err := a.w.Write(data); err != nil {
+		a.logger.Error("failed to archive frame", Err(err))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+func TestWithFrameArchive(t *testing.T) {
+	endpoint, _ := multiplexServer(t, 0)
+
+	for _, persistent := range []bool{false, true} {
+		var buf lockedBuffer
+		client := New(
+			WithEndpoint(endpoint),
+			WithLogger(NopLogger),
+			WithFrameArchive(&buf),
+			WithPersistentConnection(persistent),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		for i := 1; i <= 3; i++ {
+			payload := makePayload("queryLedgerState/epoch", Map{"n": i}, nil)
+			assert.Nil(t, client.query(ctx, payload, nil))
+		}
+		cancel()
+		assert.Nil(t, client.Close())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 3)
+		for _, line := range lines {
+			assert.Contains(t, line, `"jsonrpc":"2.0"`)
+		}
+	}
+}
@@ -3,6 +3,7 @@ module github.com/SundaeSwap-finance/ogmigo/v6
 go 1.24.0
 
 require (
+	filippo.io/edwards25519 v1.1.0
 	github.com/aws/aws-sdk-go v1.44.197
 	github.com/btcsuite/btcutil v1.0.2
 	github.com/buger/jsonparser v1.1.2
@@ -10,11 +11,14 @@ require (
 	github.com/gorilla/websocket v1.5.0
 	github.com/nsf/jsondiff v0.0.0-20210926074059-1e845ec5d249
 	github.com/rs/zerolog v1.34.0
+	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.8.1
 	github.com/tj/assert v0.0.3
 	go.uber.org/zap v1.27.0
+	golang.org/x/crypto v0.13.0
 	golang.org/x/sync v0.1.0
-	golang.org/x/text v0.7.0
+	golang.org/x/text v0.13.0
+	golang.org/x/time v0.5.0
 )
 
 require (
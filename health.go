@@ -0,0 +1,169 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/gorilla/websocket"
+)
+
+// ConnectionStatus values reported by the ogmios health endpoint
+const (
+	ConnectionStatusConnected    = "connected"
+	ConnectionStatusDisconnected = "disconnected"
+)
+
+// Health of the ogmios server as reported by its /health endpoint
+type Health struct {
+	StartTime              time.Time        `json:"startTime"`
+	LastKnownTip           *chainsync.Point `json:"lastKnownTip,omitempty"`
+	LastTipUpdate          *time.Time       `json:"lastTipUpdate,omitempty"`
+	NetworkSynchronization float64          `json:"networkSynchronization"`
+	CurrentEra             string           `json:"currentEra"`
+	Metrics                HealthMetrics    `json:"metrics"`
+	ConnectionStatus       string           `json:"connectionStatus"`
+	CurrentEpoch           uint64           `json:"currentEpoch"`
+	SlotInEpoch            uint64           `json:"slotInEpoch"`
+	Version                string           `json:"version"`
+	Network                string           `json:"network"`
+}
+
+// HealthMetrics reported by the ogmios health endpoint
+type HealthMetrics struct {
+	ActiveConnections uint64 `json:"activeConnections"`
+	RuntimeStats      struct {
+		CPUTime         uint64 `json:"cpuTime"`
+		CurrentHeapSize uint64 `json:"currentHeapSize"`
+		GCCPUTime       uint64 `json:"gcCpuTime"`
+		MaxHeapSize     uint64 `json:"maxHeapSize"`
+	} `json:"runtimeStats"`
+	SessionDurations struct {
+		Max  float64 `json:"max"`
+		Mean float64 `json:"mean"`
+		Min  float64 `json:"min"`
+	} `json:"sessionDurations"`
+	TotalConnections uint64 `json:"totalConnections"`
+	TotalMessages    uint64 `json:"totalMessages"`
+	TotalUnrouted    uint64 `json:"totalUnrouted"`
+}
+
+// Synced returns true if ogmios is connected to a node that has fully
+// synchronized with the network
+func (h Health) Synced() bool {
+	return h.ConnectionStatus == ConnectionStatusConnected &&
+		h.NetworkSynchronization >= 1
+}
+
+// Health queries the ogmios http /health endpoint, e.g. to wait for the node
+// to be synced prior to starting ChainSync
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	endpoint, err := healthURL(c.endpoint())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health request: %w", err)
+	}
+
+	client := healthClient(c.options.dialer)
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query health, %v: %w", endpoint, err)
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read health response: %w", err)
+	}
+
+	// ogmios reports 503 when it is unable to reach the node, and still
+	// includes the health payload
+	if resp.StatusCode/100 != 2 &&
+		resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf(
+			"failed to query health, %v: unexpected status %v",
+			endpoint,
+			resp.StatusCode,
+		)
+	}
+
+	var health Health
+	if err := json.Unmarshal(data, &health); err != nil {
+		return nil, fmt.Errorf("failed to decode health response: %w", err)
+	}
+	return &health, nil
+}
+
+// healthClient returns an http client that connects the way dialer does,
+// i.e. via the same proxy, dial hooks and tls configuration
+func healthClient(dialer *websocket.Dialer) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = dialer.Proxy
+	switch {
+	case dialer.NetDialContext != nil:
+		transport.DialContext = dialer.NetDialContext
+	case dialer.NetDial != nil:
+		netDial := dialer.NetDial
+		transport.DialContext = func(
+			_ context.Context,
+			network, addr string,
+		) (net.Conn, error) {
+			return netDial(network, addr)
+		}
+	}
+	if dialer.NetDialTLSContext != nil {
+		transport.DialTLSContext = dialer.NetDialTLSContext
+	}
+	if dialer.TLSClientConfig != nil {
+		transport.TLSClientConfig = dialer.TLSClientConfig
+	}
+	return &http.Client{Transport: transport}
+}
+
+// healthURL maps the websocket endpoint to the http health endpoint, keeping
+// any path prefix e.g. of a gateway
+func healthURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse endpoint, %v: %w", endpoint, err)
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	u.Path = path.Join("/", u.Path, "health")
+	u.RawPath = ""
+	u.RawQuery = ""
+	return u.String(), nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+const healthResponse = `{
+  "startTime": "2024-01-02T03:04:05.678Z",
+  "lastKnownTip": {"slot": 123, "id": "abc", "height": 45},
+  "lastTipUpdate": "2024-01-02T03:04:06.000Z",
+  "networkSynchronization": 1.0,
+  "currentEra": "babbage",
+  "metrics": {
+    "activeConnections": 2,
+    "runtimeStats": {"cpuTime": 1, "currentHeapSize": 2, "gcCpuTime": 3, "maxHeapSize": 4},
+    "sessionDurations": {"max": 3, "mean": 2, "min": 1},
+    "totalConnections": 10,
+    "totalMessages": 100,
+    "totalUnrouted": 0
+  },
+  "connectionStatus": "connected",
+  "currentEpoch": 7,
+  "slotInEpoch": 8,
+  "version": "v6.0.0",
+  "network": "mainnet"
+}`
+
+func TestClient_Health(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(healthResponse))
+	}))
+	defer server.Close()
+
+	client := New(WithEndpoint("ws" + strings.TrimPrefix(server.URL, "http")))
+	health, err := client.Health(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, health.Synced())
+	assert.Equal(t, "babbage", health.CurrentEra)
+	assert.EqualValues(t, 7, health.CurrentEpoch)
+	assert.EqualValues(t, 100, health.Metrics.TotalMessages)
+
+	ps, ok := health.LastKnownTip.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 123, ps.Slot)
+
+	health.ConnectionStatus = ConnectionStatusDisconnected
+	assert.False(t, health.Synced())
+}
+
+func TestClient_HealthDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/ogmios/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(healthResponse))
+	}))
+	defer server.Close()
+
+	// the endpoint does not resolve; only the dialer knows the way
+	dialer := &websocket.Dialer{
+		NetDialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	client := New(
+		WithEndpoint("ws://ogmios.invalid/ogmios"),
+		WithDialer(dialer),
+	)
+	health, err := client.Health(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "babbage", health.CurrentEra)
+}
+
+func TestClient_HealthUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"connectionStatus":"disconnected","networkSynchronization":0.5,"lastKnownTip":"origin"}`))
+	}))
+	defer server.Close()
+
+	client := New(WithEndpoint("ws" + strings.TrimPrefix(server.URL, "http")))
+	health, err := client.Health(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, health.Synced())
+
+	_, ok := health.LastKnownTip.PointStruct()
+	assert.False(t, ok)
+}
+
+func TestHealthURL(t *testing.T) {
+	tests := map[string]struct {
+		endpoint string
+		want     string
+	}{
+		"root": {
+			endpoint: "ws://127.0.0.1:1337",
+			want:     "http://127.0.0.1:1337/health",
+		},
+		"trailing slash": {
+			endpoint: "ws://127.0.0.1:1337/",
+			want:     "http://127.0.0.1:1337/health",
+		},
+		"query": {
+			endpoint: "wss://example.com:1337/?q=1",
+			want:     "https://example.com:1337/health",
+		},
+		"path prefix": {
+			endpoint: "wss://example.com/ogmios",
+			want:     "https://example.com/ogmios/health",
+		},
+		"nested prefix": {
+			endpoint: "wss://example.com/api/v1/ogmios/?key=secret",
+			want:     "https://example.com/api/v1/ogmios/health",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := healthURL(tc.endpoint)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// Interceptor wraps a ChainSyncFunc, allowing cross-cutting concerns such as
+// metrics, logging, or panic recovery to be layered around the callback
+type Interceptor func(next ChainSyncFunc) ChainSyncFunc
+
+// WithInterceptor wraps the ChainSync callback with the interceptors.  May be
+// specified more than once; the first interceptor specified is outermost and
+// so sees each message first
+func WithInterceptor(interceptors ...Interceptor) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.interceptors = append(opts.interceptors, interceptors...)
+	}
+}
+
+// intercept returns callback wrapped by the interceptors, outermost first
+func intercept(
+	callback ChainSyncFunc,
+	interceptors []Interceptor,
+) ChainSyncFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] != nil {
+			callback = interceptors[i](callback)
+		}
+	}
+	return callback
+}
+
+// RecoverInterceptor converts a panic within the callback into an error,
+// stopping ChainSync rather than crashing the process
+func RecoverInterceptor(next ChainSyncFunc) ChainSyncFunc {
+	return func(ctx context.Context, data []byte) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("callback panicked: %v\n%s", r, debug.Stack())
+			}
+		}()
+		return next(ctx, data)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestIntercept(t *testing.T) {
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(next ChainSyncFunc) ChainSyncFunc {
+			return func(ctx context.Context, data []byte) error {
+				calls = append(calls, name+":before")
+				err := next(ctx, data)
+				calls = append(calls, name+":after")
+				return err
+			}
+		}
+	}
+	callback := func(context.Context, []byte) error {
+		calls = append(calls, "callback")
+		return nil
+	}
+
+	fn := intercept(callback, []Interceptor{record("a"), nil, record("b")})
+	assert.Nil(t, fn(context.Background(), nil))
+	assert.Equal(t,
+		[]string{"a:before", "b:before", "callback", "b:after", "a:after"},
+		calls,
+	)
+}
+
+func TestRecoverInterceptor(t *testing.T) {
+	fn := RecoverInterceptor(func(context.Context, []byte) error {
+		panic("boom")
+	})
+	err := fn(context.Background(), nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "boom"))
+
+	want := errors.New("want")
+	fn = RecoverInterceptor(func(context.Context, []byte) error { return want })
+	assert.Equal(t, want, fn(context.Background(), nil))
+}
+
+func TestClient_ChainSyncInterceptor(t *testing.T) {
+	endpoint := chainSyncServer(t, 5)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var intercepted, delivered int
+	counter := func(next ChainSyncFunc) ChainSyncFunc {
+		return func(ctx context.Context, data []byte) error {
+			intercepted++
+			return next(ctx, data)
+		}
+	}
+	callback := func(context.Context, []byte) error {
+		if delivered++; delivered == 3 {
+			panic("boom")
+		}
+		return nil
+	}
+
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithInterceptor(counter),
+		WithInterceptor(RecoverInterceptor),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+
+	err = chainSync.Close()
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "callback panicked"))
+	assert.Equal(t, 3, intercepted)
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// Blocks returns an iterator over events from the given points, or origin if
+// none are given.  Iteration ends cleanly once ctx is cancelled; breaking out
+// of the loop stops chain sync and closes the connection.  If chain sync
+// fails, the error is yielded as the final element
+func (c *Client) Blocks(
+	ctx context.Context,
+	points ...chainsync.Point,
+) iter.Seq2[chainsync.BlockEvent, error] {
+	return c.blockSeq(ctx, c.BlocksChan, points...)
+}
+
+// Headers is Blocks, but each Block carries only the header; the transactions
+// are neither decoded nor delivered
+func (c *Client) Headers(
+	ctx context.Context,
+	points ...chainsync.Point,
+) iter.Seq2[chainsync.BlockEvent, error] {
+	return c.blockSeq(ctx, c.HeadersChan, points...)
+}
+
+func (c *Client) blockSeq(
+	ctx context.Context,
+	open func(
+		ctx context.Context,
+		points ...chainsync.Point,
+	) (<-chan chainsync.BlockEvent, error),
+	points ...chainsync.Point,
+) iter.Seq2[chainsync.BlockEvent, error] {
+	return func(yield func(chainsync.BlockEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		events, err := open(ctx, points...)
+		if err != nil {
+			yield(chainsync.BlockEvent{}, err)
+			return
+		}
+		defer func() {
+			cancel()
+			for range events {
+				// wait for chain sync to stop
+			}
+		}()
+
+		for event := range events {
+			if event.Err != nil {
+				yield(chainsync.BlockEvent{}, event.Err)
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+// BlockRange returns an iterator over events from the given point through
+// toSlot inclusive; 0 iterates indefinitely.  If chain sync fails, the final
+// event carries the error in Err
+func (c *Client) BlockRange(
+	ctx context.Context,
+	from chainsync.Point,
+	toSlot uint64,
+) iter.Seq[chainsync.BlockEvent] {
+	return func(yield func(chainsync.BlockEvent) bool) {
+		for event, err := range c.Blocks(ctx, from) {
+			if err != nil {
+				yield(chainsync.BlockEvent{Err: err})
+				return
+			}
+			if !yield(event) {
+				return
+			}
+			if event.Type == chainsync.RollForwardEvent && toSlot > 0 &&
+				event.Block != nil && event.Block.Slot >= toSlot {
+				return
+			}
+		}
+	}
+}
+
+// UtxosByAddressSeq returns an iterator over the utxos held by the addresses.
+// Utxos are read from the socket and decoded one at a time as the loop
+// advances, so memory use stays bounded for even the largest addresses.
+//
+// Each step yields a shared.Utxo and an error rather than a bare TxOut: the
+// Utxo keeps the output reference (Transaction and Index), and a query or decode
+// failure part way through the stream is reported as a final non-nil error
+// instead of being lost. A TxOut-only view is a matter of ranging over the
+// results and reading Utxo.Address, Utxo.Value, etc.
+func (c *Client) UtxosByAddressSeq(
+	ctx context.Context,
+	addresses ...string,
+) iter.Seq2[shared.Utxo, error] {
+	payload := makePayload(
+		"queryLedgerState/utxo",
+		Map{"addresses": addresses},
+		nil,
+	)
+	return c.utxoSeq(ctx, payload, "failed to query utxos by address")
+}
+
+// UtxosByTxInSeq returns an iterator over the utxos for the given references.
+// Utxos are read from the socket and decoded one at a time as the loop advances
+func (c *Client) UtxosByTxInSeq(
+	ctx context.Context,
+	txIns ...chainsync.TxInQuery,
+) iter.Seq2[shared.Utxo, error] {
+	payload := makePayload(
+		"queryLedgerState/utxo",
+		Map{"outputReferences": txIns},
+		nil,
+	)
+	return c.utxoSeq(ctx, payload, "failed to query utxos by tx in")
+}
+
+func (c *Client) utxoSeq(
+	ctx context.Context,
+	payload Map,
+	message string,
+) iter.Seq2[shared.Utxo, error] {
+	return func(yield func(shared.Utxo, error) bool) {
+		decode := func(decoder *json.Decoder) error {
+			var utxo shared.Utxo
+			if err := decoder.Decode(&utxo); err != nil {
+				return fmt.Errorf("failed to decode utxo: %w", err)
+			}
+			if !yield(utxo, nil) {
+				return errStopStream
+			}
+			return nil
+		}
+
+		var err error
+		if c.options.archive != nil {
+			err = c.bufferedResult(ctx, payload, decode) // archive needs the frame
+		} else {
+			err = c.streamResult(ctx, payload, decode)
+		}
+		if err != nil && !errors.Is(err, errStopStream) {
+			yield(shared.Utxo{}, fmt.Errorf("%v: %w", message, err))
+		}
+	}
+}
+
+// bufferedResult is streamResult, but reads the whole frame via query first
+func (c *Client) bufferedResult(
+	ctx context.Context,
+	payload Map,
+	fn func(decoder *json.Decoder) error,
+) error {
+	var raw json.RawMessage
+	if err := c.query(ctx, payload, &raw); err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	return decodeStream(decoder, methodName(payload), fn)
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestClient_BlockRange(t *testing.T) {
+	endpoint := chainSyncServer(t, 10)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var slots []uint64
+	for event := range client.BlockRange(ctx, chainsync.Origin, 5) {
+		assert.Nil(t, event.Err)
+		if event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+	}
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, slots)
+
+	slots = nil
+	for event := range client.BlockRange(ctx, chainsync.Origin, 0) {
+		if event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+			if len(slots) == 2 {
+				break
+			}
+		}
+	}
+	assert.Equal(t, []uint64{1, 2}, slots)
+}
+
+func TestClient_Blocks(t *testing.T) {
+	endpoint := chainSyncServer(t, 3)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var slots []uint64
+	for event, err := range client.Blocks(ctx) {
+		assert.Nil(t, err)
+		if event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+			if event.Block.Slot == 3 {
+				cancel() // the server awaits the next block indefinitely
+			}
+		}
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+
+	client = New(WithEndpoint("ws://127.0.0.1:1"), WithLogger(NopLogger))
+	var errs int
+	for _, err := range client.Blocks(context.Background()) {
+		assert.NotNil(t, err)
+		errs++
+	}
+	assert.Equal(t, 1, errs)
+}
+
+func TestClient_UtxosByAddressSeq(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","result":[`+
+			`{"transaction":{"id":"a"},"index":0,"address":"addr1","value":{"ada":{"lovelace":1}}},`+
+			`{"transaction":{"id":"b"},"index":1,"address":"addr1","value":{"ada":{"lovelace":2}}}]}`,
+		`{"jsonrpc":"2.0","error":{"code":2001,"message":"boom"}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	ctx := context.Background()
+
+	var ids []string
+	for utxo, err := range client.UtxosByAddressSeq(ctx, "addr1") {
+		assert.Nil(t, err)
+		ids = append(ids, utxo.Transaction.ID)
+	}
+	assert.Equal(t, []string{"a", "b"}, ids)
+
+	var errs int
+	for _, err := range client.UtxosByAddressSeq(ctx, "addr1") {
+		assert.NotNil(t, err)
+		errs++
+	}
+	assert.Equal(t, 1, errs)
+}
+
+func TestClient_UtxosByAddressSeq_Stream(t *testing.T) {
+	utxo := `{"transaction":{"id":"a"},"index":0,"address":"addr1","value":{"ada":{"lovelace":1}}}`
+	endpoint, requests := scripted(t,
+		`{"jsonrpc":"2.0","error":{"code":2003,"message":"acquired expired"}}`,
+		`{"jsonrpc":"2.0","method":"queryLedgerState/utxo","result":[`+utxo+`,`+utxo+`,`+utxo+`],"id":null}`,
+		`{"jsonrpc":"2.0","result":null}`,
+		`{"jsonrpc":"2.0","error":{"code":2001,"message":"boom"}}`,
+	)
+	client := New(
+		WithEndpoint(endpoint),
+		WithLogger(NopLogger),
+		WithRetry(1, ConstantBackoff(0)),
+	)
+	ctx := context.Background()
+
+	var n int
+	for _, err := range client.UtxosByAddressSeq(ctx, "addr1") {
+		assert.Nil(t, err)
+		if n++; n == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, n)
+	assert.EqualValues(t, 2, atomic.LoadInt64(requests))
+
+	for range client.UtxosByAddressSeq(ctx, "addr1") {
+		t.Fatalf("got utxo; want none")
+	}
+
+	var errs int
+	for _, err := range client.UtxosByAddressSeq(ctx, "addr1") {
+		var rpcErr *RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, 2001, rpcErr.Code)
+		assert.Equal(t, "queryLedgerState/utxo", rpcErr.Method)
+		errs++
+	}
+	assert.Equal(t, 1, errs)
+}
+
+func TestClient_UtxosByAddressSeq_Archive(t *testing.T) {
+	response := `{"jsonrpc":"2.0","result":[` +
+		`{"transaction":{"id":"a"},"index":0,"address":"addr1","value":{"ada":{"lovelace":1}}}]}`
+	endpoint, _ := scripted(t, response)
+
+	var archive bytes.Buffer
+	client := New(
+		WithEndpoint(endpoint),
+		WithLogger(NopLogger),
+		WithFrameArchive(&archive),
+	)
+
+	var ids []string
+	for utxo, err := range client.UtxosByAddressSeq(context.Background(), "addr1") {
+		assert.Nil(t, err)
+		ids = append(ids, utxo.Transaction.ID)
+	}
+	assert.Equal(t, []string{"a"}, ids)
+	assert.Equal(t, response+"\n", archive.String())
+}
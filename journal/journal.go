@@ -0,0 +1,307 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package journal assigns sequence numbers to the blocks streamed via
+// ChainSync and, on rollback, reports the range of sequence numbers that was
+// invalidated.  Downstream consumers, e.g. queues or databases, may use the
+// ranges to compensate for events that are no longer on chain.
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// DefaultDepth is the number of recent sequence numbers retained by default;
+// it matches the security parameter, k, of mainnet
+const DefaultDepth = 2160
+
+// ErrRollbackTooDeep indicates a rollback past the oldest retained sequence
+// number; the invalidated range cannot be determined
+var ErrRollbackTooDeep = errors.New("rollback exceeds journal depth")
+
+// Kind distinguishes forward records from rollback records
+type Kind int
+
+const (
+	// Forward records a block rolled forward
+	Forward Kind = iota + 1
+	// Rollback records a roll backward along with the range it invalidated
+	Rollback
+)
+
+// String implements fmt.Stringer
+func (k Kind) String() string {
+	switch k {
+	case Forward:
+		return "forward"
+	case Rollback:
+		return "rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// Range is an inclusive range of sequence numbers.  A Range where From
+// exceeds To is empty.
+type Range struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// Empty returns true if the range contains no sequence numbers
+func (r Range) Empty() bool {
+	return r.From > r.To
+}
+
+// Contains returns true if seq is within the range
+func (r Range) Contains(seq uint64) bool {
+	return seq >= r.From && seq <= r.To
+}
+
+// Record is delivered to the Func for each block rolled forward or backward.
+// Sequence numbers increase monotonically and are never reused, so the ranges
+// of successive rollbacks may overlap; compensation should be idempotent.
+type Record struct {
+	Kind        Kind            `json:"kind"`
+	Seq         uint64          `json:"seq,omitempty"`         // set on Forward
+	Invalidated *Range          `json:"invalidated,omitempty"` // set on Rollback
+	Point       chainsync.Point `json:"point"`
+	Data        json.RawMessage `json:"data"` // the chainsync response
+}
+
+// Func receives each Record in chain order
+type Func func(ctx context.Context, record Record) error
+
+// Mark associates a sequence number with the slot of its block
+type Mark struct {
+	Seq  uint64 `json:"seq"`
+	Slot uint64 `json:"slot"`
+}
+
+// State captures the journal so numbering may resume after a restart.  State
+// is json serializable.
+type State struct {
+	Next  uint64 `json:"next"`            // next sequence number to assign
+	Floor uint64 `json:"floor,omitempty"` // oldest slot a rollback may target
+	Marks []Mark `json:"marks,omitempty"`
+}
+
+// Options configures a Journal
+type Options struct {
+	depth int
+	state *State
+}
+
+// Option provides the functional options pattern for Journal
+type Option func(*Options)
+
+// WithDepth sets the number of recent sequence numbers that may be rolled back
+func WithDepth(n int) Option {
+	return func(opts *Options) {
+		opts.depth = n
+	}
+}
+
+// WithState resumes numbering from a State previously returned by State
+func WithState(state State) Option {
+	return func(opts *Options) {
+		opts.state = &state
+	}
+}
+
+// Journal numbers chainsync responses and tracks the sequence numbers of
+// recent blocks.  Journal is safe for concurrent use.
+type Journal struct {
+	mutex    sync.Mutex
+	callback Func
+	depth    int
+	next     uint64
+	floor    uint64
+	marks    []Mark
+}
+
+// New returns a Journal that delivers records to the callback
+func New(callback Func, opts ...Option) *Journal {
+	options := Options{
+		depth: DefaultDepth,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.depth <= 0 {
+		options.depth = DefaultDepth
+	}
+
+	j := &Journal{
+		callback: callback,
+		depth:    options.depth,
+		next:     1,
+	}
+	if state := options.state; state != nil {
+		if state.Next > 0 {
+			j.next = state.Next
+		}
+		j.floor = state.Floor
+		j.marks = append(j.marks, state.Marks...)
+		j.trim()
+	}
+	return j
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that journals each
+// nextBlock response; other responses are ignored
+func (j *Journal) ChainSyncFunc() func(
+	ctx context.Context,
+	data []byte,
+) error {
+	return func(ctx context.Context, data []byte) error {
+		method, _ := jsonparser.GetString(data, "method")
+		if method != chainsync.NextBlockMethod {
+			return nil
+		}
+
+		direction, _ := jsonparser.GetString(data, "result", "direction")
+		switch direction {
+		case chainsync.RollForwardString:
+			slot, err := jsonparser.GetInt(data, "result", "block", "slot")
+			if err != nil {
+				return fmt.Errorf("failed to decode block slot: %w", err)
+			}
+			id, _ := jsonparser.GetString(data, "result", "block", "id")
+			point := chainsync.PointStruct{ID: id, Slot: uint64(slot)}.Point()
+			return j.Forward(ctx, point, data)
+
+		case chainsync.RollBackwardString:
+			raw, dataType, _, err := jsonparser.Get(data, "result", "point")
+			if err != nil {
+				return fmt.Errorf("failed to decode rollback point: %w", err)
+			}
+			point := chainsync.Origin
+			if dataType != jsonparser.String {
+				if err := json.Unmarshal(raw, &point); err != nil {
+					return fmt.Errorf("failed to decode rollback point: %w", err)
+				}
+			}
+			return j.Rollback(ctx, point, data)
+		}
+		return nil
+	}
+}
+
+// Forward assigns the next sequence number to the block at point and delivers
+// the record
+func (j *Journal) Forward(
+	ctx context.Context,
+	point chainsync.Point,
+	data []byte,
+) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var slot uint64
+	if ps, ok := point.PointStruct(); ok {
+		slot = ps.Slot
+	}
+	record := Record{
+		Kind:  Forward,
+		Seq:   j.next,
+		Point: point,
+		Data:  data,
+	}
+	if err := j.callback(ctx, record); err != nil {
+		return err
+	}
+
+	j.marks = append(j.marks, Mark{Seq: j.next, Slot: slot})
+	j.next++
+	j.trim()
+	return nil
+}
+
+// Rollback invalidates the sequence numbers of every block after point and
+// delivers the record.  Rolling back to the origin invalidates every sequence
+// number assigned.
+func (j *Journal) Rollback(
+	ctx context.Context,
+	point chainsync.Point,
+	data []byte,
+) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	ps, ok := point.PointStruct()
+	if ok && ps.Slot < j.floor {
+		return fmt.Errorf("failed to rollback to slot %v: %w",
+			ps.Slot, ErrRollbackTooDeep)
+	}
+
+	i := 0
+	if ok {
+		i = len(j.marks)
+		for i > 0 && j.marks[i-1].Slot > ps.Slot {
+			i--
+		}
+	}
+
+	invalidated := Range{From: j.next, To: j.next - 1}
+	switch {
+	case !ok:
+		invalidated.From = 1 // origin
+	case i < len(j.marks):
+		invalidated.From = j.marks[i].Seq
+	}
+
+	record := Record{
+		Kind:        Rollback,
+		Invalidated: &invalidated,
+		Point:       point,
+		Data:        data,
+	}
+	if err := j.callback(ctx, record); err != nil {
+		return err
+	}
+
+	j.marks = j.marks[:i]
+	if !ok {
+		j.floor = 0
+	}
+	return nil
+}
+
+// State returns a copy of the journal state
+func (j *Journal) State() State {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return State{
+		Next:  j.next,
+		Floor: j.floor,
+		Marks: append([]Mark(nil), j.marks...),
+	}
+}
+
+// trim assumes the caller holds the mutex
+func (j *Journal) trim() {
+	if n := len(j.marks) - j.depth; n > 0 {
+		j.floor = j.marks[n-1].Slot
+		j.marks = append(j.marks[:0], j.marks[n:]...)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func point(slot uint64) chainsync.Point {
+	return chainsync.PointStruct{ID: "block", Slot: slot}.Point()
+}
+
+func record(records *[]Record) Func {
+	return func(_ context.Context, record Record) error {
+		*records = append(*records, record)
+		return nil
+	}
+}
+
+func TestJournal(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		records []Record
+		journal = New(record(&records))
+	)
+	for _, slot := range []uint64{1, 2, 3} {
+		assert.Nil(t, journal.Forward(ctx, point(slot), nil))
+	}
+	assert.Nil(t, journal.Rollback(ctx, point(1), nil))
+	assert.Nil(t, journal.Forward(ctx, point(4), nil))
+	assert.Nil(t, journal.Rollback(ctx, point(4), nil))
+
+	assert.Len(t, records, 6)
+	for i, seq := range []uint64{1, 2, 3} {
+		assert.Equal(t, Forward, records[i].Kind)
+		assert.Equal(t, seq, records[i].Seq)
+	}
+	assert.Equal(t, Rollback, records[3].Kind)
+	assert.Equal(t, Range{From: 2, To: 3}, *records[3].Invalidated)
+	assert.EqualValues(t, 4, records[4].Seq)
+	assert.True(t, records[5].Invalidated.Empty())
+
+	assert.Nil(t, journal.Rollback(ctx, chainsync.Origin, nil))
+	invalidated := records[6].Invalidated
+	assert.Equal(t, Range{From: 1, To: 4}, *invalidated)
+	assert.True(t, invalidated.Contains(2))
+	assert.False(t, invalidated.Contains(5))
+}
+
+func TestJournal_Depth(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		records []Record
+		journal = New(record(&records), WithDepth(2))
+	)
+	for _, slot := range []uint64{1, 2, 3} {
+		assert.Nil(t, journal.Forward(ctx, point(slot), nil))
+	}
+
+	err := journal.Rollback(ctx, point(0), nil)
+	assert.True(t, errors.Is(err, ErrRollbackTooDeep))
+
+	assert.Nil(t, journal.Rollback(ctx, point(1), nil))
+	assert.Equal(t, Range{From: 2, To: 3}, *records[3].Invalidated)
+}
+
+func TestJournal_State(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		records []Record
+		journal = New(record(&records))
+	)
+	assert.Nil(t, journal.Forward(ctx, point(1), nil))
+	assert.Nil(t, journal.Forward(ctx, point(2), nil))
+
+	data, err := json.Marshal(journal.State())
+	assert.Nil(t, err)
+
+	var state State
+	assert.Nil(t, json.Unmarshal(data, &state))
+
+	records = nil
+	resumed := New(record(&records), WithState(state))
+	assert.Nil(t, resumed.Forward(ctx, point(3), nil))
+	assert.Nil(t, resumed.Rollback(ctx, point(1), nil))
+
+	assert.EqualValues(t, 3, records[0].Seq)
+	assert.Equal(t, Range{From: 2, To: 3}, *records[1].Invalidated)
+}
+
+func TestJournal_CallbackError(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		boom    = errors.New("boom")
+		journal = New(func(context.Context, Record) error { return boom })
+	)
+	assert.Equal(t, boom, journal.Forward(ctx, point(1), nil))
+	assert.EqualValues(t, 1, journal.State().Next)
+}
+
+func TestJournal_ChainSyncFunc(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		records []Record
+		fn      = New(record(&records)).ChainSyncFunc()
+	)
+
+	forward, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollForwardString,
+			Block:     &chainsync.Block{ID: "block", Slot: 10},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, fn(ctx, forward))
+
+	origin := chainsync.Origin
+	backward, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &origin,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, fn(ctx, backward))
+	assert.Nil(t, fn(ctx, []byte(`{"method":"findIntersection"}`)))
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, point(10), records[0].Point)
+	assert.Equal(t, json.RawMessage(forward), records[0].Data)
+	assert.Equal(t, chainsync.Origin, records[1].Point)
+	assert.Equal(t, Range{From: 1, To: 1}, *records[1].Invalidated)
+}
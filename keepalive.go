@@ -0,0 +1,146 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// PongTimeoutError indicates ogmios stopped answering pings, as configured
+// via WithKeepAlive, and the connection was torn down
+type PongTimeoutError struct {
+	LastPong time.Time     // LastPong is when the last pong was received
+	Timeout  time.Duration // Timeout is how long a pong was awaited
+}
+
+// Error implements error interface
+func (e *PongTimeoutError) Error() string {
+	return fmt.Sprintf(
+		"ogmios unresponsive: no pong within %v, last at %v",
+		e.Timeout,
+		e.LastPong.Format(time.RFC3339),
+	)
+}
+
+// Temporary returns true so ChainSync and MonitorMempool reconnect
+func (e *PongTimeoutError) Temporary() bool {
+	return true
+}
+
+// keepAlive manages the ping and pong handling of a single connection
+type keepAlive struct {
+	conn     *websocket.Conn
+	interval time.Duration
+	timeout  time.Duration
+	lastPong int64 // atomic; unix nanos of the last pong or creation
+	pending  int64 // atomic; unix nanos the pending read began, or 0 if none
+	done     chan struct{}
+	once     sync.Once
+
+	mutex sync.Mutex
+	err   error
+}
+
+// startKeepAlive answers server pings via the ping handler and, if interval is
+// positive, pings conn every interval.  Should no pong arrive within timeout
+// while a read via readMessage is pending, conn is closed and err reports a
+// PongTimeoutError.  Call stop once the connection is no longer used
+func startKeepAlive(
+	conn *websocket.Conn,
+	interval time.Duration,
+	timeout time.Duration,
+) *keepAlive {
+	k := &keepAlive{
+		conn:     conn,
+		interval: interval,
+		timeout:  timeout,
+		lastPong: time.Now().UnixNano(),
+		done:     make(chan struct{}),
+	}
+	conn.SetPingHandler(func(data string) error {
+		deadline := time.Now().Add(time.Second)
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), deadline)
+		var ne net.Error
+		if errors.Is(err, websocket.ErrCloseSent) ||
+			(errors.As(err, &ne) && ne.Timeout()) {
+			return nil
+		}
+		return err
+	})
+	conn.SetPongHandler(func(string) error {
+		atomic.StoreInt64(&k.lastPong, time.Now().UnixNano())
+		return nil
+	})
+	if interval > 0 {
+		go k.run()
+	}
+	return k
+}
+
+func (k *keepAlive) run() {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ticker.C:
+		}
+
+		// pongs are only processed while a read is pending, so time spent
+		// elsewhere, e.g. in a slow callback, does not count toward the timeout
+		pending := atomic.LoadInt64(&k.pending)
+		last := time.Unix(0, max(atomic.LoadInt64(&k.lastPong), pending))
+		if pending > 0 && time.Since(last) > k.timeout {
+			k.mutex.Lock()
+			k.err = &PongTimeoutError{LastPong: last, Timeout: k.timeout}
+			k.mutex.Unlock()
+			_ = k.conn.Close()
+			return
+		}
+
+		deadline := time.Now().Add(k.interval)
+		if err := k.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+			return // the reader surfaces the broken connection
+		}
+	}
+}
+
+// readMessage reads the next message from conn, awaiting pongs only for as
+// long as the read is pending
+func (k *keepAlive) readMessage() (messageType int, data []byte, err error) {
+	atomic.StoreInt64(&k.pending, time.Now().UnixNano())
+	defer atomic.StoreInt64(&k.pending, 0)
+	return readMessage(k.conn)
+}
+
+// failed returns the PongTimeoutError, if any, that tore down the connection
+func (k *keepAlive) failed() error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.err
+}
+
+func (k *keepAlive) stop() {
+	k.once.Do(func() { close(k.done) })
+}
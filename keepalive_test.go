@@ -0,0 +1,224 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+// pingServer returns the endpoint of a server that reads until the client
+// disconnects, answering pings only if pong is true
+func pingServer(t *testing.T, pong bool, pings *int64) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		conn.SetPingHandler(func(data string) error {
+			atomic.AddInt64(pings, 1)
+			if !pong {
+				return nil
+			}
+			deadline := time.Now().Add(time.Second)
+			return conn.WriteControl(websocket.PongMessage, []byte(data), deadline)
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestKeepAlive(t *testing.T) {
+	var pings int64
+	endpoint := pingServer(t, true, &pings)
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	assert.Nil(t, err)
+	//nolint:errcheck
+	defer conn.Close()
+
+	keepalive := startKeepAlive(conn, 10*time.Millisecond, 50*time.Millisecond)
+	defer keepalive.stop()
+
+	// pongs are only processed while reading
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, atomic.LoadInt64(&pings) >= 5)
+	assert.Nil(t, keepalive.failed())
+}
+
+func TestKeepAlive_PongTimeout(t *testing.T) {
+	var pings int64
+	endpoint := pingServer(t, false, &pings)
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	assert.Nil(t, err)
+	//nolint:errcheck
+	defer conn.Close()
+
+	keepalive := startKeepAlive(conn, 10*time.Millisecond, 50*time.Millisecond)
+	defer keepalive.stop()
+
+	_, _, err = keepalive.readMessage()
+	assert.NotNil(t, err)
+
+	var pongErr *PongTimeoutError
+	assert.True(t, errors.As(keepalive.failed(), &pongErr))
+	assert.Equal(t, 50*time.Millisecond, pongErr.Timeout)
+	assert.True(t, isTemporaryError(pongErr))
+}
+
+func TestKeepAlive_ServerPing(t *testing.T) {
+	pongs := make(chan string, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		conn.SetPongHandler(func(data string) error {
+			pongs <- data
+			return nil
+		})
+		deadline := time.Now().Add(time.Second)
+		if err := conn.WriteControl(websocket.PingMessage, []byte("hello"), deadline); err != nil {
+			return
+		}
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.Nil(t, err)
+	//nolint:errcheck
+	defer conn.Close()
+
+	keepalive := startKeepAlive(conn, 0, 0)
+	defer keepalive.stop()
+	go func() { _, _, _ = conn.ReadMessage() }()
+
+	select {
+	case data := <-pongs:
+		assert.Equal(t, "hello", data)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("got no pong; want pong")
+	}
+}
+
+func TestClient_ChainSync_PongTimeout(t *testing.T) {
+	var pings int64
+	endpoint := pingServer(t, false, &pings)
+	client := New(
+		WithEndpoint(endpoint),
+		WithLogger(NopLogger),
+		WithProtocol(ProtocolV6),
+		WithKeepAlive(10*time.Millisecond, 50*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chainSync, err := client.ChainSync(ctx, func(context.Context, []byte) error {
+		return nil
+	})
+	assert.Nil(t, err)
+
+	select {
+	case <-chainSync.Done():
+	case <-ctx.Done():
+		t.Fatalf("got timeout; want chain sync to stop")
+	}
+	err = chainSync.Close()
+	var pongErr *PongTimeoutError
+	assert.True(t, errors.As(err, &pongErr))
+}
+
+func TestKeepAlive_Idle(t *testing.T) {
+	var pings int64
+	endpoint := pingServer(t, false, &pings)
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	assert.Nil(t, err)
+	//nolint:errcheck
+	defer conn.Close()
+
+	keepalive := startKeepAlive(conn, 10*time.Millisecond, 50*time.Millisecond)
+	defer keepalive.stop()
+
+	// no read is pending, so the missing pongs are not a timeout
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, atomic.LoadInt64(&pings) >= 5)
+	assert.Nil(t, keepalive.failed())
+}
+
+func TestClient_ChainSync_SlowCallback(t *testing.T) {
+	server := fallbackServer(t)
+	client := New(
+		WithEndpoint(server.URL),
+		WithLogger(NopLogger),
+		WithProtocol(ProtocolV6),
+		WithKeepAlive(10*time.Millisecond, 50*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var calls int64
+	chainSync, err := client.ChainSync(ctx, func(context.Context, []byte) error {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(100 * time.Millisecond) // well beyond the pong timeout
+		return nil
+	}, WithStopAtSlot(3))
+	assert.Nil(t, err)
+
+	select {
+	case <-chainSync.Done():
+	case <-ctx.Done():
+		t.Fatalf("got timeout; want chain sync to stop")
+	}
+	assert.Nil(t, chainSync.Close())
+	assert.True(t, atomic.LoadInt64(&calls) >= 3)
+}
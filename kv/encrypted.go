@@ -0,0 +1,187 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// encryptedVersion prefixes each value sealed by Encrypted
+const encryptedVersion = 1
+
+// ErrDecrypt indicates a value could not be authenticated, e.g. because it
+// was sealed with a different key or has been tampered with
+var ErrDecrypt = errors.New("kv: unable to decrypt value")
+
+// Envelope supplies the AES key used to seal each value.  The wrapped form of
+// the key is stored alongside the ciphertext so a KMS may hold the key
+// encrypting key.
+type Envelope interface {
+	// DataKey returns the key to seal a new value along with its wrapped form
+	DataKey(ctx context.Context) (key, wrapped []byte, err error)
+	// Unwrap returns the key for a wrapped key previously returned by DataKey
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+type staticKey []byte
+
+// StaticKey returns an Envelope that seals every value with key, which must
+// be 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256
+func StaticKey(key []byte) Envelope {
+	return staticKey(append([]byte(nil), key...))
+}
+
+func (k staticKey) DataKey(context.Context) ([]byte, []byte, error) {
+	return k, nil, nil
+}
+
+func (k staticKey) Unwrap(context.Context, []byte) ([]byte, error) {
+	return k, nil
+}
+
+// Encrypted is a Store decorator that encrypts values at rest with AES-GCM.
+// Keys are left in the clear; each value is bound to its key so values may
+// not be swapped between keys.
+type Encrypted struct {
+	store    Store
+	envelope Envelope
+}
+
+var _ Store = (*Encrypted)(nil)
+
+// NewEncrypted returns a Store that encrypts values before passing them to
+// store
+func NewEncrypted(store Store, envelope Envelope) *Encrypted {
+	return &Encrypted{
+		store:    store,
+		envelope: envelope,
+	}
+}
+
+// Get implements Store
+func (e *Encrypted) Get(
+	ctx context.Context,
+	key string,
+) ([]byte, bool, error) {
+	data, ok, err := e.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	value, err := Open(ctx, e.envelope, key, data)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get %v: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set implements Store
+func (e *Encrypted) Set(ctx context.Context, key string, value []byte) error {
+	data, err := Seal(ctx, e.envelope, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set %v: %w", key, err)
+	}
+	return e.store.Set(ctx, key, data)
+}
+
+// Delete implements Store
+func (e *Encrypted) Delete(ctx context.Context, key string) error {
+	return e.store.Delete(ctx, key)
+}
+
+// Seal encrypts value with a key from envelope, binding it to key.  The result
+// encodes version, wrapped key length, wrapped key, nonce, and ciphertext.
+func Seal(
+	ctx context.Context,
+	envelope Envelope,
+	key string,
+	value []byte,
+) ([]byte, error) {
+	dataKey, wrapped, err := envelope.DataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 3 + len(wrapped) + aead.NonceSize() + len(value) + aead.Overhead()
+	data := make([]byte, 3, size)
+	data[0] = encryptedVersion
+	binary.BigEndian.PutUint16(data[1:], uint16(len(wrapped)))
+	data = append(data, wrapped...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data = append(data, nonce...)
+	return aead.Seal(data, nonce, value, []byte(key)), nil
+}
+
+// Open decrypts data previously returned by Seal for the same key; ErrDecrypt
+// is returned if data cannot be authenticated
+func Open(
+	ctx context.Context,
+	envelope Envelope,
+	key string,
+	data []byte,
+) ([]byte, error) {
+	if len(data) < 3 || data[0] != encryptedVersion {
+		return nil, ErrDecrypt
+	}
+	n := int(binary.BigEndian.Uint16(data[1:]))
+	if len(data) < 3+n {
+		return nil, ErrDecrypt
+	}
+	wrapped, data := data[3:3+n], data[3+n:]
+
+	dataKey, err := envelope.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return value, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
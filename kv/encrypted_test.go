@@ -0,0 +1,138 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/tj/assert"
+)
+
+func TestEncrypted(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		memory    = NewMemory()
+		key       = bytes.Repeat([]byte{1}, 32)
+		encrypted = NewEncrypted(memory, StaticKey(key))
+	)
+
+	assert.Nil(t, encrypted.Set(ctx, "a", []byte("secret")))
+	assert.Nil(t, encrypted.Set(ctx, "b", []byte("secret")))
+
+	raw, ok, err := memory.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, bytes.Contains(raw, []byte("secret")))
+
+	// random nonces; the same value encrypts differently
+	other, _, _ := memory.Get(ctx, "b")
+	assert.NotEqual(t, raw, other)
+
+	value, ok, err := encrypted.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "secret", string(value))
+
+	_, ok, err = encrypted.Get(ctx, "missing")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// values are bound to their key
+	assert.Nil(t, memory.Set(ctx, "b", raw))
+	_, _, err = encrypted.Get(ctx, "b")
+	assert.True(t, errors.Is(err, ErrDecrypt))
+
+	// and to the key they were sealed with
+	wrong := NewEncrypted(memory, StaticKey(bytes.Repeat([]byte{2}, 32)))
+	_, _, err = wrong.Get(ctx, "a")
+	assert.True(t, errors.Is(err, ErrDecrypt))
+
+	assert.Nil(t, memory.Set(ctx, "c", []byte{1, 0}))
+	_, _, err = encrypted.Get(ctx, "c")
+	assert.True(t, errors.Is(err, ErrDecrypt))
+
+	assert.Nil(t, encrypted.Delete(ctx, "a"))
+	_, ok, _ = memory.Get(ctx, "a")
+	assert.False(t, ok)
+
+	err = NewEncrypted(memory, StaticKey([]byte("short"))).Set(ctx, "a", nil)
+	assert.NotNil(t, err)
+}
+
+// fakeKMS wraps data keys by reversing them
+type fakeKMS struct {
+	kmsiface.KMSAPI
+	generated int
+	decrypted int
+}
+
+func reverse(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func (f *fakeKMS) GenerateDataKeyWithContext(
+	_ context.Context,
+	_ *kms.GenerateDataKeyInput,
+	_ ...request.Option,
+) (*kms.GenerateDataKeyOutput, error) {
+	f.generated++
+	key := bytes.Repeat([]byte{byte(f.generated)}, 31)
+	key = append(key, 0)
+	return &kms.GenerateDataKeyOutput{
+		Plaintext:      key,
+		CiphertextBlob: reverse(key),
+	}, nil
+}
+
+func (f *fakeKMS) DecryptWithContext(
+	_ context.Context,
+	input *kms.DecryptInput,
+	_ ...request.Option,
+) (*kms.DecryptOutput, error) {
+	f.decrypted++
+	return &kms.DecryptOutput{Plaintext: reverse(input.CiphertextBlob)}, nil
+}
+
+func TestKMSEnvelope(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		memory = NewMemory()
+		client = &fakeKMS{}
+	)
+
+	encrypted := NewEncrypted(memory, NewKMSEnvelope(client, "alias/test"))
+	assert.Nil(t, encrypted.Set(ctx, "a", []byte("1")))
+	assert.Nil(t, encrypted.Set(ctx, "b", []byte("2")))
+	assert.Equal(t, 1, client.generated)
+
+	// a new envelope must unwrap the data key via kms, once
+	encrypted = NewEncrypted(memory, NewKMSEnvelope(client, "alias/test"))
+	for _, key := range []string{"a", "b"} {
+		_, ok, err := encrypted.Get(ctx, key)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 1, client.decrypted)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// KMSEnvelope is an Envelope whose data keys are generated and wrapped by AWS
+// KMS.  A single data key is generated on first use and reused for the life
+// of the envelope; unwrapped keys are cached so KMS is called at most once
+// per data key.
+type KMSEnvelope struct {
+	client kmsiface.KMSAPI
+	keyID  string
+
+	mutex   sync.Mutex
+	key     []byte
+	wrapped []byte
+	keys    map[string][]byte // wrapped -> key
+}
+
+var _ Envelope = (*KMSEnvelope)(nil)
+
+// NewKMSEnvelope returns an Envelope using the KMS key, keyID, as the key
+// encrypting key
+func NewKMSEnvelope(client kmsiface.KMSAPI, keyID string) *KMSEnvelope {
+	return &KMSEnvelope{
+		client: client,
+		keyID:  keyID,
+		keys:   map[string][]byte{},
+	}
+}
+
+// DataKey implements Envelope
+func (k *KMSEnvelope) DataKey(ctx context.Context) ([]byte, []byte, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if k.key != nil {
+		return k.key, k.wrapped, nil
+	}
+
+	output, err := k.client.GenerateDataKeyWithContext(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(k.keyID),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	k.key, k.wrapped = output.Plaintext, output.CiphertextBlob
+	k.keys[string(k.wrapped)] = k.key
+	return k.key, k.wrapped, nil
+}
+
+// Unwrap implements Envelope
+func (k *KMSEnvelope) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if key, ok := k.keys[string(wrapped)]; ok {
+		return key, nil
+	}
+
+	output, err := k.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		CiphertextBlob: wrapped,
+		KeyId:          aws.String(k.keyID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	k.keys[string(wrapped)] = output.Plaintext
+	return output.Plaintext, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kv defines the key value interface ogmigo persists small pieces of
+// state through, so they may be backed by storage of the caller's choosing:
+// checkpoints via ogmigo.NewKVStore and the ChainSync replay window via
+// ogmigo.WithStateStore.  Memory provides an in-memory store with optional
+// least recently used eviction
+package kv
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Store is a key value store.  Implementations must be safe for concurrent
+// use.  Bounded stores, e.g. Memory with WithCapacity, may evict entries at
+// any time, so a Get following a Set is not guaranteed to find the value.
+type Store interface {
+	// Get returns the value for key; ok is false if the key is not found
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key
+	Set(ctx context.Context, key string, value []byte) error
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryOptions configures a Memory store
+type MemoryOptions struct {
+	capacity int
+}
+
+// MemoryOption provides the functional options pattern for Memory
+type MemoryOption func(*MemoryOptions)
+
+// WithCapacity bounds the number of entries held; once full, the least
+// recently used entry is evicted.  Defaults to 0, unbounded
+func WithCapacity(n int) MemoryOption {
+	return func(opts *MemoryOptions) {
+		opts.capacity = n
+	}
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+// Memory is an in-memory Store with optional least recently used eviction
+type Memory struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+// NewMemory returns a new in-memory Store
+func NewMemory(opts ...MemoryOption) *Memory {
+	var options MemoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Memory{
+		capacity: options.capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get implements Store
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*entry).value, true, nil
+}
+
+// Set implements Store
+func (m *Memory) Set(_ context.Context, key string, value []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*entry).value = value
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&entry{key: key, value: value})
+	if m.capacity > 0 && m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*entry).key)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.order.Remove(element)
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of entries held
+func (m *Memory) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.order.Len()
+}
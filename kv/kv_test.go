@@ -0,0 +1,62 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+var _ Store = (*Memory)(nil)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	_, ok, err := m.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, m.Set(ctx, "a", []byte("1")))
+	assert.Nil(t, m.Set(ctx, "a", []byte("2")))
+	value, ok, err := m.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "2", string(value))
+
+	assert.Nil(t, m.Delete(ctx, "a"))
+	assert.Nil(t, m.Delete(ctx, "missing"))
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMemory_Capacity(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(WithCapacity(2))
+
+	assert.Nil(t, m.Set(ctx, "a", []byte("a")))
+	assert.Nil(t, m.Set(ctx, "b", []byte("b")))
+	_, _, _ = m.Get(ctx, "a") // b is now least recently used
+	assert.Nil(t, m.Set(ctx, "c", []byte("c")))
+
+	assert.Equal(t, 2, m.Len())
+	_, ok, _ := m.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok, _ = m.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok, _ = m.Get(ctx, "c")
+	assert.True(t, ok)
+}
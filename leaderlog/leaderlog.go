@@ -0,0 +1,226 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leaderlog computes a stake pool's slot leader schedule.  Given the
+// epoch nonce, the pool's share of the active stake, and its VRF signing key,
+// Schedule evaluates the Praos leader check for every slot in the epoch, as
+// the node will when the epoch arrives.  Leadership is only expected; a slot
+// may still be lost to slot or height battles.
+//
+// The nonce for the current epoch and the active stake snapshot are not
+// provided by ogmios; they are typically sourced from cardano-cli or a chain
+// indexer.  statequery.StakeDistribution may be used to approximate the share
+// from the live stake.
+package leaderlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/statequery"
+	"golang.org/x/crypto/blake2b"
+)
+
+// precision of the floating point used by the leader threshold
+const precision = 256
+
+// certNatMax is 2^256, the bound of the Praos leader value
+var certNatMax = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// Params describe the epoch and the pool whose schedule is to be computed
+type Params struct {
+	Epoch       uint64 // Epoch being scheduled
+	FirstSlot   uint64 // FirstSlot is the absolute slot the epoch begins at
+	EpochLength uint64 // EpochLength in slots, 432000 on mainnet
+	Nonce       []byte // Nonce is the 32 byte epoch nonce
+
+	// ActiveSlotsCoefficient, f, is the fraction of slots expected to have a
+	// leader; 1/20 on mainnet
+	ActiveSlotsCoefficient *big.Rat
+
+	// Sigma is the pool's share of the active stake
+	Sigma *big.Rat
+}
+
+func (p Params) validate() error {
+	switch {
+	case len(p.Nonce) != 32:
+		return fmt.Errorf("invalid epoch nonce length, %v", len(p.Nonce))
+	case p.EpochLength == 0:
+		return errors.New("epoch length not specified")
+	case p.ActiveSlotsCoefficient == nil ||
+		p.ActiveSlotsCoefficient.Sign() <= 0 ||
+		p.ActiveSlotsCoefficient.Cmp(big.NewRat(1, 1)) >= 0:
+		return errors.New("active slots coefficient must be within (0, 1)")
+	case p.Sigma == nil ||
+		p.Sigma.Sign() < 0 ||
+		p.Sigma.Cmp(big.NewRat(1, 1)) > 0:
+		return errors.New("sigma must be within [0, 1]")
+	}
+	return nil
+}
+
+// Sigma returns the pool's share of the stake within the distribution, e.g.
+// as returned by LiveStakeDistribution
+func Sigma(
+	distribution statequery.StakeDistribution,
+	poolID string,
+) (*big.Rat, error) {
+	pool, ok := distribution[poolID]
+	if !ok {
+		return nil, fmt.Errorf("pool not found in stake distribution, %v", poolID)
+	}
+	return pool.Fraction()
+}
+
+// Slot the pool is expected to lead
+type Slot struct {
+	Epoch       uint64 `json:"epoch"`
+	Slot        uint64 `json:"slot"`        // absolute slot
+	SlotInEpoch uint64 `json:"slotInEpoch"` // slot relative to the epoch start
+}
+
+// Schedule returns the slots within the epoch the pool is expected to lead
+func Schedule(key *SigningKey, params Params) ([]Slot, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	bound := leaderBound(params.ActiveSlotsCoefficient, params.Sigma)
+	var slots []Slot
+	for i := uint64(0); i < params.EpochLength; i++ {
+		slot := params.FirstSlot + i
+		ok, err := isLeader(key, slot, params.Nonce, bound)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			slots = append(slots, Slot{
+				Epoch:       params.Epoch,
+				Slot:        slot,
+				SlotInEpoch: i,
+			})
+		}
+	}
+	return slots, nil
+}
+
+// IsLeader evaluates the Praos leader check for a single slot
+func IsLeader(key *SigningKey, slot uint64, params Params) (bool, error) {
+	if err := params.validate(); err != nil {
+		return false, err
+	}
+	bound := leaderBound(params.ActiveSlotsCoefficient, params.Sigma)
+	return isLeader(key, slot, params.Nonce, bound)
+}
+
+// Expected returns the number of slots the pool is expected to lead within the
+// epoch
+func Expected(params Params) (float64, error) {
+	if err := params.validate(); err != nil {
+		return 0, err
+	}
+	p := probability(params.ActiveSlotsCoefficient, params.Sigma)
+	expected, _ := p.Mul(p, newFloat().SetUint64(params.EpochLength)).Float64()
+	return expected, nil
+}
+
+func isLeader(
+	key *SigningKey,
+	slot uint64,
+	nonce []byte,
+	bound *big.Int,
+) (bool, error) {
+	output, err := key.Output(Input(slot, nonce))
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to evaluate vrf for slot %v: %w",
+			slot,
+			err,
+		)
+	}
+	return new(big.Int).SetBytes(LeaderValue(output)).Cmp(bound) < 0, nil
+}
+
+// Input returns the Praos VRF input for the slot and epoch nonce
+func Input(slot uint64, nonce []byte) []byte {
+	data := binary.BigEndian.AppendUint64(nil, slot)
+	data = append(data, nonce...)
+	digest := blake2b.Sum256(data)
+	return digest[:]
+}
+
+// LeaderValue returns the Praos leader value derived from a VRF output
+func LeaderValue(output []byte) []byte {
+	digest := blake2b.Sum256(append([]byte("L"), output...))
+	return digest[:]
+}
+
+// leaderBound returns the value below which a leader value wins the slot,
+// 2^256 * (1 - (1-f)^sigma)
+func leaderBound(f, sigma *big.Rat) *big.Int {
+	p := probability(f, sigma)
+	bound, _ := p.Mul(p, newFloat().SetInt(certNatMax)).Int(nil)
+	return bound
+}
+
+// probability returns 1 - (1-f)^sigma, the chance of leading any given slot
+func probability(f, sigma *big.Rat) *big.Float {
+	x := ln1m(newFloat().SetRat(f))
+	x.Mul(x, newFloat().SetRat(sigma))
+	q := exp(x)
+	return q.Sub(newFloat().SetInt64(1), q)
+}
+
+// ln1m returns ln(1 - f) = -sum(f^k / k) for 0 < f < 1
+func ln1m(f *big.Float) *big.Float {
+	var (
+		sum     = newFloat()
+		power   = newFloat().SetInt64(1)
+		epsilon = newFloat().SetMantExp(newFloat().SetInt64(1), -precision)
+	)
+	for k := int64(1); k < 10000; k++ {
+		power.Mul(power, f)
+		term := newFloat().Quo(power, newFloat().SetInt64(k))
+		sum.Sub(sum, term)
+		if term.Cmp(epsilon) < 0 {
+			break
+		}
+	}
+	return sum
+}
+
+// exp returns e^x via its taylor series; x is expected to be small
+func exp(x *big.Float) *big.Float {
+	var (
+		sum     = newFloat().SetInt64(1)
+		term    = newFloat().SetInt64(1)
+		epsilon = newFloat().SetMantExp(newFloat().SetInt64(1), -precision)
+	)
+	for k := int64(1); k < 10000; k++ {
+		term.Mul(term, x)
+		term.Quo(term, newFloat().SetInt64(k))
+		sum.Add(sum, term)
+		if newFloat().Abs(term).Cmp(epsilon) < 0 {
+			break
+		}
+	}
+	return sum
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(precision)
+}
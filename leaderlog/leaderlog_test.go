@@ -0,0 +1,117 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderlog
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/statequery"
+	"github.com/tj/assert"
+)
+
+func testParams(sigma *big.Rat, length uint64) Params {
+	return Params{
+		Epoch:                  500,
+		FirstSlot:              120_000_000,
+		EpochLength:            length,
+		Nonce:                  bytes.Repeat([]byte{0xab}, 32),
+		ActiveSlotsCoefficient: big.NewRat(1, 20),
+		Sigma:                  sigma,
+	}
+}
+
+func testKey(t *testing.T) *SigningKey {
+	key, err := ParseSigningKey([]byte(
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+	))
+	assert.Nil(t, err)
+	return key
+}
+
+func TestProbability(t *testing.T) {
+	f := big.NewRat(1, 20)
+
+	p, _ := probability(f, big.NewRat(1, 1)).Float64()
+	assert.InDelta(t, 0.05, p, 1e-15)
+
+	p, _ = probability(f, big.NewRat(0, 1)).Float64()
+	assert.Equal(t, 0.0, p)
+
+	p, _ = probability(f, big.NewRat(1, 1000)).Float64()
+	assert.InDelta(t, 1-math.Pow(0.95, 0.001), p, 1e-15)
+}
+
+func TestExpected(t *testing.T) {
+	expected, err := Expected(testParams(big.NewRat(1, 1000), 432000))
+	assert.Nil(t, err)
+	assert.InDelta(t, 432000*(1-math.Pow(0.95, 0.001)), expected, 1e-6)
+}
+
+func TestSchedule(t *testing.T) {
+	var (
+		key    = testKey(t)
+		params = testParams(big.NewRat(1, 1), 2000)
+	)
+
+	slots, err := Schedule(key, params)
+	assert.Nil(t, err)
+
+	// with all the stake, roughly 1 in 20 slots is led
+	assert.True(t, len(slots) > 60 && len(slots) < 140, "got %v", len(slots))
+	for _, slot := range slots {
+		assert.EqualValues(t, 500, slot.Epoch)
+		assert.Equal(t, params.FirstSlot+slot.SlotInEpoch, slot.Slot)
+
+		ok, err := IsLeader(key, slot.Slot, params)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+
+	slots, err = Schedule(key, testParams(big.NewRat(0, 1), 2000))
+	assert.Nil(t, err)
+	assert.Len(t, slots, 0)
+}
+
+func TestParams_Validate(t *testing.T) {
+	params := testParams(big.NewRat(1, 10), 10)
+	params.Nonce = nil
+	_, err := Schedule(testKey(t), params)
+	assert.NotNil(t, err)
+
+	params = testParams(big.NewRat(2, 1), 10)
+	_, err = Expected(params)
+	assert.NotNil(t, err)
+
+	params = testParams(big.NewRat(1, 10), 10)
+	params.ActiveSlotsCoefficient = big.NewRat(1, 1)
+	_, err = IsLeader(testKey(t), 0, params)
+	assert.NotNil(t, err)
+}
+
+func TestSigma(t *testing.T) {
+	distribution := statequery.StakeDistribution{
+		"pool1": {Stake: "1/250"},
+	}
+
+	sigma, err := Sigma(distribution, "pool1")
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewRat(1, 250), sigma)
+
+	_, err = Sigma(distribution, "pool2")
+	assert.NotNil(t, err)
+}
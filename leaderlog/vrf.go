@@ -0,0 +1,178 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderlog
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"filippo.io/edwards25519"
+)
+
+// suite identifies ECVRF-ED25519-SHA512-Elligator2 as used by cardano's Praos
+const suite = 0x04
+
+var (
+	// fieldPrime is 2^255 - 19
+	fieldPrime = new(big.Int).Sub(
+		new(big.Int).Lsh(big.NewInt(1), 255),
+		big.NewInt(19),
+	)
+	curveA = big.NewInt(486662)
+
+	// chiExponent is (p-1)/2, used for the legendre symbol
+	chiExponent = new(big.Int).Rsh(
+		new(big.Int).Sub(fieldPrime, big.NewInt(1)),
+		1,
+	)
+)
+
+// SigningKey is a pool's VRF signing key
+type SigningKey struct {
+	scalar *edwards25519.Scalar
+	public []byte
+}
+
+// NewSigningKey returns a SigningKey from either the 32 byte seed or the 64
+// byte seed || public key form used by cardano-cli
+func NewSigningKey(key []byte) (*SigningKey, error) {
+	if len(key) != 32 && len(key) != 64 {
+		return nil, fmt.Errorf("invalid vrf signing key length, %v", len(key))
+	}
+
+	digest := sha512.Sum512(key[:32])
+	scalar, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	if err != nil {
+		return nil, fmt.Errorf("invalid vrf signing key: %w", err)
+	}
+	public := new(edwards25519.Point).ScalarBaseMult(scalar).Bytes()
+	if len(key) == 64 && !bytes.Equal(key[32:], public) {
+		return nil, errors.New("invalid vrf signing key: public key mismatch")
+	}
+
+	return &SigningKey{
+		scalar: scalar,
+		public: public,
+	}, nil
+}
+
+// ParseSigningKey parses a VRF signing key either as a cardano-cli text
+// envelope, e.g. the contents of vrf.skey, or as hex
+func ParseSigningKey(data []byte) (*SigningKey, error) {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		var envelope struct {
+			Type    string `json:"type"`
+			CborHex string `json:"cborHex"`
+		}
+		if err := json.Unmarshal([]byte(text), &envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode vrf signing key: %w", err)
+		}
+		if !strings.HasPrefix(envelope.Type, "VrfSigningKey") {
+			return nil, fmt.Errorf("unexpected key type, %v", envelope.Type)
+		}
+		// strip the cbor byte string header, 0x5840
+		text = strings.TrimPrefix(envelope.CborHex, "5840")
+	}
+
+	key, err := hex.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vrf signing key: %w", err)
+	}
+	return NewSigningKey(key)
+}
+
+// PublicKey returns the VRF verification key
+func (k *SigningKey) PublicKey() []byte {
+	return append([]byte(nil), k.public...)
+}
+
+// Output returns the 64 byte VRF output for alpha
+func (k *SigningKey) Output(alpha []byte) ([]byte, error) {
+	h, err := hashToCurve(k.public, alpha)
+	if err != nil {
+		return nil, err
+	}
+
+	gamma := new(edwards25519.Point).ScalarMult(k.scalar, h)
+	gamma.MultByCofactor(gamma)
+
+	digest := sha512.New()
+	digest.Write([]byte{suite, 0x03})
+	digest.Write(gamma.Bytes())
+	return digest.Sum(nil), nil
+}
+
+// hashToCurve implements ECVRF_hash_to_curve_elligator2_25519
+func hashToCurve(public, alpha []byte) (*edwards25519.Point, error) {
+	digest := sha512.New()
+	digest.Write([]byte{suite, 0x01})
+	digest.Write(public)
+	digest.Write(alpha)
+	r := digest.Sum(nil)[:32]
+	r[31] &= 0x7f
+
+	// elligator2 onto the montgomery curve
+	var (
+		p   = fieldPrime
+		u   = new(big.Int).SetBytes(reverse(r))
+		rr2 = new(big.Int).Mul(u, u)
+	)
+	rr2.Lsh(rr2, 1).Add(rr2, big.NewInt(1)).Mod(rr2, p)
+	rr2.ModInverse(rr2, p)
+
+	x := new(big.Int).Mul(curveA, rr2)
+	x.Neg(x).Mod(x, p)
+
+	x2 := new(big.Int).Mul(x, x)
+	e := new(big.Int).Mul(x2, x)
+	e.Add(e, x)
+	e.Add(e, x2.Mul(x2, curveA))
+	e.Mod(e, p)
+
+	if chi := new(big.Int).Exp(e, chiExponent, p); chi.Cmp(big.NewInt(1)) > 0 {
+		x.Neg(x).Sub(x, curveA).Mod(x, p) // e is not square
+	}
+
+	// birational map to edwards; y = (x-1)/(x+1)
+	numerator := new(big.Int).Sub(x, big.NewInt(1))
+	denominator := new(big.Int).Add(x, big.NewInt(1))
+	denominator.ModInverse(denominator, p)
+	y := numerator.Mul(numerator, denominator).Mod(numerator, p)
+
+	encoded := make([]byte, 32)
+	y.FillBytes(encoded)
+	point, err := new(edwards25519.Point).SetBytes(reverse(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash to curve: %w", err)
+	}
+	return point.MultByCofactor(point), nil
+}
+
+// reverse returns a reversed copy of data, converting between little and big
+// endian
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderlog
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestSigningKey_Output(t *testing.T) {
+	// draft-irtf-cfrg-vrf-03, ECVRF-ED25519-SHA512-Elligator2 examples 10, 11
+	tests := map[string]struct {
+		SK    string
+		PK    string
+		Alpha string
+		Beta  string
+	}{
+		"empty": {
+			SK: "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			PK: "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			Beta: "5b49b554d05c0cd5a5325376b3387de59d924fd1e13ded44648ab33c21349a60" +
+				"3f25b84ec5ed887995b33da5e3bfcb87cd2f64521c4c62cf825cffabbe5d31cc",
+		},
+		"single byte": {
+			SK:    "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			PK:    "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			Alpha: "72",
+			Beta: "94f4487e1b2fec954309ef1289ecb2e15043a2461ecc7b2ae7d4470607ef82eb" +
+				"1cfa97d84991fe4a7bfdfd715606bc27e2967a6c557cfb5875879b671740b7d8",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, err := ParseSigningKey([]byte(tc.SK + tc.PK))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.PK, hex.EncodeToString(key.PublicKey()))
+
+			alpha, err := hex.DecodeString(tc.Alpha)
+			assert.Nil(t, err)
+			output, err := key.Output(alpha)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Beta, hex.EncodeToString(output))
+		})
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	const (
+		sk = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+		pk = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	)
+
+	envelope := `{
+    "type": "VrfSigningKey_PraosVRF",
+    "description": "VRF Signing Key",
+    "cborHex": "5840` + sk + pk + `"
+}`
+	key, err := ParseSigningKey([]byte(envelope))
+	assert.Nil(t, err)
+	assert.Equal(t, pk, hex.EncodeToString(key.PublicKey()))
+
+	key, err = ParseSigningKey([]byte(sk))
+	assert.Nil(t, err)
+	assert.Equal(t, pk, hex.EncodeToString(key.PublicKey()))
+
+	_, err = ParseSigningKey([]byte(sk + sk))
+	assert.NotNil(t, err) // public key mismatch
+
+	_, err = ParseSigningKey([]byte(`{"type":"PaymentSigningKeyShelley_ed25519"}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logruslogger adapts a logrus logger to ogmigo.Logger
+package logruslogger
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+)
+
+type Logger struct {
+	entry *logrus.Entry
+}
+
+// Wrap returns an ogmigo.Logger that logs via logger
+func Wrap(logger logrus.FieldLogger) *Logger {
+	return &Logger{
+		entry: logger.WithFields(logrus.Fields{}),
+	}
+}
+
+func (l *Logger) Debug(message string, kvs ...ogmigo.KeyValue) {
+	l.entry.WithFields(getFields(kvs)).Debug(message)
+}
+
+func (l *Logger) Info(message string, kvs ...ogmigo.KeyValue) {
+	l.entry.WithFields(getFields(kvs)).Info(message)
+}
+
+func (l *Logger) Warn(message string, kvs ...ogmigo.KeyValue) {
+	l.entry.WithFields(getFields(kvs)).Warn(message)
+}
+
+func (l *Logger) Error(message string, kvs ...ogmigo.KeyValue) {
+	l.entry.WithFields(getFields(kvs)).Error(message)
+}
+
+func (l *Logger) With(kvs ...ogmigo.KeyValue) ogmigo.Logger {
+	return &Logger{
+		entry: l.entry.WithFields(getFields(kvs)),
+	}
+}
+
+func getFields(kvs []ogmigo.KeyValue) logrus.Fields {
+	fields := make(logrus.Fields, len(kvs))
+	for _, kv := range kvs {
+		fields[kv.Key] = kv.Any()
+	}
+	return fields
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logruslogger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tj/assert"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+)
+
+func TestLogger(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		target = logrus.New()
+	)
+	target.SetOutput(&buf)
+	target.SetLevel(logrus.InfoLevel)
+	target.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	var l ogmigo.Logger = Wrap(target)
+	l.Debug("debug", ogmigo.KV("foo", "bar"))
+	l = l.With(ogmigo.KV("service", "ogmios"))
+	l.Info("info", ogmigo.KV("hello", "world"))
+	l.Warn("warn")
+	l.Error("error")
+
+	assert.Equal(t,
+		"level=info msg=info hello=world service=ogmios\n"+
+			"level=warning msg=warn service=ogmios\n"+
+			"level=error msg=error service=ogmios\n",
+		buf.String(),
+	)
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sloglogger adapts a log/slog Logger to ogmigo.Logger
+package sloglogger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+)
+
+type Logger struct {
+	logger *slog.Logger
+}
+
+// Wrap returns an ogmigo.Logger that logs via logger
+func Wrap(logger *slog.Logger) *Logger {
+	return &Logger{
+		logger: logger,
+	}
+}
+
+func (l *Logger) Debug(message string, kvs ...ogmigo.KeyValue) {
+	l.log(slog.LevelDebug, message, kvs)
+}
+
+func (l *Logger) Info(message string, kvs ...ogmigo.KeyValue) {
+	l.log(slog.LevelInfo, message, kvs)
+}
+
+func (l *Logger) Warn(message string, kvs ...ogmigo.KeyValue) {
+	l.log(slog.LevelWarn, message, kvs)
+}
+
+func (l *Logger) Error(message string, kvs ...ogmigo.KeyValue) {
+	l.log(slog.LevelError, message, kvs)
+}
+
+func (l *Logger) With(kvs ...ogmigo.KeyValue) ogmigo.Logger {
+	var args []any
+	for _, attr := range getAttrs(kvs) {
+		args = append(args, attr)
+	}
+	return &Logger{
+		logger: l.logger.With(args...),
+	}
+}
+
+func (l *Logger) log(
+	level slog.Level,
+	message string,
+	kvs []ogmigo.KeyValue,
+) {
+	l.logger.LogAttrs(context.Background(), level, message, getAttrs(kvs)...)
+}
+
+func getAttrs(kvs []ogmigo.KeyValue) []slog.Attr {
+	var attrs []slog.Attr
+	for _, kv := range kvs {
+		attrs = append(attrs, slog.Any(kv.Key, kv.Any()))
+	}
+	return attrs
+}
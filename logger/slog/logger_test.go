@@ -0,0 +1,72 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sloglogger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/tj/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	var l ogmigo.Logger = Wrap(slog.New(handler))
+	l.Debug("debug", ogmigo.KV("foo", "bar"))
+	l = l.With(ogmigo.KV("service", "ogmios"))
+	l.Info("info", ogmigo.KV("hello", "world"))
+	l.Warn("warn")
+	l.Error("error")
+
+	assert.Equal(t,
+		"level=INFO msg=info service=ogmios hello=world\n"+
+			"level=WARN msg=warn service=ogmios\n"+
+			"level=ERROR msg=error service=ogmios\n",
+		buf.String(),
+	)
+}
+
+func TestLogger_TypedFields(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	Wrap(slog.New(handler)).Info("typed",
+		ogmigo.Uint64("slot", 10),
+		ogmigo.Bool("ok", true),
+	)
+	assert.Equal(t,
+		`{"level":"INFO","msg":"typed","slot":10,"ok":true}`+"\n",
+		buf.String(),
+	)
+}
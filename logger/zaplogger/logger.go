@@ -39,6 +39,14 @@ func (l *Logger) Info(message string, kvs ...ogmigo.KeyValue) {
 	l.logger.Info(message, getFields(kvs)...)
 }
 
+func (l *Logger) Warn(message string, kvs ...ogmigo.KeyValue) {
+	l.logger.Warn(message, getFields(kvs)...)
+}
+
+func (l *Logger) Error(message string, kvs ...ogmigo.KeyValue) {
+	l.logger.Error(message, getFields(kvs)...)
+}
+
 func (l *Logger) With(kvs ...ogmigo.KeyValue) ogmigo.Logger {
 	return &Logger{
 		logger: l.logger.With(getFields(kvs)...),
@@ -48,7 +56,7 @@ func (l *Logger) With(kvs ...ogmigo.KeyValue) ogmigo.Logger {
 func getFields(kvs []ogmigo.KeyValue) []zap.Field {
 	var fields []zap.Field
 	for _, kv := range kvs {
-		fields = append(fields, zap.String(kv.Key, kv.Value))
+		fields = append(fields, zap.Any(kv.Key, kv.Any()))
 	}
 	return fields
 }
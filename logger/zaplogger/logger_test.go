@@ -27,4 +27,6 @@ func TestLogger_Debug(t *testing.T) {
 	l := Wrap(logger)
 	l.Debug("debug", ogmigo.KV("foo", "bar"))
 	l.Info("info", ogmigo.KV("hello", "world"))
+	l.Warn("warn", ogmigo.KV("hello", "world"))
+	l.Error("error", ogmigo.KV("hello", "world"))
 }
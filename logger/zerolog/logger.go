@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"time"
+
 	ogmigo "github.com/SundaeSwap-finance/ogmigo/v6"
 	"github.com/rs/zerolog"
 )
@@ -21,15 +23,32 @@ func (l Logger) log(
 	message string,
 	kvs ...ogmigo.KeyValue,
 ) {
-	for _, kv := range kvs {
-		event = event.Str(kv.Key, kv.Value)
+	for _, kv := range l.kvs {
+		event = field(event, kv)
 	}
 	for _, kv := range kvs {
-		event = event.Str(kv.Key, kv.Value)
+		event = field(event, kv)
 	}
 	event.Msg(message)
 }
 
+func field(event *zerolog.Event, kv ogmigo.KeyValue) *zerolog.Event {
+	switch v := kv.Any().(type) {
+	case int64:
+		return event.Int64(kv.Key, v)
+	case uint64:
+		return event.Uint64(kv.Key, v)
+	case time.Duration:
+		return event.Dur(kv.Key, v)
+	case bool:
+		return event.Bool(kv.Key, v)
+	case error:
+		return event.AnErr(kv.Key, v)
+	default:
+		return event.Str(kv.Key, kv.Value)
+	}
+}
+
 func (l Logger) Debug(message string, kvs ...ogmigo.KeyValue) {
 	l.log(l.target.Debug(), message, kvs...)
 }
@@ -38,6 +57,14 @@ func (l Logger) Info(message string, kvs ...ogmigo.KeyValue) {
 	l.log(l.target.Info(), message, kvs...)
 }
 
+func (l Logger) Warn(message string, kvs ...ogmigo.KeyValue) {
+	l.log(l.target.Warn(), message, kvs...)
+}
+
+func (l Logger) Error(message string, kvs ...ogmigo.KeyValue) {
+	l.log(l.target.Error(), message, kvs...)
+}
+
 func (l Logger) With(kvs ...ogmigo.KeyValue) ogmigo.Logger {
 	return Logger{
 		target: l.target,
@@ -17,12 +17,26 @@ package ogmigo
 import (
 	"bytes"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// KeyValue is a structured log field.  Value always holds the field formatted
+// as a string; loggers that support typed fields may use Any instead
 type KeyValue struct {
 	Key   string
 	Value string
+	raw   any // raw holds the typed value for fields not built via KV
+}
+
+// Any returns the typed value of the field i.e. an int64, uint64,
+// time.Duration, bool, error, or string
+func (kv KeyValue) Any() any {
+	if kv.raw != nil {
+		return kv.raw
+	}
+	return kv.Value
 }
 
 func KV(key, value string) KeyValue {
@@ -32,12 +46,96 @@ func KV(key, value string) KeyValue {
 	}
 }
 
+// Int64 returns a field holding an int64
+func Int64(key string, value int64) KeyValue {
+	return KeyValue{Key: key, Value: strconv.FormatInt(value, 10), raw: value}
+}
+
+// Uint64 returns a field holding a uint64 e.g. a slot or block height
+func Uint64(key string, value uint64) KeyValue {
+	return KeyValue{Key: key, Value: strconv.FormatUint(value, 10), raw: value}
+}
+
+// Duration returns a field holding a time.Duration
+func Duration(key string, value time.Duration) KeyValue {
+	return KeyValue{Key: key, Value: value.String(), raw: value}
+}
+
+// Bool returns a field holding a bool
+func Bool(key string, value bool) KeyValue {
+	return KeyValue{Key: key, Value: strconv.FormatBool(value), raw: value}
+}
+
+// Err returns a field holding err under the key "err"
+func Err(err error) KeyValue {
+	if err == nil {
+		return KeyValue{Key: "err", Value: "<nil>"}
+	}
+	return KeyValue{Key: "err", Value: err.Error(), raw: err}
+}
+
 type Logger interface {
 	Debug(message string, kvs ...KeyValue)
 	Info(message string, kvs ...KeyValue)
+	Warn(message string, kvs ...KeyValue)
+	Error(message string, kvs ...KeyValue)
 	With(kvs ...KeyValue) Logger
 }
 
+// BasicLogger is the subset of Logger implemented by loggers that predate the
+// Warn and Error levels
+type BasicLogger interface {
+	Debug(message string, kvs ...KeyValue)
+	Info(message string, kvs ...KeyValue)
+}
+
+// Leveled adapts a BasicLogger to Logger.  Warn and Error are logged via Info
+// with an additional level key, and With is emulated by prepending the kvs
+// to every message
+func Leveled(logger BasicLogger) Logger {
+	if l, ok := logger.(Logger); ok {
+		return l
+	}
+	return leveled{logger: logger}
+}
+
+type leveled struct {
+	logger BasicLogger
+	kvs    []KeyValue
+}
+
+func (l leveled) with(kvs []KeyValue) []KeyValue {
+	return append(append([]KeyValue(nil), l.kvs...), kvs...)
+}
+
+func (l leveled) Debug(message string, kvs ...KeyValue) {
+	l.logger.Debug(message, l.with(kvs)...)
+}
+
+func (l leveled) Info(message string, kvs ...KeyValue) {
+	l.logger.Info(message, l.with(kvs)...)
+}
+
+func (l leveled) Warn(message string, kvs ...KeyValue) {
+	l.level("warn", message, kvs)
+}
+
+func (l leveled) Error(message string, kvs ...KeyValue) {
+	l.level("error", message, kvs)
+}
+
+func (l leveled) level(level, message string, kvs []KeyValue) {
+	kvs = append([]KeyValue{KV("level", level)}, kvs...)
+	l.logger.Info(message, l.with(kvs)...)
+}
+
+func (l leveled) With(kvs ...KeyValue) Logger {
+	return leveled{
+		logger: l.logger,
+		kvs:    l.with(kvs),
+	}
+}
+
 // DefaultLogger logs via the log package
 var DefaultLogger = defaultLogger{}
 
@@ -71,6 +169,14 @@ func (d defaultLogger) Info(message string, kvs ...KeyValue) {
 	d.print(message, kvs...)
 }
 
+func (d defaultLogger) Warn(message string, kvs ...KeyValue) {
+	d.print("WARN "+message, kvs...)
+}
+
+func (d defaultLogger) Error(message string, kvs ...KeyValue) {
+	d.print("ERROR "+message, kvs...)
+}
+
 func (d defaultLogger) With(kvs ...KeyValue) Logger {
 	return defaultLogger{
 		kvs: append(d.kvs, kvs...),
@@ -84,4 +190,6 @@ type nopLogger struct{}
 
 func (n nopLogger) Debug(string, ...KeyValue) {}
 func (n nopLogger) Info(string, ...KeyValue)  {}
+func (n nopLogger) Warn(string, ...KeyValue)  {}
+func (n nopLogger) Error(string, ...KeyValue) {}
 func (n nopLogger) With(...KeyValue) Logger   { return n }
@@ -15,7 +15,11 @@
 package ogmigo
 
 import (
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/tj/assert"
 )
 
 func Test_DefaultLogger_print(t *testing.T) {
@@ -25,3 +29,61 @@ func Test_DefaultLogger_print(t *testing.T) {
 func Test_NopLogger_print(t *testing.T) {
 	NopLogger.Info("test", KV("key", "value"))
 }
+
+type basicLogger struct {
+	lines [][]KeyValue
+}
+
+func (b *basicLogger) Debug(message string, kvs ...KeyValue) {
+	b.lines = append(b.lines, append([]KeyValue{KV("msg", message)}, kvs...))
+}
+
+func (b *basicLogger) Info(message string, kvs ...KeyValue) {
+	b.lines = append(b.lines, append([]KeyValue{KV("msg", message)}, kvs...))
+}
+
+func Test_Leveled(t *testing.T) {
+	var (
+		basic  = &basicLogger{}
+		logger = Leveled(basic).With(KV("service", "ogmios"))
+	)
+	logger.Info("info")
+	logger.Warn("warn", KV("a", "1"))
+	logger.With(KV("shard", "0")).Error("error")
+
+	assert.Equal(t, [][]KeyValue{
+		{KV("msg", "info"), KV("service", "ogmios")},
+		{KV("msg", "warn"), KV("service", "ogmios"), KV("level", "warn"), KV("a", "1")},
+		{KV("msg", "error"), KV("service", "ogmios"), KV("shard", "0"), KV("level", "error")},
+	}, basic.lines)
+
+	// loggers that already implement Logger are returned as is
+	assert.Equal(t, NopLogger, Leveled(NopLogger))
+}
+
+func Test_DefaultLogger_levels(t *testing.T) {
+	DefaultLogger.Warn("test", KV("key", "value"))
+	DefaultLogger.Error("test", KV("key", "value"))
+}
+
+func Test_TypedFields(t *testing.T) {
+	err := errors.New("boom")
+	tests := []struct {
+		kv    KeyValue
+		value string
+		typed any
+	}{
+		{KV("id", "abc"), "abc", "abc"},
+		{Int64("n", -1), "-1", int64(-1)},
+		{Uint64("slot", 123), "123", uint64(123)},
+		{Duration("wait", 1500*time.Millisecond), "1.5s", 1500 * time.Millisecond},
+		{Bool("ok", true), "true", true},
+		{Err(err), "boom", err},
+		{Err(nil), "<nil>", "<nil>"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.value, tc.kv.Value)
+		assert.Equal(t, tc.typed, tc.kv.Any())
+	}
+	assert.Equal(t, "err", Err(err).Key)
+}
@@ -2,7 +2,6 @@ package ogmigo
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -88,10 +87,10 @@ func (c *Client) MonitorMempool(
 			err = c.doMonitorMempool(ctx, callback, options)
 			if err != nil && isTemporaryError(err) {
 				if options.reconnect {
-					c.options.logger.Info(
+					c.options.logger.Warn(
 						"websocket connection error: will retry",
-						KV("delay", timeout.Round(time.Millisecond).String()),
-						KV("err", err.Error()),
+						Duration("delay", timeout.Round(time.Millisecond)),
+						Err(err),
 					)
 
 					select {
@@ -105,6 +104,9 @@ func (c *Client) MonitorMempool(
 
 			break
 		}
+		if err != nil && ctx.Err() == nil {
+			c.options.logger.Error("ogmigo mempool monitoring failed", Err(err))
+		}
 		errs <- err
 	}()
 
@@ -143,14 +145,16 @@ func (c *Client) doMonitorMempool(
 	callback MonitorMempoolFunc,
 	options MonitorMempoolOptions,
 ) error {
-	conn, _, err := websocket.DefaultDialer.Dial(c.options.endpoint, nil)
+	conn, err := c.dial(ctx)
 	if err != nil {
 		return fmt.Errorf(
 			"failed to connect to ogmios, %v: %w",
-			c.options.endpoint,
+			c.endpoint(),
 			err,
 		)
 	}
+	keepalive := startKeepAlive(conn, c.options.keepAlive, c.options.keepTimeout)
+	defer keepalive.stop()
 
 	group, ctx := errgroup.WithContext(ctx)
 	group.Go(func() error {
@@ -220,7 +224,7 @@ func (c *Client) doMonitorMempool(
 		var transactions []*chainsync.Tx
 		var slot uint64
 		for n := uint64(1); ; n++ {
-			messageType, data, err := conn.ReadMessage()
+			messageType, data, err := keepalive.readMessage()
 			if err != nil {
 				if errors.Is(err, io.EOF) {
 					return nil
@@ -233,36 +237,25 @@ func (c *Client) doMonitorMempool(
 				}
 				return fmt.Errorf("failed to read message from ogmios: %w", err)
 			}
+			c.options.archive.write(data)
 
 			switch messageType {
 			case websocket.BinaryMessage:
-				c.options.logger.Info("skipping unexpected binary message")
+				c.options.logger.Warn("skipping unexpected binary message")
 				continue
 
 			case websocket.CloseMessage:
 				return nil
 
-			case websocket.PingMessage:
-				if err := conn.WriteMessage(websocket.PongMessage, nil); err != nil {
-					return fmt.Errorf(
-						"failed to respond with pong to ogmios: %w",
-						err,
-					)
-				}
-				continue
-
-			case websocket.PongMessage:
-				continue
-
 			case websocket.TextMessage:
 				// ok
 			}
 
 			var acquireMempoolResponse AcquireMempoolResponse
-			acquireMempoolErr := json.Unmarshal(data, &acquireMempoolResponse)
+			acquireMempoolErr := c.options.codec.Unmarshal(data, &acquireMempoolResponse)
 
 			var nextTransactionResponse NextTransactionResponse
-			nextTransactionErr := json.Unmarshal(data, &nextTransactionResponse)
+			nextTransactionErr := c.options.codec.Unmarshal(data, &nextTransactionResponse)
 
 			if acquireMempoolErr != nil && nextTransactionErr != nil {
 				return fmt.Errorf(
@@ -288,5 +281,9 @@ func (c *Client) doMonitorMempool(
 			}
 		}
 	})
-	return group.Wait()
+	err = group.Wait()
+	if kaErr := keepalive.failed(); kaErr != nil {
+		return kaErr
+	}
+	return err
 }
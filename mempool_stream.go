@@ -0,0 +1,145 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// MempoolStream emits each transaction once when it is first seen in the
+// mempool.  The mempool is monitored via MonitorMempool, which reacquires a
+// snapshot each time the previous snapshot has been read.  The channel,
+// buffered to WithPipeline, is closed once ctx is cancelled or monitoring
+// stops; errors that stop monitoring are logged.
+//
+// Only transactions in the latest snapshot are remembered, so a transaction
+// that leaves the mempool and later returns, e.g. following a rollback, is
+// emitted again.
+func (c *Client) MempoolStream(
+	ctx context.Context,
+	opts ...MonitorMempoolOption,
+) (<-chan chainsync.Tx, error) {
+	var (
+		ch   = make(chan chainsync.Tx, c.options.pipeline)
+		seen = newMempoolSeen()
+	)
+
+	var callback MonitorMempoolFunc = func(
+		ctx context.Context,
+		txs []*chainsync.Tx,
+		_ uint64,
+	) error {
+		for _, tx := range seen.update(txs) {
+			select {
+			case ch <- *tx:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+
+	monitor, err := c.MonitorMempool(ctx, callback, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(ch)
+
+		<-monitor.Done()
+		err := monitor.Close()
+		if err == nil || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return
+		}
+		c.options.logger.Error("mempool stream stopped", Err(err))
+	}()
+
+	return ch, nil
+}
+
+// mempoolSeen tracks the transactions in the latest mempool snapshot
+type mempoolSeen struct {
+	ids map[string]struct{}
+}
+
+func newMempoolSeen() *mempoolSeen {
+	return &mempoolSeen{ids: map[string]struct{}{}}
+}
+
+// update replaces the snapshot with txs and returns the transactions not
+// present in the prior snapshot
+func (m *mempoolSeen) update(txs []*chainsync.Tx) []*chainsync.Tx {
+	var (
+		ids   = make(map[string]struct{}, len(txs))
+		added []*chainsync.Tx
+	)
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		if _, ok := ids[tx.ID]; ok {
+			continue
+		}
+		ids[tx.ID] = struct{}{}
+		if _, ok := m.ids[tx.ID]; !ok {
+			added = append(added, tx)
+		}
+	}
+	m.ids = ids
+	return added
+}
+
+// AwaitInMempool blocks until the transaction is observed in the mempool or
+// ctx is cancelled.  Monitoring begins when AwaitInMempool is called; a
+// transaction that has already left the mempool, e.g. because it was included
+// in a block, will not be observed.
+func (c *Client) AwaitInMempool(
+	ctx context.Context,
+	txID string,
+	opts ...MonitorMempoolOption,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch, err := c.MempoolStream(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to await tx %v in mempool: %w", txID, err)
+	}
+	return awaitInMempool(ctx, ch, txID)
+}
+
+func awaitInMempool(
+	ctx context.Context,
+	ch <-chan chainsync.Tx,
+	txID string,
+) error {
+	for tx := range ch {
+		if tx.ID == txID {
+			return nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to await tx %v in mempool: %w", txID, err)
+	}
+	return fmt.Errorf(
+		"failed to await tx %v in mempool: mempool monitoring stopped",
+		txID,
+	)
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func txIDs(txs []*chainsync.Tx) []string {
+	var ids []string
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	return ids
+}
+
+func mempoolSnapshot(ids ...string) []*chainsync.Tx {
+	var txs []*chainsync.Tx
+	for _, id := range ids {
+		txs = append(txs, &chainsync.Tx{ID: id})
+	}
+	return txs
+}
+
+func TestMempoolSeen(t *testing.T) {
+	seen := newMempoolSeen()
+
+	added := seen.update(mempoolSnapshot("a", "b"))
+	assert.Equal(t, []string{"a", "b"}, txIDs(added))
+
+	// a remains, b leaves, c arrives
+	added = seen.update(mempoolSnapshot("a", "c", "c"))
+	assert.Equal(t, []string{"c"}, txIDs(added))
+
+	// an empty snapshot forgets everything
+	added = seen.update(nil)
+	assert.Len(t, added, 0)
+
+	added = seen.update(append(mempoolSnapshot("a"), nil))
+	assert.Equal(t, []string{"a"}, txIDs(added))
+}
+
+func TestAwaitInMempool(t *testing.T) {
+	ctx := context.Background()
+
+	ch := make(chan chainsync.Tx, 2)
+	ch <- chainsync.Tx{ID: "a"}
+	ch <- chainsync.Tx{ID: "b"}
+	assert.Nil(t, awaitInMempool(ctx, ch, "b"))
+
+	ch = make(chan chainsync.Tx, 1)
+	ch <- chainsync.Tx{ID: "a"}
+	close(ch)
+	assert.NotNil(t, awaitInMempool(ctx, ch, "b"))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	ch = make(chan chainsync.Tx)
+	close(ch)
+	err := awaitInMempool(ctx, ch, "b")
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/address"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// Network magics of the well known networks
+const (
+	MainnetMagic uint32 = 764824073
+	PreprodMagic uint32 = 1
+	PreviewMagic uint32 = 2
+)
+
+// NetworkInfo identifies the network of the connected node
+type NetworkInfo struct {
+	Network address.Network // Network is the network id encoded by addresses
+	Magic   uint32          // Magic is the network magic
+}
+
+// Name returns the name of a well known network, e.g. preprod, or the network
+// and its magic otherwise
+func (n NetworkInfo) Name() string {
+	switch {
+	case n.Network == address.Mainnet && n.Magic == MainnetMagic:
+		return "mainnet"
+	case n.Network == address.Testnet && n.Magic == PreprodMagic:
+		return "preprod"
+	case n.Network == address.Testnet && n.Magic == PreviewMagic:
+		return "preview"
+	default:
+		return fmt.Sprintf("%v(%v)", n.Network, n.Magic)
+	}
+}
+
+// Network returns the network of the connected node, read from its shelley
+// genesis configuration.  The result is cached until the client fails over to
+// another endpoint.
+func (c *Client) Network(ctx context.Context) (NetworkInfo, error) {
+	if info := c.network.Load(); info != nil {
+		return *info, nil
+	}
+
+	raw, err := c.GenesisConfig(ctx, "shelley")
+	if err != nil {
+		return NetworkInfo{}, fmt.Errorf("failed to query network: %w", err)
+	}
+	var genesis struct {
+		Network      chainsync.Network `json:"network"`
+		NetworkMagic uint32            `json:"networkMagic"`
+	}
+	if err := json.Unmarshal(raw, &genesis); err != nil {
+		return NetworkInfo{}, fmt.Errorf("failed to decode genesis config: %w", err)
+	}
+	network, ok := address.NetworkOf(genesis.Network)
+	if !ok {
+		return NetworkInfo{}, fmt.Errorf(
+			"failed to decode genesis config: unknown network, %q",
+			genesis.Network,
+		)
+	}
+
+	info := NetworkInfo{Network: network, Magic: genesis.NetworkMagic}
+	c.network.Store(&info)
+	return info, nil
+}
+
+// ValidateAddress parses s and returns address.ErrNetworkMismatch unless it
+// belongs to the network of the connected node
+func (c *Client) ValidateAddress(
+	ctx context.Context,
+	s string,
+) (address.Address, error) {
+	a, err := address.Parse(s)
+	if err != nil {
+		return address.Address{}, err
+	}
+	info, err := c.Network(ctx)
+	if err != nil {
+		return address.Address{}, err
+	}
+	if err := a.Validate(info.Network); err != nil {
+		return address.Address{}, err
+	}
+	return a, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/address"
+	"github.com/tj/assert"
+)
+
+const (
+	mainnetAddress = "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	testnetAddress = "addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs68faae"
+)
+
+func TestClient_Network(t *testing.T) {
+	endpoint, requests := scripted(t,
+		`{"jsonrpc":"2.0","method":"queryNetwork/genesisConfiguration","result":{"era":"shelley","network":"testnet","networkMagic":1}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	info, err := client.Network(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, address.Testnet, info.Network)
+	assert.Equal(t, PreprodMagic, info.Magic)
+	assert.Equal(t, "preprod", info.Name())
+
+	// cached
+	_, err = client.ValidateAddress(context.Background(), testnetAddress)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, *requests)
+
+	_, err = client.ValidateAddress(context.Background(), mainnetAddress)
+	assert.True(t, errors.Is(err, address.ErrNetworkMismatch))
+
+	_, err = client.ValidateAddress(context.Background(), "bogus")
+	assert.True(t, errors.Is(err, address.ErrInvalidAddress))
+}
+
+func TestClient_NetworkInvalid(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","method":"queryNetwork/genesisConfiguration","result":{"era":"shelley"}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	_, err := client.Network(context.Background())
+	assert.NotNil(t, err)
+}
+
+func TestNetworkInfo_Name(t *testing.T) {
+	assert.Equal(t, "mainnet", NetworkInfo{Network: address.Mainnet, Magic: MainnetMagic}.Name())
+	assert.Equal(t, "preview", NetworkInfo{Network: address.Testnet, Magic: PreviewMagic}.Name())
+	assert.Equal(t, "testnet(42)", NetworkInfo{Network: address.Testnet, Magic: 42}.Name())
+}
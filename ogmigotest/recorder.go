@@ -0,0 +1,306 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Exchange is a single request and the response ogmios returned for it
+type Exchange struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Recorder is a websocket proxy that forwards traffic to a live ogmios and
+// records every request/response pair as a json line; the recording may be
+// served back via WithReplay.  Ogmios responds to the requests on a
+// connection in order, so each response is paired with the oldest request
+// awaiting a response on the same connection.
+type Recorder struct {
+	// URL of the websocket endpoint e.g. ws://127.0.0.1:1234
+	URL string
+
+	server   *httptest.Server
+	upstream string
+	upgrader websocket.Upgrader
+	wg       sync.WaitGroup // wg tracks proxied connections
+
+	mutex sync.Mutex
+	w     io.Writer
+	err   error // err is the first error writing the recording
+	conns map[*websocket.Conn]struct{}
+}
+
+// NewRecorder starts a Recorder proxying to the upstream ogmios endpoint and
+// writing the recording to w; callers should Close it when finished
+func NewRecorder(upstream string, w io.Writer) *Recorder {
+	r := &Recorder{
+		upstream: upstream,
+		w:        w,
+		conns:    map[*websocket.Conn]struct{}{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	r.URL = "ws" + strings.TrimPrefix(r.server.URL, "http")
+	return r
+}
+
+// Close shuts down the proxy and returns the first error, if any, writing
+// the recording
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mutex.Unlock()
+
+	r.server.Close()
+	r.wg.Wait()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.wg.Add(1)
+	defer r.wg.Done()
+
+	upstream, _, err := websocket.DefaultDialer.DialContext(
+		req.Context(),
+		r.upstream,
+		nil,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	//nolint:errcheck
+	defer upstream.Close()
+
+	conn, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	r.mutex.Lock()
+	r.conns[conn], r.conns[upstream] = struct{}{}, struct{}{}
+	r.mutex.Unlock()
+	defer func() {
+		r.mutex.Lock()
+		delete(r.conns, conn)
+		delete(r.conns, upstream)
+		r.mutex.Unlock()
+		_ = conn.Close()
+	}()
+
+	var (
+		pending = make(chan json.RawMessage, 1024) // requests awaiting responses
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		//nolint:errcheck
+		defer upstream.Close()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case pending <- json.RawMessage(data):
+			default:
+				r.record(Exchange{Request: data}) // too many in flight
+			}
+			if err := upstream.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		messageType, data, err := upstream.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		exchange := Exchange{Response: data}
+		select {
+		case exchange.Request = <-pending:
+		default:
+		}
+		r.record(exchange)
+
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			break
+		}
+	}
+	_ = conn.Close()
+	<-done
+}
+
+func (r *Recorder) record(exchange Exchange) {
+	data, err := json.Marshal(exchange)
+	if err == nil {
+		data = append(data, '\n')
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err == nil {
+		_, err = r.w.Write(data)
+	}
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to record exchange: %w", err)
+	}
+}
+
+// ReadRecording decodes the json lines written by a Recorder
+func ReadRecording(rd io.Reader) ([]Exchange, error) {
+	var (
+		exchanges []Exchange
+		scanner   = bufio.NewScanner(rd)
+	)
+	scanner.Buffer(nil, 64*1024*1024) // blocks may be large
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(line, &exchange); err != nil {
+			return nil, fmt.Errorf("failed to decode recording: %w", err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return exchanges, nil
+}
+
+// LoadRecording reads the recording at path
+func LoadRecording(path string) ([]Exchange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording, %v: %w", path, err)
+	}
+	//nolint:errcheck
+	defer f.Close()
+
+	return ReadRecording(f)
+}
+
+// replay serves recorded responses to matching requests
+type replay struct {
+	mutex     sync.Mutex
+	responses map[string][]json.RawMessage // responses by request key, in order
+}
+
+func newReplay(exchanges []Exchange) *replay {
+	r := &replay{responses: map[string][]json.RawMessage{}}
+	for _, exchange := range exchanges {
+		if len(exchange.Request) == 0 || len(exchange.Response) == 0 {
+			continue
+		}
+		key, ok := replayKey(exchange.Request)
+		if !ok {
+			continue
+		}
+		r.responses[key] = append(r.responses[key], exchange.Response)
+	}
+	return r
+}
+
+// next returns the next recorded response to the request, rewritten to
+// carry the id, or for v5 the reflection, of the request
+func (r *replay) next(request []byte) (json.RawMessage, bool, error) {
+	key, ok := replayKey(request)
+	if !ok {
+		return nil, false, nil
+	}
+
+	r.mutex.Lock()
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		r.mutex.Unlock()
+		return nil, false, nil
+	}
+	response := responses[0]
+	r.responses[key] = responses[1:]
+	r.mutex.Unlock()
+
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Mirror json.RawMessage `json:"mirror"`
+	}
+	_ = json.Unmarshal(request, &req)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil, false, fmt.Errorf("failed to decode recorded response: %w", err)
+	}
+	switch {
+	case len(req.ID) > 0:
+		fields["id"] = req.ID
+	case len(req.Mirror) > 0:
+		fields["reflection"] = req.Mirror
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode recorded response: %w", err)
+	}
+	return data, true, nil
+}
+
+// replayKey identifies a request by its method and canonical params,
+// ignoring the id or mirror
+func replayKey(request []byte) (string, bool) {
+	var req struct {
+		Method     string `json:"method"`
+		Params     any    `json:"params"`
+		MethodName string `json:"methodname"`
+		Args       any    `json:"args"`
+	}
+	if err := json.Unmarshal(request, &req); err != nil {
+		return "", false
+	}
+
+	method, params := req.Method, req.Params
+	if method == "" {
+		method, params = req.MethodName, req.Args
+	}
+	if method == "" {
+		return "", false
+	}
+	data, err := json.Marshal(params) // maps marshal with sorted keys
+	if err != nil {
+		return "", false
+	}
+	return method + " " + string(data), true
+}
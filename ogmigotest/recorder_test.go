@@ -0,0 +1,99 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigotest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+// clientSession exercises the client against endpoint, returning what it saw
+func clientSession(t *testing.T, endpoint string) (uint64, []uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(endpoint),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+	)
+	epoch, err := client.CurrentEpoch(ctx)
+	assert.Nil(t, err)
+
+	events, err := client.BlocksChan(ctx)
+	assert.Nil(t, err)
+
+	var slots []uint64
+	for len(slots) < 3 {
+		event := next(t, events)
+		if event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+	}
+	return epoch, slots
+}
+
+func TestRecorder(t *testing.T) {
+	live := NewServer(
+		WithBlocks(blocks(1, 3)...),
+		WithResult("queryLedgerState/epoch", 123),
+	)
+	defer live.Close()
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(live.URL, &buf)
+	epoch, slots := clientSession(t, recorder.URL)
+	assert.EqualValues(t, 123, epoch)
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+
+	// responses are recorded before they are forwarded to the client
+	assert.Nil(t, recorder.Close())
+
+	recording, err := ReadRecording(&buf)
+	assert.Nil(t, err)
+	assert.True(t, len(recording) >= 5)
+	for _, exchange := range recording {
+		assert.NotEmpty(t, exchange.Request)
+		assert.NotEmpty(t, exchange.Response)
+	}
+
+	// the replay serves the recording without the blocks or results
+	replay := NewServer(WithReplay(recording...))
+	defer replay.Close()
+
+	epoch, slots = clientSession(t, replay.URL)
+	assert.EqualValues(t, 123, epoch)
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+}
+
+func TestReplayKey(t *testing.T) {
+	a, ok := replayKey([]byte(`{"jsonrpc":"2.0","method":"m","params":{"b":1,"a":2},"id":1}`))
+	assert.True(t, ok)
+	b, ok := replayKey([]byte(`{"jsonrpc":"2.0","method":"m","params":{"a":2,"b":1},"id":2}`))
+	assert.True(t, ok)
+	assert.Equal(t, a, b)
+
+	c, ok := replayKey([]byte(`{"methodname":"Query","args":{"query":"ledgerTip"},"mirror":1}`))
+	assert.True(t, ok)
+	assert.NotEqual(t, a, c)
+
+	_, ok = replayKey([]byte(`{}`))
+	assert.False(t, ok)
+}
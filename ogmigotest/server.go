@@ -0,0 +1,896 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ogmigotest provides an in-process ogmios server for tests, in the
+// spirit of net/http/httptest.  The server speaks either the v6 json-rpc or
+// the v5 jsonwsp wire format, serves a chain of blocks via chain sync along
+// with canned query results, and supports scripted faults such as dropped
+// connections and slow responses.
+//
+//	server := ogmigotest.NewServer(
+//		ogmigotest.WithBlocks(blocks...),
+//		ogmigotest.WithResult("queryLedgerState/epoch", 123),
+//	)
+//	defer server.Close()
+//
+//	client := ogmigo.New(ogmigo.WithEndpoint(server.URL))
+package ogmigotest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	v5 "github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/v5"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Protocol selects the wire format spoken by the Server
+type Protocol int
+
+const (
+	// ProtocolV6 speaks the ogmios v6 json-rpc protocol; this is the default
+	ProtocolV6 Protocol = iota
+	// ProtocolV5 speaks the ogmios v5 jsonwsp protocol
+	ProtocolV5
+)
+
+// Error codes returned by the Server
+const (
+	CodeIntersectionNotFound = 1000
+	CodeInvalidRequest       = -32600
+	CodeMethodNotFound       = -32601
+	CodeInvalidParams        = -32602
+)
+
+// Fault describes a failure injected into the response to a single request
+type Fault struct {
+	// Method the fault applies to i.e. the v6 method or the v5 methodname,
+	// or for v5 queries, the query name; empty matches any request
+	Method string
+	// Delay before responding
+	Delay time.Duration
+	// Disconnect drops the connection instead of responding
+	Disconnect bool
+	// Error responds with the error; v5 responds with a jsonwsp fault
+	Error *chainsync.ResultError
+}
+
+// Fixture holds canned data for the Server, typically loaded via LoadFixture
+type Fixture struct {
+	// Blocks served via chain sync, in chain order
+	Blocks []chainsync.Block `json:"blocks,omitempty"`
+	// Results by v6 method or v5 query name e.g. queryLedgerState/epoch
+	Results map[string]json.RawMessage `json:"results,omitempty"`
+}
+
+// LoadFixture reads a json encoded Fixture from path
+func LoadFixture(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("failed to read fixture, %v: %w", path, err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return Fixture{}, fmt.Errorf("failed to decode fixture, %v: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Option provides functional options for NewServer
+type Option func(*Server)
+
+// WithProtocol sets the wire format spoken by the server; defaults to v6
+func WithProtocol(protocol Protocol) Option {
+	return func(s *Server) {
+		s.protocol = protocol
+	}
+}
+
+// WithBlocks appends blocks to the chain served via chain sync
+func WithBlocks(blocks ...chainsync.Block) Option {
+	return func(s *Server) {
+		s.blocks = append(s.blocks, blocks...)
+	}
+}
+
+// WithResult sets the result returned for the v6 method or v5 query name.
+// result is encoded as json; a json.RawMessage is returned as is
+func WithResult(method string, result any) Option {
+	return func(s *Server) {
+		s.results[method] = result
+	}
+}
+
+// WithFixture loads the blocks and results from the fixture
+func WithFixture(fixture Fixture) Option {
+	return func(s *Server) {
+		s.blocks = append(s.blocks, fixture.Blocks...)
+		for method, result := range fixture.Results {
+			s.results[method] = result
+		}
+	}
+}
+
+// WithReplay serves the recorded exchanges, captured via Recorder, in
+// preference to the blocks and results of the server.  Each request is
+// answered by the next unused response recorded for a request with the same
+// method and params, so repeated requests replay in recorded order.
+func WithReplay(exchanges ...Exchange) Option {
+	return func(s *Server) {
+		s.replay = newReplay(exchanges)
+	}
+}
+
+// WithLatency delays every response by d
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// Server is an in-process ogmios server
+type Server struct {
+	// URL of the websocket endpoint e.g. ws://127.0.0.1:1234
+	URL string
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+	protocol Protocol
+	closed   chan struct{}
+
+	mutex     sync.Mutex
+	blocks    []chainsync.Block
+	changed   chan struct{} // changed is closed when blocks changes
+	results   map[string]any
+	faults    []Fault
+	replay    *replay
+	latency   time.Duration
+	conns     map[*websocket.Conn]struct{}
+	requests  int
+	submitted []string
+}
+
+// NewServer starts and returns a new Server; callers should Close it when
+// finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		closed:  make(chan struct{}),
+		changed: make(chan struct{}),
+		results: map[string]any{},
+		conns:   map[*websocket.Conn]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	return s
+}
+
+// Close disconnects all clients and shuts down the server
+func (s *Server) Close() {
+	s.mutex.Lock()
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+	s.mutex.Unlock()
+
+	s.Disconnect()
+	s.server.Close()
+}
+
+// AddBlocks extends the chain; chain sync clients waiting at the tip receive
+// the new blocks
+func (s *Server) AddBlocks(blocks ...chainsync.Block) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.blocks = append(s.blocks, blocks...)
+	s.notify()
+}
+
+// Rollback discards blocks after slot; chain sync clients past slot roll
+// backward to the new tip
+func (s *Server) Rollback(slot uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := len(s.blocks)
+	for i > 0 && s.blocks[i-1].Slot > slot {
+		i--
+	}
+	s.blocks = s.blocks[:i]
+	s.notify()
+}
+
+// SetResult sets the result returned for the v6 method or v5 query name
+func (s *Server) SetResult(method string, result any) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.results[method] = result
+}
+
+// Inject queues faults; each fault applies to the next matching request only
+func (s *Server) Inject(faults ...Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.faults = append(s.faults, faults...)
+}
+
+// Disconnect drops every open connection
+func (s *Server) Disconnect() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for conn := range s.conns {
+		_ = conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Requests returns the number of websocket requests received
+func (s *Server) Requests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests
+}
+
+// Submitted returns the hex encoded cbor of each transaction submitted
+func (s *Server) Submitted() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.submitted...)
+}
+
+// notify wakes clients waiting for the chain to change; assumes the caller
+// holds the mutex
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if !websocket.IsWebSocketUpgrade(req) {
+		s.serveHealth(w)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.conns[conn] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		_ = conn.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	session := &session{server: s, conn: conn}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := session.handle(ctx, data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) serveHealth(w http.ResponseWriter) {
+	version := "v6.0.0"
+	if s.protocol == ProtocolV5 {
+		version = "v5.6.0"
+	}
+
+	s.mutex.Lock()
+	health := map[string]any{
+		"connectionStatus":       "connected",
+		"networkSynchronization": 1,
+		"version":                version,
+	}
+	if tip := s.tip(); tip != nil {
+		health["lastKnownTip"] = tip
+	}
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(health)
+}
+
+// tip returns the last block of the chain, or nil if the chain is empty;
+// assumes the caller holds the mutex
+func (s *Server) tip() *chainsync.PointStruct {
+	if len(s.blocks) == 0 {
+		return nil
+	}
+	ps := s.blocks[len(s.blocks)-1].PointStruct()
+	return &ps
+}
+
+// fault removes and returns the first queued fault matching method
+func (s *Server) fault(method string) (Fault, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests++
+	for i, f := range s.faults {
+		if f.Method == "" || f.Method == method {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			return f, true
+		}
+	}
+	return Fault{}, false
+}
+
+func (s *Server) result(method string) (json.RawMessage, bool, error) {
+	s.mutex.Lock()
+	result, ok := s.results[method]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	if raw, ok := result.(json.RawMessage); ok {
+		return raw, true, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode result for %v: %w", method, err)
+	}
+	return data, true, nil
+}
+
+// errDisconnect signals the session should drop the connection
+var errDisconnect = errors.New("disconnect")
+
+// session holds the chain sync state of a single connection
+type session struct {
+	server *Server
+	conn   *websocket.Conn
+
+	intersected bool                   // intersected is true once findIntersection succeeds
+	rollback    *chainsync.Point       // rollback is delivered by the next nextBlock
+	last        *chainsync.PointStruct // last block delivered
+}
+
+type requestV6 struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+	raw     []byte
+}
+
+type requestV5 struct {
+	MethodName string          `json:"methodname"`
+	Args       json.RawMessage `json:"args"`
+	Mirror     json.RawMessage `json:"mirror"`
+	raw        []byte
+}
+
+func (s *session) handle(ctx context.Context, data []byte) error {
+	var v6 requestV6
+	if err := json.Unmarshal(data, &v6); err != nil {
+		return s.writeFault("invalid request")
+	}
+
+	if s.server.protocol == ProtocolV5 {
+		if v6.JsonRpc != "" {
+			return s.writeFault("unsupported request")
+		}
+		v5 := requestV5{raw: data}
+		_ = json.Unmarshal(data, &v5)
+		return s.handleV5(ctx, v5)
+	}
+
+	if v6.JsonRpc == "" {
+		return s.writeError("", nil, CodeInvalidRequest, "invalid request", nil)
+	}
+	v6.raw = data
+	return s.handleV6(ctx, v6)
+}
+
+// replayed writes the recorded response to the request, if any
+func (s *session) replayed(request []byte) (bool, error) {
+	if s.server.replay == nil {
+		return false, nil
+	}
+	response, ok, err := s.server.replay.next(request)
+	if !ok || err != nil {
+		return false, err
+	}
+	return true, s.write(response)
+}
+
+// applyFault applies the latency and any fault queued for method, returning
+// the error to respond with, if any; errDisconnect drops the connection
+func (s *session) applyFault(
+	ctx context.Context,
+	method string,
+) (*chainsync.ResultError, error) {
+	f, _ := s.server.fault(method)
+	s.server.mutex.Lock()
+	delay := s.server.latency + f.Delay
+	s.server.mutex.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.Disconnect {
+		return nil, errDisconnect
+	}
+	return f.Error, nil
+}
+
+func (s *session) handleV6(ctx context.Context, req requestV6) error {
+	fault, err := s.applyFault(ctx, req.Method)
+	if err != nil {
+		return err
+	}
+	if fault != nil {
+		return s.writeError(
+			req.Method,
+			req.ID,
+			int(fault.Code),
+			fault.Message,
+			fault.Data,
+		)
+	}
+	if ok, err := s.replayed(req.raw); ok || err != nil {
+		return err
+	}
+
+	switch req.Method {
+	case chainsync.FindIntersectionMethod:
+		var params struct {
+			Points chainsync.Points `json:"points"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		result := s.findIntersection(params.Points)
+		if e := result.Error; e != nil {
+			return s.writeError(req.Method, req.ID, int(e.Code), e.Message, e.Data)
+		}
+		return s.writeResult(req.Method, req.ID, result)
+
+	case chainsync.NextBlockMethod:
+		result, err := s.nextBlock(ctx)
+		if err != nil {
+			return err
+		}
+		return s.writeResult(req.Method, req.ID, result)
+
+	case "submitTransaction":
+		var params struct {
+			Transaction struct {
+				CBOR string `json:"cbor"`
+			} `json:"transaction"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		if raw, ok, err := s.server.result(req.Method); ok || err != nil {
+			if err != nil {
+				return err
+			}
+			s.server.submit(params.Transaction.CBOR)
+			return s.writeResult(req.Method, req.ID, raw)
+		}
+		id, err := s.server.submitTx(params.Transaction.CBOR)
+		if err != nil {
+			return s.writeError(
+				req.Method,
+				req.ID,
+				CodeInvalidParams,
+				err.Error(),
+				nil,
+			)
+		}
+		return s.writeResult(req.Method, req.ID, chainsync.ResultSubmitTransactionPraos{
+			Transaction: chainsync.SubmittedTx{ID: id},
+		})
+	}
+
+	raw, ok, err := s.server.result(req.Method)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.writeResult(req.Method, req.ID, raw)
+	}
+
+	switch req.Method {
+	case "queryNetwork/tip", "queryLedgerState/tip":
+		s.server.mutex.Lock()
+		tip := s.server.tip()
+		s.server.mutex.Unlock()
+		if tip == nil {
+			return s.writeResult(req.Method, req.ID, chainsync.Origin)
+		}
+		return s.writeResult(req.Method, req.ID, tip)
+	}
+
+	return s.writeError(
+		req.Method,
+		req.ID,
+		CodeMethodNotFound,
+		"method not found, "+req.Method,
+		nil,
+	)
+}
+
+func (s *session) handleV5(ctx context.Context, req requestV5) error {
+	name := req.MethodName
+	if name == "Query" {
+		var args struct {
+			Query json.RawMessage `json:"query"`
+		}
+		_ = json.Unmarshal(req.Args, &args)
+		name = queryNameV5(args.Query)
+	}
+
+	fault, err := s.applyFault(ctx, name)
+	if err != nil {
+		return err
+	}
+	if fault != nil {
+		return s.writeFault(fault.Message)
+	}
+	if ok, err := s.replayed(req.raw); ok || err != nil {
+		return err
+	}
+
+	switch req.MethodName {
+	case chainsync.FindIntersectMethod:
+		var args struct {
+			Points []v5.PointV5 `json:"points"`
+		}
+		_ = json.Unmarshal(req.Args, &args)
+		var points chainsync.Points
+		for _, point := range args.Points {
+			points = append(points, point.ConvertToV6())
+		}
+		data, err := v5.MarshalFindIntersectionV5(
+			s.findIntersection(points),
+			req.Mirror,
+		)
+		if err != nil {
+			return err
+		}
+		return s.write(data)
+
+	case chainsync.RequestNextMethod:
+		result, err := s.nextBlock(ctx)
+		if err != nil {
+			return err
+		}
+		data, err := v5.MarshalNextBlockV5(result, req.Mirror)
+		if err != nil {
+			return err
+		}
+		return s.write(data)
+
+	case "SubmitTx":
+		var args struct {
+			Submit string `json:"submit"`
+		}
+		_ = json.Unmarshal(req.Args, &args)
+		if raw, ok, err := s.server.result(name); ok || err != nil {
+			if err != nil {
+				return err
+			}
+			s.server.submit(args.Submit)
+			return s.writeResultV5(req, raw)
+		}
+		id, err := s.server.submitTx(args.Submit)
+		if err != nil {
+			return s.writeFault(err.Error())
+		}
+		return s.writeResultV5(req, map[string]any{
+			"SubmitSuccess": map[string]string{"txId": id},
+		})
+	}
+
+	raw, ok, err := s.server.result(name)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.writeResultV5(req, raw)
+	}
+
+	if name == "ledgerTip" || name == "chainTip" {
+		s.server.mutex.Lock()
+		tip := s.server.tip()
+		s.server.mutex.Unlock()
+		if tip == nil {
+			return s.writeResultV5(req, "origin")
+		}
+		return s.writeResultV5(req, v5.PointStructV5{Slot: tip.Slot, Hash: tip.ID})
+	}
+
+	return s.writeFault("unsupported request, " + name)
+}
+
+// queryNameV5 returns the name of a v5 query, either a string or an object
+// keyed by the name
+func queryNameV5(query json.RawMessage) string {
+	var name string
+	if err := json.Unmarshal(query, &name); err == nil {
+		return name
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(query, &object); err == nil {
+		for key := range object {
+			return key
+		}
+	}
+	return ""
+}
+
+// findIntersection finds the first of points on the chain and resets the
+// session to deliver the blocks that follow it
+func (s *session) findIntersection(
+	points chainsync.Points,
+) chainsync.ResultFindIntersectionPraos {
+	s.server.mutex.Lock()
+	defer s.server.mutex.Unlock()
+
+	tip := s.server.tip()
+	if len(points) == 0 {
+		points = chainsync.Points{chainsync.Origin}
+	}
+	for _, point := range points {
+		ps, ok := point.PointStruct()
+		if !ok {
+			s.reset(chainsync.Origin, nil)
+			return chainsync.ResultFindIntersectionPraos{
+				Intersection: &chainsync.Origin,
+				Tip:          tip,
+			}
+		}
+		for _, block := range s.server.blocks {
+			if block.Slot == ps.Slot && block.ID == ps.ID {
+				intersection := point
+				s.reset(intersection, ps)
+				return chainsync.ResultFindIntersectionPraos{
+					Intersection: &intersection,
+					Tip:          tip,
+				}
+			}
+		}
+	}
+
+	data, _ := json.Marshal(map[string]any{"tip": tip})
+	return chainsync.ResultFindIntersectionPraos{
+		Error: &chainsync.ResultError{
+			Code:    CodeIntersectionNotFound,
+			Message: "no intersection found",
+			Data:    data,
+		},
+	}
+}
+
+func (s *session) reset(point chainsync.Point, last *chainsync.PointStruct) {
+	s.intersected = true
+	s.rollback = &point
+	s.last = last
+}
+
+// nextBlock returns the next roll forward or roll backward, waiting at the
+// tip for the chain to be extended
+func (s *session) nextBlock(
+	ctx context.Context,
+) (chainsync.ResultNextBlockPraos, error) {
+	for {
+		s.server.mutex.Lock()
+		if !s.intersected {
+			s.reset(chainsync.Origin, nil)
+		}
+		result, ok := s.advance()
+		changed := s.server.changed
+		s.server.mutex.Unlock()
+		if ok {
+			return result, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return chainsync.ResultNextBlockPraos{}, ctx.Err()
+		}
+	}
+}
+
+// advance returns the next result, if available; assumes the caller holds
+// the server mutex
+func (s *session) advance() (chainsync.ResultNextBlockPraos, bool) {
+	var (
+		blocks = s.server.blocks
+		tip    = s.server.tip()
+		next   = 0 // index of the block following last
+	)
+	if s.last != nil {
+		next = -1
+		for i, block := range blocks {
+			if block.Slot == s.last.Slot && block.ID == s.last.ID {
+				next = i + 1
+				break
+			}
+		}
+		if next < 0 && s.rollback == nil {
+			// last was rolled back; roll back to the latest prior block
+			var (
+				point = chainsync.Origin
+				slot  = s.last.Slot
+			)
+			s.last = nil
+			for i := len(blocks) - 1; i >= 0; i-- {
+				if blocks[i].Slot <= slot {
+					ps := blocks[i].PointStruct()
+					point, s.last = ps.Point(), &ps
+					break
+				}
+			}
+			s.rollback = &point
+		}
+	}
+
+	if s.rollback != nil {
+		point := *s.rollback
+		s.rollback = nil
+		return chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Tip:       tip,
+			Point:     &point,
+		}, true
+	}
+
+	if next >= len(blocks) {
+		return chainsync.ResultNextBlockPraos{}, false
+	}
+	block := blocks[next]
+	ps := block.PointStruct()
+	s.last = &ps
+	return chainsync.ResultNextBlockPraos{
+		Direction: chainsync.RollForwardString,
+		Tip:       tip,
+		Block:     &block,
+	}, true
+}
+
+func (s *Server) submit(tx string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.submitted = append(s.submitted, tx)
+}
+
+// submitTx records the transaction and returns its id i.e. the blake2b-256
+// hash of the transaction body
+func (s *Server) submitTx(tx string) (string, error) {
+	data, err := hex.DecodeString(tx)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+	var parts []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &parts); err != nil || len(parts) == 0 {
+		return "", fmt.Errorf("invalid transaction: not a cbor encoded tx")
+	}
+
+	s.submit(tx)
+	id := blake2b.Sum256(parts[0])
+	return hex.EncodeToString(id[:]), nil
+}
+
+func (s *session) write(data []byte) error {
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (s *session) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.write(data)
+}
+
+func (s *session) writeResult(method string, id json.RawMessage, result any) error {
+	return s.writeJSON(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"result":  result,
+		"id":      id,
+	})
+}
+
+func (s *session) writeError(
+	method string,
+	id json.RawMessage,
+	code int,
+	message string,
+	data json.RawMessage,
+) error {
+	e := map[string]any{"code": code, "message": message}
+	if len(data) > 0 {
+		e["data"] = data
+	}
+	response := map[string]any{
+		"jsonrpc": "2.0",
+		"error":   e,
+		"id":      id,
+	}
+	if method != "" {
+		response["method"] = method
+	}
+	return s.writeJSON(response)
+}
+
+func (s *session) writeResultV5(req requestV5, result any) error {
+	return s.writeJSON(map[string]any{
+		"type":        "jsonwsp/response",
+		"version":     "1.0",
+		"servicename": "ogmios",
+		"methodname":  req.MethodName,
+		"result":      result,
+		"reflection":  req.Mirror,
+	})
+}
+
+func (s *session) writeFault(message string) error {
+	return s.writeJSON(map[string]any{
+		"type":        "jsonwsp/fault",
+		"version":     "1.0",
+		"servicename": "ogmios",
+		"fault":       map[string]string{"code": "client", "string": message},
+	})
+}
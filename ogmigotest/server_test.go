@@ -0,0 +1,284 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigotest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/tj/assert"
+)
+
+func blocks(from, to uint64) []chainsync.Block {
+	var blocks []chainsync.Block
+	for slot := from; slot <= to; slot++ {
+		blocks = append(blocks, chainsync.Block{
+			Type:   "praos",
+			Era:    "babbage",
+			ID:     fmt.Sprintf("%064x", slot),
+			Height: slot,
+			Slot:   slot,
+		})
+	}
+	return blocks
+}
+
+func next(t *testing.T, events <-chan chainsync.BlockEvent) chainsync.BlockEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		assert.Nil(t, event.Err)
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for block event")
+		return chainsync.BlockEvent{}
+	}
+}
+
+func TestServer_ChainSync(t *testing.T) {
+	for _, protocol := range []ogmigo.Protocol{ogmigo.ProtocolV6, ogmigo.ProtocolV5} {
+		t.Run(protocol.String(), func(t *testing.T) {
+			serverProtocol := ProtocolV6
+			if protocol == ogmigo.ProtocolV5 {
+				serverProtocol = ProtocolV5
+			}
+			server := NewServer(
+				WithProtocol(serverProtocol),
+				WithBlocks(blocks(1, 3)...),
+			)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			client := ogmigo.New(
+				ogmigo.WithEndpoint(server.URL),
+				ogmigo.WithProtocol(protocol),
+				ogmigo.WithLogger(ogmigo.NopLogger),
+			)
+			events, err := client.BlocksChan(ctx)
+			assert.Nil(t, err)
+
+			event := next(t, events)
+			assert.Equal(t, chainsync.RollBackwardEvent, event.Type)
+			assert.Equal(t, chainsync.Origin.String(), event.Point.String())
+
+			for slot := uint64(1); slot <= 3; slot++ {
+				event := next(t, events)
+				assert.Equal(t, chainsync.RollForwardEvent, event.Type)
+				assert.Equal(t, slot, event.Block.Slot)
+			}
+
+			// blocks added at the tip are delivered to waiting clients
+			server.AddBlocks(blocks(4, 4)...)
+			event = next(t, events)
+			assert.EqualValues(t, 4, event.Block.Slot)
+
+			// clients past a rollback roll backward to the new tip
+			server.Rollback(2)
+			server.AddBlocks(blocks(5, 5)...)
+			event = next(t, events)
+			assert.Equal(t, chainsync.RollBackwardEvent, event.Type)
+			ps, ok := event.Point.PointStruct()
+			assert.True(t, ok)
+			assert.EqualValues(t, 2, ps.Slot)
+
+			event = next(t, events)
+			assert.EqualValues(t, 5, event.Block.Slot)
+		})
+	}
+}
+
+func TestServer_Intersection(t *testing.T) {
+	chain := blocks(1, 3)
+	server := NewServer(WithBlocks(chain...))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(server.URL),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+	)
+	events, err := client.BlocksChan(ctx, chain[1].PointStruct().Point())
+	assert.Nil(t, err)
+
+	event := next(t, events)
+	assert.Equal(t, chainsync.RollBackwardEvent, event.Type)
+	event = next(t, events)
+	assert.EqualValues(t, 3, event.Block.Slot)
+}
+
+func TestServer_Query(t *testing.T) {
+	server := NewServer(
+		WithBlocks(blocks(1, 2)...),
+		WithResult("queryLedgerState/epoch", 123),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(server.URL),
+		ogmigo.WithProtocol(ogmigo.ProtocolAuto),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+	)
+
+	protocol, err := client.Protocol(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, ogmigo.ProtocolV6, protocol)
+
+	epoch, err := client.CurrentEpoch(ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123, epoch)
+
+	tip, err := client.ChainTip(ctx)
+	assert.Nil(t, err)
+	ps, ok := tip.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 2, ps.Slot)
+
+	// methods without a result respond with a json-rpc error
+	params, err := client.CurrentProtocolParameters(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, params, 0)
+}
+
+func TestServer_QueryV5(t *testing.T) {
+	server := NewServer(
+		WithProtocol(ProtocolV5),
+		WithBlocks(blocks(1, 2)...),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(server.URL),
+		ogmigo.WithProtocol(ogmigo.ProtocolAuto),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+	)
+
+	protocol, err := client.Protocol(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, ogmigo.ProtocolV5, protocol)
+
+	tip, err := client.ChainTip(ctx)
+	assert.Nil(t, err)
+	ps, ok := tip.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 2, ps.Slot)
+}
+
+func TestServer_SubmitTx(t *testing.T) {
+	data, err := cbor.Marshal([]any{
+		map[uint64]any{2: 170000},
+		map[uint64]any{},
+		true,
+		nil,
+	})
+	assert.Nil(t, err)
+	tx := hex.EncodeToString(data)
+
+	for _, protocol := range []Protocol{ProtocolV6, ProtocolV5} {
+		server := NewServer(WithProtocol(protocol))
+
+		client := ogmigo.New(
+			ogmigo.WithEndpoint(server.URL),
+			ogmigo.WithProtocol(ogmigo.ProtocolAuto),
+			ogmigo.WithLogger(ogmigo.NopLogger),
+		)
+		response, err := client.SubmitTx(context.Background(), tx)
+		assert.Nil(t, err)
+		assert.Nil(t, response.Err())
+		assert.Len(t, response.ID, 64)
+		assert.Equal(t, []string{tx}, server.Submitted())
+
+		server.Close()
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	server := NewServer(WithResult("queryLedgerState/epoch", 123))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(server.URL),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+		ogmigo.WithQueryTimeout(250*time.Millisecond),
+	)
+
+	server.Inject(Fault{Method: "queryLedgerState/epoch", Disconnect: true})
+	_, err := client.CurrentEpoch(ctx)
+	assert.NotNil(t, err)
+
+	server.Inject(Fault{Delay: time.Second})
+	_, err = client.CurrentEpoch(ctx)
+	assert.NotNil(t, err)
+
+	server.Inject(Fault{
+		Error: &chainsync.ResultError{Code: 2003, Message: "acquired expired"},
+	})
+	_, err = client.CurrentEpoch(ctx)
+	assert.NotNil(t, err)
+
+	// faults apply once
+	epoch, err := client.CurrentEpoch(ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123, epoch)
+	assert.Equal(t, 4, server.Requests())
+}
+
+func TestLoadFixture(t *testing.T) {
+	fixture := Fixture{
+		Blocks: blocks(1, 2),
+		Results: map[string]json.RawMessage{
+			"queryLedgerState/epoch": json.RawMessage(`42`),
+		},
+	}
+	data, err := json.Marshal(fixture)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	assert.Nil(t, os.WriteFile(path, data, 0o600))
+
+	loaded, err := LoadFixture(path)
+	assert.Nil(t, err)
+	assert.Len(t, loaded.Blocks, 2)
+
+	server := NewServer(WithFixture(loaded))
+	defer server.Close()
+
+	client := ogmigo.New(
+		ogmigo.WithEndpoint(server.URL),
+		ogmigo.WithLogger(ogmigo.NopLogger),
+	)
+	epoch, err := client.CurrentEpoch(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, epoch)
+
+	_, err = LoadFixture(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
@@ -14,17 +14,84 @@
 
 package ogmigo
 
+import (
+	"crypto/tls"
+	"io"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
 // Options available to ogmios client
 type Options struct {
-	endpoint     string
-	logger       Logger
-	pipeline     int
-	saveInterval uint64
+	archive          *frameArchive
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	codec            JSONCodec
+	compression      bool
+	dialer           *websocket.Dialer
+	endpoint         string
+	endpoints        []string
+	failoverLag      uint64
+	keepAlive        time.Duration
+	keepTimeout      time.Duration
+	lagInterval      time.Duration
+	logger           Logger
+	persistent       bool
+	pipeline         int
+	poolSize         int
+	protocol         Protocol
+	queryTimeout     time.Duration
+	rateBurst        int
+	rateLimit        float64
+	retryBackoff     Backoff
+	retryMax         int
+	saveInterval     uint64
+	tlsConfig        *tls.Config
 }
 
 // Option to cardano client
 type Option func(*Options)
 
+// WithCircuitBreaker fails queries fast once an endpoint has failed threshold
+// consecutive times, e.g. failed to connect or timed out, rather than piling up
+// requests against a dead ogmios.  After cooldown, defaulting to 30s, a single
+// probe is let through; its success closes the circuit.  Queries rejected
+// while the circuit is open return an error wrapping ErrCircuitOpen
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(opts *Options) {
+		opts.breakerThreshold = threshold
+		opts.breakerCooldown = cooldown
+	}
+}
+
+// WithCompression negotiates permessage-deflate with ogmios, trading cpu for
+// bandwidth; block payloads compress roughly 5x, which helps when ogmios is
+// reached over a WAN.  Ogmios must support the extension for it to take effect
+func WithCompression(enabled bool) Option {
+	return func(opts *Options) {
+		opts.compression = enabled
+	}
+}
+
+// WithConnectionPool keeps n long-lived connections for state queries and
+// submissions.  Requests are dispatched round-robin, connections are health
+// checked via ping, and dead connections are replaced.  Call Client.Close
+// when done
+func WithConnectionPool(n int) Option {
+	return func(opts *Options) {
+		opts.poolSize = n
+	}
+}
+
+// WithDialer allows a custom websocket dialer to be used e.g. to set proxies or
+// handshake timeouts; defaults to websocket.DefaultDialer
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(opts *Options) {
+		opts.dialer = dialer
+	}
+}
+
 // WithEndpoint allows ogmios endpoint to set; defaults to ws://127.0.0.1:1337
 func WithEndpoint(endpoint string) Option {
 	return func(opts *Options) {
@@ -32,6 +99,34 @@ func WithEndpoint(endpoint string) Option {
 	}
 }
 
+// WithEndpoints allows multiple ogmios endpoints to be provided.  Should the
+// active endpoint become unreachable, or fall behind as per WithFailoverLag,
+// the client fails over to the next.  ChainSync reconnects, negotiating the
+// intersection from the last points delivered, and suppresses blocks that
+// were already delivered
+func WithEndpoints(endpoints ...string) Option {
+	return func(opts *Options) {
+		opts.endpoints = endpoints
+	}
+}
+
+// WithFailoverLag sets how many slots the active endpoint may lag another
+// endpoint before ChainSync fails over; defaults to 120
+func WithFailoverLag(slots uint64) Option {
+	return func(opts *Options) {
+		opts.failoverLag = slots
+	}
+}
+
+// WithFrameArchive streams every raw inbound frame, newline delimited, to w
+// for byte-exact retention of what ogmios reported.  See package archive for
+// compressed, rotating, and S3 backed writers
+func WithFrameArchive(w io.Writer) Option {
+	return func(opts *Options) {
+		opts.archive = &frameArchive{w: w}
+	}
+}
+
 // WithInterval specifies how frequently to save checkpoints when reading
 func WithInterval(n int) Option {
 	return func(options *Options) {
@@ -39,6 +134,26 @@ func WithInterval(n int) Option {
 	}
 }
 
+// WithJSONCodec specifies the codec used to decode blocks and query results
+// e.g. to substitute goccy/go-json or bytedance/sonic on the hot path;
+// defaults to StdJSONCodec
+func WithJSONCodec(codec JSONCodec) Option {
+	return func(opts *Options) {
+		opts.codec = codec
+	}
+}
+
+// WithKeepAlive pings ogmios every interval on chain sync, mempool, and pooled
+// connections.  Should no pong arrive within timeout, the connection is torn
+// down with a PongTimeoutError; a timeout of 0 defaults to twice the interval.
+// Server pings are always answered
+func WithKeepAlive(interval, timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.keepAlive = interval
+		opts.keepTimeout = timeout
+	}
+}
+
 // WithLogger allows custom logger to be specified
 func WithLogger(logger Logger) Option {
 	return func(opts *Options) {
@@ -46,27 +161,133 @@ func WithLogger(logger Logger) Option {
 	}
 }
 
-// WithPipeline allows number of pipelined ogmios requests to be provided
+// WithPersistentConnection keeps a single long-lived connection for state
+// queries and submissions, multiplexing concurrent requests by id, rather than
+// dialing a new connection per request; equivalent to WithConnectionPool(1).
+// Call Client.Close when done
+func WithPersistentConnection(enabled bool) Option {
+	return func(opts *Options) {
+		opts.persistent = enabled
+	}
+}
+
+// WithPipeline allows number of pipelined ogmios requests to be provided; this
+// also bounds the requests in flight on each pooled connection.  See
+// WithAdaptivePipeline to have ChainSync adapt the depth instead
 func WithPipeline(n int) Option {
 	return func(opts *Options) {
 		opts.pipeline = n
 	}
 }
 
+// WithProtocol selects the protocol spoken to ogmios; defaults to ProtocolV6.
+// ProtocolAuto detects whether the server speaks v5 or v6 so ChainSync,
+// ChainTip, and SubmitTx work unchanged against either
+func WithProtocol(protocol Protocol) Option {
+	return func(opts *Options) {
+		opts.protocol = protocol
+	}
+}
+
+// WithQueryTimeout bounds each individual request, e.g. state queries and
+// submissions, independent of the context passed in; 0 disables the timeout
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.queryTimeout = timeout
+	}
+}
+
+// WithRateLimit limits state queries, submissions, and other json-rpc requests
+// to rps per second, allowing bursts of up to burst requests, to protect shared
+// ogmios endpoints.  Retries count against the limit; the nextBlock requests
+// of ChainSync do not.  Requests wait for capacity, bounded by their context
+func WithRateLimit(rps float64, burst int) Option {
+	return func(opts *Options) {
+		opts.rateLimit = rps
+		opts.rateBurst = burst
+	}
+}
+
+// WithRetry retries state queries and submissions up to max additional times
+// when they fail with transport errors or transient ogmios acquire errors.
+// Deterministic failures, e.g. ledger rejections, are never retried.
+// Submissions are retried only if the transaction was never written, as the
+// node may already have accepted one whose response was lost
+func WithRetry(max int, backoff Backoff) Option {
+	return func(opts *Options) {
+		opts.retryMax = max
+		opts.retryBackoff = backoff
+	}
+}
+
+// WithTLSConfig specifies the tls configuration used when connecting to wss://
+// endpoints e.g. to trust self-signed certificates or present client certs
+func WithTLSConfig(config *tls.Config) Option {
+	return func(opts *Options) {
+		opts.tlsConfig = config
+	}
+}
+
 func buildOptions(opts ...Option) Options {
 	var options Options
 	for _, opt := range opts {
 		opt(&options)
 	}
+	if options.dialer == nil {
+		options.dialer = websocket.DefaultDialer
+	}
+	if options.tlsConfig != nil || options.compression {
+		dialer := *options.dialer
+		if options.tlsConfig != nil {
+			dialer.TLSClientConfig = options.tlsConfig
+		}
+		if options.compression {
+			dialer.EnableCompression = true
+		}
+		options.dialer = &dialer
+	}
+	if len(options.endpoints) > 0 {
+		options.endpoint = options.endpoints[0]
+	}
 	if options.endpoint == "" {
 		options.endpoint = "ws://127.0.0.1:1337"
 	}
+	if len(options.endpoints) == 0 {
+		options.endpoints = []string{options.endpoint}
+	}
+	if options.failoverLag == 0 {
+		options.failoverLag = 120
+	}
+	if options.lagInterval <= 0 {
+		options.lagInterval = lagInterval
+	}
 	if options.logger == nil {
 		options.logger = DefaultLogger
 	}
+	if options.rateLimit > 0 && options.rateBurst < 1 {
+		options.rateBurst = 1
+	}
+	if options.breakerCooldown <= 0 {
+		options.breakerCooldown = defaultCooldown
+	}
+	if options.keepAlive > 0 && options.keepTimeout <= 0 {
+		options.keepTimeout = 2 * options.keepAlive
+	}
+	if options.codec == nil {
+		options.codec = StdJSONCodec
+	}
+	if options.archive != nil {
+		options.archive.logger = options.logger
+	}
 	if options.pipeline <= 0 {
 		options.pipeline = 50
 	}
+	if options.persistent && options.poolSize <= 0 {
+		options.poolSize = 1
+	}
+	if options.retryBackoff == nil {
+		options.retryBackoff = ConstantBackoff(time.Second)
+	}
 	if options.saveInterval <= 0 {
 		options.saveInterval = 2160
 	}
@@ -15,8 +15,16 @@
 package ogmigo
 
 import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 func TestWithInterval(t *testing.T) {
@@ -41,3 +49,85 @@ func TestWithPipeline(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestWithDialer(t *testing.T) {
+	dialer := &websocket.Dialer{HandshakeTimeout: time.Second}
+	options := buildOptions(WithDialer(dialer))
+	if got, want := options.dialer, dialer; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	config := &tls.Config{InsecureSkipVerify: true}
+	options := buildOptions(WithTLSConfig(config))
+	if got, want := options.dialer.TLSClientConfig, config; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := websocket.DefaultDialer.TLSClientConfig; got != nil {
+		t.Fatalf("got %v; want nil", got)
+	}
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	options := buildOptions(WithQueryTimeout(time.Second))
+	if got, want := options.queryTimeout, time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	options := buildOptions(WithCompression(true))
+	if !options.dialer.EnableCompression {
+		t.Fatalf("got false; want true")
+	}
+	if websocket.DefaultDialer.EnableCompression {
+		t.Fatalf("got true; want false")
+	}
+
+	var extensions string
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		extensions = req.Header.Get("Sec-WebSocket-Extensions")
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		result := `{"jsonrpc":"2.0","result":"` + strings.Repeat("a", 4096) + `"}`
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(result))
+	}))
+	defer server.Close()
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithCompression(true),
+	)
+	result, err := client.Request(context.Background(), "bogus", nil)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(result), 4096; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !strings.Contains(extensions, "permessage-deflate") {
+		t.Fatalf("got %v; want permessage-deflate", extensions)
+	}
+}
+
+func TestWithKeepAlive(t *testing.T) {
+	options := buildOptions(WithKeepAlive(time.Second, 0))
+	if got, want := options.keepTimeout, 2*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	options = buildOptions(WithKeepAlive(time.Second, 5*time.Second))
+	if got, want := options.keepTimeout, 5*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
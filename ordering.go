@@ -0,0 +1,158 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// OrderingMode controls the order in which ChainSyncFunc callbacks are delivered
+type OrderingMode int
+
+const (
+	// OrderingStrict delivers each message only after the callback for the
+	// prior message has returned, i.e. strictly in chain order.  This is the
+	// default.
+	OrderingStrict OrderingMode = iota
+
+	// OrderingRelaxed allows up to WithCallbackConcurrency callbacks to run at
+	// once, so callbacks may complete out of chain order.  Rollbacks and
+	// checkpoints act as barriers: all earlier callbacks complete before a
+	// rollback is delivered or a point is saved to the store.
+	OrderingRelaxed
+)
+
+// String implements fmt.Stringer
+func (m OrderingMode) String() string {
+	switch m {
+	case OrderingStrict:
+		return "strict"
+	case OrderingRelaxed:
+		return "relaxed"
+	default:
+		return "unknown"
+	}
+}
+
+// dispatcher delivers messages to the callback according to the OrderingMode
+type dispatcher struct {
+	callback ChainSyncFunc
+	mode     OrderingMode
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	workers  *workers // nil unless WithWorkers was specified
+
+	mutex sync.Mutex
+	err   error
+}
+
+func newDispatcher(callback ChainSyncFunc, options ChainSyncOptions) *dispatcher {
+	concurrency := options.concurrency
+	if options.workers > 0 {
+		concurrency = options.workers
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	d := &dispatcher{
+		callback: callback,
+		mode:     options.ordering,
+		sem:      make(chan struct{}, concurrency),
+	}
+	if options.workers > 0 {
+		d.workers = newWorkers(d, options.workers, options.prepare)
+	}
+	return d
+}
+
+// dispatch delivers data to the callback.  In strict mode, or for any message
+// other than a roll forward, the callback is invoked synchronously.
+func (d *dispatcher) dispatch(ctx context.Context, data []byte) error {
+	if d.workers != nil {
+		return d.workers.dispatch(ctx, data)
+	}
+	if d.mode != OrderingRelaxed || cap(d.sem) == 1 || !isRollForward(data) {
+		if err := d.barrier(); err != nil {
+			return err
+		}
+		return d.callback(ctx, data)
+	}
+
+	if err := d.failed(); err != nil {
+		return err
+	}
+
+	d.sem <- struct{}{}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() { <-d.sem }()
+
+		if err := d.callback(ctx, data); err != nil {
+			d.fail(err)
+		}
+	}()
+	return nil
+}
+
+// barrier waits for all in-flight callbacks to complete and returns the first
+// error encountered, if any
+func (d *dispatcher) barrier() error {
+	d.wg.Wait()
+	return d.failed()
+}
+
+// close waits for all in-flight callbacks to complete and releases any workers
+func (d *dispatcher) close() error {
+	err := d.barrier()
+	if d.workers != nil {
+		d.workers.close()
+	}
+	return err
+}
+
+// saturated returns true if every callback slot, or every worker, is busy
+func (d *dispatcher) saturated() bool {
+	if d.workers != nil {
+		return d.workers.saturated()
+	}
+	return d.mode == OrderingRelaxed && len(d.sem) == cap(d.sem)
+}
+
+// fail records err unless an earlier error was recorded
+func (d *dispatcher) fail(err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *dispatcher) failed() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.err
+}
+
+// isRollForward returns true if data is a nextBlock response rolling forward
+func isRollForward(data []byte) bool {
+	direction, _ := jsonparser.GetString(data, "result", "direction")
+	return direction == chainsync.RollForwardString
+}
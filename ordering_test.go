@@ -0,0 +1,180 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+// orderingRecorder records the slots delivered to a callback
+type orderingRecorder struct {
+	mutex     sync.Mutex
+	slots     []uint64
+	completed map[uint64]bool
+	inflight  int64
+	peak      int64
+	saves     int
+	done      chan struct{}
+	want      int
+	err       error
+}
+
+func newOrderingRecorder(want int) *orderingRecorder {
+	return &orderingRecorder{
+		completed: map[uint64]bool{},
+		done:      make(chan struct{}),
+		want:      want,
+	}
+}
+
+func (r *orderingRecorder) callback(_ context.Context, data []byte) error {
+	point, slot, _, ok := getBackfillPoint(data)
+	if !ok {
+		return nil
+	}
+	if _, ok := point.PointStruct(); !ok || !isRollForward(data) {
+		return nil
+	}
+
+	v := atomic.AddInt64(&r.inflight, 1)
+	defer atomic.AddInt64(&r.inflight, -1)
+	for {
+		peak := atomic.LoadInt64(&r.peak)
+		if v <= peak || atomic.CompareAndSwapInt64(&r.peak, peak, v) {
+			break
+		}
+	}
+
+	// later slots finish sooner to encourage reordering
+	time.Sleep(time.Duration(10-slot%10) * time.Millisecond)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.slots = append(r.slots, slot)
+	r.completed[slot] = true
+	if len(r.slots) == r.want {
+		close(r.done)
+	}
+	return nil
+}
+
+// Save verifies every block up to the checkpoint has been delivered
+func (r *orderingRecorder) Save(_ context.Context, point chainsync.Point) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.saves++
+	if ps, ok := point.PointStruct(); ok {
+		for slot := uint64(1); slot <= ps.Slot; slot++ {
+			if !r.completed[slot] && r.err == nil {
+				r.err = fmt.Errorf("saved slot %v before slot %v completed", ps.Slot, slot)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *orderingRecorder) Load(context.Context) (chainsync.Points, error) {
+	return nil, nil
+}
+
+func runOrdering(t *testing.T, n int, opts ...ChainSyncOption) *orderingRecorder {
+	t.Helper()
+
+	var (
+		ctx      = context.Background()
+		endpoint = chainSyncServer(t, n)
+		client   = New(WithEndpoint(endpoint), WithLogger(NopLogger), WithInterval(7))
+		recorder = newOrderingRecorder(n)
+	)
+
+	opts = append(opts, WithStore(recorder))
+	chainSync, err := client.ChainSync(ctx, recorder.callback, opts...)
+	assert.Nil(t, err)
+
+	select {
+	case <-recorder.done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for %v blocks", n)
+	}
+	assert.Nil(t, chainSync.Close())
+	assert.Nil(t, recorder.err)
+	assert.True(t, recorder.saves > 0)
+	return recorder
+}
+
+func TestChainSync_OrderingStrict(t *testing.T) {
+	recorder := runOrdering(t, 30, WithCallbackConcurrency(4))
+
+	assert.EqualValues(t, 1, recorder.peak)
+	for i, slot := range recorder.slots {
+		assert.EqualValues(t, i+1, slot)
+	}
+}
+
+func TestChainSync_OrderingRelaxed(t *testing.T) {
+	recorder := runOrdering(t, 30,
+		WithOrdering(OrderingRelaxed),
+		WithCallbackConcurrency(4),
+	)
+
+	assert.True(t, recorder.peak > 1)
+	assert.True(t, recorder.peak <= 4)
+	assert.Len(t, recorder.completed, 30)
+}
+
+func TestChainSync_OrderingRelaxedError(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		endpoint = chainSyncServer(t, 30)
+		client   = New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		boom     = fmt.Errorf("boom")
+	)
+
+	callback := func(_ context.Context, data []byte) error {
+		if _, slot, _, _ := getBackfillPoint(data); slot == 5 {
+			return boom
+		}
+		return nil
+	}
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithOrdering(OrderingRelaxed),
+		WithCallbackConcurrency(4),
+	)
+	assert.Nil(t, err)
+
+	select {
+	case <-chainSync.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for callback error")
+	}
+	if err := chainSync.Close(); !errors.Is(err, boom) {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+}
+
+func TestOrderingMode_String(t *testing.T) {
+	assert.Equal(t, "strict", OrderingStrict.String())
+	assert.Equal(t, "relaxed", OrderingRelaxed.String())
+}
@@ -0,0 +1,349 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package address parses cardano shelley and byron addresses into their
+// payment and stake credentials
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	// ErrInvalidAddress indicates the address could not be parsed
+	ErrInvalidAddress = errors.New("address: invalid address")
+	// ErrNetworkMismatch indicates the address is for a different network
+	ErrNetworkMismatch = errors.New("address: network mismatch")
+)
+
+// Network identifies the network an address belongs to
+type Network byte
+
+const (
+	Testnet Network = 0 // Testnet covers preview, preprod, and other testnets
+	Mainnet Network = 1
+)
+
+// String implements fmt.Stringer
+func (n Network) String() string {
+	switch n {
+	case Mainnet:
+		return "mainnet"
+	case Testnet:
+		return "testnet"
+	default:
+		return fmt.Sprintf("network(%d)", byte(n))
+	}
+}
+
+// Type is the kind of address
+type Type int
+
+const (
+	TypeBase       Type = iota // Base has payment and stake credentials
+	TypePointer                // Pointer references its stake credential
+	TypeEnterprise             // Enterprise has no stake credential
+	TypeReward                 // Reward is a stake credential only
+	TypeByron                  // Byron is a bootstrap address
+)
+
+// String implements fmt.Stringer
+func (t Type) String() string {
+	switch t {
+	case TypeBase:
+		return "base"
+	case TypePointer:
+		return "pointer"
+	case TypeEnterprise:
+		return "enterprise"
+	case TypeReward:
+		return "reward"
+	case TypeByron:
+		return "byron"
+	default:
+		return "unknown"
+	}
+}
+
+// CredentialType distinguishes key hash from script hash credentials
+type CredentialType int
+
+const (
+	KeyHash CredentialType = iota
+	ScriptHash
+)
+
+// Credential is a payment or stake credential
+type Credential struct {
+	Type CredentialType
+	Hash []byte // Hash is the 28 byte blake2b-224 hash of the key or script
+}
+
+// String returns the hex encoded hash
+func (c Credential) String() string {
+	return hex.EncodeToString(c.Hash)
+}
+
+// IsScript returns true if the credential is a script hash
+func (c Credential) IsScript() bool {
+	return c.Type == ScriptHash
+}
+
+// Pointer locates the stake registration certificate of a pointer address
+type Pointer struct {
+	Slot      uint64
+	TxIndex   uint64
+	CertIndex uint64
+}
+
+// Address is a parsed cardano address
+type Address struct {
+	Type    Type
+	Network Network
+	// Payment is nil for reward addresses.  For byron addresses it holds the
+	// address root, a hash of the spending data, as a KeyHash.
+	Payment *Credential
+	// Stake is set for base and reward addresses
+	Stake *Credential
+	// Pointer is set for pointer addresses
+	Pointer *Pointer
+
+	raw []byte
+}
+
+const hashLen = 28
+
+// Parse parses a bech32 shelley address or a base58 byron address
+func Parse(s string) (Address, error) {
+	if strings.Contains(s, "1") {
+		if hrp, data, err := bech32Decode(s); err == nil {
+			a, err := FromBytes(data)
+			if err != nil {
+				return Address{}, err
+			}
+			if want := hrpOf(a); hrp != want {
+				return Address{}, fmt.Errorf(
+					"%w: %v has prefix %v; want %v", ErrInvalidAddress, s, hrp, want,
+				)
+			}
+			return a, nil
+		}
+	}
+
+	data := base58.Decode(s)
+	if len(data) == 0 {
+		return Address{}, fmt.Errorf("%w: %v", ErrInvalidAddress, s)
+	}
+	return FromBytes(data)
+}
+
+// FromBytes parses the binary encoding of an address
+func FromBytes(data []byte) (Address, error) {
+	if len(data) == 0 {
+		return Address{}, fmt.Errorf("%w: empty", ErrInvalidAddress)
+	}
+
+	header, body := data[0], data[1:]
+	a := Address{
+		Network: Network(header & 0x0f),
+		raw:     append([]byte(nil), data...),
+	}
+	credential := func(b []byte, script bool) *Credential {
+		c := &Credential{Type: KeyHash, Hash: append([]byte(nil), b...)}
+		if script {
+			c.Type = ScriptHash
+		}
+		return c
+	}
+
+	kind := header >> 4
+	switch {
+	case kind <= 3:
+		if len(body) != 2*hashLen {
+			return Address{}, fmt.Errorf("%w: base address length %v", ErrInvalidAddress, len(data))
+		}
+		a.Type = TypeBase
+		a.Payment = credential(body[:hashLen], kind&1 == 1)
+		a.Stake = credential(body[hashLen:], kind&2 == 2)
+
+	case kind == 4 || kind == 5:
+		if len(body) < hashLen+3 {
+			return Address{}, fmt.Errorf("%w: pointer address length %v", ErrInvalidAddress, len(data))
+		}
+		pointer, err := decodePointer(body[hashLen:])
+		if err != nil {
+			return Address{}, err
+		}
+		a.Type = TypePointer
+		a.Payment = credential(body[:hashLen], kind == 5)
+		a.Pointer = &pointer
+
+	case kind == 6 || kind == 7:
+		if len(body) != hashLen {
+			return Address{}, fmt.Errorf("%w: enterprise address length %v", ErrInvalidAddress, len(data))
+		}
+		a.Type = TypeEnterprise
+		a.Payment = credential(body, kind == 7)
+
+	case kind == 8:
+		return fromByron(data)
+
+	case kind == 14 || kind == 15:
+		if len(body) != hashLen {
+			return Address{}, fmt.Errorf("%w: reward address length %v", ErrInvalidAddress, len(data))
+		}
+		a.Type = TypeReward
+		a.Stake = credential(body, kind == 15)
+
+	default:
+		return Address{}, fmt.Errorf("%w: unknown header %x", ErrInvalidAddress, header)
+	}
+	return a, nil
+}
+
+// decodePointer decodes the three variable length naturals of a pointer
+func decodePointer(data []byte) (Pointer, error) {
+	var values [3]uint64
+	for i := range values {
+		var v uint64
+		for {
+			if len(data) == 0 {
+				return Pointer{}, fmt.Errorf("%w: truncated pointer", ErrInvalidAddress)
+			}
+			b := data[0]
+			data = data[1:]
+			if v > (1<<64-1)>>7 {
+				return Pointer{}, fmt.Errorf("%w: pointer overflow", ErrInvalidAddress)
+			}
+			v = v<<7 | uint64(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		values[i] = v
+	}
+	if len(data) != 0 {
+		return Pointer{}, fmt.Errorf("%w: trailing pointer bytes", ErrInvalidAddress)
+	}
+	return Pointer{Slot: values[0], TxIndex: values[1], CertIndex: values[2]}, nil
+}
+
+type byronAddress struct {
+	_       struct{} `cbor:",toarray"`
+	Payload cbor.Tag
+	CRC     uint32
+}
+
+type byronPayload struct {
+	_          struct{} `cbor:",toarray"`
+	Root       []byte
+	Attributes map[uint64]cbor.RawMessage
+	Type       uint64
+}
+
+// fromByron decodes the cbor of a byron address; base58 decoding aside,
+// byron addresses begin with the cbor array header 0x82
+func fromByron(data []byte) (Address, error) {
+	var address byronAddress
+	if err := cbor.Unmarshal(data, &address); err != nil {
+		return Address{}, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	content, ok := address.Payload.Content.([]byte)
+	if address.Payload.Number != 24 || !ok {
+		return Address{}, fmt.Errorf("%w: byron payload", ErrInvalidAddress)
+	}
+	if crc32.ChecksumIEEE(content) != address.CRC {
+		return Address{}, fmt.Errorf("%w: byron checksum", ErrInvalidAddress)
+	}
+
+	var payload byronPayload
+	if err := cbor.Unmarshal(content, &payload); err != nil {
+		return Address{}, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	if len(payload.Root) != hashLen {
+		return Address{}, fmt.Errorf("%w: byron root length %v", ErrInvalidAddress, len(payload.Root))
+	}
+
+	a := Address{
+		Type:    TypeByron,
+		Network: Mainnet,
+		Payment: &Credential{Type: KeyHash, Hash: payload.Root},
+		raw:     append([]byte(nil), data...),
+	}
+	if _, ok := payload.Attributes[2]; ok {
+		a.Network = Testnet // only testnets encode a protocol magic
+	}
+	return a, nil
+}
+
+// Bytes returns the binary encoding of the address
+func (a Address) Bytes() []byte {
+	return append([]byte(nil), a.raw...)
+}
+
+// String returns the bech32, or for byron addresses base58, encoding
+func (a Address) String() string {
+	if len(a.raw) == 0 {
+		return ""
+	}
+	if a.Type == TypeByron {
+		return base58.Encode(a.raw)
+	}
+	return bech32Encode(hrpOf(a), a.raw)
+}
+
+// Validate returns ErrNetworkMismatch unless the address is for the network
+func (a Address) Validate(network Network) error {
+	if a.Network != network {
+		return fmt.Errorf("%w: %v is a %v address", ErrNetworkMismatch, a, a.Network)
+	}
+	return nil
+}
+
+// RewardAddress returns the reward address of the stake credential; only base
+// and reward addresses have one
+func (a Address) RewardAddress() (Address, bool) {
+	if a.Stake == nil {
+		return Address{}, false
+	}
+
+	header := byte(0xe0) | byte(a.Network)&0x0f
+	if a.Stake.IsScript() {
+		header |= 0x10
+	}
+	return Address{
+		Type:    TypeReward,
+		Network: a.Network,
+		Stake:   a.Stake,
+		raw:     append([]byte{header}, a.Stake.Hash...),
+	}, true
+}
+
+func hrpOf(a Address) string {
+	prefix := "addr"
+	if a.Type == TypeReward {
+		prefix = "stake"
+	}
+	if a.Network != Mainnet {
+		prefix += "_test"
+	}
+	return prefix
+}
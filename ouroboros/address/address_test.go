@@ -0,0 +1,139 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+// test vectors from CIP-19
+const (
+	paymentHash = "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"
+	stakeHash   = "337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251"
+	scriptHash  = "c37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f"
+
+	baseKeyKey     = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	baseScriptKey  = "addr1z8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gten0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs9yc0hh"
+	baseKeyTestnet = "addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs68faae"
+	pointer        = "addr1gx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer5pnz75xxcrzqf96k"
+	enterprise     = "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	reward         = "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw"
+	rewardTestnet  = "stake_test1uqehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gssrtvn"
+	byronMainnet   = "Ae2tdPwUPEZFRbyhz3cpfC2CumGzNkFBN2L42rcUc2yjQpEkxDbkPodpMAi"
+	byronTestnet   = "37btjrVyb4KDXBNC4haBVPCrro8AQPHwvCMp3RFhhSVWwfFmZ6wwzSK6JK1hY6wHNmtrpTf1kdbva8TCneM2YsiXT7mrzT21EacHnPpz5YyUdj64na"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("base", func(t *testing.T) {
+		a, err := Parse(baseKeyKey)
+		assert.Nil(t, err)
+		assert.Equal(t, TypeBase, a.Type)
+		assert.Equal(t, Mainnet, a.Network)
+		assert.Equal(t, paymentHash, a.Payment.String())
+		assert.False(t, a.Payment.IsScript())
+		assert.Equal(t, stakeHash, a.Stake.String())
+		assert.Equal(t, baseKeyKey, a.String())
+
+		r, ok := a.RewardAddress()
+		assert.True(t, ok)
+		assert.Equal(t, reward, r.String())
+	})
+
+	t.Run("base script", func(t *testing.T) {
+		a, err := Parse(baseScriptKey)
+		assert.Nil(t, err)
+		assert.True(t, a.Payment.IsScript())
+		assert.Equal(t, scriptHash, a.Payment.String())
+		assert.False(t, a.Stake.IsScript())
+	})
+
+	t.Run("testnet", func(t *testing.T) {
+		a, err := Parse(baseKeyTestnet)
+		assert.Nil(t, err)
+		assert.Equal(t, Testnet, a.Network)
+		assert.Nil(t, a.Validate(Testnet))
+		assert.True(t, errors.Is(a.Validate(Mainnet), ErrNetworkMismatch))
+
+		r, ok := a.RewardAddress()
+		assert.True(t, ok)
+		assert.Equal(t, rewardTestnet, r.String())
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		a, err := Parse(pointer)
+		assert.Nil(t, err)
+		assert.Equal(t, TypePointer, a.Type)
+		assert.Equal(t, Pointer{Slot: 2498243, TxIndex: 27, CertIndex: 3}, *a.Pointer)
+		_, ok := a.RewardAddress()
+		assert.False(t, ok)
+	})
+
+	t.Run("enterprise", func(t *testing.T) {
+		a, err := Parse(enterprise)
+		assert.Nil(t, err)
+		assert.Equal(t, TypeEnterprise, a.Type)
+		assert.Equal(t, paymentHash, a.Payment.String())
+		assert.Nil(t, a.Stake)
+	})
+
+	t.Run("reward", func(t *testing.T) {
+		a, err := Parse(reward)
+		assert.Nil(t, err)
+		assert.Equal(t, TypeReward, a.Type)
+		assert.Nil(t, a.Payment)
+		assert.Equal(t, stakeHash, a.Stake.String())
+	})
+
+	t.Run("byron", func(t *testing.T) {
+		a, err := Parse(byronMainnet)
+		assert.Nil(t, err)
+		assert.Equal(t, TypeByron, a.Type)
+		assert.Equal(t, Mainnet, a.Network)
+		assert.Len(t, a.Payment.Hash, 28)
+		assert.Equal(t, byronMainnet, a.String())
+
+		a, err = Parse(byronTestnet)
+		assert.Nil(t, err)
+		assert.Equal(t, Testnet, a.Network)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3y", // checksum
+			"stake1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8",                                             // prefix
+			"Ae2tdPwUPEZFRbyhz3cpfC2CumGzNkFBN2L42rcUc2yjQpEkxDbkPodpMAj",                                             // crc
+		} {
+			_, err := Parse(s)
+			assert.True(t, errors.Is(err, ErrInvalidAddress), s)
+		}
+	})
+}
+
+func TestGroupByStake(t *testing.T) {
+	groups := GroupByStake(chainsync.TxOuts{
+		{Address: baseKeyKey},
+		{Address: enterprise},
+		{Address: baseScriptKey},
+		{Address: "bogus"},
+	})
+	assert.Len(t, groups, 2)
+	assert.Len(t, groups[reward], 2)
+	assert.Len(t, groups[""], 2)
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// GroupByStake groups outputs by the bech32 reward address of their stake
+// credential.  Outputs without one, such as enterprise, pointer, and byron
+// addresses, or whose address cannot be parsed, are grouped under "".
+func GroupByStake(outputs chainsync.TxOuts) map[string]chainsync.TxOuts {
+	groups := map[string]chainsync.TxOuts{}
+	for _, output := range outputs {
+		key, _ := StakeAddress(output)
+		groups[key] = append(groups[key], output)
+	}
+	return groups
+}
+
+// StakeAddress returns the bech32 reward address of the output's stake
+// credential, if any
+func StakeAddress(output chainsync.TxOut) (string, bool) {
+	a, err := Parse(output.Address)
+	if err != nil {
+		return "", false
+	}
+	reward, ok := a.RewardAddress()
+	if !ok {
+		return "", false
+	}
+	return reward.String(), true
+}
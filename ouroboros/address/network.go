@@ -0,0 +1,67 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// NetworkOf returns the network id encoded by addresses on the tx network n;
+// ok is false if n is not a known network
+func NetworkOf(n chainsync.Network) (network Network, ok bool) {
+	switch n {
+	case chainsync.NetworkMainnet:
+		return Mainnet, true
+	case chainsync.NetworkTestnet:
+		return Testnet, true
+	default:
+		return 0, false
+	}
+}
+
+// TxNetwork returns the tx network of addresses with network id n
+func (n Network) TxNetwork() chainsync.Network {
+	if n == Mainnet {
+		return chainsync.NetworkMainnet
+	}
+	return chainsync.NetworkTestnet
+}
+
+// ValidateOutputs returns ErrNetworkMismatch if any output pays an address on
+// a network other than network, or ErrInvalidAddress if one cannot be parsed
+func ValidateOutputs(network Network, outputs chainsync.TxOuts) error {
+	for i, output := range outputs {
+		a, err := Parse(output.Address)
+		if err != nil {
+			return fmt.Errorf("output %v: %w", i, err)
+		}
+		if err := a.Validate(network); err != nil {
+			return fmt.Errorf("output %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTx validates the outputs of the tx against the network the tx is
+// bound to.  Txs that do not specify a network are not validated.
+func ValidateTx(tx chainsync.Tx) error {
+	network, ok := NetworkOf(tx.Network)
+	if !ok {
+		return nil
+	}
+	return ValidateOutputs(network, tx.Outputs)
+}
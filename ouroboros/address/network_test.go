@@ -0,0 +1,59 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestNetworkOf(t *testing.T) {
+	network, ok := NetworkOf(chainsync.NetworkMainnet)
+	assert.True(t, ok)
+	assert.Equal(t, Mainnet, network)
+	assert.Equal(t, chainsync.NetworkMainnet, network.TxNetwork())
+
+	network, ok = NetworkOf(chainsync.NetworkTestnet)
+	assert.True(t, ok)
+	assert.Equal(t, Testnet, network)
+	assert.Equal(t, chainsync.NetworkTestnet, network.TxNetwork())
+
+	_, ok = NetworkOf("")
+	assert.False(t, ok)
+}
+
+func TestValidateTx(t *testing.T) {
+	tx := chainsync.Tx{
+		Network: chainsync.NetworkMainnet,
+		Outputs: chainsync.TxOuts{
+			{Address: baseKeyKey},
+			{Address: enterprise},
+		},
+	}
+	assert.Nil(t, ValidateTx(tx))
+
+	tx.Outputs = append(tx.Outputs, chainsync.TxOut{Address: baseKeyTestnet})
+	err := ValidateTx(tx)
+	assert.True(t, errors.Is(err, ErrNetworkMismatch))
+
+	tx.Network = ""
+	assert.Nil(t, ValidateTx(tx))
+
+	err = ValidateOutputs(Testnet, chainsync.TxOuts{{Address: "bogus"}})
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// Certificate types as reported by ogmios
+const (
+	CertificateStakeCredentialRegistration        = "stakeCredentialRegistration"
+	CertificateStakeCredentialDeregistration      = "stakeCredentialDeregistration"
+	CertificateStakeDelegation                    = "stakeDelegation"
+	CertificateStakePoolRegistration              = "stakePoolRegistration"
+	CertificateStakePoolRetirement                = "stakePoolRetirement"
+	CertificateGenesisDelegation                  = "genesisDelegation"
+	CertificateDelegateRepresentativeRegistration = "delegateRepresentativeRegistration"
+	CertificateDelegateRepresentativeUpdate       = "delegateRepresentativeUpdate"
+	CertificateDelegateRepresentativeRetirement   = "delegateRepresentativeRetirement"
+	CertificateConstitutionalCommitteeDelegation  = "constitutionalCommitteeDelegation"
+	CertificateConstitutionalCommitteeRetirement  = "constitutionalCommitteeRetirement"
+)
+
+// Certificate is implemented by each of the typed certificates below and by
+// RawCertificate, for kinds not otherwise known.  Use a type switch to
+// distinguish them.
+type Certificate interface {
+	// CertificateType returns the ogmios type of the certificate
+	CertificateType() string
+}
+
+// Anchor references off chain metadata by url and hash
+type Anchor struct {
+	URL  string `json:"url"  dynamodbav:"url"`
+	Hash string `json:"hash" dynamodbav:"hash"`
+}
+
+// DelegateRepresentative is a drep; Type is one of registered, abstain, or
+// noConfidence and only registered dreps have an ID
+type DelegateRepresentative struct {
+	Type string `json:"type"           dynamodbav:"type"`
+	ID   string `json:"id,omitempty"   dynamodbav:"id,omitempty"`
+	From string `json:"from,omitempty" dynamodbav:"from,omitempty"`
+}
+
+// CommitteeMember is the credential of a constitutional committee member
+type CommitteeMember struct {
+	ID   string `json:"id"   dynamodbav:"id"`
+	From string `json:"from" dynamodbav:"from"`
+}
+
+// StakePoolID references a stake pool by its bech32 id
+type StakePoolID struct {
+	ID string `json:"id" dynamodbav:"id"`
+}
+
+// StakePoolMetadata references the stake pool's off chain metadata
+type StakePoolMetadata struct {
+	URL  string `json:"url"  dynamodbav:"url"`
+	Hash string `json:"hash" dynamodbav:"hash"`
+}
+
+// StakePoolParameters are the parameters of a stake pool registration
+type StakePoolParameters struct {
+	ID                     string             `json:"id"                     dynamodbav:"id"`
+	VrfVerificationKeyHash string             `json:"vrfVerificationKeyHash" dynamodbav:"vrfVerificationKeyHash"`
+	Owners                 []string           `json:"owners"                 dynamodbav:"owners"`
+	Cost                   shared.Value       `json:"cost"                   dynamodbav:"cost"`
+	Margin                 string             `json:"margin"                 dynamodbav:"margin"`
+	Pledge                 shared.Value       `json:"pledge"                 dynamodbav:"pledge"`
+	RewardAccount          string             `json:"rewardAccount"          dynamodbav:"rewardAccount"`
+	Metadata               *StakePoolMetadata `json:"metadata,omitempty"     dynamodbav:"metadata,omitempty"`
+	Relays                 json.RawMessage    `json:"relays,omitempty"       dynamodbav:"relays,omitempty"`
+}
+
+// StakePoolRetirementParameters identify the pool and the epoch it retires
+type StakePoolRetirementParameters struct {
+	ID              string `json:"id"              dynamodbav:"id"`
+	RetirementEpoch uint64 `json:"retirementEpoch" dynamodbav:"retirementEpoch"`
+}
+
+// StakeCredentialRegistration registers a stake credential
+type StakeCredentialRegistration struct {
+	Type       string        `json:"type"              dynamodbav:"type"`
+	Credential string        `json:"credential"        dynamodbav:"credential"`
+	Deposit    *shared.Value `json:"deposit,omitempty" dynamodbav:"deposit,omitempty"`
+}
+
+// StakeCredentialDeregistration deregisters a stake credential
+type StakeCredentialDeregistration struct {
+	Type       string        `json:"type"              dynamodbav:"type"`
+	Credential string        `json:"credential"        dynamodbav:"credential"`
+	Deposit    *shared.Value `json:"deposit,omitempty" dynamodbav:"deposit,omitempty"`
+}
+
+// StakeDelegation delegates a stake credential to a stake pool, a drep, or
+// both.  Vote delegation is a StakeDelegation with a DelegateRepresentative.
+type StakeDelegation struct {
+	Type                   string                  `json:"type"                             dynamodbav:"type"`
+	Credential             string                  `json:"credential"                       dynamodbav:"credential"`
+	StakePool              *StakePoolID            `json:"stakePool,omitempty"              dynamodbav:"stakePool,omitempty"`
+	DelegateRepresentative *DelegateRepresentative `json:"delegateRepresentative,omitempty" dynamodbav:"delegateRepresentative,omitempty"`
+}
+
+// StakePoolRegistration registers or updates a stake pool
+type StakePoolRegistration struct {
+	Type      string              `json:"type"      dynamodbav:"type"`
+	StakePool StakePoolParameters `json:"stakePool" dynamodbav:"stakePool"`
+}
+
+// StakePoolRetirement announces the retirement of a stake pool
+type StakePoolRetirement struct {
+	Type      string                        `json:"type"      dynamodbav:"type"`
+	StakePool StakePoolRetirementParameters `json:"stakePool" dynamodbav:"stakePool"`
+}
+
+// GenesisDelegation delegates a genesis key; pre-conway only
+type GenesisDelegation struct {
+	Type     string          `json:"type"     dynamodbav:"type"`
+	Delegate json.RawMessage `json:"delegate" dynamodbav:"delegate"`
+	Issuer   json.RawMessage `json:"issuer"   dynamodbav:"issuer"`
+}
+
+// DelegateRepresentativeRegistration registers a drep
+type DelegateRepresentativeRegistration struct {
+	Type                   string                 `json:"type"                   dynamodbav:"type"`
+	DelegateRepresentative DelegateRepresentative `json:"delegateRepresentative" dynamodbav:"delegateRepresentative"`
+	Deposit                shared.Value           `json:"deposit"                dynamodbav:"deposit"`
+	Metadata               *Anchor                `json:"metadata,omitempty"     dynamodbav:"metadata,omitempty"`
+}
+
+// DelegateRepresentativeUpdate updates the metadata of a drep
+type DelegateRepresentativeUpdate struct {
+	Type                   string                 `json:"type"                   dynamodbav:"type"`
+	DelegateRepresentative DelegateRepresentative `json:"delegateRepresentative" dynamodbav:"delegateRepresentative"`
+	Metadata               *Anchor                `json:"metadata,omitempty"     dynamodbav:"metadata,omitempty"`
+}
+
+// DelegateRepresentativeRetirement retires a drep and refunds its deposit
+type DelegateRepresentativeRetirement struct {
+	Type                   string                 `json:"type"                   dynamodbav:"type"`
+	DelegateRepresentative DelegateRepresentative `json:"delegateRepresentative" dynamodbav:"delegateRepresentative"`
+	Deposit                shared.Value           `json:"deposit"                dynamodbav:"deposit"`
+}
+
+// ConstitutionalCommitteeDelegation authorizes a hot credential, Delegate,
+// to vote on behalf of the cold credential of a committee Member
+type ConstitutionalCommitteeDelegation struct {
+	Type     string          `json:"type"     dynamodbav:"type"`
+	Member   CommitteeMember `json:"member"   dynamodbav:"member"`
+	Delegate CommitteeMember `json:"delegate" dynamodbav:"delegate"`
+}
+
+// ConstitutionalCommitteeRetirement resigns the cold credential of a
+// committee member
+type ConstitutionalCommitteeRetirement struct {
+	Type     string          `json:"type"             dynamodbav:"type"`
+	Member   CommitteeMember `json:"member"           dynamodbav:"member"`
+	Metadata *Anchor         `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+}
+
+// RawCertificate holds a certificate of an unknown kind exactly as received
+type RawCertificate struct {
+	Type string
+	Raw  json.RawMessage
+}
+
+// MarshalJSON returns the certificate as received
+func (c RawCertificate) MarshalJSON() ([]byte, error) {
+	if c.Raw == nil {
+		return []byte("null"), nil
+	}
+	return c.Raw, nil
+}
+
+func (c StakeCredentialRegistration) CertificateType() string        { return c.Type }
+func (c StakeCredentialDeregistration) CertificateType() string      { return c.Type }
+func (c StakeDelegation) CertificateType() string                    { return c.Type }
+func (c StakePoolRegistration) CertificateType() string              { return c.Type }
+func (c StakePoolRetirement) CertificateType() string                { return c.Type }
+func (c GenesisDelegation) CertificateType() string                  { return c.Type }
+func (c DelegateRepresentativeRegistration) CertificateType() string { return c.Type }
+func (c DelegateRepresentativeUpdate) CertificateType() string       { return c.Type }
+func (c DelegateRepresentativeRetirement) CertificateType() string   { return c.Type }
+func (c ConstitutionalCommitteeDelegation) CertificateType() string  { return c.Type }
+func (c ConstitutionalCommitteeRetirement) CertificateType() string  { return c.Type }
+func (c RawCertificate) CertificateType() string                     { return c.Type }
+
+// DecodeCertificate decodes a single certificate.  Certificates of an unknown
+// type are returned as a RawCertificate.
+func DecodeCertificate(data json.RawMessage) (Certificate, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+
+	var (
+		certificate Certificate
+		err         error
+	)
+	switch head.Type {
+	case CertificateStakeCredentialRegistration:
+		certificate, err = decodeCertificate[StakeCredentialRegistration](data)
+	case CertificateStakeCredentialDeregistration:
+		certificate, err = decodeCertificate[StakeCredentialDeregistration](data)
+	case CertificateStakeDelegation:
+		certificate, err = decodeCertificate[StakeDelegation](data)
+	case CertificateStakePoolRegistration:
+		certificate, err = decodeCertificate[StakePoolRegistration](data)
+	case CertificateStakePoolRetirement:
+		certificate, err = decodeCertificate[StakePoolRetirement](data)
+	case CertificateGenesisDelegation:
+		certificate, err = decodeCertificate[GenesisDelegation](data)
+	case CertificateDelegateRepresentativeRegistration:
+		certificate, err = decodeCertificate[DelegateRepresentativeRegistration](data)
+	case CertificateDelegateRepresentativeUpdate:
+		certificate, err = decodeCertificate[DelegateRepresentativeUpdate](data)
+	case CertificateDelegateRepresentativeRetirement:
+		certificate, err = decodeCertificate[DelegateRepresentativeRetirement](data)
+	case CertificateConstitutionalCommitteeDelegation:
+		certificate, err = decodeCertificate[ConstitutionalCommitteeDelegation](data)
+	case CertificateConstitutionalCommitteeRetirement:
+		certificate, err = decodeCertificate[ConstitutionalCommitteeRetirement](data)
+	default:
+		raw := append(json.RawMessage(nil), data...)
+		return RawCertificate{Type: head.Type, Raw: raw}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %v certificate: %w", head.Type, err)
+	}
+	return certificate, nil
+}
+
+func decodeCertificate[T Certificate](data []byte) (Certificate, error) {
+	var certificate T
+	if err := json.Unmarshal(data, &certificate); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// DecodeCertificates decodes the certificates of the transaction, if any
+func (t Tx) DecodeCertificates() ([]Certificate, error) {
+	if len(t.Certificates) == 0 {
+		return nil, nil
+	}
+
+	certificates := make([]Certificate, 0, len(t.Certificates))
+	for i, data := range t.Certificates {
+		certificate, err := DecodeCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("tx %v, certificate %v: %w", t.ID, i, err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
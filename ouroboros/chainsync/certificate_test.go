@@ -0,0 +1,93 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestTx_DecodeCertificates(t *testing.T) {
+	tx := Tx{
+		ID: "tx",
+		Certificates: []json.RawMessage{
+			json.RawMessage(`{"type":"stakeCredentialRegistration","credential":"cred","deposit":{"ada":{"lovelace":2000000}}}`),
+			json.RawMessage(`{"type":"stakeDelegation","credential":"cred","stakePool":{"id":"pool1"},"delegateRepresentative":{"type":"abstain"}}`),
+			json.RawMessage(`{"type":"stakePoolRegistration","stakePool":{"id":"pool1","vrfVerificationKeyHash":"vrf","owners":["owner"],"cost":{"ada":{"lovelace":340000000}},"margin":"1/20","pledge":{"ada":{"lovelace":100}},"rewardAccount":"stake1","relays":[]}}`),
+			json.RawMessage(`{"type":"stakePoolRetirement","stakePool":{"id":"pool1","retirementEpoch":300}}`),
+			json.RawMessage(`{"type":"delegateRepresentativeRegistration","delegateRepresentative":{"type":"registered","id":"drep","from":"verificationKey"},"deposit":{"ada":{"lovelace":500000000}},"metadata":{"url":"https://example.com","hash":"abc"}}`),
+			json.RawMessage(`{"type":"constitutionalCommitteeDelegation","member":{"id":"cold","from":"script"},"delegate":{"id":"hot","from":"verificationKey"}}`),
+			json.RawMessage(`{"type":"constitutionalCommitteeRetirement","member":{"id":"cold","from":"script"}}`),
+			json.RawMessage(`{"type":"somethingNew","field":1}`),
+		},
+	}
+
+	certificates, err := tx.DecodeCertificates()
+	assert.Nil(t, err)
+	assert.Len(t, certificates, len(tx.Certificates))
+
+	registration, ok := certificates[0].(StakeCredentialRegistration)
+	assert.True(t, ok)
+	assert.Equal(t, "cred", registration.Credential)
+	assert.EqualValues(t, 2000000, registration.Deposit.AdaLovelace().Int64())
+
+	delegation, ok := certificates[1].(StakeDelegation)
+	assert.True(t, ok)
+	assert.Equal(t, "pool1", delegation.StakePool.ID)
+	assert.Equal(t, "abstain", delegation.DelegateRepresentative.Type)
+
+	pool, ok := certificates[2].(StakePoolRegistration)
+	assert.True(t, ok)
+	assert.Equal(t, "1/20", pool.StakePool.Margin)
+	assert.EqualValues(t, 340000000, pool.StakePool.Cost.AdaLovelace().Int64())
+
+	retirement, ok := certificates[3].(StakePoolRetirement)
+	assert.True(t, ok)
+	assert.EqualValues(t, 300, retirement.StakePool.RetirementEpoch)
+
+	drep, ok := certificates[4].(DelegateRepresentativeRegistration)
+	assert.True(t, ok)
+	assert.Equal(t, "drep", drep.DelegateRepresentative.ID)
+	assert.Equal(t, "https://example.com", drep.Metadata.URL)
+
+	hot, ok := certificates[5].(ConstitutionalCommitteeDelegation)
+	assert.True(t, ok)
+	assert.Equal(t, "cold", hot.Member.ID)
+	assert.Equal(t, "hot", hot.Delegate.ID)
+
+	_, ok = certificates[6].(ConstitutionalCommitteeRetirement)
+	assert.True(t, ok)
+
+	raw, ok := certificates[7].(RawCertificate)
+	assert.True(t, ok)
+	assert.Equal(t, "somethingNew", raw.CertificateType())
+	data, err := json.Marshal(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, string(tx.Certificates[7]), string(data))
+}
+
+func TestDecodeCertificate_Invalid(t *testing.T) {
+	_, err := DecodeCertificate(json.RawMessage(`{"type":"stakePoolRetirement","stakePool":"bogus"}`))
+	assert.NotNil(t, err)
+
+	_, err = DecodeCertificate(json.RawMessage(`[]`))
+	assert.NotNil(t, err)
+
+	certificates, err := Tx{}.DecodeCertificates()
+	assert.Nil(t, err)
+	assert.Len(t, certificates, 0)
+}
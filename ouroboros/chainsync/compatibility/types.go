@@ -195,23 +195,30 @@ func (c CompatibleResultNextBlock) String() string {
 	)
 }
 
-// Frontend for converting v5 JSON responses to v6.
+// Frontend for converting v5 JSON responses to v6.  Covers the chainsync,
+// submitTransaction, evaluateTransaction, and state query methods.
 type CompatibleResponsePraos chainsync.ResponsePraos
 
 func (c *CompatibleResponsePraos) UnmarshalJSON(data []byte) error {
 	var r chainsync.ResponsePraos
-	err := json.Unmarshal(data, &r)
-	if err == nil && r.Result != nil {
+	err1 := json.Unmarshal(data, &r)
+	if err1 == nil && (r.Result != nil || r.Error != nil) {
 		*c = CompatibleResponsePraos(r)
 		return nil
 	}
+	if err1 != nil && r.JsonRpc != "" {
+		return err1 // v6, but not a method we understand
+	}
 
 	var r5 v5.ResponseV5
-	err = json.Unmarshal(data, &r5)
+	err := json.Unmarshal(data, &r5)
 	if err != nil {
 		// Just skip all the data processing, as it's useless.
 		return err
 	}
+	if r5.Result == nil {
+		return fmt.Errorf("unable to parse as either v5 or v6 response: '%w'", err1)
+	}
 
 	*c = CompatibleResponsePraos(r5.ConvertToV6())
 	return nil
@@ -251,44 +258,66 @@ func (c CompatibleResponsePraos) MarshalDynamoDBAttributeValue(
 	return nil
 }
 
+// FindIntersectResult returns the result of a findIntersection response
+func (r CompatibleResponsePraos) FindIntersectResult() (
+	CompatibleResultFindIntersection,
+	error,
+) {
+	result, err := chainsync.ResponsePraos(r).FindIntersectResult()
+	return CompatibleResultFindIntersection(result), err
+}
+
+// NextBlockResult returns the result of a nextBlock response
+func (r CompatibleResponsePraos) NextBlockResult() (
+	CompatibleResultNextBlock,
+	error,
+) {
+	result, err := chainsync.ResponsePraos(r).NextBlockResult()
+	return CompatibleResultNextBlock(result), err
+}
+
+// SubmitTransactionResult returns the result of a submitTransaction response
+func (r CompatibleResponsePraos) SubmitTransactionResult() (
+	chainsync.ResultSubmitTransactionPraos,
+	error,
+) {
+	return chainsync.ResponsePraos(r).SubmitTransactionResult()
+}
+
+// EvaluateTransactionResult returns the result of an evaluateTransaction
+// response
+func (r CompatibleResponsePraos) EvaluateTransactionResult() (
+	chainsync.ResultEvaluateTransactionPraos,
+	error,
+) {
+	return chainsync.ResponsePraos(r).EvaluateTransactionResult()
+}
+
+// QueryResult returns the undecoded result of a state query response
+func (r CompatibleResponsePraos) QueryResult() (json.RawMessage, error) {
+	return chainsync.ResponsePraos(r).QueryResult()
+}
+
+// MustFindIntersectResult is FindIntersectResult, panicking on error.
+//
+// Deprecated: use FindIntersectResult, which does not panic on untrusted input
 func (r CompatibleResponsePraos) MustFindIntersectResult() CompatibleResultFindIntersection {
-	if r.Method != chainsync.FindIntersectionMethod {
-		panic(
-			fmt.Errorf(
-				"must only use *Must* methods after switching on the findIntersection method; called on %v",
-				r.Method,
-			),
-		)
-	}
-	t, ok := r.Result.(chainsync.ResultFindIntersectionPraos)
-	if ok {
-		return CompatibleResultFindIntersection(t)
-	}
-	u, ok := r.Result.(*chainsync.ResultFindIntersectionPraos)
-	if ok && u != nil {
-		return CompatibleResultFindIntersection(*u)
+	result, err := r.FindIntersectResult()
+	if err != nil {
+		panic(err)
 	}
-	panic(errors.New("must method called on incompatible type"))
+	return result
 }
 
+// MustNextBlockResult is NextBlockResult, panicking on error.
+//
+// Deprecated: use NextBlockResult, which does not panic on untrusted input
 func (r CompatibleResponsePraos) MustNextBlockResult() CompatibleResultNextBlock {
-	if r.Method != chainsync.NextBlockMethod {
-		panic(
-			fmt.Errorf(
-				"must only use *Must* methods after switching on the nextBlock method; called on %v",
-				r.Method,
-			),
-		)
-	}
-	t, ok := r.Result.(chainsync.ResultNextBlockPraos)
-	if ok {
-		return CompatibleResultNextBlock(t)
-	}
-	u, ok := r.Result.(*chainsync.ResultNextBlockPraos)
-	if ok && u != nil {
-		return CompatibleResultNextBlock(*u)
+	result, err := r.NextBlockResult()
+	if err != nil {
+		panic(err)
 	}
-	panic(errors.New("must method called on incompatible type"))
+	return result
 }
 
 type CompatibleValue shared.Value
@@ -17,8 +17,10 @@ package compatibility
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
@@ -843,6 +845,169 @@ func Test_UnmarshalTxWithNilMetadata(t *testing.T) {
 	var tx CompatibleTx
 	err = json.Unmarshal(data, &tx)
 	assert.Nil(t, err)
-	_, err = GetMetadataDatumMap(tx.Metadata, 103251)
+	_, err = GetMetadataDatumMap(tx.Metadata.RawJSON(), 103251)
 	assert.Nil(t, err)
 }
+
+func TestCompatibleResultNextBlock_Byron(t *testing.T) {
+	rawData, err := os.ReadFile("test_data/RollForward_Byron_v5.json")
+	assert.Nil(t, err)
+
+	var compatible CompatibleResultNextBlock
+	err = json.Unmarshal(rawData, &compatible)
+	assert.Nil(t, err)
+	assert.Equal(t, chainsync.RollForwardString, compatible.Direction)
+	assert.NotNil(t, compatible.Block)
+
+	block := compatible.Block
+	assert.Equal(t, "bft", block.Type)
+	assert.Equal(t, "byron", block.Era)
+	assert.EqualValues(t, 4520, block.Slot)
+	assert.EqualValues(t, 4500, block.Height)
+	assert.Len(t, block.Transactions, 1)
+
+	tx := block.Transactions[0]
+	assert.Equal(t, "a12a839c25a01fa5d118167db5acdbd9e38172ae8f00e5ac0a4997ef792a2007", tx.ID)
+	assert.Len(t, tx.Inputs, 1)
+	assert.Equal(t, 0, tx.Inputs[0].Index)
+	assert.Len(t, tx.Outputs, 1)
+	assert.EqualValues(t, 1000000, tx.Outputs[0].Value.AdaLovelace().Int64())
+
+	// round trip back through the v5 form
+	data, err := json.Marshal(compatible)
+	assert.Nil(t, err)
+
+	var again CompatibleResultNextBlock
+	err = json.Unmarshal(data, &again)
+	assert.Nil(t, err)
+	assert.Equal(t, compatible.Block.ID, again.Block.ID)
+	assert.Equal(t, tx.Inputs, again.Block.Transactions[0].Inputs)
+}
+
+func TestCompatibleResultNextBlock_Nonce(t *testing.T) {
+	rawData, err := os.ReadFile("test_data/Response_NextBlock_v5.json")
+	assert.Nil(t, err)
+
+	var response CompatibleResponsePraos
+	err = json.Unmarshal(rawData, &response)
+	assert.Nil(t, err)
+
+	result, err := response.NextBlockResult()
+	assert.Nil(t, err)
+	assert.NotNil(t, result.Block.Nonce)
+	assert.NotEqual(t, result.Block.Nonce.Output, result.Block.Nonce.Proof)
+	assert.True(t, strings.HasPrefix(result.Block.Nonce.Proof, "AX7wW34N"))
+}
+
+func TestCompatibleResponse_Methods(t *testing.T) {
+	decode := func(t *testing.T, filename string) CompatibleResponsePraos {
+		data, err := os.ReadFile("test_data/" + filename)
+		assert.Nil(t, err)
+
+		var response CompatibleResponsePraos
+		assert.Nil(t, json.Unmarshal(data, &response))
+		return response
+	}
+
+	// roundTrip encodes the response as v5 and decodes it again
+	roundTrip := func(t *testing.T, r CompatibleResponsePraos) CompatibleResponsePraos {
+		data, err := json.Marshal(r)
+		assert.Nil(t, err)
+
+		var response CompatibleResponsePraos
+		assert.Nil(t, json.Unmarshal(data, &response))
+		return response
+	}
+
+	t.Run("submit", func(t *testing.T) {
+		for _, filename := range []string{
+			"Response_SubmitTx_v5.json",
+			"Response_SubmitTransaction_v6.json",
+		} {
+			response := decode(t, filename)
+			assert.Equal(t, chainsync.SubmitTransactionMethod, response.Method)
+
+			result, err := response.SubmitTransactionResult()
+			assert.Nil(t, err)
+			assert.Equal(t, "e1a2b3", result.Transaction.ID)
+
+			result, err = roundTrip(t, response).SubmitTransactionResult()
+			assert.Nil(t, err)
+			assert.Equal(t, "e1a2b3", result.Transaction.ID)
+		}
+	})
+
+	t.Run("submit failed", func(t *testing.T) {
+		for _, filename := range []string{
+			"Response_SubmitTxFail_v5.json",
+			"Response_SubmitTransactionError_v6.json",
+		} {
+			response := decode(t, filename)
+			assert.Equal(t, chainsync.SubmitTransactionMethod, response.Method)
+			assert.NotNil(t, response.Error)
+
+			_, err := response.SubmitTransactionResult()
+			assert.True(t, errors.Is(err, chainsync.ErrIncompatibleResult))
+
+			response = roundTrip(t, response)
+			assert.Equal(t, chainsync.SubmitTransactionMethod, response.Method)
+			assert.NotNil(t, response.Error)
+		}
+	})
+
+	t.Run("evaluate", func(t *testing.T) {
+		want := chainsync.ResultEvaluateTransactionPraos{
+			{
+				Validator: chainsync.RedeemerValidator{Purpose: "spend", Index: 1},
+				Budget:    chainsync.ExUnits{Memory: 1, CPU: 2},
+			},
+			{
+				Validator: chainsync.RedeemerValidator{Purpose: "withdraw", Index: 0},
+				Budget:    chainsync.ExUnits{Memory: 3, CPU: 4},
+			},
+		}
+		for _, filename := range []string{
+			"Response_EvaluateTx_v5.json",
+			"Response_EvaluateTransaction_v6.json",
+		} {
+			response := decode(t, filename)
+			result, err := response.EvaluateTransactionResult()
+			assert.Nil(t, err)
+			assert.Equal(t, want, result)
+
+			result, err = roundTrip(t, response).EvaluateTransactionResult()
+			assert.Nil(t, err)
+			assert.Equal(t, want, result)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		response := decode(t, "Response_QueryLedgerState_v6.json")
+		assert.Equal(t, "queryLedgerState/epoch", response.Method)
+
+		result, err := response.QueryResult()
+		assert.Nil(t, err)
+		assert.Equal(t, "432", string(result))
+
+		response = roundTrip(t, response)
+		assert.Equal(t, chainsync.QueryMethod, response.Method)
+		result, err = response.QueryResult()
+		assert.Nil(t, err)
+		assert.Equal(t, "432", string(result))
+
+		response = decode(t, "Response_Query_v5.json")
+		assert.Equal(t, chainsync.QueryMethod, response.Method)
+		result, err = response.QueryResult()
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"slot":1234,"hash":"abcd"}`, string(result))
+
+		_, err = response.NextBlockResult()
+		assert.True(t, errors.Is(err, chainsync.ErrUnexpectedMethod))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		var response CompatibleResponsePraos
+		err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"bogus","result":{}}`), &response)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// InlineDatum returns the cbor encoded datum held inline by the output; ok
+// is false if the output carries no inline datum
+func (t TxOut) InlineDatum() ([]byte, bool) {
+	if t.Datum == "" {
+		return nil, false
+	}
+	data, err := hex.DecodeString(t.Datum)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// DatumRef returns the hash of the datum referenced by the output; ok is false
+// if the output carries no datum hash, e.g. because its datum is inline
+func (t TxOut) DatumRef() (string, bool) {
+	return t.DatumHash, t.DatumHash != ""
+}
+
+// NormalizeDatum returns a copy of the output with the datum hash held in
+// DatumHash and Datum only set for inline datums.  A Datum is treated as a
+// hash when IsDatumHash reports it as such.
+func (t TxOut) NormalizeDatum(witness Datums) TxOut {
+	if t.Datum == "" || t.DatumHash != "" {
+		return t
+	}
+	if IsDatumHash(t.Datum, witness) {
+		t.DatumHash, t.Datum = t.Datum, ""
+	}
+	return t
+}
+
+// UnmarshalJSON implements json.Unmarshaler; outputs previously encoded with
+// a datum hash in Datum, e.g. converted from v5, are normalized
+func (t *TxOut) UnmarshalJSON(data []byte) error {
+	type txOut TxOut
+	var out txOut
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*t = TxOut(out).NormalizeDatum(nil)
+	return nil
+}
+
+// IsDatumHash returns true if the hex encoded datum is a datum hash rather
+// than an inline datum, i.e. it matches a datum in the witness set or is a 32
+// byte value that is not well formed cbor
+func IsDatumHash(datum string, witness Datums) bool {
+	if len(datum) != 64 {
+		return false
+	}
+	data, err := hex.DecodeString(datum)
+	if err != nil {
+		return false
+	}
+	if _, ok := witness[datum]; ok {
+		return true
+	}
+	return cbor.Valid(data) != nil
+}
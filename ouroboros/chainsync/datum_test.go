@@ -0,0 +1,76 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestTxOut_Datum(t *testing.T) {
+	var (
+		hash   = "ff" + strings.Repeat("00", 31)   // not well formed cbor
+		inline = "581e" + strings.Repeat("00", 30) // 32 byte inline datum
+	)
+
+	t.Run("inline", func(t *testing.T) {
+		var out TxOut
+		err := json.Unmarshal([]byte(`{"datum":"`+inline+`"}`), &out)
+		assert.Nil(t, err)
+
+		datum, ok := out.InlineDatum()
+		assert.True(t, ok)
+		assert.Len(t, datum, 32)
+		_, ok = out.DatumRef()
+		assert.False(t, ok)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		var out TxOut
+		err := json.Unmarshal([]byte(`{"datumHash":"`+hash+`"}`), &out)
+		assert.Nil(t, err)
+
+		_, ok := out.InlineDatum()
+		assert.False(t, ok)
+		ref, ok := out.DatumRef()
+		assert.True(t, ok)
+		assert.Equal(t, hash, ref)
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		var out TxOut
+		err := json.Unmarshal([]byte(`{"datum":"`+hash+`"}`), &out)
+		assert.Nil(t, err)
+		assert.Equal(t, "", out.Datum)
+		assert.Equal(t, hash, out.DatumHash)
+	})
+
+	t.Run("witness", func(t *testing.T) {
+		out := TxOut{Datum: inline}.NormalizeDatum(Datums{inline: "d87980"})
+		assert.Equal(t, "", out.Datum)
+		assert.Equal(t, inline, out.DatumHash)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		var out TxOut
+		_, ok := out.InlineDatum()
+		assert.False(t, ok)
+		_, ok = out.DatumRef()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+)
+
+// BlockEventType distinguishes roll forward from roll backward events
+type BlockEventType int
+
+const (
+	// RollForwardEvent carries a new block
+	RollForwardEvent BlockEventType = iota
+	// RollBackwardEvent carries the point rolled back to
+	RollBackwardEvent
+)
+
+// String implements fmt.Stringer
+func (t BlockEventType) String() string {
+	switch t {
+	case RollForwardEvent:
+		return RollForwardString
+	case RollBackwardEvent:
+		return RollBackwardString
+	default:
+		return "unknown"
+	}
+}
+
+// BlockEvent is a typed nextBlock response
+type BlockEvent struct {
+	Type  BlockEventType
+	Block *Block       // Block is set for RollForwardEvent
+	Point Point        // Point of the block, or the point rolled back to
+	Tip   *PointStruct // Tip of the chain as reported by ogmios
+	Err   error        // Err is set on the final event if chain sync failed
+}
+
+// NewBlockEvent decodes a json encoded ResponsePraos; ok is false for
+// responses other than nextBlock
+func NewBlockEvent(data []byte) (event BlockEvent, ok bool, err error) {
+	return DecodeBlockEvent(data, json.Unmarshal)
+}
+
+// DecodeBlockEvent is NewBlockEvent, but decodes the response via unmarshal,
+// which must be compatible with json.Unmarshal
+func DecodeBlockEvent(
+	data []byte,
+	unmarshal func(data []byte, v any) error,
+) (event BlockEvent, ok bool, err error) {
+	if method, _ := jsonparser.GetString(data, "method"); method != NextBlockMethod {
+		return BlockEvent{}, false, nil
+	}
+
+	var response ResponsePraos
+	if err := unmarshal(data, &response); err != nil {
+		return BlockEvent{}, false, fmt.Errorf(
+			"failed to decode block event: %w",
+			err,
+		)
+	}
+	result, err := response.NextBlockResult()
+	if err != nil {
+		return BlockEvent{}, false, fmt.Errorf(
+			"failed to decode block event: %w",
+			err,
+		)
+	}
+	switch result.Direction {
+	case RollForwardString:
+		if result.Block == nil {
+			return BlockEvent{}, false, errors.New(
+				"failed to decode block event: missing block",
+			)
+		}
+		return BlockEvent{
+			Type:  RollForwardEvent,
+			Block: result.Block,
+			Point: result.Block.PointStruct().Point(),
+			Tip:   result.Tip,
+		}, true, nil
+
+	case RollBackwardString:
+		if result.Point == nil {
+			return BlockEvent{}, false, errors.New(
+				"failed to decode block event: missing point",
+			)
+		}
+		return BlockEvent{
+			Type:  RollBackwardEvent,
+			Point: *result.Point,
+			Tip:   result.Tip,
+		}, true, nil
+
+	default:
+		return BlockEvent{}, false, fmt.Errorf(
+			"failed to decode block event: unknown direction, %v",
+			result.Direction,
+		)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestNewBlockEvent(t *testing.T) {
+	_, ok, err := NewBlockEvent([]byte(`{"jsonrpc":"2.0","method":"findIntersection","result":{"intersection":"origin","tip":"origin"}}`))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	event, ok, err := NewBlockEvent([]byte(`{"jsonrpc":"2.0","method":"nextBlock","result":{"direction":"backward","point":{"slot":1,"id":"a"},"tip":{"slot":2,"id":"b"}}}`))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, RollBackwardEvent, event.Type)
+	assert.Equal(t, "backward", event.Type.String())
+	assert.EqualValues(t, 2, event.Tip.Slot)
+
+	_, _, err = NewBlockEvent([]byte(`{"jsonrpc":"2.0","method":"nextBlock","result":{"direction":"sideways"}}`))
+	assert.NotNil(t, err)
+}
+
+func TestResponsePraos_ResultAccessors(t *testing.T) {
+	response := ResponsePraos{
+		Method: NextBlockMethod,
+		Result: &ResultNextBlockPraos{Direction: RollForwardString},
+	}
+	result, err := response.NextBlockResult()
+	assert.Nil(t, err)
+	assert.Equal(t, RollForwardString, result.Direction)
+
+	_, err = response.FindIntersectResult()
+	if !errors.Is(err, ErrUnexpectedMethod) {
+		t.Fatalf("got %v; want %v", err, ErrUnexpectedMethod)
+	}
+
+	response.Result = "bogus"
+	_, err = response.NextBlockResult()
+	if !errors.Is(err, ErrIncompatibleResult) {
+		t.Fatalf("got %v; want %v", err, ErrIncompatibleResult)
+	}
+
+	response = ResponsePraos{
+		Method: FindIntersectionMethod,
+		Result: ResultFindIntersectionPraos{Intersection: &Origin},
+	}
+	intersection, err := response.FindIntersectResult()
+	assert.Nil(t, err)
+	assert.Equal(t, Origin, *intersection.Intersection)
+}
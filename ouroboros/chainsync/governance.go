@@ -0,0 +1,163 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// Governance action types as reported by ogmios
+const (
+	GovernanceActionProtocolParametersUpdate = "protocolParametersUpdate"
+	GovernanceActionHardForkInitiation       = "hardForkInitiation"
+	GovernanceActionTreasuryWithdrawals      = "treasuryWithdrawals"
+	GovernanceActionConstitutionalCommittee  = "constitutionalCommittee"
+	GovernanceActionConstitution             = "constitution"
+	GovernanceActionNoConfidence             = "noConfidence"
+	GovernanceActionInformation              = "information"
+)
+
+// Voter roles as reported by ogmios
+const (
+	VoterConstitutionalCommittee = "constitutionalCommittee"
+	VoterDelegateRepresentative  = "delegateRepresentative"
+	VoterStakePoolOperator       = "stakePoolOperator"
+)
+
+// Votes as reported by ogmios
+const (
+	VoteYes     = "yes"
+	VoteNo      = "no"
+	VoteAbstain = "abstain"
+)
+
+// GovernanceProposalReference identifies a proposal by the transaction that
+// submitted it and its index within that transaction
+type GovernanceProposalReference struct {
+	Transaction struct {
+		ID string `json:"id" dynamodbav:"id"`
+	} `json:"transaction" dynamodbav:"transaction"`
+	Index int `json:"index" dynamodbav:"index"`
+}
+
+// String returns the reference as id#index
+func (r GovernanceProposalReference) String() string {
+	return fmt.Sprintf("%v#%v", r.Transaction.ID, r.Index)
+}
+
+// ScriptHash references a script, such as the guardrails script, by hash
+type ScriptHash struct {
+	Hash string `json:"hash" dynamodbav:"hash"`
+}
+
+// CommitteeMemberTerm is a committee member added with the epoch its term ends
+type CommitteeMemberTerm struct {
+	ID      string `json:"id"   dynamodbav:"id"`
+	From    string `json:"from" dynamodbav:"from"`
+	Mandate struct {
+		Epoch uint64 `json:"epoch" dynamodbav:"epoch"`
+	} `json:"mandate" dynamodbav:"mandate"`
+}
+
+// CommitteeMembers are the members added and removed by a committee update
+type CommitteeMembers struct {
+	Added   []CommitteeMemberTerm `json:"added,omitempty"   dynamodbav:"added,omitempty"`
+	Removed []CommitteeMember     `json:"removed,omitempty" dynamodbav:"removed,omitempty"`
+}
+
+// GovernanceAction is the action a proposal would enact.  Type determines
+// which of the remaining fields are set:
+//
+//   - protocolParametersUpdate: Ancestor, Parameters, Guardrails
+//   - hardForkInitiation: Ancestor, Version
+//   - treasuryWithdrawals: Withdrawals, Guardrails
+//   - constitutionalCommittee: Ancestor, Members, Quorum
+//   - constitution: Ancestor, Metadata, Guardrails
+//   - noConfidence: Ancestor
+//   - information: none
+type GovernanceAction struct {
+	Type        string                       `json:"type"                  dynamodbav:"type"`
+	Ancestor    *GovernanceProposalReference `json:"ancestor,omitempty"    dynamodbav:"ancestor,omitempty"`
+	Parameters  json.RawMessage              `json:"parameters,omitempty"  dynamodbav:"parameters,omitempty"`
+	Guardrails  *ScriptHash                  `json:"guardrails,omitempty"  dynamodbav:"guardrails,omitempty"`
+	Version     *ProtocolVersion             `json:"version,omitempty"     dynamodbav:"version,omitempty"`
+	Withdrawals Withdrawals                  `json:"withdrawals,omitempty" dynamodbav:"withdrawals,omitempty"`
+	Members     *CommitteeMembers            `json:"members,omitempty"     dynamodbav:"members,omitempty"`
+	Quorum      string                       `json:"quorum,omitempty"      dynamodbav:"quorum,omitempty"`
+	Metadata    *Anchor                      `json:"metadata,omitempty"    dynamodbav:"metadata,omitempty"`
+}
+
+// GovernanceProposal is a proposal submitted by a transaction
+type GovernanceProposal struct {
+	Deposit       shared.Value     `json:"deposit"            dynamodbav:"deposit"`
+	ReturnAccount string           `json:"returnAccount"      dynamodbav:"returnAccount"`
+	Metadata      *Anchor          `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+	Action        GovernanceAction `json:"action"             dynamodbav:"action"`
+}
+
+// GovernanceVoter identifies the issuer of a vote; Role is one of the Voter
+// constants
+type GovernanceVoter struct {
+	Role string `json:"role"           dynamodbav:"role"`
+	ID   string `json:"id"             dynamodbav:"id"`
+	From string `json:"from,omitempty" dynamodbav:"from,omitempty"`
+}
+
+// GovernanceVote is a vote cast on a proposal; Vote is one of the Vote
+// constants
+type GovernanceVote struct {
+	Issuer   GovernanceVoter             `json:"issuer"             dynamodbav:"issuer"`
+	Proposal GovernanceProposalReference `json:"proposal"           dynamodbav:"proposal"`
+	Vote     string                      `json:"vote"               dynamodbav:"vote"`
+	Metadata *Anchor                     `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+}
+
+// ProposalReference returns the reference to the i-th proposal of the
+// transaction, suitable for comparison with GovernanceVote.Proposal
+func (t Tx) ProposalReference(i int) GovernanceProposalReference {
+	var r GovernanceProposalReference
+	r.Transaction.ID = t.ID
+	r.Index = i
+	return r
+}
+
+// DecodeProposals decodes the governance proposals of the transaction, if any
+func (t Tx) DecodeProposals() ([]GovernanceProposal, error) {
+	if len(t.Proposals) == 0 || string(t.Proposals) == "null" {
+		return nil, nil
+	}
+
+	var proposals []GovernanceProposal
+	if err := json.Unmarshal(t.Proposals, &proposals); err != nil {
+		return nil, fmt.Errorf("failed to decode proposals for tx %v: %w", t.ID, err)
+	}
+	return proposals, nil
+}
+
+// DecodeVotes decodes the governance votes of the transaction, if any
+func (t Tx) DecodeVotes() ([]GovernanceVote, error) {
+	if len(t.Votes) == 0 || string(t.Votes) == "null" {
+		return nil, nil
+	}
+
+	var votes []GovernanceVote
+	if err := json.Unmarshal(t.Votes, &votes); err != nil {
+		return nil, fmt.Errorf("failed to decode votes for tx %v: %w", t.ID, err)
+	}
+	return votes, nil
+}
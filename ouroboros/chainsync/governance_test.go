@@ -0,0 +1,86 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestTx_DecodeProposals(t *testing.T) {
+	tx := Tx{
+		ID: "tx",
+		Proposals: json.RawMessage(`[
+			{"deposit":{"ada":{"lovelace":100000000000}},"returnAccount":"stake1","metadata":{"url":"https://example.com","hash":"abc"},"action":{"type":"information"}},
+			{"deposit":{"ada":{"lovelace":1}},"returnAccount":"stake1","action":{"type":"hardForkInitiation","ancestor":{"transaction":{"id":"prev"},"index":2},"version":{"major":10,"minor":0}}},
+			{"deposit":{"ada":{"lovelace":1}},"returnAccount":"stake1","action":{"type":"treasuryWithdrawals","withdrawals":{"stake2":{"ada":{"lovelace":42}}},"guardrails":{"hash":"script"}}},
+			{"deposit":{"ada":{"lovelace":1}},"returnAccount":"stake1","action":{"type":"protocolParametersUpdate","parameters":{"minFeeConstant":{"ada":{"lovelace":155381}}}}}
+		]`),
+	}
+
+	proposals, err := tx.DecodeProposals()
+	assert.Nil(t, err)
+	assert.Len(t, proposals, 4)
+
+	assert.Equal(t, GovernanceActionInformation, proposals[0].Action.Type)
+	assert.Equal(t, "https://example.com", proposals[0].Metadata.URL)
+	assert.EqualValues(t, 100000000000, proposals[0].Deposit.AdaLovelace().Int64())
+
+	fork := proposals[1].Action
+	assert.Equal(t, GovernanceActionHardForkInitiation, fork.Type)
+	assert.Equal(t, "prev#2", fork.Ancestor.String())
+	assert.EqualValues(t, 10, fork.Version.Major)
+
+	withdrawals := proposals[2].Action
+	assert.Equal(t, GovernanceActionTreasuryWithdrawals, withdrawals.Type)
+	assert.EqualValues(t, 42, withdrawals.Withdrawals["stake2"].AdaLovelace().Int64())
+	assert.Equal(t, "script", withdrawals.Guardrails.Hash)
+
+	update := proposals[3].Action
+	assert.Equal(t, GovernanceActionProtocolParametersUpdate, update.Type)
+	assert.Contains(t, string(update.Parameters), "minFeeConstant")
+
+	assert.Equal(t, "tx#1", tx.ProposalReference(1).String())
+}
+
+func TestTx_DecodeVotes(t *testing.T) {
+	tx := Tx{
+		ID: "tx",
+		Votes: json.RawMessage(`[
+			{"issuer":{"role":"delegateRepresentative","id":"drep","from":"verificationKey"},"proposal":{"transaction":{"id":"prev"},"index":0},"vote":"yes"},
+			{"issuer":{"role":"stakePoolOperator","id":"pool1"},"proposal":{"transaction":{"id":"prev"},"index":0},"vote":"abstain","metadata":{"url":"https://example.com","hash":"abc"}}
+		]`),
+	}
+
+	votes, err := tx.DecodeVotes()
+	assert.Nil(t, err)
+	assert.Len(t, votes, 2)
+
+	assert.Equal(t, VoterDelegateRepresentative, votes[0].Issuer.Role)
+	assert.Equal(t, VoteYes, votes[0].Vote)
+	assert.Equal(t, "prev#0", votes[0].Proposal.String())
+	assert.Equal(t, VoterStakePoolOperator, votes[1].Issuer.Role)
+	assert.Equal(t, VoteAbstain, votes[1].Vote)
+	assert.Equal(t, "abc", votes[1].Metadata.Hash)
+
+	votes, err = Tx{}.DecodeVotes()
+	assert.Nil(t, err)
+	assert.Len(t, votes, 0)
+
+	_, err = Tx{Votes: json.RawMessage(`{}`)}.DecodeVotes()
+	assert.NotNil(t, err)
+}
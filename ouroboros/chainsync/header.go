@@ -0,0 +1,144 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+)
+
+// NewHeaderEvent decodes a json encoded ResponsePraos like NewBlockEvent, but
+// decodes only the block header; Block.Transactions is always empty.  The
+// transactions are skipped without being parsed, which is considerably
+// cheaper for consumers only tracking chain growth
+func NewHeaderEvent(data []byte) (event BlockEvent, ok bool, err error) {
+	if method, _ := jsonparser.GetString(data, "method"); method != NextBlockMethod {
+		return BlockEvent{}, false, nil
+	}
+
+	var tip *PointStruct
+	if raw, _, _, err := jsonparser.Get(data, "result", "tip"); err == nil {
+		tip = &PointStruct{}
+		if err := json.Unmarshal(raw, tip); err != nil {
+			return BlockEvent{}, false, fmt.Errorf("failed to decode tip: %w", err)
+		}
+	}
+
+	direction, _ := jsonparser.GetString(data, "result", "direction")
+	switch direction {
+	case RollForwardString:
+		raw, _, _, err := jsonparser.Get(data, "result", "block")
+		if err != nil {
+			return BlockEvent{}, false, errors.New(
+				"failed to decode header event: missing block",
+			)
+		}
+		block, err := decodeHeader(raw)
+		if err != nil {
+			return BlockEvent{}, false, fmt.Errorf(
+				"failed to decode header event: %w",
+				err,
+			)
+		}
+		return BlockEvent{
+			Type:  RollForwardEvent,
+			Block: block,
+			Point: block.PointStruct().Point(),
+			Tip:   tip,
+		}, true, nil
+
+	case RollBackwardString:
+		raw, dataType, _, err := jsonparser.Get(data, "result", "point")
+		if err != nil {
+			return BlockEvent{}, false, errors.New(
+				"failed to decode header event: missing point",
+			)
+		}
+		var point Point
+		if dataType == jsonparser.String {
+			point = PointString(raw).Point()
+		} else if err := json.Unmarshal(raw, &point); err != nil {
+			return BlockEvent{}, false, fmt.Errorf(
+				"failed to decode header event: %w",
+				err,
+			)
+		}
+		return BlockEvent{
+			Type:  RollBackwardEvent,
+			Point: point,
+			Tip:   tip,
+		}, true, nil
+
+	default:
+		return BlockEvent{}, false, fmt.Errorf(
+			"failed to decode header event: unknown direction, %v",
+			direction,
+		)
+	}
+}
+
+// decodeHeader decodes every field of a json encoded Block other than the
+// transactions
+func decodeHeader(data []byte) (*Block, error) {
+	var block Block
+	err := jsonparser.ObjectEach(data, func(
+		key, value []byte,
+		dataType jsonparser.ValueType,
+		_ int,
+	) (err error) {
+		switch string(key) {
+		case "type":
+			block.Type, err = jsonparser.ParseString(value)
+		case "era":
+			block.Era, err = jsonparser.ParseString(value)
+		case "id":
+			block.ID, err = jsonparser.ParseString(value)
+		case "ancestor":
+			block.Ancestor, err = jsonparser.ParseString(value)
+		case "cbor":
+			block.CBOR, err = jsonparser.ParseString(value)
+		case "height":
+			var v int64
+			v, err = jsonparser.ParseInt(value)
+			block.Height = uint64(v)
+		case "slot":
+			var v int64
+			v, err = jsonparser.ParseInt(value)
+			block.Slot = uint64(v)
+		case "nonce":
+			if dataType != jsonparser.Null {
+				block.Nonce = &Nonce{}
+				err = json.Unmarshal(value, block.Nonce)
+			}
+		case "size":
+			err = json.Unmarshal(value, &block.Size)
+		case "protocol":
+			err = json.Unmarshal(value, &block.Protocol)
+		case "issuer":
+			err = json.Unmarshal(value, &block.Issuer)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode block %v: %w", string(key), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
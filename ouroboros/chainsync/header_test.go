@@ -0,0 +1,110 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestNewHeaderEvent(t *testing.T) {
+	block := &Block{
+		Type:     "praos",
+		Era:      "babbage",
+		ID:       "abc",
+		Ancestor: "def",
+		Height:   10,
+		Slot:     100,
+		Size:     BlockSize{Bytes: 1024},
+		Nonce:    &Nonce{Output: "out", Proof: "proof"},
+		Issuer:   BlockIssuer{VerificationKey: "key"},
+		CBOR:     "820102",
+		Transactions: []Tx{
+			{ID: "tx1"},
+			{ID: "tx2"},
+		},
+	}
+	data, err := json.Marshal(ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  NextBlockMethod,
+		Result: ResultNextBlockPraos{
+			Direction: RollForwardString,
+			Tip:       &PointStruct{Slot: 200, ID: "tip"},
+			Block:     block,
+		},
+	})
+	assert.Nil(t, err)
+
+	want, ok, err := NewBlockEvent(data)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	got, ok, err := NewHeaderEvent(data)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Len(t, got.Block.Transactions, 0)
+	assert.Equal(t, "820102", got.Block.CBOR)
+	assert.Equal(t, want.Point, got.Point)
+	assert.Equal(t, want.Tip, got.Tip)
+
+	want.Block.Transactions = nil
+	wantJSON, _ := json.Marshal(want.Block)
+	gotJSON, _ := json.Marshal(got.Block)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+
+	point := PointStruct{Slot: 5, ID: "xyz"}.Point()
+	data, err = json.Marshal(ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  NextBlockMethod,
+		Result: ResultNextBlockPraos{
+			Direction: RollBackwardString,
+			Point:     &point,
+		},
+	})
+	assert.Nil(t, err)
+	got, ok, err = NewHeaderEvent(data)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, RollBackwardEvent, got.Type)
+	assert.Equal(t, point, got.Point)
+	assert.Nil(t, got.Tip)
+
+	got, ok, err = NewHeaderEvent([]byte(`{"method":"nextBlock","result":{"direction":"backward","point":"origin"}}`))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Origin, got.Point)
+
+	_, ok, err = NewHeaderEvent([]byte(`{"method":"findIntersection"}`))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, err = NewHeaderEvent([]byte(`{"method":"nextBlock","result":{"direction":"forward","block":{"slot":"x"}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestBlock_RawCBOR(t *testing.T) {
+	_, err := (&Block{}).RawCBOR()
+	assert.True(t, errors.Is(err, ErrNoBlockCBOR))
+
+	data, err := (&Block{CBOR: "820102"}).RawCBOR()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x82, 0x01, 0x02}, data)
+
+	_, err = (&Block{CBOR: "zz"}).RawCBOR()
+	assert.NotNil(t, err)
+}
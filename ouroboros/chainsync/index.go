@@ -0,0 +1,110 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import "github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+
+// BlockIndex holds secondary views of a block's transactions so repeated
+// lookups, e.g. one per watch rule, do not rescan the block.  The index is
+// kept apart from the Block rather than cached on it, so Blocks remain plain
+// values that copy and compare by their fields alone.  The index refers to
+// the block's transactions, which must not be modified once indexed.
+type BlockIndex struct {
+	byAddress map[string][]*Tx
+	byPolicy  map[string][]*Tx
+}
+
+// NewBlockIndex indexes the transactions of block by address and policy
+func NewBlockIndex(block *Block) *BlockIndex {
+	index := &BlockIndex{
+		byAddress: map[string][]*Tx{},
+		byPolicy:  map[string][]*Tx{},
+	}
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+
+		addresses := map[string]struct{}{}
+		for _, output := range tx.Outputs {
+			addresses[output.Address] = struct{}{}
+		}
+		for address := range addresses {
+			index.byAddress[address] = append(index.byAddress[address], tx)
+		}
+		for policyID := range txPolicies(tx) {
+			index.byPolicy[policyID] = append(index.byPolicy[policyID], tx)
+		}
+	}
+	return index
+}
+
+// TransactionsByAddress returns the transactions with an output paying to
+// address, in block order
+func (i *BlockIndex) TransactionsByAddress(address string) []*Tx {
+	return i.byAddress[address]
+}
+
+// TransactionsByPolicy returns the transactions that mint, burn, or output
+// assets of the policy, in block order
+func (i *BlockIndex) TransactionsByPolicy(policyID string) []*Tx {
+	return i.byPolicy[policyID]
+}
+
+// TransactionsByAddress returns the transactions with an output paying to
+// address, in block order.  The block is scanned on each call; use
+// NewBlockIndex for repeated lookups.
+func (b *Block) TransactionsByAddress(address string) []*Tx {
+	var txs []*Tx
+	for i := range b.Transactions {
+		tx := &b.Transactions[i]
+		for _, output := range tx.Outputs {
+			if output.Address == address {
+				txs = append(txs, tx)
+				break
+			}
+		}
+	}
+	return txs
+}
+
+// TransactionsByPolicy returns the transactions that mint, burn, or output
+// assets of the policy, in block order.  The block is scanned on each call;
+// use NewBlockIndex for repeated lookups.
+func (b *Block) TransactionsByPolicy(policyID string) []*Tx {
+	var txs []*Tx
+	for i := range b.Transactions {
+		tx := &b.Transactions[i]
+		if _, ok := txPolicies(tx)[policyID]; ok {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// txPolicies returns the non-ada policies minted, burned, or output by tx
+func txPolicies(tx *Tx) map[string]struct{} {
+	policies := map[string]struct{}{}
+	collect := func(value shared.Value) {
+		for policyID := range value {
+			if policyID != shared.AdaPolicy {
+				policies[policyID] = struct{}{}
+			}
+		}
+	}
+	for _, output := range tx.Outputs {
+		collect(output.Value)
+	}
+	collect(tx.Mint.Value())
+	return policies
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/tj/assert"
+)
+
+func TestBlock_Indexes(t *testing.T) {
+	const policy = "a646474b8f5431261506b6c273d307c7569a4eb6c96b42dd4a29520a"
+	value := func(policyID string) shared.Value {
+		return shared.Value{
+			shared.AdaPolicy: {shared.AdaAsset: num.Int64(1)},
+			policyID:         {"": num.Int64(1)},
+		}
+	}
+
+	newBlock := func() *Block {
+		return &Block{
+			Transactions: []Tx{
+				{
+					ID: "a",
+					Outputs: TxOuts{
+						{Address: "addr1", Value: value(policy)},
+						{Address: "addr1", Value: value(policy)},
+					},
+				},
+				{
+					ID:      "b",
+					Outputs: TxOuts{{Address: "addr2", Value: value("other")}},
+					Mint:    shared.MintValue{policy: {"": num.Int64(-1)}},
+				},
+				{
+					ID:      "c",
+					Outputs: TxOuts{{Address: "addr2"}},
+				},
+			},
+		}
+	}
+	block := newBlock()
+
+	ids := func(txs []*Tx) []string {
+		var ids []string
+		for _, tx := range txs {
+			ids = append(ids, tx.ID)
+		}
+		return ids
+	}
+
+	views := map[string]interface {
+		TransactionsByAddress(address string) []*Tx
+		TransactionsByPolicy(policyID string) []*Tx
+	}{
+		"block": block,
+		"index": NewBlockIndex(block),
+	}
+	for name, view := range views {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, []string{"a"}, ids(view.TransactionsByAddress("addr1")))
+			assert.Equal(t, []string{"b", "c"}, ids(view.TransactionsByAddress("addr2")))
+			assert.Equal(t, []string{"a", "b"}, ids(view.TransactionsByPolicy(policy)))
+			assert.Equal(t, []string{"b"}, ids(view.TransactionsByPolicy("other")))
+			assert.Len(t, view.TransactionsByPolicy(shared.AdaPolicy), 0)
+			assert.Len(t, view.TransactionsByAddress("missing"), 0)
+
+			// refers to, rather than copies, the block's transactions
+			assert.True(t, view.TransactionsByAddress("addr1")[0] == &block.Transactions[0])
+		})
+	}
+
+	// lookups leave the block a plain value that compares by its fields
+	assert.Equal(t, newBlock(), block)
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+// LazyBlock holds a block whose header is decoded eagerly, but whose
+// transactions are decoded individually, on first access.  Filters that reject
+// most blocks on header fields, or on transaction ids, avoid decoding the
+// transaction bodies entirely.  LazyBlock is safe for concurrent use
+type LazyBlock struct {
+	// Header holds every field of the block other than the transactions
+	Header *Block
+
+	mutex sync.Mutex
+	raw   []json.RawMessage
+	txs   []*Tx
+}
+
+// NewLazyBlock decodes the block of a json encoded nextBlock response; ok is
+// false for responses other than roll forward.  The block is copied, so data
+// may be reused once NewLazyBlock returns
+func NewLazyBlock(data []byte) (block *LazyBlock, ok bool, err error) {
+	if method, _ := jsonparser.GetString(data, "method"); method != NextBlockMethod {
+		return nil, false, nil
+	}
+	if direction, _ := jsonparser.GetString(data, "result", "direction"); direction != RollForwardString {
+		return nil, false, nil
+	}
+	raw, _, _, err := jsonparser.Get(data, "result", "block")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode lazy block: missing block")
+	}
+	raw = append([]byte(nil), raw...)
+
+	header, err := decodeHeader(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode lazy block: %w", err)
+	}
+
+	var txs []json.RawMessage
+	_, err = jsonparser.ArrayEach(raw, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+		txs = append(txs, value)
+	}, "transactions")
+	if err != nil && err != jsonparser.KeyPathNotFoundError {
+		return nil, false, fmt.Errorf("failed to decode lazy block transactions: %w", err)
+	}
+
+	return &LazyBlock{
+		Header: header,
+		raw:    txs,
+		txs:    make([]*Tx, len(txs)),
+	}, true, nil
+}
+
+// Len returns the number of transactions in the block
+func (b *LazyBlock) Len() int {
+	return len(b.raw)
+}
+
+// TxID returns the id of the ith transaction without decoding it
+func (b *LazyBlock) TxID(i int) (string, error) {
+	if i < 0 || i >= len(b.raw) {
+		return "", fmt.Errorf("transaction index out of range, %v", i)
+	}
+	id, err := jsonparser.GetString(b.raw[i], "id")
+	if err != nil {
+		return "", fmt.Errorf("failed to read transaction id: %w", err)
+	}
+	return id, nil
+}
+
+// Tx returns the ith transaction, decoding it on first access
+func (b *LazyBlock) Tx(i int) (*Tx, error) {
+	if i < 0 || i >= len(b.raw) {
+		return nil, fmt.Errorf("transaction index out of range, %v", i)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if tx := b.txs[i]; tx != nil {
+		return tx, nil
+	}
+	var tx Tx
+	if err := json.Unmarshal(b.raw[i], &tx); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction %v: %w", i, err)
+	}
+	b.txs[i] = &tx
+	return &tx, nil
+}
+
+// Transactions returns an iterator over the transactions, decoding each as
+// the loop advances.  Iteration stops after the first decode error
+func (b *LazyBlock) Transactions() iter.Seq2[*Tx, error] {
+	return func(yield func(*Tx, error) bool) {
+		for i := range b.raw {
+			tx, err := b.Tx(i)
+			if !yield(tx, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Block decodes every transaction, returning the complete Block
+func (b *LazyBlock) Block() (*Block, error) {
+	block := *b.Header
+	block.Transactions = make([]Tx, 0, len(b.raw))
+	for tx, err := range b.Transactions() {
+		if err != nil {
+			return nil, err
+		}
+		block.Transactions = append(block.Transactions, *tx)
+	}
+	return &block, nil
+}
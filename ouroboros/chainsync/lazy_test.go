@@ -0,0 +1,106 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestNewLazyBlock(t *testing.T) {
+	block := &Block{
+		Type:   "praos",
+		Era:    "babbage",
+		ID:     "abc",
+		Height: 10,
+		Slot:   100,
+		Transactions: []Tx{
+			{ID: "tx1", ScriptIntegrityHash: "h1"},
+			{ID: "tx2", ScriptIntegrityHash: "h2"},
+		},
+	}
+	data, err := json.Marshal(ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  NextBlockMethod,
+		Result: ResultNextBlockPraos{
+			Direction: RollForwardString,
+			Block:     block,
+		},
+	})
+	assert.Nil(t, err)
+
+	lazy, ok, err := NewLazyBlock(data)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// mutating data must not affect the lazy block
+	for i := range data {
+		data[i] = ' '
+	}
+
+	assert.Equal(t, "abc", lazy.Header.ID)
+	assert.Equal(t, uint64(100), lazy.Header.Slot)
+	assert.Len(t, lazy.Header.Transactions, 0)
+	assert.Equal(t, 2, lazy.Len())
+
+	id, err := lazy.TxID(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "tx2", id)
+	assert.Nil(t, lazy.txs[1])
+
+	tx, err := lazy.Tx(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "tx2", tx.ID)
+	assert.Equal(t, "h2", tx.ScriptIntegrityHash)
+	assert.Nil(t, lazy.txs[0])
+
+	again, err := lazy.Tx(1)
+	assert.Nil(t, err)
+	assert.True(t, tx == again)
+
+	_, err = lazy.Tx(2)
+	assert.NotNil(t, err)
+
+	var ids []string
+	for tx, err := range lazy.Transactions() {
+		assert.Nil(t, err)
+		ids = append(ids, tx.ID)
+	}
+	assert.Equal(t, []string{"tx1", "tx2"}, ids)
+
+	full, err := lazy.Block()
+	assert.Nil(t, err)
+	assert.Len(t, full.Transactions, 2)
+	assert.Equal(t, "tx1", full.Transactions[0].ID)
+}
+
+func TestNewLazyBlock_NotRollForward(t *testing.T) {
+	point := PointStruct{Slot: 5, ID: "xyz"}.Point()
+	data, err := json.Marshal(ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  NextBlockMethod,
+		Result: ResultNextBlockPraos{
+			Direction: RollBackwardString,
+			Point:     &point,
+		},
+	})
+	assert.Nil(t, err)
+
+	_, ok, err := NewLazyBlock(data)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
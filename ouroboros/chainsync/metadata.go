@@ -0,0 +1,405 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Well known metadata labels
+const (
+	MetadataLabelCIP20 = 674 // transaction messages
+	MetadataLabelCIP25 = 721 // nft metadata
+)
+
+// TxMetadata is the auxiliary data of a transaction.  The json is retained as
+// received in Raw; metadata of an unrecognized shape, such as that converted
+// from ogmios v5, may have Raw but no Labels.
+type TxMetadata struct {
+	Hash   string                // Hash of the auxiliary data
+	Labels map[int]MetadataLabel // Labels holds the metadatum of each label
+	Raw    json.RawMessage       // Raw json as received
+}
+
+// MetadataLabel is the metadatum attached under a single label
+type MetadataLabel struct {
+	CBOR      string           // CBOR hex encoded, if provided by ogmios
+	JSON      json.RawMessage  // JSON as received, if provided by ogmios
+	Metadatum *OgmiosMetadatum // Metadatum is nil if JSON could not be parsed
+}
+
+type txMetadataJSON struct {
+	Hash   string                       `json:"hash"`
+	Labels map[string]metadataLabelJSON `json:"labels,omitempty"`
+}
+
+type metadataLabelJSON struct {
+	CBOR string          `json:"cbor,omitempty"`
+	JSON json.RawMessage `json:"json,omitempty"`
+}
+
+// UnmarshalJSON parses the metadata leniently; only malformed json is an error
+func (m *TxMetadata) UnmarshalJSON(data []byte) error {
+	if !json.Valid(data) {
+		return errors.New("failed to unmarshal TxMetadata: invalid json")
+	}
+
+	metadata := TxMetadata{
+		Raw: append(json.RawMessage(nil), data...),
+	}
+	var v txMetadataJSON
+	if err := json.Unmarshal(data, &v); err == nil {
+		metadata.Hash = v.Hash
+		for key, label := range v.Labels {
+			n, err := strconv.Atoi(key)
+			if err != nil {
+				continue
+			}
+			if metadata.Labels == nil {
+				metadata.Labels = map[int]MetadataLabel{}
+			}
+			metadata.Labels[n] = MetadataLabel{
+				CBOR:      label.CBOR,
+				JSON:      label.JSON,
+				Metadatum: parseMetadatum(label.JSON),
+			}
+		}
+	}
+	*m = metadata
+	return nil
+}
+
+// MarshalJSON returns Raw, if present, otherwise the ogmios v6 encoding
+func (m TxMetadata) MarshalJSON() ([]byte, error) {
+	if m.Raw != nil {
+		return m.Raw, nil
+	}
+
+	v := txMetadataJSON{Hash: m.Hash}
+	for n, label := range m.Labels {
+		if v.Labels == nil {
+			v.Labels = map[string]metadataLabelJSON{}
+		}
+		v.Labels[strconv.Itoa(n)] = metadataLabelJSON{
+			CBOR: label.CBOR,
+			JSON: label.JSON,
+		}
+	}
+	return json.Marshal(v)
+}
+
+// MarshalDynamoDBAttributeValue stores the json as binary, as it was when
+// Tx.Metadata was a json.RawMessage
+func (m TxMetadata) MarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	item.B = data
+	return nil
+}
+
+func (m *TxMetadata) UnmarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	if item == nil || len(item.B) == 0 {
+		return nil
+	}
+	return m.UnmarshalJSON(item.B)
+}
+
+// RawJSON returns Raw, or nil if m is nil, for use with GetMetadataDatumMapV6
+// and friends
+func (m *TxMetadata) RawJSON() json.RawMessage {
+	if m == nil {
+		return nil
+	}
+	return m.Raw
+}
+
+// Label returns the metadatum of the label, if present and parsed
+func (m *TxMetadata) Label(n int) (*OgmiosMetadatum, bool) {
+	if m == nil {
+		return nil, false
+	}
+	label, ok := m.Labels[n]
+	if !ok || label.Metadatum == nil {
+		return nil, false
+	}
+	return label.Metadatum, true
+}
+
+// CIP20 returns the message lines of a CIP-20 transaction message
+func (m *TxMetadata) CIP20() ([]string, bool) {
+	metadatum, ok := m.Label(MetadataLabelCIP20)
+	if !ok {
+		return nil, false
+	}
+	msg, ok := metadatum.Lookup("msg")
+	if !ok {
+		return nil, false
+	}
+
+	var lines []string
+	switch msg.Tag {
+	case OgmiosMetadatumTagString:
+		lines = append(lines, msg.StringField)
+	case OgmiosMetadatumTagList:
+		for _, item := range msg.ListField {
+			if item == nil || item.Tag != OgmiosMetadatumTagString {
+				return nil, false
+			}
+			lines = append(lines, item.StringField)
+		}
+	default:
+		return nil, false
+	}
+	return lines, true
+}
+
+// CIP25File is a file referenced by CIP-25 nft metadata
+type CIP25File struct {
+	Name      string `json:"name,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+	Src       string `json:"src,omitempty"`
+}
+
+// CIP25Asset is the CIP-25 metadata of a single asset.  Properties holds every
+// field, including those decoded into the struct.
+type CIP25Asset struct {
+	PolicyID    string // PolicyID hex encoded
+	AssetName   string // AssetName hex encoded
+	Name        string
+	Image       string
+	MediaType   string
+	Description string
+	Files       []CIP25File
+	Properties  map[string]*OgmiosMetadatum
+}
+
+// CIP25 returns the CIP-25 metadata of each asset, ordered by policy and
+// asset name.  Both version 1, with text keys, and version 2, with byte keys,
+// are supported.
+func (m *TxMetadata) CIP25() ([]CIP25Asset, bool) {
+	metadatum, ok := m.Label(MetadataLabelCIP25)
+	if !ok || metadatum.Tag != OgmiosMetadatumTagMap {
+		return nil, false
+	}
+
+	var version2 bool
+	if v, ok := metadatum.Lookup("version"); ok {
+		switch v.Tag {
+		case OgmiosMetadatumTagInt:
+			version2 = v.IntField.Cmp(big.NewInt(2)) == 0
+		case OgmiosMetadatumTagString:
+			version2 = strings.HasPrefix(v.StringField, "2")
+		}
+	}
+
+	var assets []CIP25Asset
+	for _, policy := range metadatum.MapField {
+		policyID, ok := cip25Key(policy.Key, true)
+		if !ok || policy.Value == nil || policy.Value.Tag != OgmiosMetadatumTagMap {
+			continue // e.g. version
+		}
+		for _, asset := range policy.Value.MapField {
+			assetName, ok := cip25Key(asset.Key, version2)
+			if !ok || asset.Value == nil || asset.Value.Tag != OgmiosMetadatumTagMap {
+				continue
+			}
+			assets = append(assets, newCIP25Asset(policyID, assetName, asset.Value))
+		}
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		if assets[i].PolicyID != assets[j].PolicyID {
+			return assets[i].PolicyID < assets[j].PolicyID
+		}
+		return assets[i].AssetName < assets[j].AssetName
+	})
+	return assets, len(assets) > 0
+}
+
+// cip25Key returns the hex encoding of a policy id or asset name key.  Text
+// keys are taken to be hex if isHex, otherwise their utf-8 bytes are encoded
+func cip25Key(key *OgmiosMetadatum, isHex bool) (string, bool) {
+	if key == nil {
+		return "", false
+	}
+	switch key.Tag {
+	case OgmiosMetadatumTagBytes:
+		return hex.EncodeToString(key.BytesField), true
+	case OgmiosMetadatumTagString:
+		if isHex {
+			if _, err := hex.DecodeString(key.StringField); err != nil {
+				return "", false
+			}
+			return strings.ToLower(key.StringField), true
+		}
+		return hex.EncodeToString([]byte(key.StringField)), true
+	default:
+		return "", false
+	}
+}
+
+func newCIP25Asset(policyID, assetName string, m *OgmiosMetadatum) CIP25Asset {
+	asset := CIP25Asset{
+		PolicyID:   policyID,
+		AssetName:  assetName,
+		Properties: map[string]*OgmiosMetadatum{},
+	}
+	for _, item := range m.MapField {
+		if item.Key == nil || item.Key.Tag != OgmiosMetadatumTagString {
+			continue
+		}
+		asset.Properties[item.Key.StringField] = item.Value
+	}
+
+	asset.Name, _ = m.Text("name")
+	asset.Image, _ = m.Text("image")
+	asset.MediaType, _ = m.Text("mediaType")
+	asset.Description, _ = m.Text("description")
+	if files, ok := m.Lookup("files"); ok && files.Tag == OgmiosMetadatumTagList {
+		for _, file := range files.ListField {
+			if file == nil || file.Tag != OgmiosMetadatumTagMap {
+				continue
+			}
+			var f CIP25File
+			f.Name, _ = file.Text("name")
+			f.MediaType, _ = file.Text("mediaType")
+			f.Src, _ = file.Text("src")
+			asset.Files = append(asset.Files, f)
+		}
+	}
+	return asset
+}
+
+// Lookup returns the value of the map entry with the given text key
+func (o *OgmiosMetadatum) Lookup(key string) (*OgmiosMetadatum, bool) {
+	if o == nil || o.Tag != OgmiosMetadatumTagMap {
+		return nil, false
+	}
+	for _, item := range o.MapField {
+		if item.Key != nil &&
+			item.Key.Tag == OgmiosMetadatumTagString &&
+			item.Key.StringField == key &&
+			item.Value != nil {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Text returns the text value of the map entry with the given key.  Lists of
+// text, used to work around the 64 byte limit on metadata strings, are joined
+func (o *OgmiosMetadatum) Text(key string) (string, bool) {
+	v, ok := o.Lookup(key)
+	if !ok {
+		return "", false
+	}
+	switch v.Tag {
+	case OgmiosMetadatumTagString:
+		return v.StringField, true
+	case OgmiosMetadatumTagList:
+		var sb strings.Builder
+		for _, item := range v.ListField {
+			if item == nil || item.Tag != OgmiosMetadatumTagString {
+				return "", false
+			}
+			sb.WriteString(item.StringField)
+		}
+		return sb.String(), true
+	default:
+		return "", false
+	}
+}
+
+// parseMetadatum accepts both the detailed schema, ogmios
+// --metadata-detailed-schema, and plain json, returning nil if neither fits.
+// Plain json objects that happen to look like the detailed schema, e.g.
+// {"int": 1}, are read as the detailed schema.
+func parseMetadatum(data json.RawMessage) *OgmiosMetadatum {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var metadatum OgmiosMetadatum
+	if err := json.Unmarshal(data, &metadatum); err == nil {
+		return &metadatum
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil
+	}
+	return metadatumFromPlain(v)
+}
+
+func metadatumFromPlain(v any) *OgmiosMetadatum {
+	switch v := v.(type) {
+	case json.Number:
+		i, ok := new(big.Int).SetString(v.String(), 10)
+		if !ok {
+			return nil // metadata integers only
+		}
+		return &OgmiosMetadatum{Tag: OgmiosMetadatumTagInt, IntField: i}
+	case string:
+		return &OgmiosMetadatum{Tag: OgmiosMetadatumTagString, StringField: v}
+	case []any:
+		list := make([]*OgmiosMetadatum, 0, len(v))
+		for _, item := range v {
+			metadatum := metadatumFromPlain(item)
+			if metadatum == nil {
+				return nil
+			}
+			list = append(list, metadatum)
+		}
+		return &OgmiosMetadatum{Tag: OgmiosMetadatumTagList, ListField: list}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]*OgmiosMetadatumMap, 0, len(v))
+		for _, key := range keys {
+			value := metadatumFromPlain(v[key])
+			if value == nil {
+				return nil
+			}
+			items = append(items, &OgmiosMetadatumMap{
+				Key:   &OgmiosMetadatum{Tag: OgmiosMetadatumTagString, StringField: key},
+				Value: value,
+			})
+		}
+		return &OgmiosMetadatum{Tag: OgmiosMetadatumTagMap, MapField: items}
+	default:
+		return nil // bool or null have no metadata equivalent
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/tj/assert"
+)
+
+func TestTxMetadata_CIP25(t *testing.T) {
+	policyID := "d5e6bf0500378d4f0da4e8dde6becec7621cd8cbf5cbb9b87013d4cc"
+
+	t.Run("v1 plain", func(t *testing.T) {
+		data := `{"hash":"00","labels":{"721":{"json":{"` + policyID + `":{"Sundae 1":{"name":"Sundae #1","image":["ipfs://Qm","abc"],"mediaType":"image/png","files":[{"name":"hi-res","mediaType":"image/png","src":"ipfs://Qmdef"}],"rarity":"rare"}},"version":"1.0"}}}}`
+
+		var metadata TxMetadata
+		assert.Nil(t, json.Unmarshal([]byte(data), &metadata))
+
+		assets, ok := metadata.CIP25()
+		assert.True(t, ok)
+		assert.Len(t, assets, 1)
+
+		asset := assets[0]
+		assert.Equal(t, policyID, asset.PolicyID)
+		assert.Equal(t, hex.EncodeToString([]byte("Sundae 1")), asset.AssetName)
+		assert.Equal(t, "Sundae #1", asset.Name)
+		assert.Equal(t, "ipfs://Qmabc", asset.Image)
+		assert.Equal(t, "image/png", asset.MediaType)
+		assert.Equal(t, []CIP25File{{Name: "hi-res", MediaType: "image/png", Src: "ipfs://Qmdef"}}, asset.Files)
+		assert.Equal(t, "rare", asset.Properties["rarity"].StringField)
+	})
+
+	t.Run("v2 detailed", func(t *testing.T) {
+		data := `{"hash":"00","labels":{"721":{"json":{"map":[
+			{"k":{"bytes":"` + policyID + `"},"v":{"map":[
+				{"k":{"bytes":"0102"},"v":{"map":[{"k":{"string":"name"},"v":{"string":"Two"}}]}},
+				{"k":{"bytes":"01"},"v":{"map":[{"k":{"string":"name"},"v":{"string":"One"}}]}}
+			]}},
+			{"k":{"string":"version"},"v":{"int":2}}
+		]}}}}`
+
+		var metadata TxMetadata
+		assert.Nil(t, json.Unmarshal([]byte(data), &metadata))
+
+		assets, ok := metadata.CIP25()
+		assert.True(t, ok)
+		assert.Len(t, assets, 2)
+		assert.Equal(t, "01", assets[0].AssetName)
+		assert.Equal(t, "One", assets[0].Name)
+		assert.Equal(t, "0102", assets[1].AssetName)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		var metadata *TxMetadata
+		_, ok := metadata.CIP25()
+		assert.False(t, ok)
+	})
+}
+
+func TestTxMetadata_CIP20(t *testing.T) {
+	for name, data := range map[string]string{
+		"plain":    `{"hash":"00","labels":{"674":{"json":{"msg":["hello","world"]}}}}`,
+		"detailed": `{"hash":"00","labels":{"674":{"json":{"map":[{"k":{"string":"msg"},"v":{"list":[{"string":"hello"},{"string":"world"}]}}]}}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var metadata TxMetadata
+			assert.Nil(t, json.Unmarshal([]byte(data), &metadata))
+
+			lines, ok := metadata.CIP20()
+			assert.True(t, ok)
+			assert.Equal(t, []string{"hello", "world"}, lines)
+		})
+	}
+}
+
+func TestTxMetadata_Raw(t *testing.T) {
+	data := `{"hash":"00","labels":{"1":{"cbor":"f5","json":true},"2":{"json":{"int":42}}}}`
+
+	var tx Tx
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":"tx","metadata":`+data+`}`), &tx))
+	assert.Equal(t, data, string(tx.Metadata.RawJSON()))
+	assert.Equal(t, "00", tx.Metadata.Hash)
+
+	unknown := tx.Metadata.Labels[1]
+	assert.Equal(t, "f5", unknown.CBOR)
+	assert.Equal(t, "true", string(unknown.JSON))
+	assert.Nil(t, unknown.Metadatum)
+
+	metadatum, ok := tx.Metadata.Label(2)
+	assert.True(t, ok)
+	assert.EqualValues(t, 42, metadatum.IntField.Int64())
+
+	encoded, err := json.Marshal(tx)
+	assert.Nil(t, err)
+	assert.Contains(t, string(encoded), `"metadata":`+data)
+
+	item, err := dynamodbattribute.Marshal(tx)
+	assert.Nil(t, err)
+	var got Tx
+	assert.Nil(t, dynamodbattribute.Unmarshal(item, &got))
+	assert.Equal(t, data, string(got.Metadata.RawJSON()))
+
+	// shapes other than ogmios v6 are retained but not decoded
+	var other TxMetadata
+	assert.Nil(t, json.Unmarshal([]byte(`{"hash":"00","body":{"blob":{}}}`), &other))
+	assert.Nil(t, other.Labels)
+	assert.NotNil(t, other.Raw)
+
+	// synthesized when constructed rather than received
+	encoded, err = json.Marshal(TxMetadata{
+		Hash:   "00",
+		Labels: map[int]MetadataLabel{7: {JSON: json.RawMessage(`{"int":1}`)}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"hash":"00","labels":{"7":{"json":{"int":1}}}}`, string(encoded))
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestNetwork_UnmarshalJSON(t *testing.T) {
+	var tx Tx
+	assert.Nil(t, json.Unmarshal([]byte(`{"network":"testnet"}`), &tx))
+	assert.Equal(t, NetworkTestnet, tx.Network)
+	assert.True(t, tx.Network.Valid())
+
+	tx = Tx{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":"tx"}`), &tx))
+	assert.Equal(t, Network(""), tx.Network)
+	assert.False(t, tx.Network.Valid())
+
+	// unknown networks are retained rather than failing the decode
+	assert.Nil(t, json.Unmarshal([]byte(`{"network":"bogus"}`), &tx))
+	assert.Equal(t, Network("bogus"), tx.Network)
+	assert.False(t, tx.Network.Valid())
+
+	data, err := json.Marshal(Tx{Network: NetworkMainnet})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"network":"mainnet"`)
+}
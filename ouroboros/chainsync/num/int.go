@@ -61,18 +61,51 @@ func (i Int) BigFloat() *big.Float {
 	return big.NewFloat(0).SetInt(i.BigInt())
 }
 
+// Int returns the value as an int; the result is undefined if it does not
+// fit, see SafeInt
 func (i Int) Int() int {
 	return int(i.BigInt().Int64())
 }
 
+// Int64 returns the value as an int64; the result is undefined if it does not
+// fit, see SafeInt64
 func (i Int) Int64() int64 {
 	return i.BigInt().Int64()
 }
 
+// Uint64 returns the value as a uint64; the result is undefined if it does
+// not fit, see SafeUint64
 func (i Int) Uint64() uint64 {
 	return i.BigInt().Uint64()
 }
 
+// SafeInt returns the value as an int and false if it does not fit
+func (i Int) SafeInt() (int, bool) {
+	v, ok := i.SafeInt64()
+	if !ok || int64(int(v)) != v {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// SafeInt64 returns the value as an int64 and false if it does not fit
+func (i Int) SafeInt64() (int64, bool) {
+	bi := i.BigInt()
+	if !bi.IsInt64() {
+		return 0, false
+	}
+	return bi.Int64(), true
+}
+
+// SafeUint64 returns the value as a uint64 and false if it does not fit
+func (i Int) SafeUint64() (uint64, bool) {
+	bi := i.BigInt()
+	if !bi.IsUint64() {
+		return 0, false
+	}
+	return bi.Uint64(), true
+}
+
 func (i Int) MarshalDynamoDBAttributeValue(
 	item *dynamodb.AttributeValue,
 ) error {
@@ -99,21 +132,49 @@ func (i Int) Mul(that Int) Int {
 	return Int(*product)
 }
 
+// Div returns the euclidean quotient i/that; Div panics if that is zero
 func (i Int) Div(that Int) Int {
 	quotient := big.NewInt(0).Div(i.BigInt(), that.BigInt())
 	return Int(*quotient)
 }
 
+// Neg returns -i
+func (i Int) Neg() Int {
+	negated := big.NewInt(0).Neg(i.BigInt())
+	return Int(*negated)
+}
+
+// Abs returns |i|
+func (i Int) Abs() Int {
+	abs := big.NewInt(0).Abs(i.BigInt())
+	return Int(*abs)
+}
+
+// Cmp returns -1, 0, or +1 as i is less than, equal to, or greater than that
+func (i Int) Cmp(that Int) int {
+	return i.BigInt().Cmp(that.BigInt())
+}
+
+// Sign returns -1, 0, or +1 as i is negative, zero, or positive
+func (i Int) Sign() int {
+	return i.BigInt().Sign()
+}
+
+// IsZero returns true if i is zero
+func (i Int) IsZero() bool {
+	return i.Sign() == 0
+}
+
 func (i Int) LessThan(that Int) bool {
-	return i.BigInt().Cmp(that.BigInt()) == -1
+	return i.Cmp(that) < 0
 }
 
 func (i Int) GreaterThan(that Int) bool {
-	return i.BigInt().Cmp(that.BigInt()) == 1
+	return i.Cmp(that) > 0
 }
 
 func (i Int) Equal(that Int) bool {
-	return i.BigInt().Cmp(that.BigInt()) == 0
+	return i.Cmp(that) == 0
 }
 
 func (i *Int) UnmarshalDynamoDBAttributeValue(
@@ -65,3 +65,56 @@ func TestNew(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestArithmetic(t *testing.T) {
+	huge, _ := New("1180591620717411303424") // 2^70
+	a, b := Int64(-7), Int64(2)
+
+	if got, want := a.Mul(b).String(), "-14"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Div(b).String(), "-4"; got != want {
+		t.Fatalf("got %v; want %v", got, want) // euclidean
+	}
+	if got, want := a.Neg().String(), "7"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Abs().String(), "7"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := huge.Mul(huge).Div(huge).String(), huge.String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := huge.Cmp(Uint64(1<<63)), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := huge.Neg().Sign(), -1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !(Int{}).IsZero() || huge.IsZero() {
+		t.Fatalf("got %v; want true", (Int{}).IsZero())
+	}
+}
+
+func TestSafeConversions(t *testing.T) {
+	huge, _ := New("1180591620717411303424") // 2^70
+
+	if _, ok := huge.SafeInt64(); ok {
+		t.Fatalf("got %v; want false", ok)
+	}
+	if _, ok := huge.SafeUint64(); ok {
+		t.Fatalf("got %v; want false", ok)
+	}
+	if _, ok := huge.SafeInt(); ok {
+		t.Fatalf("got %v; want false", ok)
+	}
+	if _, ok := Int64(-1).SafeUint64(); ok {
+		t.Fatalf("got %v; want false", ok)
+	}
+	if got, ok := Uint64(1 << 63).SafeUint64(); !ok || got != 1<<63 {
+		t.Fatalf("got %v; want %v", got, uint64(1<<63))
+	}
+	if got, ok := Int64(-42).SafeInt(); !ok || got != -42 {
+		t.Fatalf("got %v; want %v", got, -42)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Redeemer purposes as reported by ogmios
+const (
+	RedeemerPurposeSpend    = "spend"
+	RedeemerPurposeMint     = "mint"
+	RedeemerPurposePublish  = "publish"
+	RedeemerPurposeWithdraw = "withdraw"
+	RedeemerPurposeVote     = "vote"
+	RedeemerPurposePropose  = "propose"
+)
+
+// ExUnits are the execution units consumed by a script
+type ExUnits struct {
+	Memory uint64 `json:"memory" dynamodbav:"memory"`
+	CPU    uint64 `json:"cpu"    dynamodbav:"cpu"`
+}
+
+// Add returns the sum of the execution units
+func (e ExUnits) Add(other ExUnits) ExUnits {
+	return ExUnits{
+		Memory: e.Memory + other.Memory,
+		CPU:    e.CPU + other.CPU,
+	}
+}
+
+// RedeemerValidator identifies the script a redeemer is supplied to by
+// purpose and index e.g. the index of the spent input in sorted order
+type RedeemerValidator struct {
+	Purpose string `json:"purpose" dynamodbav:"purpose"`
+	Index   int    `json:"index"   dynamodbav:"index"`
+}
+
+// Redeemer is a decoded transaction redeemer
+type Redeemer struct {
+	Validator      RedeemerValidator `json:"validator"      dynamodbav:"validator"`
+	Redeemer       string            `json:"redeemer"       dynamodbav:"redeemer"`
+	ExecutionUnits ExUnits           `json:"executionUnits" dynamodbav:"executionUnits"`
+}
+
+// DecodeRedeemers decodes the redeemers of the transaction, if any
+func (t Tx) DecodeRedeemers() ([]Redeemer, error) {
+	if len(t.Redeemers) == 0 || string(t.Redeemers) == "null" {
+		return nil, nil
+	}
+
+	var redeemers []Redeemer
+	if err := json.Unmarshal(t.Redeemers, &redeemers); err != nil {
+		return nil, fmt.Errorf("failed to decode redeemers for tx %v: %w", t.ID, err)
+	}
+	return redeemers, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+const (
+	// rewardAccountSize is the length of a header byte plus a 28 byte hash
+	rewardAccountSize = 29
+	// rewardAccountScript is set in the header of accounts locked by a script
+	rewardAccountScript = 0x10
+	// rewardAccountHeader is the header of a reward account on network 0
+	rewardAccountHeader = 0xe0
+)
+
+// StakeCredential is the key or script hash that controls a reward account
+type StakeCredential struct {
+	Hash   string // Hash is the hex encoded 28 byte key or script hash
+	Script bool   // Script is true if Hash is a script hash
+}
+
+// RewardAccount is a bech32 encoded reward account, i.e. a stake address such
+// as stake1...
+type RewardAccount string
+
+// Withdrawals are the rewards withdrawn keyed by reward account
+type Withdrawals map[RewardAccount]shared.Value
+
+// ParseRewardAccount returns s as a RewardAccount if it is a well formed
+// stake address
+func ParseRewardAccount(s string) (RewardAccount, error) {
+	account := RewardAccount(s)
+	if _, err := account.Bytes(); err != nil {
+		return "", err
+	}
+	return account, nil
+}
+
+// NewRewardAccount encodes the reward account for credential on network, 1
+// for mainnet and 0 for the test networks
+func NewRewardAccount(
+	network uint8,
+	credential StakeCredential,
+) (RewardAccount, error) {
+	hash, err := hex.DecodeString(credential.Hash)
+	if err != nil || len(hash) != rewardAccountSize-1 {
+		return "", fmt.Errorf(
+			"failed to encode reward account: invalid credential, %v",
+			credential.Hash,
+		)
+	}
+	if network > 0x0f {
+		return "", fmt.Errorf(
+			"failed to encode reward account: invalid network, %v",
+			network,
+		)
+	}
+
+	header := byte(rewardAccountHeader) | network
+	if credential.Script {
+		header |= rewardAccountScript
+	}
+	data, err := bech32.ConvertBits(append([]byte{header}, hash...), 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reward account: %w", err)
+	}
+
+	hrp := "stake_test"
+	if network == 1 {
+		hrp = "stake"
+	}
+	s, err := bech32.Encode(hrp, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reward account: %w", err)
+	}
+	return RewardAccount(s), nil
+}
+
+// Bytes returns the raw header and credential hash of the account
+func (r RewardAccount) Bytes() ([]byte, error) {
+	_, data, err := bech32.Decode(string(r))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode reward account, %v: %w", r, err)
+	}
+	account, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode reward account, %v: %w", r, err)
+	}
+	if len(account) != rewardAccountSize ||
+		account[0]&rewardAccountHeader != rewardAccountHeader {
+		return nil, fmt.Errorf(
+			"failed to decode reward account, %v: invalid header",
+			r,
+		)
+	}
+	return account, nil
+}
+
+// Network returns the network id of the account; 1 for mainnet
+func (r RewardAccount) Network() (uint8, error) {
+	account, err := r.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	return account[0] & 0x0f, nil
+}
+
+// Credential returns the stake credential controlling the account
+func (r RewardAccount) Credential() (StakeCredential, error) {
+	account, err := r.Bytes()
+	if err != nil {
+		return StakeCredential{}, err
+	}
+	return StakeCredential{
+		Hash:   hex.EncodeToString(account[1:]),
+		Script: account[0]&rewardAccountScript != 0,
+	}, nil
+}
+
+// String implements fmt.Stringer
+func (r RewardAccount) String() string {
+	return string(r)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestRewardAccount(t *testing.T) {
+	credential := StakeCredential{Hash: strings.Repeat("ab", 28)}
+
+	account, err := NewRewardAccount(1, credential)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(account.String(), "stake1"))
+
+	parsed, err := ParseRewardAccount(account.String())
+	assert.Nil(t, err)
+	assert.Equal(t, account, parsed)
+
+	network, err := parsed.Network()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, network)
+
+	got, err := parsed.Credential()
+	assert.Nil(t, err)
+	assert.Equal(t, credential, got)
+
+	credential.Script = true
+	account, err = NewRewardAccount(0, credential)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(account.String(), "stake_test1"))
+
+	got, err = account.Credential()
+	assert.Nil(t, err)
+	assert.Equal(t, credential, got)
+}
+
+func TestRewardAccount_Invalid(t *testing.T) {
+	_, err := NewRewardAccount(1, StakeCredential{Hash: "abcd"})
+	assert.NotNil(t, err)
+
+	_, err = NewRewardAccount(16, StakeCredential{Hash: strings.Repeat("ab", 28)})
+	assert.NotNil(t, err)
+
+	_, err = ParseRewardAccount("bogus")
+	assert.NotNil(t, err)
+
+	// a payment address is not a reward account
+	_, err = ParseRewardAccount(
+		"addr_test1vz09v9yfxguvlp0zsnrpa3tdtm7el8xufp3m5lsm7qxzclgmzkket",
+	)
+	assert.NotNil(t, err)
+}
+
+func TestTx_Withdrawals(t *testing.T) {
+	account, err := NewRewardAccount(1, StakeCredential{
+		Hash: strings.Repeat("01", 28),
+	})
+	assert.Nil(t, err)
+
+	data := `{"id":"tx","withdrawals":{"` + account.String() +
+		`":{"ada":{"lovelace":42}}}}`
+	var tx Tx
+	assert.Nil(t, json.Unmarshal([]byte(data), &tx))
+
+	value, ok := tx.Withdrawals[account]
+	assert.True(t, ok)
+	assert.EqualValues(t, 42, value.AdaLovelace().Int64())
+}
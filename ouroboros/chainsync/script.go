@@ -0,0 +1,238 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Script languages as reported by ogmios
+const (
+	ScriptLanguageNative   = "native"
+	ScriptLanguagePlutusV1 = "plutus:v1"
+	ScriptLanguagePlutusV2 = "plutus:v2"
+	ScriptLanguagePlutusV3 = "plutus:v3"
+)
+
+// Native script clauses as reported by ogmios
+const (
+	NativeScriptSignature = "signature"
+	NativeScriptAll       = "all"
+	NativeScriptAny       = "any"
+	NativeScriptSome      = "some"
+	NativeScriptBefore    = "before"
+	NativeScriptAfter     = "after"
+)
+
+// NativeScript is a timelock or multisig script.  Clause determines which of
+// the remaining fields are set:
+//
+//   - signature: KeyHash
+//   - all, any: Scripts
+//   - some: AtLeast, Scripts
+//   - before, after: Slot
+type NativeScript struct {
+	Clause  string
+	KeyHash string
+	AtLeast uint64
+	Slot    uint64
+	Scripts []NativeScript
+}
+
+type nativeScriptJSON struct {
+	Clause  string          `json:"clause"`
+	From    json.RawMessage `json:"from,omitempty"`
+	AtLeast uint64          `json:"atLeast,omitempty"`
+	Slot    uint64          `json:"slot,omitempty"`
+}
+
+func (n *NativeScript) UnmarshalJSON(data []byte) error {
+	var v nativeScriptJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	script := NativeScript{Clause: v.Clause}
+	switch v.Clause {
+	case NativeScriptSignature:
+		if err := json.Unmarshal(v.From, &script.KeyHash); err != nil {
+			return fmt.Errorf("failed to decode %v clause: %w", v.Clause, err)
+		}
+	case NativeScriptAll, NativeScriptAny, NativeScriptSome:
+		if err := json.Unmarshal(v.From, &script.Scripts); err != nil {
+			return fmt.Errorf("failed to decode %v clause: %w", v.Clause, err)
+		}
+		script.AtLeast = v.AtLeast
+	case NativeScriptBefore, NativeScriptAfter:
+		script.Slot = v.Slot
+	default:
+		return fmt.Errorf("failed to decode native script: unknown clause, %v", v.Clause)
+	}
+	*n = script
+	return nil
+}
+
+func (n NativeScript) MarshalJSON() ([]byte, error) {
+	v := nativeScriptJSON{Clause: n.Clause}
+	switch n.Clause {
+	case NativeScriptSignature:
+		from, err := json.Marshal(n.KeyHash)
+		if err != nil {
+			return nil, err
+		}
+		v.From = from
+	case NativeScriptAll, NativeScriptAny, NativeScriptSome:
+		scripts := n.Scripts
+		if scripts == nil {
+			scripts = []NativeScript{}
+		}
+		from, err := json.Marshal(scripts)
+		if err != nil {
+			return nil, err
+		}
+		v.From = from
+		v.AtLeast = n.AtLeast
+	case NativeScriptBefore, NativeScriptAfter:
+		v.Slot = n.Slot
+	}
+	return json.Marshal(v)
+}
+
+// Script is a native or plutus script.  Native scripts are parsed into Native;
+// plutus scripts are only available as CBOR.  The json is retained as received
+// in Raw, compacted; scripts of an unrecognized shape, such as those converted from
+// ogmios v5, may have Raw but no Language.
+type Script struct {
+	Language string          // Language is one of the ScriptLanguage constants
+	CBOR     string          // CBOR hex encoded; always set for plutus
+	Native   *NativeScript   // Native is set for native scripts
+	Raw      json.RawMessage // Raw json as received
+}
+
+type scriptJSON struct {
+	Language string        `json:"language"`
+	JSON     *NativeScript `json:"json,omitempty"`
+	CBOR     string        `json:"cbor,omitempty"`
+}
+
+// IsNative returns true for native scripts
+func (s Script) IsNative() bool {
+	return s.Language == ScriptLanguageNative
+}
+
+// IsPlutus returns true for plutus scripts of any version
+func (s Script) IsPlutus() bool {
+	switch s.Language {
+	case ScriptLanguagePlutusV1, ScriptLanguagePlutusV2, ScriptLanguagePlutusV3:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalJSON parses the script leniently; only malformed json is an error.
+// Raw is retained in compact form.
+func (s *Script) UnmarshalJSON(data []byte) error {
+	// compact, as json.Marshal does, so Raw is stable across round trips
+	var raw bytes.Buffer
+	if err := json.Compact(&raw, data); err != nil {
+		return fmt.Errorf("failed to unmarshal Script: %w", err)
+	}
+
+	script := Script{
+		Raw: raw.Bytes(),
+	}
+	var v scriptJSON
+	if err := json.Unmarshal(data, &v); err == nil {
+		script.Language = v.Language
+		script.CBOR = v.CBOR
+		script.Native = v.JSON
+	}
+	*s = script
+	return nil
+}
+
+// MarshalJSON returns Raw, if present, otherwise the ogmios v6 encoding
+func (s Script) MarshalJSON() ([]byte, error) {
+	if s.Raw != nil {
+		return s.Raw, nil
+	}
+	return json.Marshal(scriptJSON{
+		Language: s.Language,
+		JSON:     s.Native,
+		CBOR:     s.CBOR,
+	})
+}
+
+// MarshalDynamoDBAttributeValue stores the json as binary, as it was when
+// TxOut.Script was a json.RawMessage
+func (s Script) MarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	item.B = data
+	return nil
+}
+
+func (s *Script) UnmarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	if item == nil || len(item.B) == 0 {
+		return nil
+	}
+	return s.UnmarshalJSON(item.B)
+}
+
+// Scripts holds the scripts of a transaction witness set by script hash
+type Scripts map[string]Script
+
+// MarshalDynamoDBAttributeValue stores the json as binary, as it was when
+// Tx.Scripts was a json.RawMessage
+func (s Scripts) MarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	if s == nil {
+		item.NULL = aws.Bool(true)
+		return nil
+	}
+	data, err := json.Marshal(map[string]Script(s))
+	if err != nil {
+		return err
+	}
+	item.B = data
+	return nil
+}
+
+func (s *Scripts) UnmarshalDynamoDBAttributeValue(
+	item *dynamodb.AttributeValue,
+) error {
+	if item == nil || len(item.B) == 0 {
+		return nil
+	}
+	var scripts map[string]Script
+	if err := json.Unmarshal(item.B, &scripts); err != nil {
+		return fmt.Errorf("failed to unmarshal Scripts: %w", err)
+	}
+	*s = scripts
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/tj/assert"
+)
+
+func TestScript(t *testing.T) {
+	t.Run("native", func(t *testing.T) {
+		data := `{"language":"native","json":{"clause":"some","from":[{"clause":"signature","from":"a"},{"clause":"all","from":[{"clause":"signature","from":"b"},{"clause":"before","slot":100}]},{"clause":"after","slot":5}],"atLeast":2},"cbor":"8201"}`
+
+		var script Script
+		assert.Nil(t, json.Unmarshal([]byte(data), &script))
+		assert.True(t, script.IsNative())
+		assert.False(t, script.IsPlutus())
+		assert.Equal(t, "8201", script.CBOR)
+
+		native := script.Native
+		assert.Equal(t, NativeScriptSome, native.Clause)
+		assert.EqualValues(t, 2, native.AtLeast)
+		assert.Len(t, native.Scripts, 3)
+		assert.Equal(t, "a", native.Scripts[0].KeyHash)
+		assert.Equal(t, NativeScriptBefore, native.Scripts[1].Scripts[1].Clause)
+		assert.EqualValues(t, 100, native.Scripts[1].Scripts[1].Slot)
+		assert.EqualValues(t, 5, native.Scripts[2].Slot)
+
+		script.Raw = nil
+		encoded, err := json.Marshal(script)
+		assert.Nil(t, err)
+		assert.Equal(t, data, string(encoded))
+	})
+
+	t.Run("plutus", func(t *testing.T) {
+		var tx Tx
+		err := json.Unmarshal([]byte(`{"id":"tx","scripts":{"hash":{"language":"plutus:v3","cbor":"4e4d01"}},"outputs":[{"address":"addr","script":{"language":"plutus:v2","cbor":"4d01"}}]}`), &tx)
+		assert.Nil(t, err)
+
+		script := tx.Scripts["hash"]
+		assert.True(t, script.IsPlutus())
+		assert.Equal(t, ScriptLanguagePlutusV3, script.Language)
+		assert.Equal(t, "4e4d01", script.CBOR)
+		assert.Nil(t, script.Native)
+		assert.Equal(t, ScriptLanguagePlutusV2, tx.Outputs[0].Script.Language)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		var script Script
+		assert.Nil(t, json.Unmarshal([]byte(`{ "plutus:v1": "4d01" }`), &script))
+		assert.Equal(t, "", script.Language)
+		assert.Equal(t, `{"plutus:v1":"4d01"}`, string(script.Raw))
+
+		encoded, err := json.Marshal(script)
+		assert.Nil(t, err)
+		assert.Equal(t, string(script.Raw), string(encoded))
+	})
+
+	t.Run("dynamodb", func(t *testing.T) {
+		tx := Tx{
+			ID:      "tx",
+			Scripts: Scripts{"hash": {Language: ScriptLanguagePlutusV1, CBOR: "4d01"}},
+			Outputs: TxOuts{{Address: "addr", Script: &Script{Language: ScriptLanguagePlutusV2, CBOR: "4d01"}}},
+		}
+		item, err := dynamodbattribute.Marshal(tx)
+		assert.Nil(t, err)
+		assert.NotNil(t, item.M["scripts"].B)
+
+		var got Tx
+		assert.Nil(t, dynamodbattribute.Unmarshal(item, &got))
+		assert.Equal(t, "4d01", got.Scripts["hash"].CBOR)
+		assert.Equal(t, ScriptLanguagePlutusV2, got.Outputs[0].Script.Language)
+
+		item, err = dynamodbattribute.Marshal(Tx{ID: "tx"})
+		assert.Nil(t, err)
+		_, ok := item.M["scripts"]
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Bootstrap returns true if the signature is a byron era bootstrap witness
+func (s Signature) Bootstrap() bool {
+	return s.ChainCode != "" || s.AddressAttributes != ""
+}
+
+// KeyHash returns the hex encoded blake2b-224 hash of the verification key,
+// the form used by RequiredExtraSignatories and native scripts
+func (s Signature) KeyHash() (string, error) {
+	key, err := hex.DecodeString(s.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode key, %v: %w", s.Key, err)
+	}
+	hash, err := blake2b.New(28, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create hash: %w", err)
+	}
+	hash.Write(key)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Bootstraps returns the bootstrap witnesses among the tx signatories
+func (t Tx) Bootstraps() []Signature {
+	var sigs []Signature
+	for _, sig := range t.Signatories {
+		if sig.Bootstrap() {
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs
+}
+
+// Signers returns the sorted, distinct key hashes that signed the tx
+func (t Tx) Signers() ([]string, error) {
+	seen := map[string]struct{}{}
+	var signers []string
+	for _, sig := range t.Signatories {
+		keyHash, err := sig.KeyHash()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signers of tx %v: %w", t.ID, err)
+		}
+		if _, ok := seen[keyHash]; ok {
+			continue
+		}
+		seen[keyHash] = struct{}{}
+		signers = append(signers, keyHash)
+	}
+	sort.Strings(signers)
+	return signers, nil
+}
+
+// Multisig returns true if the tx was signed by more than one distinct key
+func (t Tx) Multisig() bool {
+	seen := map[string]struct{}{}
+	for _, sig := range t.Signatories {
+		seen[sig.Key] = struct{}{}
+	}
+	return len(seen) > 1
+}
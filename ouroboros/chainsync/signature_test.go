@@ -0,0 +1,67 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+const (
+	bootstrapKey = "d88f6028cc3d6d335115de3737bc2fe80a9a57a21a2c7c228ebc33b222e0897b"
+	signatureKey = "400019217786c3630fb121c455065b879055aa0ced5076a24abe8d6c837e0318"
+)
+
+func TestSignature_KeyHash(t *testing.T) {
+	keyHash, err := Signature{Key: bootstrapKey}.KeyHash()
+	assert.Nil(t, err)
+	assert.Equal(t, "2b9c55d4bedbeca5d0c08a86a55097af4878a103d629ae11f0022039", keyHash)
+
+	_, err = Signature{Key: "zz"}.KeyHash()
+	assert.NotNil(t, err)
+}
+
+func TestTx_Signatories(t *testing.T) {
+	data := `{
+		"id": "tx",
+		"signatories": [
+			{"key": "` + bootstrapKey + `", "signature": "00", "chainCode": "1234", "addressAttributes": "a0"},
+			{"key": "` + signatureKey + `", "signature": "01"},
+			{"key": "` + signatureKey + `", "signature": "02"}
+		]
+	}`
+	var tx Tx
+	assert.Nil(t, json.Unmarshal([]byte(data), &tx))
+
+	bootstraps := tx.Bootstraps()
+	assert.Len(t, bootstraps, 1)
+	assert.Equal(t, bootstrapKey, bootstraps[0].Key)
+	assert.Equal(t, "1234", bootstraps[0].ChainCode)
+	assert.Equal(t, "a0", bootstraps[0].AddressAttributes)
+	assert.False(t, tx.Signatories[1].Bootstrap())
+
+	signers, err := tx.Signers()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"2b9c55d4bedbeca5d0c08a86a55097af4878a103d629ae11f0022039",
+		"9b9cb51f6a394c221b70750938f838dccccd3df9e41ca7ba2168ffcf",
+	}, signers)
+	assert.True(t, tx.Multisig())
+
+	tx.Signatories = tx.Signatories[1:]
+	assert.False(t, tx.Multisig())
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -47,6 +48,7 @@ type Block struct {
 	Transactions []Tx        `json:"transactions,omitempty"`
 	Protocol     Protocol    `json:"protocol,omitempty"`
 	Issuer       BlockIssuer `json:"issuer,omitempty"`
+	CBOR         string      `json:"cbor,omitempty"` // hex; only if ogmios includes it
 }
 
 type Nonce struct {
@@ -170,6 +172,76 @@ func (pp Points) Less(i, j int) bool {
 	}
 }
 
+// Dedup returns a copy of the points ordered most recent first with any
+// duplicates and invalid, i.e. zero value, points removed.  Points are
+// duplicates if they share the same slot and id; height is ignored.
+func (pp Points) Dedup() Points {
+	points := make(Points, 0, len(pp))
+	for _, p := range pp {
+		if p.pointType == PointTypeString || p.pointType == PointTypeStruct {
+			points = append(points, p)
+		}
+	}
+	sort.Stable(points)
+
+	type key struct {
+		pointType PointType
+		slot      uint64
+		id        string
+	}
+	var (
+		deduped Points
+		seen    = map[key]struct{}{}
+	)
+	for _, p := range points {
+		k := key{pointType: p.pointType, id: string(p.pointString)}
+		if p.pointType == PointTypeStruct {
+			k.slot, k.id = p.pointStruct.Slot, p.pointStruct.ID
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		deduped = append(deduped, p)
+	}
+	return deduped
+}
+
+// Merge returns the points of both pp and other, deduplicated and ordered most
+// recent first
+func (pp Points) Merge(other Points) Points {
+	points := make(Points, 0, len(pp)+len(other))
+	points = append(points, pp...)
+	points = append(points, other...)
+	return points.Dedup()
+}
+
+// Nearest returns the most recent point at or before slot.  Origin, if
+// present, is treated as preceding every slot.
+func (pp Points) Nearest(slot uint64) (Point, bool) {
+	var (
+		nearest Point
+		found   bool
+	)
+	for _, p := range pp {
+		switch p.pointType {
+		case PointTypeStruct:
+			if p.pointStruct.Slot > slot {
+				continue
+			}
+			if !found || nearest.pointType != PointTypeStruct ||
+				p.pointStruct.Slot > nearest.pointStruct.Slot {
+				nearest, found = p, true
+			}
+		case PointTypeString:
+			if !found && p.pointString == Origin.pointString {
+				nearest, found = p, true
+			}
+		}
+	}
+	return nearest, found
+}
+
 // pointCBOR provide simplified internal wrapper
 type pointCBOR struct {
 	String PointString  `cbor:"1,keyasint,omitempty"`
@@ -332,6 +404,19 @@ type RollForward struct {
 	Block     Block       `json:"block,omitempty"     dynamodbav:"block,omitempty"`
 }
 
+// RawCBOR returns the serialized block, suitable for CBOR native tooling, or
+// ErrNoBlockCBOR if ogmios did not include it
+func (b *Block) RawCBOR() ([]byte, error) {
+	if b.CBOR == "" {
+		return nil, ErrNoBlockCBOR
+	}
+	data, err := hex.DecodeString(b.CBOR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block cbor: %w", err)
+	}
+	return data, nil
+}
+
 func (b Block) PointStruct() PointStruct {
 	return PointStruct{
 		Height: &b.Height,
@@ -363,6 +448,26 @@ type ResultNextBlockPraos struct {
 	Point     *Point       `json:"point,omitempty"     dynamodbav:"point,omitempty"` // Backward
 }
 
+// ResultSubmitTransactionPraos is the result of a successful submitTransaction
+type ResultSubmitTransactionPraos struct {
+	Transaction SubmittedTx `json:"transaction" dynamodbav:"transaction"`
+}
+
+// SubmittedTx identifies a transaction accepted by the node
+type SubmittedTx struct {
+	ID string `json:"id" dynamodbav:"id"`
+}
+
+// ScriptEvaluation is the budget required by a single script
+type ScriptEvaluation struct {
+	Validator RedeemerValidator `json:"validator" dynamodbav:"validator"`
+	Budget    ExUnits           `json:"budget"    dynamodbav:"budget"`
+}
+
+// ResultEvaluateTransactionPraos is the result of a successful
+// evaluateTransaction
+type ResultEvaluateTransactionPraos []ScriptEvaluation
+
 type ResponsePraos struct {
 	JsonRpc string          `json:"jsonrpc,omitempty" dynamodbav:"jsonrpc,omitempty"`
 	Method  string          `json:"method,omitempty"  dynamodbav:"method,omitempty"`
@@ -376,8 +481,24 @@ const (
 	NextBlockMethod        = "nextBlock"
 	FindIntersectMethod    = "FindIntersect"
 	RequestNextMethod      = "RequestNext"
+
+	SubmitTransactionMethod   = "submitTransaction"
+	EvaluateTransactionMethod = "evaluateTransaction"
+	SubmitTxMethod            = "SubmitTx"
+	EvaluateTxMethod          = "EvaluateTx"
+
+	// QueryMethod is the v5 method for every state query; v6 queries are
+	// named e.g. queryLedgerState/epoch or queryNetwork/tip
+	QueryMethod = "Query"
 )
 
+// IsQueryMethod returns true if method is a v5 or v6 state query
+func IsQueryMethod(method string) bool {
+	return method == QueryMethod ||
+		strings.HasPrefix(method, "queryLedgerState/") ||
+		strings.HasPrefix(method, "queryNetwork/")
+}
+
 const (
 	RollForwardString  = "forward"
 	RollBackwardString = "backward"
@@ -403,10 +524,11 @@ func (r *ResponsePraos) UnmarshalJSON(b []byte) error {
 		if err := json.Unmarshal(m.Error, &resultError); err != nil {
 			return err
 		}
+		r.Method = m.Method
 		r.Error = &resultError
 	} else {
-		switch m.Method {
-		case FindIntersectionMethod, FindIntersectMethod:
+		switch {
+		case m.Method == FindIntersectionMethod, m.Method == FindIntersectMethod:
 			r.Method = FindIntersectionMethod
 			var findIntersection ResultFindIntersectionPraos
 			if err := json.Unmarshal(m.Result, &findIntersection); err != nil {
@@ -414,7 +536,7 @@ func (r *ResponsePraos) UnmarshalJSON(b []byte) error {
 			}
 			r.Result = findIntersection
 
-		case NextBlockMethod, RequestNextMethod:
+		case m.Method == NextBlockMethod, m.Method == RequestNextMethod:
 			r.Method = NextBlockMethod
 			var nextBlock ResultNextBlockPraos
 			if err := json.Unmarshal(m.Result, &nextBlock); err != nil {
@@ -422,54 +544,171 @@ func (r *ResponsePraos) UnmarshalJSON(b []byte) error {
 			}
 			r.Result = nextBlock
 
+		case m.Method == SubmitTransactionMethod:
+			r.Method = SubmitTransactionMethod
+			var submit ResultSubmitTransactionPraos
+			if err := json.Unmarshal(m.Result, &submit); err != nil {
+				return err
+			}
+			r.Result = submit
+
+		case m.Method == EvaluateTransactionMethod:
+			r.Method = EvaluateTransactionMethod
+			var evaluate ResultEvaluateTransactionPraos
+			if err := json.Unmarshal(m.Result, &evaluate); err != nil {
+				return err
+			}
+			r.Result = evaluate
+
+		case IsQueryMethod(m.Method):
+			// query results vary by query and are left to the caller to decode
+			r.Method = m.Method
+			r.Result = m.Result
+
 		default:
-			return fmt.Errorf("unknown method: '%v'", r.Method)
+			return fmt.Errorf("unknown method: '%v'", m.Method)
 		}
 	}
 
 	return nil
 }
 
-func (r ResponsePraos) MustFindIntersectResult() ResultFindIntersectionPraos {
+var (
+	// ErrUnexpectedMethod indicates a result accessor was used on a response
+	// for a different method
+	ErrUnexpectedMethod = errors.New("chainsync: unexpected method")
+	// ErrIncompatibleResult indicates the response result is not of the type
+	// expected for the method
+	ErrIncompatibleResult = errors.New("chainsync: incompatible result type")
+	// ErrNoBlockCBOR indicates a block was delivered without its cbor
+	ErrNoBlockCBOR = errors.New("chainsync: block cbor not included by ogmios")
+)
+
+// FindIntersectResult returns the result of a findIntersection response
+func (r ResponsePraos) FindIntersectResult() (ResultFindIntersectionPraos, error) {
 	if r.Method != FindIntersectionMethod {
-		panic(
-			fmt.Errorf(
-				"must only use *Must* methods after switching on the findIntersection method; called on %v",
-				r.Method,
-			),
+		return ResultFindIntersectionPraos{}, fmt.Errorf(
+			"%w: want %v, got %v",
+			ErrUnexpectedMethod,
+			FindIntersectionMethod,
+			r.Method,
 		)
 	}
-	t, ok := r.Result.(ResultFindIntersectionPraos)
-	if ok {
-		return t
-	}
-	u, ok := r.Result.(*ResultFindIntersectionPraos)
-	if ok && u != nil {
-		return *u
+	switch v := r.Result.(type) {
+	case ResultFindIntersectionPraos:
+		return v, nil
+	case *ResultFindIntersectionPraos:
+		if v != nil {
+			return *v, nil
+		}
 	}
-	panic(errors.New("must method used on incompatible type"))
+	return ResultFindIntersectionPraos{}, ErrIncompatibleResult
 }
 
-func (r ResponsePraos) MustNextBlockResult() ResultNextBlockPraos {
+// NextBlockResult returns the result of a nextBlock response
+func (r ResponsePraos) NextBlockResult() (ResultNextBlockPraos, error) {
 	if r.Method != NextBlockMethod {
-		panic(
-			fmt.Errorf(
-				"must only use *Must* methods after switching on the nextBlock method; called on %v",
-				r.Method,
-			),
+		return ResultNextBlockPraos{}, fmt.Errorf(
+			"%w: want %v, got %v",
+			ErrUnexpectedMethod,
+			NextBlockMethod,
+			r.Method,
+		)
+	}
+	switch v := r.Result.(type) {
+	case ResultNextBlockPraos:
+		return v, nil
+	case *ResultNextBlockPraos:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	return ResultNextBlockPraos{}, ErrIncompatibleResult
+}
+
+// SubmitTransactionResult returns the result of a submitTransaction response
+func (r ResponsePraos) SubmitTransactionResult() (ResultSubmitTransactionPraos, error) {
+	if r.Method != SubmitTransactionMethod {
+		return ResultSubmitTransactionPraos{}, fmt.Errorf(
+			"%w: want %v, got %v",
+			ErrUnexpectedMethod,
+			SubmitTransactionMethod,
+			r.Method,
+		)
+	}
+	switch v := r.Result.(type) {
+	case ResultSubmitTransactionPraos:
+		return v, nil
+	case *ResultSubmitTransactionPraos:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	return ResultSubmitTransactionPraos{}, ErrIncompatibleResult
+}
+
+// EvaluateTransactionResult returns the result of an evaluateTransaction
+// response
+func (r ResponsePraos) EvaluateTransactionResult() (ResultEvaluateTransactionPraos, error) {
+	if r.Method != EvaluateTransactionMethod {
+		return nil, fmt.Errorf(
+			"%w: want %v, got %v",
+			ErrUnexpectedMethod,
+			EvaluateTransactionMethod,
+			r.Method,
+		)
+	}
+	switch v := r.Result.(type) {
+	case ResultEvaluateTransactionPraos:
+		return v, nil
+	case *ResultEvaluateTransactionPraos:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	return nil, ErrIncompatibleResult
+}
+
+// QueryResult returns the undecoded result of a state query response.  v5
+// and v6 query results are returned as sent by Ogmios.
+func (r ResponsePraos) QueryResult() (json.RawMessage, error) {
+	if !IsQueryMethod(r.Method) {
+		return nil, fmt.Errorf(
+			"%w: want query, got %v",
+			ErrUnexpectedMethod,
+			r.Method,
 		)
 	}
-	t, ok := r.Result.(ResultNextBlockPraos)
-	if ok {
-		return t
+	if v, ok := r.Result.(json.RawMessage); ok {
+		return v, nil
+	}
+	return nil, ErrIncompatibleResult
+}
+
+// MustFindIntersectResult is FindIntersectResult, panicking on error.
+//
+// Deprecated: use FindIntersectResult, which does not panic on untrusted input
+func (r ResponsePraos) MustFindIntersectResult() ResultFindIntersectionPraos {
+	result, err := r.FindIntersectResult()
+	if err != nil {
+		panic(err)
 	}
-	u, ok := r.Result.(*ResultNextBlockPraos)
-	if ok && u != nil {
-		return *u
+	return result
+}
+
+// MustNextBlockResult is NextBlockResult, panicking on error.
+//
+// Deprecated: use NextBlockResult, which does not panic on untrusted input
+func (r ResponsePraos) MustNextBlockResult() ResultNextBlockPraos {
+	result, err := r.NextBlockResult()
+	if err != nil {
+		panic(err)
 	}
-	panic(errors.New("must method used on incompatible type"))
+	return result
 }
 
+// Signature is a verification key witness; bootstrap witnesses additionally
+// carry a ChainCode and AddressAttributes
 type Signature struct {
 	Key               string `json:"key"                         dynamodbav:"key"`
 	Signature         string `json:"signature"                   dynamodbav:"signature"`
@@ -478,31 +717,31 @@ type Signature struct {
 }
 
 type Tx struct {
-	ID                       string                  `json:"id,omitempty"                       dynamodbav:"id,omitempty"`
-	Spends                   string                  `json:"spends,omitempty"                   dynamodbav:"spends,omitempty"`
-	Inputs                   []TxIn                  `json:"inputs,omitempty"                   dynamodbav:"inputs,omitempty"`
-	References               []TxIn                  `json:"references,omitempty"               dynamodbav:"references,omitempty"`
-	Collaterals              []TxIn                  `json:"collaterals,omitempty"              dynamodbav:"collaterals,omitempty"`
-	TotalCollateral          *shared.Value           `json:"totalCollateral,omitempty"          dynamodbav:"totalCollateral,omitempty"`
-	CollateralReturn         *TxOut                  `json:"collateralReturn,omitempty"         dynamodbav:"collateralReturn,omitempty"`
-	Outputs                  TxOuts                  `json:"outputs,omitempty"                  dynamodbav:"outputs,omitempty"`
-	Certificates             []json.RawMessage       `json:"certificates,omitempty"             dynamodbav:"certificates,omitempty"`
-	Withdrawals              map[string]shared.Value `json:"withdrawals,omitempty"              dynamodbav:"withdrawals,omitempty"`
-	Fee                      shared.Value            `json:"fee,omitempty"                      dynamodbav:"fee,omitempty"`
-	ValidityInterval         ValidityInterval        `json:"validityInterval"                   dynamodbav:"validityInterval,omitempty"`
-	Mint                     shared.Value            `json:"mint,omitempty"                     dynamodbav:"mint,omitempty"`
-	Network                  json.RawMessage         `json:"network,omitempty"                  dynamodbav:"network,omitempty"`
-	ScriptIntegrityHash      string                  `json:"scriptIntegrityHash,omitempty"      dynamodbav:"scriptIntegrityHash,omitempty"`
-	RequiredExtraSignatories []string                `json:"requiredExtraSignatories,omitempty" dynamodbav:"requiredExtraSignatories,omitempty"`
-	RequiredExtraScripts     []string                `json:"requiredExtraScripts,omitempty"     dynamodbav:"requiredExtraScripts,omitempty"`
-	Proposals                json.RawMessage         `json:"proposals,omitempty"                dynamodbav:"proposals,omitempty"`
-	Votes                    json.RawMessage         `json:"votes,omitempty"                    dynamodbav:"votes,omitempty"`
-	Metadata                 json.RawMessage         `json:"metadata,omitempty"                 dynamodbav:"metadata,omitempty"`
-	Signatories              []Signature             `json:"signatories,omitempty"              dynamodbav:"signatories,omitempty"`
-	Scripts                  json.RawMessage         `json:"scripts,omitempty"                  dynamodbav:"scripts,omitempty"`
-	Datums                   Datums                  `json:"datums"                             dynamodbav:"datums,omitempty"`
-	Redeemers                json.RawMessage         `json:"redeemers,omitempty"                dynamodbav:"redeemers,omitempty"`
-	CBOR                     string                  `json:"cbor,omitempty"                     dynamodbav:"cbor,omitempty"`
+	ID                       string            `json:"id,omitempty"                       dynamodbav:"id,omitempty"`
+	Spends                   string            `json:"spends,omitempty"                   dynamodbav:"spends,omitempty"`
+	Inputs                   []TxIn            `json:"inputs,omitempty"                   dynamodbav:"inputs,omitempty"`
+	References               []TxIn            `json:"references,omitempty"               dynamodbav:"references,omitempty"`
+	Collaterals              []TxIn            `json:"collaterals,omitempty"              dynamodbav:"collaterals,omitempty"`
+	TotalCollateral          *shared.Value     `json:"totalCollateral,omitempty"          dynamodbav:"totalCollateral,omitempty"`
+	CollateralReturn         *TxOut            `json:"collateralReturn,omitempty"         dynamodbav:"collateralReturn,omitempty"`
+	Outputs                  TxOuts            `json:"outputs,omitempty"                  dynamodbav:"outputs,omitempty"`
+	Certificates             []json.RawMessage `json:"certificates,omitempty"             dynamodbav:"certificates,omitempty"`
+	Withdrawals              Withdrawals       `json:"withdrawals,omitempty"              dynamodbav:"withdrawals,omitempty"`
+	Fee                      shared.Value      `json:"fee,omitempty"                      dynamodbav:"fee,omitempty"`
+	ValidityInterval         ValidityInterval  `json:"validityInterval"                   dynamodbav:"validityInterval,omitempty"`
+	Mint                     shared.MintValue  `json:"mint,omitempty"                     dynamodbav:"mint,omitempty"`
+	Network                  Network           `json:"network,omitempty"                  dynamodbav:"network,omitempty"`
+	ScriptIntegrityHash      string            `json:"scriptIntegrityHash,omitempty"      dynamodbav:"scriptIntegrityHash,omitempty"`
+	RequiredExtraSignatories []string          `json:"requiredExtraSignatories,omitempty" dynamodbav:"requiredExtraSignatories,omitempty"`
+	RequiredExtraScripts     []string          `json:"requiredExtraScripts,omitempty"     dynamodbav:"requiredExtraScripts,omitempty"`
+	Proposals                json.RawMessage   `json:"proposals,omitempty"                dynamodbav:"proposals,omitempty"`
+	Votes                    json.RawMessage   `json:"votes,omitempty"                    dynamodbav:"votes,omitempty"`
+	Metadata                 *TxMetadata       `json:"metadata,omitempty"                 dynamodbav:"metadata,omitempty"`
+	Signatories              []Signature       `json:"signatories,omitempty"              dynamodbav:"signatories,omitempty"`
+	Scripts                  Scripts           `json:"scripts,omitempty"                  dynamodbav:"scripts,omitempty"`
+	Datums                   Datums            `json:"datums"                             dynamodbav:"datums,omitempty"`
+	Redeemers                json.RawMessage   `json:"redeemers,omitempty"                dynamodbav:"redeemers,omitempty"`
+	CBOR                     string            `json:"cbor,omitempty"                     dynamodbav:"cbor,omitempty"`
 }
 
 type TxID string
@@ -550,12 +789,29 @@ func (t TxIn) TxID() TxID {
 	return NewTxID(t.Transaction.ID, t.Index)
 }
 
+// Query returns the TxInQuery that looks up the output referenced by t
+func (t TxIn) Query() TxInQuery {
+	return TxInQuery{
+		Transaction: shared.UtxoTxID{ID: t.Transaction.ID},
+		Index:       uint32(t.Index),
+	}
+}
+
+// Queries returns the TxInQuery for each TxIn
+func (tt TxIns) Queries() []TxInQuery {
+	queries := make([]TxInQuery, 0, len(tt))
+	for _, t := range tt {
+		queries = append(queries, t.Query())
+	}
+	return queries
+}
+
 type TxOut struct {
-	Address   string          `json:"address,omitempty"   dynamodbav:"address,omitempty"`
-	Datum     string          `json:"datum,omitempty"     dynamodbav:"datum,omitempty"`
-	DatumHash string          `json:"datumHash,omitempty" dynamodbav:"datumHash,omitempty"`
-	Value     shared.Value    `json:"value,omitempty"     dynamodbav:"value,omitempty"`
-	Script    json.RawMessage `json:"script,omitempty"    dynamodbav:"script,omitempty"`
+	Address   string       `json:"address,omitempty"   dynamodbav:"address,omitempty"`
+	Datum     string       `json:"datum,omitempty"     dynamodbav:"datum,omitempty"`
+	DatumHash string       `json:"datumHash,omitempty" dynamodbav:"datumHash,omitempty"`
+	Value     shared.Value `json:"value,omitempty"     dynamodbav:"value,omitempty"`
+	Script    *Script      `json:"script,omitempty"    dynamodbav:"script,omitempty"`
 }
 
 type TxOuts []TxOut
@@ -629,7 +885,7 @@ func (d *Datums) UnmarshalDynamoDBAttributeValue(
 }
 
 type Witness struct {
-	Bootstrap  []json.RawMessage `json:"bootstrap,omitempty"  dynamodbav:"bootstrap,omitempty"`
+	Bootstrap  []Signature       `json:"bootstrap,omitempty"  dynamodbav:"bootstrap,omitempty"`
 	Datums     Datums            `json:"datums"               dynamodbav:"datums,omitempty"`
 	Redeemers  json.RawMessage   `json:"redeemers,omitempty"  dynamodbav:"redeemers,omitempty"`
 	Scripts    json.RawMessage   `json:"scripts,omitempty"    dynamodbav:"scripts,omitempty"`
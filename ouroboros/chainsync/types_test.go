@@ -26,6 +26,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/fxamacker/cbor/v2"
@@ -354,6 +355,87 @@ func TestPoints_Sort(t *testing.T) {
 	}
 }
 
+func TestPoints_Dedup(t *testing.T) {
+	height := uint64(3)
+	p1 := PointStruct{Slot: 10, ID: "a"}.Point()
+	p1h := PointStruct{Slot: 10, ID: "a", Height: &height}.Point()
+	p2 := PointStruct{Slot: 20, ID: "b"}.Point()
+	p3 := PointStruct{Slot: 20, ID: "c"}.Point()
+	tests := map[string]struct {
+		Input Points
+		Want  Points
+	}{
+		"nil": {
+			Input: nil,
+			Want:  nil,
+		},
+		"duplicates": {
+			Input: Points{p1, p2, p1h, Origin, p2, Origin},
+			Want:  Points{p2, p1, Origin},
+		},
+		"same slot": {
+			Input: Points{p2, p3, p2},
+			Want:  Points{p2, p3},
+		},
+		"invalid": {
+			Input: Points{{}, p1, {}},
+			Want:  Points{p1},
+		},
+	}
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			got := tc.Input.Dedup()
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestPoints_Merge(t *testing.T) {
+	p1 := PointStruct{Slot: 10, ID: "a"}.Point()
+	p2 := PointStruct{Slot: 20, ID: "b"}.Point()
+	p3 := PointStruct{Slot: 30, ID: "c"}.Point()
+
+	a := Points{p1, p2}
+	got := a.Merge(Points{p3, p1})
+	if want := (Points{p3, p2, p1}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if want := (Points{p1, p2}); !reflect.DeepEqual(a, want) {
+		t.Fatalf("got %v; want %v", a, want)
+	}
+}
+
+func TestPoints_Nearest(t *testing.T) {
+	p1 := PointStruct{Slot: 10, ID: "a"}.Point()
+	p2 := PointStruct{Slot: 20, ID: "b"}.Point()
+	points := Points{p2, Origin, p1}
+	tests := map[string]struct {
+		Points Points
+		Slot   uint64
+		Want   Point
+		OK     bool
+	}{
+		"exact":   {Points: points, Slot: 20, Want: p2, OK: true},
+		"between": {Points: points, Slot: 15, Want: p1, OK: true},
+		"after":   {Points: points, Slot: 99, Want: p2, OK: true},
+		"origin":  {Points: points, Slot: 5, Want: Origin, OK: true},
+		"none":    {Points: Points{p1, p2}, Slot: 5, OK: false},
+	}
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			got, ok := tc.Points.Nearest(tc.Slot)
+			if ok != tc.OK {
+				t.Fatalf("got %v; want %v", ok, tc.OK)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
 func TestPraosResponse(t *testing.T) {
 	data := `{
 		"jsonrpc": "2.0",
@@ -1507,3 +1589,12 @@ func Test_UnmarshalOgmiosMetadataV6(t *testing.T) {
 	err := json.Unmarshal(meta, &o)
 	assert.Nil(t, err)
 }
+
+func TestTxIns_Queries(t *testing.T) {
+	txIns := TxIns{{Transaction: TxInID{ID: "a"}, Index: 2}}
+	got := txIns.Queries()
+	want := []TxInQuery{{Transaction: shared.UtxoTxID{ID: "a"}, Index: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
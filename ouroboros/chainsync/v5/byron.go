@@ -46,15 +46,72 @@ type ByronHeader struct {
 }
 
 type ByronTxBody struct {
-	Inputs  []chainsync.TxIn
-	Outputs []chainsync.TxOut
+	Inputs  TxInsV5  `json:"inputs,omitempty"`
+	Outputs TxOutsV5 `json:"outputs,omitempty"`
 }
 
 type ByronTxPayload struct {
 	ID      string
+	Body    ByronTxBody `json:"body,omitempty"`
 	Witness []ByronWitness
 }
 
 type ByronWitness struct {
 	RedeemWitness map[string]string
 }
+
+// ConvertToV6 returns the block in the v6 form; epoch boundary blocks, which
+// carry no genesis key, are of type ebb and all others bft
+func (b ByronBlock) ConvertToV6() chainsync.Block {
+	blockType := "bft"
+	if b.Header.GenesisKey == "" {
+		blockType = "ebb"
+	}
+
+	var txs []chainsync.Tx
+	for _, payload := range b.Body.TxPayload {
+		txs = append(txs, chainsync.Tx{
+			ID:      payload.ID,
+			Inputs:  payload.Body.Inputs.ConvertToV6(),
+			Outputs: payload.Body.Outputs.ConvertToV6(),
+		})
+	}
+
+	return chainsync.Block{
+		Type:         blockType,
+		Era:          "byron",
+		ID:           b.Hash,
+		Ancestor:     b.Header.PrevHash,
+		Height:       b.Header.BlockHeight,
+		Slot:         b.Header.Slot,
+		Transactions: txs,
+		Protocol:     chainsync.Protocol{Version: b.Header.ProtocolVersion},
+		Issuer:       chainsync.BlockIssuer{VerificationKey: b.Header.GenesisKey},
+	}
+}
+
+// ByronBlockFromV6 returns the v5 form of a v6 byron block
+func ByronBlockFromV6(b chainsync.Block) ByronBlock {
+	var payloads []ByronTxPayload
+	for _, tx := range b.Transactions {
+		payloads = append(payloads, ByronTxPayload{
+			ID: tx.ID,
+			Body: ByronTxBody{
+				Inputs:  InputsFromV6(tx.Inputs),
+				Outputs: TxOutsFromV6(tx.Outputs),
+			},
+		})
+	}
+
+	return ByronBlock{
+		Body: ByronBody{TxPayload: payloads},
+		Hash: b.ID,
+		Header: ByronHeader{
+			BlockHeight:     b.Height,
+			GenesisKey:      b.Issuer.VerificationKey,
+			PrevHash:        b.Ancestor,
+			ProtocolVersion: b.Protocol.Version,
+			Slot:            b.Slot,
+		},
+	}
+}
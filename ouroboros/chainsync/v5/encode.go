@@ -0,0 +1,207 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v5
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+const (
+	responseTypeV5 = "jsonwsp/response"
+	serviceNameV5  = "ogmios"
+	versionV5      = "1.0"
+)
+
+// ErrNoResultV5 indicates a v6 result has no v5 equivalent
+var ErrNoResultV5 = errors.New("v5: result cannot be encoded")
+
+// MarshalFindIntersectionV5 encodes a v6 findIntersection result as a v5
+// FindIntersect jsonwsp response.  reflection, if present, is echoed as is.
+func MarshalFindIntersectionV5(
+	result chainsync.ResultFindIntersectionPraos,
+	reflection json.RawMessage,
+) ([]byte, error) {
+	rfi, err := resultFindIntersectionFromV6(result)
+	if err != nil {
+		return nil, err
+	}
+	return marshalResponseV5(chainsync.FindIntersectMethod, &ResultV5{
+		IntersectionFound:    rfi.IntersectionFound,
+		IntersectionNotFound: rfi.IntersectionNotFound,
+	}, reflection)
+}
+
+// MarshalNextBlockV5 encodes a v6 nextBlock result as a v5 RequestNext
+// jsonwsp response.  reflection, if present, is echoed as is.
+func MarshalNextBlockV5(
+	result chainsync.ResultNextBlockPraos,
+	reflection json.RawMessage,
+) ([]byte, error) {
+	rnb, err := resultNextBlockFromV6(result)
+	if err != nil {
+		return nil, err
+	}
+	return marshalResponseV5(chainsync.RequestNextMethod, &ResultV5{
+		RollForward:  rnb.RollForward,
+		RollBackward: rnb.RollBackward,
+	}, reflection)
+}
+
+// MarshalResponseV5 encodes a v6 findIntersection or nextBlock response as a
+// v5 jsonwsp response, using the response ID as the reflection.  An
+// intersection not found error is encoded as IntersectionNotFound.
+func MarshalResponseV5(r chainsync.ResponsePraos) ([]byte, error) {
+	switch r.Method {
+	case chainsync.FindIntersectionMethod:
+		if r.Error != nil {
+			result := chainsync.ResultFindIntersectionPraos{Error: r.Error}
+			return MarshalFindIntersectionV5(result, r.ID)
+		}
+		v, err := r.FindIntersectResult()
+		if err != nil {
+			return nil, err
+		}
+		return MarshalFindIntersectionV5(v, r.ID)
+
+	case chainsync.NextBlockMethod:
+		v, err := r.NextBlockResult()
+		if err != nil {
+			return nil, err
+		}
+		return MarshalNextBlockV5(v, r.ID)
+
+	default:
+		return nil, fmt.Errorf(
+			"%w: method %v", chainsync.ErrUnexpectedMethod, r.Method,
+		)
+	}
+}
+
+func marshalResponseV5(
+	methodName string,
+	result *ResultV5,
+	reflection json.RawMessage,
+) ([]byte, error) {
+	return json.Marshal(ResponseV5{
+		Type:        responseTypeV5,
+		Version:     versionV5,
+		ServiceName: serviceNameV5,
+		MethodName:  methodName,
+		Result:      result,
+		Reflection:  reflection,
+	})
+}
+
+func resultFindIntersectionFromV6(
+	rfi chainsync.ResultFindIntersectionPraos,
+) (ResultFindIntersectionV5, error) {
+	var r ResultFindIntersectionV5
+	switch {
+	case rfi.Intersection != nil:
+		point := PointFromV6(*rfi.Intersection)
+		if point == nil {
+			return r, fmt.Errorf("%w: invalid intersection", ErrNoResultV5)
+		}
+		tip := tipFromV6(rfi.Tip)
+		r.IntersectionFound = &IntersectionFoundV5{
+			Point: point,
+			Tip:   &tip,
+		}
+	case rfi.Error != nil:
+		tip := rfi.Tip
+		if tip == nil {
+			tip = tipFromErrorData(rfi.Error.Data)
+		}
+		v := tipFromV6(tip)
+		r.IntersectionNotFound = &IntersectionNotFoundV5{
+			Tip: &v,
+		}
+	default:
+		return r, fmt.Errorf("%w: no intersection", ErrNoResultV5)
+	}
+	return r, nil
+}
+
+func resultNextBlockFromV6(
+	rnb chainsync.ResultNextBlockPraos,
+) (ResultNextBlockV5, error) {
+	var r ResultNextBlockV5
+	switch rnb.Direction {
+	case chainsync.RollForwardString:
+		if rnb.Block == nil {
+			return r, fmt.Errorf("%w: no block", ErrNoResultV5)
+		}
+		block, err := BlockFromV6(*rnb.Block)
+		if err != nil {
+			return r, fmt.Errorf("%w: %w", ErrNoResultV5, err)
+		}
+		r.RollForward = &RollForwardV5{
+			Block: block,
+			Tip:   tipFromV6(rnb.Tip),
+		}
+	case chainsync.RollBackwardString:
+		var point *PointV5
+		if rnb.Point != nil {
+			point = PointFromV6(*rnb.Point)
+		}
+		if point == nil {
+			return r, fmt.Errorf("%w: no point", ErrNoResultV5)
+		}
+		r.RollBackward = &RollBackwardV5{
+			Point: *point,
+			Tip:   tipFromV6(rnb.Tip),
+		}
+	default:
+		return r, fmt.Errorf(
+			"%w: unknown direction, %v", ErrNoResultV5, rnb.Direction,
+		)
+	}
+	return r, nil
+}
+
+// tipFromV6 returns the zero tip when tip is nil
+func tipFromV6(tip *chainsync.PointStruct) PointStructV5 {
+	if tip == nil {
+		return PointStructV5{}
+	}
+	v := PointStructV5{
+		Hash: tip.ID,
+		Slot: tip.Slot,
+	}
+	if tip.Height != nil {
+		v.BlockNo = *tip.Height
+	}
+	return v
+}
+
+// tipFromErrorData accepts both the ogmios v6 error data, {"tip": {...}}, and
+// the bare tip produced by ResultFindIntersectionV5.ConvertToV6
+func tipFromErrorData(data json.RawMessage) *chainsync.PointStruct {
+	var wrapped struct {
+		Tip *chainsync.PointStruct `json:"tip"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil && wrapped.Tip != nil {
+		return wrapped.Tip
+	}
+	var tip chainsync.PointStruct
+	if err := json.Unmarshal(data, &tip); err != nil {
+		return nil
+	}
+	return &tip
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v5
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalFindIntersectionV5(t *testing.T) {
+	var (
+		height     = uint64(3)
+		tip        = chainsync.PointStruct{ID: "tip", Slot: 100, Height: &height}
+		point      = chainsync.PointStruct{ID: "abc", Slot: 10}.Point()
+		reflection = json.RawMessage(`{"step":"INIT"}`)
+	)
+
+	t.Run("found", func(t *testing.T) {
+		data, err := MarshalFindIntersectionV5(
+			chainsync.ResultFindIntersectionPraos{Intersection: &point, Tip: &tip},
+			reflection,
+		)
+		assert.Nil(t, err)
+
+		typ, _ := jsonparser.GetString(data, "type")
+		assert.Equal(t, "jsonwsp/response", typ)
+		method, _ := jsonparser.GetString(data, "methodname")
+		assert.Equal(t, chainsync.FindIntersectMethod, method)
+		hash, _ := jsonparser.GetString(data, "result", "IntersectionFound", "point", "hash")
+		assert.Equal(t, "abc", hash)
+		blockNo, _ := jsonparser.GetInt(data, "result", "IntersectionFound", "tip", "blockNo")
+		assert.EqualValues(t, 3, blockNo)
+		step, _ := jsonparser.GetString(data, "reflection", "step")
+		assert.Equal(t, "INIT", step)
+
+		var r ResponseV5
+		assert.Nil(t, json.Unmarshal(data, &r))
+		v, err := r.ConvertToV6().FindIntersectResult()
+		assert.Nil(t, err)
+		assert.Equal(t, point.String(), v.Intersection.String())
+		assert.Equal(t, tip, *v.Tip)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, raw := range []string{
+			`{"tip":{"id":"tip","slot":100,"height":3}}`, // ogmios v6
+			`{"id":"tip","slot":100,"height":3}`,         // ConvertToV6
+		} {
+			data, err := MarshalResponseV5(chainsync.ResponsePraos{
+				Method: chainsync.FindIntersectionMethod,
+				Error:  &chainsync.ResultError{Code: 1000, Data: json.RawMessage(raw)},
+			})
+			assert.Nil(t, err)
+
+			hash, _ := jsonparser.GetString(data, "result", "IntersectionNotFound", "tip", "hash")
+			assert.Equal(t, "tip", hash)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := MarshalFindIntersectionV5(chainsync.ResultFindIntersectionPraos{}, nil)
+		assert.True(t, errors.Is(err, ErrNoResultV5))
+	})
+}
+
+func TestMarshalNextBlockV5(t *testing.T) {
+	var (
+		tip   = chainsync.PointStruct{ID: "tip", Slot: 100}
+		point = chainsync.PointStruct{ID: "abc", Slot: 10}.Point()
+	)
+
+	t.Run("roll forward", func(t *testing.T) {
+		block := chainsync.Block{Era: "babbage", ID: "abc", Slot: 10}
+		data, err := MarshalResponseV5(chainsync.ResponsePraos{
+			Method: chainsync.NextBlockMethod,
+			ID:     json.RawMessage(`"42"`),
+			Result: chainsync.ResultNextBlockPraos{
+				Direction: chainsync.RollForwardString,
+				Block:     &block,
+			},
+		})
+		assert.Nil(t, err)
+
+		method, _ := jsonparser.GetString(data, "methodname")
+		assert.Equal(t, chainsync.RequestNextMethod, method)
+		hash, _ := jsonparser.GetString(data, "result", "RollForward", "block", "babbage", "headerHash")
+		assert.Equal(t, "abc", hash)
+		reflection, _ := jsonparser.GetString(data, "reflection")
+		assert.Equal(t, "42", reflection)
+	})
+
+	t.Run("roll backward", func(t *testing.T) {
+		data, err := MarshalNextBlockV5(chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &point,
+			Tip:       &tip,
+		}, nil)
+		assert.Nil(t, err)
+
+		var r ResponseV5
+		assert.Nil(t, json.Unmarshal(data, &r))
+		v, err := r.ConvertToV6().NextBlockResult()
+		assert.Nil(t, err)
+		assert.Equal(t, chainsync.RollBackwardString, v.Direction)
+		assert.Equal(t, point.String(), v.Point.String())
+		assert.Equal(t, tip, *v.Tip)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := MarshalNextBlockV5(chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollForwardString,
+		}, nil)
+		assert.True(t, errors.Is(err, ErrNoResultV5))
+
+		_, err = MarshalResponseV5(chainsync.ResponsePraos{Method: "bogus"})
+		assert.True(t, errors.Is(err, chainsync.ErrUnexpectedMethod))
+	})
+}
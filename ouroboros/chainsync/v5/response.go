@@ -0,0 +1,132 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v5
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// SubmitSuccessV5 is the result of a successful v5 SubmitTx
+type SubmitSuccessV5 struct {
+	TxID string `json:"txId" dynamodbav:"txId"`
+}
+
+// ExUnitsV5 is the budget of a single script as reported by v5 EvaluateTx
+type ExUnitsV5 struct {
+	Memory uint64 `json:"memory" dynamodbav:"memory"`
+	Steps  uint64 `json:"steps"  dynamodbav:"steps"`
+}
+
+// v5 and v6 disagree on the names of some redeemer purposes
+var purposesV5 = map[string]string{
+	"certificate": chainsync.RedeemerPurposePublish,
+	"withdrawal":  chainsync.RedeemerPurposeWithdraw,
+}
+
+// UnmarshalJSON decodes chainsync, SubmitTx, and EvaluateTx results; any
+// other result is assumed to be the answer to a Query and retained as is
+func (r *ResultV5) UnmarshalJSON(data []byte) error {
+	type alias ResultV5
+	var a alias
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &a); err != nil {
+			return err
+		}
+	}
+	if a.IntersectionFound == nil &&
+		a.IntersectionNotFound == nil &&
+		a.RollForward == nil &&
+		a.RollBackward == nil &&
+		a.SubmitSuccess == nil &&
+		a.SubmitFail == nil &&
+		a.EvaluationResult == nil &&
+		a.EvaluationFailure == nil {
+		a.Query = append(json.RawMessage(nil), data...)
+	}
+	*r = ResultV5(a)
+	return nil
+}
+
+// MarshalJSON encodes Query results as they were received
+func (r ResultV5) MarshalJSON() ([]byte, error) {
+	if r.Query != nil {
+		return r.Query, nil
+	}
+	type alias ResultV5
+	return json.Marshal(alias(r))
+}
+
+func evaluationFromV5(
+	result map[string]ExUnitsV5,
+) chainsync.ResultEvaluateTransactionPraos {
+	evaluation := chainsync.ResultEvaluateTransactionPraos{}
+	for key, units := range result {
+		purpose, index, ok := strings.Cut(key, ":")
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+		if v, ok := purposesV5[purpose]; ok {
+			purpose = v
+		}
+		evaluation = append(evaluation, chainsync.ScriptEvaluation{
+			Validator: chainsync.RedeemerValidator{Purpose: purpose, Index: i},
+			Budget:    chainsync.ExUnits{Memory: units.Memory, CPU: units.Steps},
+		})
+	}
+	sort.Slice(evaluation, func(i, j int) bool {
+		a, b := evaluation[i].Validator, evaluation[j].Validator
+		if a.Purpose != b.Purpose {
+			return a.Purpose < b.Purpose
+		}
+		return a.Index < b.Index
+	})
+	return evaluation
+}
+
+func evaluationFromV6(
+	evaluation chainsync.ResultEvaluateTransactionPraos,
+) map[string]ExUnitsV5 {
+	result := map[string]ExUnitsV5{}
+	for _, e := range evaluation {
+		purpose := e.Validator.Purpose
+		for v5, v6 := range purposesV5 {
+			if purpose == v6 {
+				purpose = v5
+			}
+		}
+		key := purpose + ":" + strconv.Itoa(e.Validator.Index)
+		result[key] = ExUnitsV5{Memory: e.Budget.Memory, Steps: e.Budget.CPU}
+	}
+	return result
+}
+
+// errorDataV5 returns the data of a v6 error, or the message if there is none
+func errorDataV5(e chainsync.ResultError) json.RawMessage {
+	if len(e.Data) > 0 {
+		return e.Data
+	}
+	data, _ := json.Marshal(e.Message)
+	return data
+}
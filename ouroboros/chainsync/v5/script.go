@@ -0,0 +1,160 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v5
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// scriptFromV5 retains the v5 json as Raw, decoding scripts of the form
+// {"native": ...} or {"plutus:v1": "cbor"}
+func scriptFromV5(data json.RawMessage) *chainsync.Script {
+	if len(data) == 0 || bytes.Equal(data, json.RawMessage("null")) {
+		return nil
+	}
+
+	var raw bytes.Buffer
+	if err := json.Compact(&raw, data); err != nil {
+		return nil
+	}
+	script := chainsync.Script{
+		Raw: raw.Bytes(),
+	}
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v); err != nil || len(v) != 1 {
+		return &script
+	}
+	for language, value := range v {
+		switch language {
+		case chainsync.ScriptLanguageNative:
+			native, err := nativeScriptFromV5(value)
+			if err != nil {
+				return &script
+			}
+			script.Language = language
+			script.Native = &native
+		case chainsync.ScriptLanguagePlutusV1,
+			chainsync.ScriptLanguagePlutusV2,
+			chainsync.ScriptLanguagePlutusV3:
+			if err := json.Unmarshal(value, &script.CBOR); err != nil {
+				return &script
+			}
+			script.Language = language
+		}
+	}
+	return &script
+}
+
+// nativeScriptFromV5 decodes the v5 encoding of native scripts; a key hash,
+// {"all": [...]}, {"any": [...]}, {"<n>": [...]}, {"expiresAt": slot}, or
+// {"startsAt": slot}
+func nativeScriptFromV5(data json.RawMessage) (chainsync.NativeScript, error) {
+	var keyHash string
+	if err := json.Unmarshal(data, &keyHash); err == nil {
+		return chainsync.NativeScript{
+			Clause:  chainsync.NativeScriptSignature,
+			KeyHash: keyHash,
+		}, nil
+	}
+
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v); err != nil {
+		return chainsync.NativeScript{}, err
+	}
+	if len(v) != 1 {
+		return chainsync.NativeScript{}, fmt.Errorf("invalid native script, %s", data)
+	}
+
+	for key, value := range v {
+		switch key {
+		case "expiresAt", "startsAt":
+			var slot uint64
+			if err := json.Unmarshal(value, &slot); err != nil {
+				return chainsync.NativeScript{}, err
+			}
+			clause := chainsync.NativeScriptBefore
+			if key == "startsAt" {
+				clause = chainsync.NativeScriptAfter
+			}
+			return chainsync.NativeScript{Clause: clause, Slot: slot}, nil
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(value, &items); err != nil {
+			return chainsync.NativeScript{}, err
+		}
+		script := chainsync.NativeScript{Clause: key}
+		switch key {
+		case chainsync.NativeScriptAll, chainsync.NativeScriptAny:
+		default:
+			n, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				return chainsync.NativeScript{}, fmt.Errorf("invalid native script clause, %v", key)
+			}
+			script.Clause = chainsync.NativeScriptSome
+			script.AtLeast = n
+		}
+		for _, item := range items {
+			child, err := nativeScriptFromV5(item)
+			if err != nil {
+				return chainsync.NativeScript{}, err
+			}
+			script.Scripts = append(script.Scripts, child)
+		}
+		return script, nil
+	}
+	return chainsync.NativeScript{}, fmt.Errorf("invalid native script, %s", data)
+}
+
+func scriptsFromV5(data json.RawMessage) chainsync.Scripts {
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v); err != nil || v == nil {
+		return nil
+	}
+	scripts := make(chainsync.Scripts, len(v))
+	for hash, raw := range v {
+		if script := scriptFromV5(raw); script != nil {
+			scripts[hash] = *script
+		}
+	}
+	return scripts
+}
+
+func scriptToV5(script *chainsync.Script) json.RawMessage {
+	if script == nil {
+		return nil
+	}
+	data, err := json.Marshal(script)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func scriptsToV5(scripts chainsync.Scripts) json.RawMessage {
+	if scripts == nil {
+		return nil
+	}
+	data, err := json.Marshal(scripts)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -50,9 +50,9 @@ type TxV5 struct {
 // it's okay to populate the relevant fields in v6. (Example: The "scripts" field in v5
 // and v6 may contain scripts that aren't considered required in v6.)
 func (t TxV5) ConvertToV6() chainsync.Tx {
-	withdrawals := map[string]shared.Value{}
-	for txid, amt := range t.Body.Withdrawals {
-		withdrawals[txid] = shared.CreateAdaValue(amt)
+	withdrawals := chainsync.Withdrawals{}
+	for account, amt := range t.Body.Withdrawals {
+		withdrawals[chainsync.RewardAccount(account)] = shared.CreateAdaValue(amt)
 	}
 
 	var tc *shared.Value
@@ -62,7 +62,7 @@ func (t TxV5) ConvertToV6() chainsync.Tx {
 	}
 	var cr *chainsync.TxOut
 	if t.Body.CollateralReturn != nil {
-		temp := t.Body.CollateralReturn.ConvertToV6()
+		temp := t.Body.CollateralReturn.convertToV6(t.Witness.Datums)
 		cr = &temp
 	}
 
@@ -75,11 +75,7 @@ func (t TxV5) ConvertToV6() chainsync.Tx {
 	// addressAttributes (bootstrap) may be Base64. (chainCode should be hex-only.)
 	// For v6, we need to decode all Base64 sig data to hex strings.
 	signatories := []chainsync.Signature{}
-	for _, sig := range t.Witness.Bootstrap {
-		var s chainsync.Signature
-		// NOTE: error handling is ignored here, we should thread through the error
-		_ = json.Unmarshal(sig, &s)
-
+	for _, s := range t.Witness.Bootstrap {
 		if decodedSig, error := base64.StdEncoding.DecodeString(s.Signature); error == nil {
 			s.Signature = hex.EncodeToString(decodedSig)
 		}
@@ -120,21 +116,21 @@ func (t TxV5) ConvertToV6() chainsync.Tx {
 		Collaterals:              t.Body.Collaterals.ConvertToV6(),
 		TotalCollateral:          tc,
 		CollateralReturn:         cr,
-		Outputs:                  t.Body.Outputs.ConvertToV6(),
+		Outputs:                  t.Body.Outputs.convertToV6(t.Witness.Datums),
 		Certificates:             certificates,
 		Withdrawals:              withdrawals,
 		Fee:                      shared.CreateAdaValue(t.Body.Fee.Int64()),
 		ValidityInterval:         t.Body.ValidityInterval.ConvertToV6(),
-		Mint:                     mint,
+		Mint:                     shared.MintValue(mint),
 		Network:                  t.Body.Network,
 		ScriptIntegrityHash:      t.Body.ScriptIntegrityHash,
 		RequiredExtraSignatories: t.Body.RequiredExtraSignatures,
 		RequiredExtraScripts:     nil,
 		Proposals:                t.Body.Update,
 		Votes:                    nil,
-		Metadata:                 t.Metadata,
+		Metadata:                 metadataFromV5(t.Metadata),
 		Signatories:              signatories,
-		Scripts:                  t.Witness.Scripts,
+		Scripts:                  scriptsFromV5(t.Witness.Scripts),
 		Datums:                   t.Witness.Datums,
 		Redeemers:                t.Witness.Redeemers,
 		CBOR:                     cborHex,
@@ -145,10 +141,10 @@ func (t TxV5) ConvertToV6() chainsync.Tx {
 
 func TxFromV6(t chainsync.Tx) TxV5 {
 	withdrawals := map[string]int64{}
-	for txid, amt := range t.Withdrawals {
+	for account, amt := range t.Withdrawals {
 		for _, policyMap := range amt {
 			for _, assets := range policyMap {
-				withdrawals[txid] = assets.Int64()
+				withdrawals[string(account)] = assets.Int64()
 			}
 		}
 	}
@@ -164,7 +160,7 @@ func TxFromV6(t chainsync.Tx) TxV5 {
 		cr = &temp
 	}
 
-	mint := ValueFromV6(t.Mint)
+	mint := ValueFromV6(t.Mint.Value())
 
 	certificates := []json.RawMessage{}
 	if t.Certificates != nil {
@@ -177,7 +173,7 @@ func TxFromV6(t chainsync.Tx) TxV5 {
 	witness := chainsync.Witness{
 		Datums:     t.Datums,
 		Redeemers:  t.Redeemers,
-		Scripts:    t.Scripts,
+		Scripts:    scriptsToV5(t.Scripts),
 		Signatures: map[string]string{},
 	}
 	for _, sig := range t.Signatories {
@@ -193,14 +189,12 @@ func TxFromV6(t chainsync.Tx) TxV5 {
 					attrData,
 				)
 			}
-			s, _ := json.Marshal(newSig)
-			witness.Bootstrap = append(witness.Bootstrap, s)
+			witness.Bootstrap = append(witness.Bootstrap, newSig)
 		} else {
 			witness.Signatures[newSig.Key] = newSig.Signature
 		}
 	}
 
-	network := []byte(t.Network)
 	tx := TxV5{
 		ID:          t.ID,
 		InputSource: t.Spends,
@@ -216,13 +210,13 @@ func TxFromV6(t chainsync.Tx) TxV5 {
 			Fee:                     t.Fee.AdaLovelace(),
 			ValidityInterval:        ValidityIntervalFromV6(t.ValidityInterval),
 			Mint:                    &mint,
-			Network:                 network,
+			Network:                 t.Network,
 			ScriptIntegrityHash:     t.ScriptIntegrityHash,
 			RequiredExtraSignatures: t.RequiredExtraSignatories,
 			Update:                  t.Proposals,
 		},
 		Raw:      cborB64,
-		Metadata: t.Metadata,
+		Metadata: metadataToV5(t.Metadata),
 		Witness:  witness,
 	}
 
@@ -235,7 +229,7 @@ type TxBodyV5 struct {
 	Fee                     num.Int            `json:"fee,omitempty"                     dynamodbav:"fee,omitempty"`
 	Inputs                  TxInsV5            `json:"inputs,omitempty"                  dynamodbav:"inputs,omitempty"`
 	Mint                    *ValueV5           `json:"mint,omitempty"                    dynamodbav:"mint,omitempty"`
-	Network                 json.RawMessage    `json:"network,omitempty"                 dynamodbav:"network,omitempty"`
+	Network                 chainsync.Network  `json:"network,omitempty"                 dynamodbav:"network,omitempty"`
 	Outputs                 TxOutsV5           `json:"outputs,omitempty"                 dynamodbav:"outputs,omitempty"`
 	RequiredExtraSignatures []string           `json:"requiredExtraSignatures,omitempty" dynamodbav:"requiredExtraSignatures,omitempty"`
 	ScriptIntegrityHash     string             `json:"scriptIntegrityHash,omitempty"     dynamodbav:"scriptIntegrityHash,omitempty"`
@@ -299,13 +293,32 @@ type TxOutV5 struct {
 }
 
 func (t TxOutV5) ConvertToV6() chainsync.TxOut {
+	return t.convertToV6(nil)
+}
+
+func (t TxOutV5) convertToV6(witness chainsync.Datums) chainsync.TxOut {
+	t = t.NormalizeDatum(witness)
 	return chainsync.TxOut{
 		Address:   t.Address,
 		Datum:     t.Datum,
 		DatumHash: t.DatumHash,
 		Value:     t.Value.ConvertToV6(),
-		Script:    t.Script,
+		Script:    scriptFromV5(t.Script),
+	}
+}
+
+// NormalizeDatum returns a copy of the output with v6 datum semantics; the
+// hash is held in DatumHash and Datum is only set for inline datums.  Alonzo
+// era v5 outputs report the datum hash via Datum, so a Datum is treated as a
+// hash when chainsync.IsDatumHash reports it as such.
+func (t TxOutV5) NormalizeDatum(witness chainsync.Datums) TxOutV5 {
+	if t.Datum == "" || t.DatumHash != "" {
+		return t
 	}
+	if chainsync.IsDatumHash(t.Datum, witness) {
+		t.DatumHash, t.Datum = t.Datum, ""
+	}
+	return t
 }
 
 func TxOutFromV6(t chainsync.TxOut) TxOutV5 {
@@ -314,16 +327,20 @@ func TxOutFromV6(t chainsync.TxOut) TxOutV5 {
 		Datum:     t.Datum,
 		DatumHash: t.DatumHash,
 		Value:     ValueFromV6(t.Value),
-		Script:    t.Script,
+		Script:    scriptToV5(t.Script),
 	}
 }
 
 type TxOutsV5 []TxOutV5
 
 func (t TxOutsV5) ConvertToV6() chainsync.TxOuts {
+	return t.convertToV6(nil)
+}
+
+func (t TxOutsV5) convertToV6(witness chainsync.Datums) chainsync.TxOuts {
 	var txOuts []chainsync.TxOut
 	for _, txOut := range t {
-		txOuts = append(txOuts, txOut.ConvertToV6())
+		txOuts = append(txOuts, txOut.convertToV6(witness))
 	}
 	return txOuts
 }
@@ -373,7 +390,7 @@ type ValueV5 struct {
 
 func (v ValueV5) ConvertToV6() shared.Value {
 	assets := shared.Value{}
-	if v.Coins.Uint64() != 0 {
+	if !v.Coins.IsZero() {
 		assets[shared.AdaPolicy] = map[string]num.Int{
 			shared.AdaAsset: v.Coins,
 		}
@@ -661,6 +678,11 @@ type ResultV5 struct {
 	IntersectionNotFound *IntersectionNotFoundV5 `json:",omitempty" dynamodbav:",omitempty"`
 	RollForward          *RollForwardV5          `json:",omitempty" dynamodbav:",omitempty"`
 	RollBackward         *RollBackwardV5         `json:",omitempty" dynamodbav:",omitempty"`
+	SubmitSuccess        *SubmitSuccessV5        `json:",omitempty" dynamodbav:",omitempty"`
+	SubmitFail           json.RawMessage         `json:",omitempty" dynamodbav:",omitempty"`
+	EvaluationResult     map[string]ExUnitsV5    `json:",omitempty" dynamodbav:",omitempty"`
+	EvaluationFailure    json.RawMessage         `json:",omitempty" dynamodbav:",omitempty"`
+	Query                json.RawMessage         `json:"-"          dynamodbav:",omitempty"` // Query result, as sent
 }
 
 type ResultFindIntersectionV5 struct {
@@ -692,27 +714,7 @@ func (r ResultFindIntersectionV5) ConvertToV6() chainsync.ResultFindIntersection
 func ResultFindIntersectionFromV6(
 	rfi chainsync.ResultFindIntersectionPraos,
 ) ResultFindIntersectionV5 {
-	var r ResultFindIntersectionV5
-	if rfi.Intersection != nil {
-		p := PointFromV6(*rfi.Intersection)
-		tip := PointStructV5{
-			Hash: rfi.Tip.ID,
-			Slot: rfi.Tip.Slot,
-		}
-		if rfi.Tip.Height != nil {
-			tip.BlockNo = *rfi.Tip.Height
-		}
-		r.IntersectionFound = &IntersectionFoundV5{
-			Point: p,
-			Tip:   &tip,
-		}
-	} else if rfi.Error != nil {
-		var tip PointStructV5
-		_ = json.Unmarshal(rfi.Error.Data, &tip)
-		r.IntersectionNotFound = &IntersectionNotFoundV5{
-			Tip: &tip,
-		}
-	}
+	r, _ := resultFindIntersectionFromV6(rfi)
 	return r
 }
 
@@ -741,6 +743,8 @@ func (b RollForwardBlockV5) Era() string {
 		return "alonzo"
 	} else if b.Babbage != nil {
 		return "babbage"
+	} else if b.Byron != nil {
+		return "byron"
 	} else {
 		return "unknown"
 	}
@@ -763,9 +767,12 @@ func (b RollForwardBlockV5) GetNonByronBlock() *BlockV5 {
 }
 
 func (b RollForwardBlockV5) ConvertToV6() (chainsync.Block, error) {
+	if b.Byron != nil {
+		return b.Byron.ConvertToV6(), nil
+	}
 	nbb := b.GetNonByronBlock()
 	if nbb == nil {
-		return chainsync.Block{}, errors.New("byron blocks not supported")
+		return chainsync.Block{}, errors.New("block has no era")
 	}
 	var txArray []chainsync.Tx
 	for _, t := range nbb.Body {
@@ -775,7 +782,7 @@ func (b RollForwardBlockV5) ConvertToV6() (chainsync.Block, error) {
 	// The v5 spec indicates that both nonce entries are optional. We'll create a v6
 	// entry (which also indicates both are optional) if either is present.
 	nonceOutput := nbb.Header.Nonce["output"]
-	nonceProof := nbb.Header.Nonce["output"]
+	nonceProof := nbb.Header.Nonce["proof"]
 	var nonce *chainsync.Nonce
 	if nonceOutput != "" || nonceProof != "" {
 		nonce = &chainsync.Nonce{Output: nonceOutput, Proof: nonceProof}
@@ -852,7 +859,8 @@ func (b RollForwardBlockV5) ConvertToV6() (chainsync.Block, error) {
 
 func BlockFromV6(b chainsync.Block) (RollForwardBlockV5, error) {
 	if b.Era == "byron" {
-		return RollForwardBlockV5{}, errors.New("byron blocks not supported")
+		byron := ByronBlockFromV6(b)
+		return RollForwardBlockV5{Byron: &byron}, nil
 	}
 
 	var txArray []TxV5
@@ -959,8 +967,7 @@ func (r ResultNextBlockV5) ConvertToV6() chainsync.ResultNextBlockPraos {
 		tip := r.RollForward.Tip.ConvertToV6()
 		block, err := r.RollForward.Block.ConvertToV6()
 		if err != nil {
-			// NOTE: we currently don't support byron blocks, please reach out if you need this!
-			return rnb
+			return rnb // block has no era
 		}
 		rnb.Direction = chainsync.RollForwardString
 		rnb.Tip = &tip
@@ -979,53 +986,17 @@ func (r ResultNextBlockV5) ConvertToV6() chainsync.ResultNextBlockPraos {
 func ResultNextBlockFromV6(
 	rnb chainsync.ResultNextBlockPraos,
 ) ResultNextBlockV5 {
-	var r ResultNextBlockV5
-	switch rnb.Direction {
-	case chainsync.RollForwardString:
-		tip := PointStructV5{
-			Hash: rnb.Tip.ID,
-			Slot: rnb.Tip.Slot,
-		}
-		if rnb.Tip.Height != nil {
-			tip.BlockNo = *rnb.Tip.Height
-		}
-		block, err := BlockFromV6(*rnb.Block)
-		if err != nil {
-			// NOTE: we don't currently support byron
-			return r
-		}
-		r.RollForward = &RollForwardV5{
-			Block: block,
-			Tip:   tip,
-		}
-	case chainsync.RollBackwardString:
-		tip := PointStructV5{
-			Hash: rnb.Tip.ID,
-			Slot: rnb.Tip.Slot,
-		}
-		if rnb.Tip.Height != nil {
-			tip.BlockNo = *rnb.Tip.Height
-		}
-		point := PointFromV6(*rnb.Point)
-		if point == nil {
-			return r
-		}
-		r.RollBackward = &RollBackwardV5{
-			Point: *point,
-			Tip:   tip,
-		}
-	}
-
+	r, _ := resultNextBlockFromV6(rnb)
 	return r
 }
 
 type IntersectionFoundV5 struct {
-	Point *PointV5
-	Tip   *PointStructV5
+	Point *PointV5       `json:"point" dynamodbav:"Point"`
+	Tip   *PointStructV5 `json:"tip"   dynamodbav:"Tip"`
 }
 
 type IntersectionNotFoundV5 struct {
-	Tip *PointStructV5
+	Tip *PointStructV5 `json:"tip" dynamodbav:"Tip"`
 }
 
 type ResponseV5 struct {
@@ -1039,6 +1010,9 @@ type ResponseV5 struct {
 
 func (r ResponseV5) ConvertToV6() chainsync.ResponsePraos {
 	var c chainsync.ResponsePraos
+	if r.Result == nil {
+		return c
+	}
 
 	// All we really care about is the result, not the metadata.
 	if r.Result.IntersectionFound != nil {
@@ -1065,8 +1039,7 @@ func (r ResponseV5) ConvertToV6() chainsync.ResponsePraos {
 
 		block, err := r.Result.RollForward.Block.ConvertToV6()
 		if err != nil {
-			// NOTE: we currently don't support byron, reach out to us if you need this supported!
-			return c
+			return c // block has no era
 		}
 
 		t := r.Result.RollForward.Tip.ConvertToV6()
@@ -1091,6 +1064,32 @@ func (r ResponseV5) ConvertToV6() chainsync.ResponsePraos {
 		nextBlock.Tip = &t
 		nextBlock.Point = &p
 		c.Result = &nextBlock
+	} else if r.Result.SubmitSuccess != nil {
+		c.Method = chainsync.SubmitTransactionMethod
+		c.Result = &chainsync.ResultSubmitTransactionPraos{
+			Transaction: chainsync.SubmittedTx{ID: r.Result.SubmitSuccess.TxID},
+		}
+	} else if r.Result.SubmitFail != nil {
+		c.Method = chainsync.SubmitTransactionMethod
+		c.Error = &chainsync.ResultError{
+			Code:    3000,
+			Message: "Transaction submission failed - Conversion from a v5 Ogmigo call",
+			Data:    r.Result.SubmitFail,
+		}
+	} else if r.Result.EvaluationResult != nil {
+		c.Method = chainsync.EvaluateTransactionMethod
+		evaluation := evaluationFromV5(r.Result.EvaluationResult)
+		c.Result = &evaluation
+	} else if r.Result.EvaluationFailure != nil {
+		c.Method = chainsync.EvaluateTransactionMethod
+		c.Error = &chainsync.ResultError{
+			Code:    3000,
+			Message: "Transaction evaluation failed - Conversion from a v5 Ogmigo call",
+			Data:    r.Result.EvaluationFailure,
+		}
+	} else if r.Result.Query != nil {
+		c.Method = chainsync.QueryMethod
+		c.Result = r.Result.Query
 	}
 	c.ID = r.Reflection
 	c.JsonRpc = "2.0"
@@ -1099,10 +1098,20 @@ func (r ResponseV5) ConvertToV6() chainsync.ResponsePraos {
 
 // I don't really understand the nest of types here...
 func ResponseFromV6(r chainsync.ResponsePraos) ResponseV5 {
-	var result *ResultV5
+	var (
+		result     *ResultV5
+		methodName = "cardano"
+	)
 	switch r.Method {
 	case chainsync.FindIntersectionMethod:
-		rfi := ResultFindIntersectionFromV6(r.MustFindIntersectResult())
+		v, err := r.FindIntersectResult()
+		if r.Error != nil {
+			v, err = chainsync.ResultFindIntersectionPraos{Error: r.Error}, nil
+		}
+		if err != nil {
+			break
+		}
+		rfi := ResultFindIntersectionFromV6(v)
 		if rfi.IntersectionFound != nil {
 			result = &ResultV5{
 				IntersectionFound: rfi.IntersectionFound,
@@ -1113,7 +1122,11 @@ func ResponseFromV6(r chainsync.ResponsePraos) ResponseV5 {
 			}
 		}
 	case chainsync.NextBlockMethod:
-		rnb := ResultNextBlockFromV6(r.MustNextBlockResult())
+		v, err := r.NextBlockResult()
+		if err != nil {
+			break
+		}
+		rnb := ResultNextBlockFromV6(v)
 		if rnb.RollForward != nil {
 			result = &ResultV5{
 				RollForward: rnb.RollForward,
@@ -1123,13 +1136,50 @@ func ResponseFromV6(r chainsync.ResponsePraos) ResponseV5 {
 				RollBackward: rnb.RollBackward,
 			}
 		}
+	case chainsync.SubmitTransactionMethod:
+		methodName = chainsync.SubmitTxMethod
+		if r.Error != nil {
+			fail, _ := json.Marshal([]json.RawMessage{errorDataV5(*r.Error)})
+			result = &ResultV5{SubmitFail: fail}
+			break
+		}
+		v, err := r.SubmitTransactionResult()
+		if err != nil {
+			break
+		}
+		result = &ResultV5{
+			SubmitSuccess: &SubmitSuccessV5{TxID: v.Transaction.ID},
+		}
+	case chainsync.EvaluateTransactionMethod:
+		methodName = chainsync.EvaluateTxMethod
+		if r.Error != nil {
+			result = &ResultV5{EvaluationFailure: errorDataV5(*r.Error)}
+			break
+		}
+		v, err := r.EvaluateTransactionResult()
+		if err != nil {
+			break
+		}
+		result = &ResultV5{EvaluationResult: evaluationFromV6(v)}
+	default:
+		if !chainsync.IsQueryMethod(r.Method) {
+			break
+		}
+		methodName = chainsync.QueryMethod
+		if r.Error != nil {
+			result = &ResultV5{Query: errorDataV5(*r.Error)}
+			break
+		}
+		if v, err := r.QueryResult(); err == nil {
+			result = &ResultV5{Query: v}
+		}
 	}
 
 	return ResponseV5{
 		Type:        "response",
 		Version:     "1.0",
 		ServiceName: "cardano",
-		MethodName:  "cardano",
+		MethodName:  methodName,
 		Result:      result,
 		Reflection:  r.ID,
 	}
@@ -1219,3 +1269,35 @@ func OgmiosAuxiliaryDataFromV6(
 		},
 	}, nil
 }
+
+// metadataFromV5 retains the v5 json as Raw, decoding the labels of the blob
+func metadataFromV5(data json.RawMessage) *chainsync.TxMetadata {
+	if len(data) == 0 || bytes.Equal(data, json.RawMessage("null")) {
+		return nil
+	}
+
+	var metadata chainsync.TxMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil
+	}
+	var auxData OgmiosAuxiliaryDataV5
+	if err := json.Unmarshal(data, &auxData); err == nil && auxData.Body != nil {
+		metadata.Hash = auxData.Hash
+		metadata.Labels = map[int]chainsync.MetadataLabel{}
+		for n, metadatum := range auxData.Body.Blob {
+			metadata.Labels[n] = chainsync.MetadataLabel{Metadatum: &metadatum}
+		}
+	}
+	return &metadata
+}
+
+func metadataToV5(metadata *chainsync.TxMetadata) json.RawMessage {
+	if metadata == nil {
+		return nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+	return data
+}
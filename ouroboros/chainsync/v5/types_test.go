@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"math/big"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
@@ -38,13 +39,6 @@ func TestV5(t *testing.T) {
 		v5Conversion := TxFromV6(expectedV6)
 		v6Conversion := v5Conversion.ConvertToV6()
 
-		for _, x := range v5Conversion.Witness.Bootstrap {
-			var sig chainsync.Signature
-			err = json.Unmarshal(x, &sig)
-			assert.Nil(t, err)
-		}
-
-		network := "\"mainnet\""
 		bootstrap := "{\"key\":\"d88f6028cc3d6d335115de3737bc2fe80a9a57a21a2c7c228ebc33b222e0897b\",\"signature\":\"/rRH7Ka4GfiLS2qsgalyABId1EUb/Mtl9z0x3ilrVALurUKEiAhjOtHUr7+tOi8ZZ85lUWrcpc03NnP3WKnAlg==\",\"chainCode\":\"12340000\",\"addressAttributes\":\"Lw==\"}"
 		assert.Equal(t, "2f", expectedV6.Signatories[0].AddressAttributes)
 		assert.Equal(t, "12340000", expectedV6.Signatories[0].ChainCode)
@@ -58,14 +52,16 @@ func TestV5(t *testing.T) {
 			"feb447eca6b819f88b4b6aac81a97200121dd4451bfccb65f73d31de296b5402eead42848808633ad1d4afbfad3a2f1967ce65516adca5cd373673f758a9c096",
 			expectedV6.Signatories[0].Signature,
 		)
-		assert.Equal(t, bootstrap, string(v5Conversion.Witness.Bootstrap[0]))
+		encoded, err := json.Marshal(v5Conversion.Witness.Bootstrap[0])
+		assert.Nil(t, err)
+		assert.Equal(t, bootstrap, string(encoded))
 		assert.Equal(
 			t,
 			"IFb1lTq+ivhYQz6fAoPZQXuGgebeh5fIsM8rocK03mbss8yaUQpf871Qso2aAYaxjDadDHzMfUPRCJDpTyVxQg==",
 			v5Conversion.Witness.Signatures["400019217786c3630fb121c455065b879055aa0ced5076a24abe8d6c837e0318"],
 		)
-		assert.Equal(t, json.RawMessage(network), v5Conversion.Body.Network)
-		assert.Equal(t, json.RawMessage(network), v6Conversion.Network)
+		assert.Equal(t, chainsync.NetworkMainnet, v5Conversion.Body.Network)
+		assert.Equal(t, chainsync.NetworkMainnet, v6Conversion.Network)
 		assert.Equal(
 			t,
 			expectedV6.Signatories[0].AddressAttributes,
@@ -199,3 +195,86 @@ func Test_UnmarshalOgmiosMetadataV5(t *testing.T) {
 	err := json.Unmarshal(meta, &o)
 	assert.Nil(t, err)
 }
+
+func TestTxOutV5_NormalizeDatum(t *testing.T) {
+	var (
+		hash   = "ff" + strings.Repeat("00", 31)                            // not well formed cbor
+		inline = "581e" + strings.Repeat("00", 30)                          // 32 byte inline datum
+		long   = "d8799f581cc86e835a1b093aa81610fd3935f463ce529929cded8b75" // inline datum
+	)
+
+	t.Run("hash", func(t *testing.T) {
+		got := TxOutV5{Datum: hash}.ConvertToV6()
+		assert.Equal(t, "", got.Datum)
+		assert.Equal(t, hash, got.DatumHash)
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		got := TxOutV5{Datum: inline}.ConvertToV6()
+		assert.Equal(t, inline, got.Datum)
+		assert.Equal(t, "", got.DatumHash)
+
+		got = TxOutV5{Datum: long}.ConvertToV6()
+		assert.Equal(t, long, got.Datum)
+		assert.Equal(t, "", got.DatumHash)
+	})
+
+	t.Run("witness", func(t *testing.T) {
+		tx := TxV5{
+			Body: TxBodyV5{
+				Outputs: TxOutsV5{{Datum: inline}},
+			},
+			Witness: chainsync.Witness{
+				Datums: chainsync.Datums{inline: "d87980"},
+			},
+		}
+		got := tx.ConvertToV6()
+		assert.Equal(t, "", got.Outputs[0].Datum)
+		assert.Equal(t, inline, got.Outputs[0].DatumHash)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		out := TxOutV5{Datum: inline, DatumHash: hash}
+		assert.Equal(t, out, out.NormalizeDatum(nil))
+	})
+}
+
+func TestTxV5_Metadata(t *testing.T) {
+	meta := json.RawMessage(`{"hash":"00","body":{"blob":{"674":{"map":[{"k":{"string":"msg"},"v":{"list":[{"string":"hello"}]}}]}}}}`)
+
+	tx := TxV5{ID: "tx", Metadata: meta}.ConvertToV6()
+	assert.Equal(t, "00", tx.Metadata.Hash)
+	lines, ok := tx.Metadata.CIP20()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"hello"}, lines)
+
+	assert.Equal(t, string(meta), string(TxFromV6(tx).Metadata))
+	assert.Nil(t, TxV5{ID: "tx"}.ConvertToV6().Metadata)
+}
+
+func TestTxV5_Scripts(t *testing.T) {
+	tx := TxV5{
+		ID: "tx",
+		Body: TxBodyV5{
+			Outputs: TxOutsV5{{Address: "addr", Script: json.RawMessage(`{"plutus:v2":"4d01"}`)}},
+		},
+		Witness: chainsync.Witness{
+			Scripts: json.RawMessage(`{"hash":{"native":{"any":[{"startsAt":71907},{"2":["a","b",{"expiresAt":100}]}]}}}`),
+		},
+	}.ConvertToV6()
+
+	native := tx.Scripts["hash"].Native
+	assert.Equal(t, chainsync.NativeScriptAny, native.Clause)
+	assert.Equal(t, chainsync.NativeScriptAfter, native.Scripts[0].Clause)
+	assert.EqualValues(t, 71907, native.Scripts[0].Slot)
+	assert.Equal(t, chainsync.NativeScriptSome, native.Scripts[1].Clause)
+	assert.EqualValues(t, 2, native.Scripts[1].AtLeast)
+	assert.Equal(t, "b", native.Scripts[1].Scripts[1].KeyHash)
+	assert.Equal(t, chainsync.NativeScriptBefore, native.Scripts[1].Scripts[2].Clause)
+
+	script := tx.Outputs[0].Script
+	assert.Equal(t, chainsync.ScriptLanguagePlutusV2, script.Language)
+	assert.Equal(t, "4d01", script.CBOR)
+
+	assert.Equal(t, `{"plutus:v2":"4d01"}`, string(TxFromV6(tx).Body.Outputs[0].Script))
+}
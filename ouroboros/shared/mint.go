@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"sort"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+)
+
+// MintValue holds the quantities minted, when positive, or burned, when
+// negative, by a transaction keyed by policy id and then asset name
+type MintValue map[string]map[string]num.Int
+
+// Minted returns the assets minted i.e. those with positive quantities
+func (m MintValue) Minted() Value {
+	return m.filter(func(n num.Int) (num.Int, bool) {
+		return n, n.Sign() > 0
+	})
+}
+
+// Burned returns the assets burned as positive quantities
+func (m MintValue) Burned() Value {
+	return m.filter(func(n num.Int) (num.Int, bool) {
+		return n.Neg(), n.Sign() < 0
+	})
+}
+
+// ByPolicy returns the quantities of each asset of the policy keyed by asset
+// name, or nil if the policy neither minted nor burned
+func (m MintValue) ByPolicy(policyID string) map[string]num.Int {
+	return m[policyID]
+}
+
+// Policies returns the sorted ids of the policies that minted or burned
+func (m MintValue) Policies() []string {
+	policies := make([]string, 0, len(m))
+	for policyID := range m {
+		policies = append(policies, policyID)
+	}
+	sort.Strings(policies)
+	return policies
+}
+
+// Value returns the mint as a Value with signed quantities
+func (m MintValue) Value() Value {
+	return Value(m)
+}
+
+func (m MintValue) filter(fn func(num.Int) (num.Int, bool)) Value {
+	v := Value{}
+	for policyID, assets := range m {
+		for assetName, n := range assets {
+			n, ok := fn(n)
+			if !ok {
+				continue
+			}
+			if v[policyID] == nil {
+				v[policyID] = map[string]num.Int{}
+			}
+			v[policyID][assetName] = n
+		}
+	}
+	return v
+}
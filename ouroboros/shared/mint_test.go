@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/tj/assert"
+)
+
+func TestMintValue(t *testing.T) {
+	var mint MintValue
+	err := json.Unmarshal(
+		[]byte(`{"policyA":{"a":5,"b":-2},"policyB":{"":-1}}`),
+		&mint,
+	)
+	assert.Nil(t, err)
+
+	minted := mint.Minted()
+	assert.True(t, Equal(Value{"policyA": {"a": num.Int64(5)}}, minted))
+
+	burned := mint.Burned()
+	want := Value{
+		"policyA": {"b": num.Int64(2)},
+		"policyB": {"": num.Int64(1)},
+	}
+	assert.True(t, Equal(want, burned), "got %v; want %v", burned, want)
+
+	assert.Equal(t, num.Int64(-2), mint.ByPolicy("policyA")["b"])
+	assert.Nil(t, mint.ByPolicy("policyC"))
+	assert.Equal(t, []string{"policyA", "policyB"}, mint.Policies())
+	assert.Equal(t, num.Int64(-1), mint.Value().AssetAmount(AssetID("policyB")))
+}
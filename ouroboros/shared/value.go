@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math/big"
+	"sort"
+	"strings"
 
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
 )
@@ -56,25 +59,114 @@ func Subtract(a Value, b Value) Value {
 	return result
 }
 
-func Enough(have Value, want Value) (bool, error) {
+// AddAssign adds that to v in place, reusing the maps of v rather than
+// allocating a new Value as Add does
+func (v *Value) AddAssign(that Value) {
+	v.assign(that, num.Int.Add)
+}
+
+// SubAssign subtracts that from v in place, reusing the maps of v rather than
+// allocating a new Value as Subtract does
+func (v *Value) SubAssign(that Value) {
+	v.assign(that, num.Int.Sub)
+}
+
+func (v *Value) assign(that Value, op func(a, b num.Int) num.Int) {
+	if v == nil {
+		return
+	}
+	if *v == nil {
+		*v = Value{}
+	}
+
+	for policy, assets := range that {
+		inner, ok := (*v)[policy]
+		if !ok {
+			inner = make(map[string]num.Int, len(assets))
+			(*v)[policy] = inner
+		}
+		for asset, amt := range assets {
+			inner[asset] = op(inner[asset], amt)
+		}
+	}
+}
+
+// AssetShortfall records an asset for which have falls short of want
+type AssetShortfall struct {
+	AssetID AssetID
+	Have    num.Int
+	Want    num.Int
+}
+
+// Missing returns the amount by which have falls short
+func (a AssetShortfall) Missing() num.Int {
+	return a.Want.Sub(a.Have)
+}
+
+// ShortfallError reports every asset for which have falls short of want.
+// ShortfallError wraps ErrInsufficientFunds.
+type ShortfallError struct {
+	Assets []AssetShortfall // Assets are ordered by AssetID
+}
+
+func (e *ShortfallError) Error() string {
+	parts := make([]string, 0, len(e.Assets))
+	for _, a := range e.Assets {
+		parts = append(parts, fmt.Sprintf(
+			"not enough %v (%v) to meet demand (%v)",
+			a.AssetID,
+			a.Have,
+			a.Want,
+		))
+	}
+	return fmt.Sprintf(
+		"%v: %v",
+		strings.Join(parts, "; "),
+		ErrInsufficientFunds,
+	)
+}
+
+func (e *ShortfallError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// Missing returns the amount missing of each asset
+func (e *ShortfallError) Missing() Value {
+	missing := Value{}
+	for _, a := range e.Assets {
+		missing.AddAsset(Coin{AssetId: a.AssetID, Amount: a.Missing()})
+	}
+	return missing
+}
+
+// Shortfall returns every asset for which have falls short of want, ordered
+// by AssetID, or nil if have is enough
+func Shortfall(have Value, want Value) []AssetShortfall {
+	var shortfall []AssetShortfall
 	for policyId, assets := range want {
 		for assetName, amt := range assets {
-			haveAssets, ok := have[policyId]
-			haveAmt := num.Uint64(0)
-			if ok {
-				haveAmt = haveAssets[assetName]
-			}
+			haveAmt := have[policyId][assetName]
 			if haveAmt.LessThan(amt) {
-				return false, fmt.Errorf(
-					"not enough %v (%v) to meet demand (%v): %w",
-					assetName,
-					have[policyId][assetName].String(),
-					amt,
-					ErrInsufficientFunds,
-				)
+				shortfall = append(shortfall, AssetShortfall{
+					AssetID: FromSeparate(policyId, assetName),
+					Have:    haveAmt,
+					Want:    amt,
+				})
 			}
 		}
 	}
+	sort.Slice(shortfall, func(i, j int) bool {
+		return shortfall[i].AssetID < shortfall[j].AssetID
+	})
+	return shortfall
+}
+
+// Enough returns true if have holds at least want of every asset; otherwise
+// the error is a *ShortfallError listing every asset that falls short
+func Enough(have Value, want Value) (bool, error) {
+	if shortfall := Shortfall(have, want); len(shortfall) > 0 {
+		return false, &ShortfallError{Assets: shortfall}
+	}
 	return true, nil
 }
 
@@ -222,6 +314,58 @@ func (v Value) IsAdaPresent() bool {
 	return false
 }
 
+// IsZero returns true if every asset quantity in v is zero, including when v
+// is nil or empty
+func (v Value) IsZero() bool {
+	for _, assets := range v {
+		for _, amt := range assets {
+			if !amt.IsZero() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Equal returns true if v and that hold the same quantity of every asset.
+// Missing assets are treated as zero.
+func (v Value) Equal(that Value) bool {
+	return Equal(v, that)
+}
+
+// Clone returns a deep copy of v that shares no state with the original
+func (v Value) Clone() Value {
+	if v == nil {
+		return nil
+	}
+	clone := make(Value, len(v))
+	for policy, assets := range v {
+		inner := make(map[string]num.Int, len(assets))
+		for asset, amt := range assets {
+			inner[asset] = num.Int(*new(big.Int).Set(amt.BigInt()))
+		}
+		clone[policy] = inner
+	}
+	return clone
+}
+
+// Normalize returns a copy of v without zero quantity assets or policies left
+// empty as a result
+func (v Value) Normalize() Value {
+	normalized := Value{}
+	for policy, assets := range v.Clone() {
+		for asset, amt := range assets {
+			if amt.IsZero() {
+				delete(assets, asset)
+			}
+		}
+		if len(assets) > 0 {
+			normalized[policy] = assets
+		}
+	}
+	return normalized
+}
+
 type Coin struct {
 	AssetId AssetID
 	Amount  num.Int
@@ -0,0 +1,96 @@
+package shared
+
+import (
+	"math/big"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+)
+
+// ValueBuilder sums many values, e.g. every TxOut of a block, without the
+// intermediate allocations of repeated calls to Add.  Quantities accumulate
+// in place and a Value is only built when requested.  The zero value is ready
+// to use; ValueBuilder is not safe for concurrent use
+type ValueBuilder struct {
+	sums map[string]map[string]*big.Int
+}
+
+// Add adds each of the values to the running total
+func (b *ValueBuilder) Add(values ...Value) {
+	for _, v := range values {
+		b.apply(v, false)
+	}
+}
+
+// Sub subtracts each of the values from the running total
+func (b *ValueBuilder) Sub(values ...Value) {
+	for _, v := range values {
+		b.apply(v, true)
+	}
+}
+
+// AddAsset adds the coins to the running total
+func (b *ValueBuilder) AddAsset(coins ...Coin) {
+	for _, coin := range coins {
+		sum := b.sum(coin.AssetId.PolicyID(), coin.AssetId.AssetName())
+		amt := big.Int(coin.Amount)
+		sum.Add(sum, &amt)
+	}
+}
+
+func (b *ValueBuilder) apply(v Value, subtract bool) {
+	for policy, assets := range v {
+		for asset, amt := range assets {
+			sum := b.sum(policy, asset)
+			x := big.Int(amt)
+			if subtract {
+				sum.Sub(sum, &x)
+			} else {
+				sum.Add(sum, &x)
+			}
+		}
+	}
+}
+
+func (b *ValueBuilder) sum(policy, asset string) *big.Int {
+	if b.sums == nil {
+		b.sums = map[string]map[string]*big.Int{}
+	}
+	inner, ok := b.sums[policy]
+	if !ok {
+		inner = map[string]*big.Int{}
+		b.sums[policy] = inner
+	}
+	sum, ok := inner[asset]
+	if !ok {
+		sum = new(big.Int)
+		inner[asset] = sum
+	}
+	return sum
+}
+
+// Value returns the running total as a Value that shares no state with the
+// builder, which may continue to be used.  Assets totalling zero are omitted
+func (b *ValueBuilder) Value() Value {
+	v := make(Value, len(b.sums))
+	for policy, assets := range b.sums {
+		inner := make(map[string]num.Int, len(assets))
+		for asset, sum := range assets {
+			if sum.Sign() != 0 {
+				inner[asset] = num.Int(*new(big.Int).Set(sum))
+			}
+		}
+		if len(inner) > 0 {
+			v[policy] = inner
+		}
+	}
+	return v
+}
+
+// Reset discards the running total, retaining the allocated accumulators
+func (b *ValueBuilder) Reset() {
+	for _, assets := range b.sums {
+		for _, sum := range assets {
+			sum.SetInt64(0)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/tj/assert"
+)
+
+func Test_AddAssign(t *testing.T) {
+	var v Value
+	v.AddAssign(Value{"ada": {"lovelace": num.Uint64(5)}})
+	v.AddAssign(Value{
+		"ada":     {"lovelace": num.Uint64(2)},
+		"policy1": {"asset1": num.Uint64(3)},
+	})
+	other := Value{"policy1": {"asset1": num.Uint64(1)}}
+	v.SubAssign(other)
+
+	assert.True(t, v.Equal(Value{
+		"ada":     {"lovelace": num.Uint64(7)},
+		"policy1": {"asset1": num.Uint64(2)},
+	}))
+	assert.True(t, Equal(Add(v, other), Value{
+		"ada":     {"lovelace": num.Uint64(7)},
+		"policy1": {"asset1": num.Uint64(3)},
+	}))
+
+	// the maps of that must not be adopted by v
+	v.AddAssign(other)
+	assert.Equal(t, int64(1), other["policy1"]["asset1"].Int64())
+}
+
+func Test_ValueBuilder(t *testing.T) {
+	var b ValueBuilder
+	assert.True(t, b.Value().IsZero())
+
+	outputs := []Value{
+		CreateAdaValue(10),
+		{"policy1": {"asset1": num.Uint64(1)}},
+		ValueFromCoins(CreateAdaCoin(num.Int64(5)), Coin{
+			AssetId: FromSeparate("policy1", "asset1"),
+			Amount:  num.Uint64(2),
+		}),
+	}
+	b.Add(outputs...)
+	b.AddAsset(Coin{AssetId: FromSeparate("policy2", "asset2"), Amount: num.Uint64(4)})
+	b.Sub(Value{"policy2": {"asset2": num.Uint64(4)}})
+
+	want := Value{
+		"ada":     {"lovelace": num.Uint64(15)},
+		"policy1": {"asset1": num.Uint64(3)},
+	}
+	got := b.Value()
+	assert.Equal(t, want, got)
+
+	// mutating the total must not affect the builder
+	got.AddAssign(CreateAdaValue(1))
+	assert.Equal(t, want, b.Value())
+
+	b.Reset()
+	assert.Equal(t, Value{}, b.Value())
+	b.Add(CreateAdaValue(1))
+	assert.Equal(t, CreateAdaValue(1), b.Value())
+}
@@ -0,0 +1,105 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+)
+
+// ValueFormat selects the wire format used by Value.MarshalJSONFormat.
+//
+// The format applies only to a Value encoded directly via MarshalJSONFormat.
+// encoding/json offers no way to carry an option through nested values, so a
+// Value within a struct, e.g. a chainsync.Tx or Block, always encodes as v6.
+// To encode whole txs or blocks as v5, convert them with the v5 package e.g.
+// v5.TxFromV6
+type ValueFormat int
+
+const (
+	// ValueFormatV6 is the ogmios v6 format, e.g.
+	// {"ada":{"lovelace":1},"{policy}":{"{asset}":2}}
+	ValueFormatV6 ValueFormat = iota
+	// ValueFormatV5 is the ogmios v5 format, e.g.
+	// {"coins":1,"assets":{"{policy}.{asset}":2}}
+	ValueFormatV5
+)
+
+// valueV5 is the ogmios v5 encoding of a Value
+type valueV5 struct {
+	Coins  num.Int            `json:"coins"`
+	Assets map[string]num.Int `json:"assets,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler and accepts both the v6 and the
+// v5 wire formats
+func (v *Value) UnmarshalJSON(data []byte) error {
+	type value Value
+	var v6 value
+	err := json.Unmarshal(data, &v6)
+	if err == nil {
+		if _, ok := v6["assets"]; !ok { // assets is never a policy id
+			*v = Value(v6)
+			return nil
+		}
+	}
+
+	var v5 valueV5
+	if err5 := json.Unmarshal(data, &v5); err5 != nil {
+		if err == nil {
+			err = err5
+		}
+		return fmt.Errorf("failed to decode value: %w", err)
+	}
+	*v = v5.value()
+	return nil
+}
+
+// MarshalJSONFormat encodes the value in the given wire format.  json.Marshal
+// always uses ValueFormatV6; see ValueFormat
+func (v Value) MarshalJSONFormat(format ValueFormat) ([]byte, error) {
+	switch format {
+	case ValueFormatV6:
+		return json.Marshal(map[string]map[string]num.Int(v))
+	case ValueFormatV5:
+		return json.Marshal(newValueV5(v))
+	default:
+		return nil, fmt.Errorf("unknown value format, %v", format)
+	}
+}
+
+func newValueV5(v Value) valueV5 {
+	v5 := valueV5{Coins: v.AdaLovelace()}
+	for policyID, assets := range v {
+		for assetName, amount := range assets {
+			if policyID == AdaPolicy && assetName == AdaAsset {
+				continue
+			}
+			if v5.Assets == nil {
+				v5.Assets = map[string]num.Int{}
+			}
+			assetID := policyID
+			if assetName != "" {
+				assetID += "." + assetName
+			}
+			v5.Assets[assetID] = amount
+		}
+	}
+	return v5
+}
+
+func (v5 valueV5) value() Value {
+	v := Value{}
+	if !v5.Coins.IsZero() {
+		v[AdaPolicy] = map[string]num.Int{AdaAsset: v5.Coins}
+	}
+	for assetID, amount := range v5.Assets {
+		policyID, assetName, _ := strings.Cut(assetID, ".")
+		if v[policyID] == nil {
+			v[policyID] = map[string]num.Int{}
+		}
+		v[policyID][assetName] = amount
+	}
+	return v
+}
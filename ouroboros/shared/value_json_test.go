@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/tj/assert"
+)
+
+func TestValue_UnmarshalJSON(t *testing.T) {
+	want := Value{
+		AdaPolicy: {AdaAsset: num.Int64(1)},
+		"policy":  {"asset": num.Int64(2), "": num.Int64(3)},
+	}
+
+	formats := map[string]string{
+		"v6": `{"ada":{"lovelace":1},"policy":{"asset":2,"":3}}`,
+		"v5": `{"coins":1,"assets":{"policy.asset":2,"policy":3}}`,
+	}
+	for name, data := range formats {
+		t.Run(name, func(t *testing.T) {
+			var got Value
+			assert.Nil(t, json.Unmarshal([]byte(data), &got))
+			assert.True(t, Equal(want, got), "got %v; want %v", got, want)
+		})
+	}
+
+	t.Run("assets only", func(t *testing.T) {
+		var got Value
+		err := json.Unmarshal([]byte(`{"assets":{"policy.asset":2}}`), &got)
+		assert.Nil(t, err)
+		assert.Equal(t, num.Int64(2), got["policy"]["asset"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var got Value
+		assert.NotNil(t, json.Unmarshal([]byte(`{"coins":"bogus"}`), &got))
+	})
+}
+
+func TestValue_MarshalJSONFormat(t *testing.T) {
+	v := Value{
+		AdaPolicy: {AdaAsset: num.Int64(1)},
+		"policy":  {"asset": num.Int64(2)},
+	}
+
+	data, err := v.MarshalJSONFormat(ValueFormatV5)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"coins":1,"assets":{"policy.asset":2}}`, string(data))
+
+	var got Value
+	assert.Nil(t, json.Unmarshal(data, &got))
+	assert.True(t, Equal(v, got))
+
+	data, err = v.MarshalJSONFormat(ValueFormatV6)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"ada":{"lovelace":1},"policy":{"asset":2}}`, string(data))
+
+	_, err = v.MarshalJSONFormat(ValueFormat(-1))
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -58,6 +59,95 @@ func Test_Enough(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func Test_EnoughShortfall(t *testing.T) {
+	have := Value{
+		"ada": {
+			"lovelace": num.Uint64(5),
+		},
+		"policy1": {
+			"asset1": num.Uint64(10),
+			"asset2": num.Uint64(1),
+		},
+	}
+	want := Value{
+		"ada": {
+			"lovelace": num.Uint64(7),
+		},
+		"policy1": {
+			"asset1": num.Uint64(10),
+			"asset2": num.Uint64(3),
+		},
+		"policy2": {
+			"asset3": num.Uint64(4),
+		},
+	}
+
+	ok, err := Enough(have, want)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+
+	var shortfall *ShortfallError
+	assert.True(t, errors.As(err, &shortfall))
+	assert.Len(t, shortfall.Assets, 3)
+	assert.Equal(t, AdaAssetID, shortfall.Assets[0].AssetID)
+	assert.Equal(t, AssetID("policy1.asset2"), shortfall.Assets[1].AssetID)
+	assert.Equal(t, AssetID("policy2.asset3"), shortfall.Assets[2].AssetID)
+	assert.Equal(t, int64(2), shortfall.Assets[1].Missing().Int64())
+	assert.Equal(t, int64(0), shortfall.Assets[2].Have.Int64())
+
+	missing := shortfall.Missing()
+	assert.Equal(t, int64(2), missing.AdaLovelace().Int64())
+	assert.Equal(t, int64(2), missing["policy1"]["asset2"].Int64())
+	assert.Equal(t, int64(4), missing["policy2"]["asset3"].Int64())
+	assert.Contains(t, err.Error(), "policy2.asset3")
+
+	ok, err = Enough(want, have)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Nil(t, Shortfall(want, have))
+}
+
+func Test_ValueHelpers(t *testing.T) {
+	v := Value{
+		"ada": {
+			"lovelace": num.Uint64(5),
+		},
+		"policy1": {
+			"asset1": num.Uint64(0),
+			"asset2": num.Uint64(2),
+		},
+		"policy2": {
+			"asset3": num.Uint64(0),
+		},
+	}
+
+	assert.False(t, v.IsZero())
+	assert.True(t, Value(nil).IsZero())
+	assert.True(t, Value{"policy2": {"asset3": num.Uint64(0)}}.IsZero())
+
+	normalized := v.Normalize()
+	assert.Equal(t, Value{
+		"ada": {
+			"lovelace": num.Uint64(5),
+		},
+		"policy1": {
+			"asset2": num.Uint64(2),
+		},
+	}, normalized)
+	assert.True(t, v.Equal(normalized))
+	assert.True(t, normalized.Equal(v))
+	assert.False(t, v.Equal(CreateAdaValue(5)))
+	assert.Len(t, v["policy1"], 2, "Normalize must not modify v")
+
+	clone := v.Clone()
+	assert.True(t, clone.Equal(v))
+	clone["policy1"]["asset2"] = num.Uint64(3)
+	clone.AddAsset(CreateAdaCoin(num.Uint64(1)))
+	assert.Equal(t, int64(2), v["policy1"]["asset2"].Int64())
+	assert.Equal(t, int64(5), v.AdaLovelace().Int64())
+	assert.Nil(t, Value(nil).Clone())
+}
+
 func Test_AddAsset(t *testing.T) {
 	v1 := Value{
 		"ada": {
@@ -125,3 +215,28 @@ func Test_AddAsset(t *testing.T) {
 	)
 	assert.EqualValues(t, false, v3.IsAdaPresent())
 }
+
+func Test_LargeQuantities(t *testing.T) {
+	const policy = "da8c30857834c6ae7203935b89278c532b3995245295456f993e1d24"
+	var (
+		max   = num.Uint64(1<<64 - 1)
+		two64 = max.Add(num.Uint64(1))
+		a     = Value{policy: {"4c51": max}}
+		b     = Value{policy: {"4c51": num.Uint64(1)}}
+	)
+
+	sum := Add(a, b)
+	assert.Equal(t, "18446744073709551616", sum[policy]["4c51"].String())
+	assert.True(t, Equal(sum, Value{policy: {"4c51": two64}}))
+	assert.True(t, Equal(Subtract(sum, b), a))
+
+	ok, err := Enough(sum, a)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = Enough(a, sum)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+	assert.True(t, GreaterThanOrEqual(sum, a))
+	assert.False(t, LessThanOrEqual(sum, a))
+}
@@ -0,0 +1,231 @@
+package statequery
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/address"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// utxoEntryOverhead is the fixed number of bytes the ledger adds to the size
+// of each output when computing the minimum deposit
+const utxoEntryOverhead = 160
+
+// Bytes is a size as reported by ogmios e.g. {"bytes": 16384}
+type Bytes struct {
+	Bytes uint64 `json:"bytes"`
+}
+
+// ProtocolParameters holds the commonly used subset of the protocol parameters
+// returned by queryLedgerState/protocolParameters; decode the json returned
+// by Client.CurrentProtocolParameters
+type ProtocolParameters struct {
+	MinFeeCoefficient         uint64       `json:"minFeeCoefficient"`
+	MinFeeConstant            shared.Value `json:"minFeeConstant"`
+	MaxBlockBodySize          Bytes        `json:"maxBlockBodySize"`
+	MaxBlockHeaderSize        Bytes        `json:"maxBlockHeaderSize"`
+	MaxTransactionSize        Bytes        `json:"maxTransactionSize"`
+	MaxValueSize              Bytes        `json:"maxValueSize"`
+	StakeCredentialDeposit    shared.Value `json:"stakeCredentialDeposit"`
+	StakePoolDeposit          shared.Value `json:"stakePoolDeposit"`
+	MinUtxoDepositCoefficient uint64       `json:"minUtxoDepositCoefficient"`
+	MinUtxoDepositConstant    shared.Value `json:"minUtxoDepositConstant"`
+	CollateralPercentage      uint64       `json:"collateralPercentage"`
+	MaxCollateralInputs       uint64       `json:"maxCollateralInputs"`
+}
+
+// MinUTxO returns the minimum lovelace the output must hold, i.e.
+// minUtxoDepositConstant + (160 + size) * minUtxoDepositCoefficient where
+// size is the size of the serialized output.  Since the size depends on the
+// lovelace held, the result is the smallest amount that covers an output
+// holding that amount; the lovelace currently held by out is ignored.
+//
+// The output is sized in the post-alonzo map format.  Legacy outputs encode
+// a few bytes smaller, so the result is an upper bound for them.
+func (p ProtocolParameters) MinUTxO(out chainsync.TxOut) (num.Int, error) {
+	if p.MinUtxoDepositCoefficient == 0 {
+		return num.Int{}, errors.New(
+			"failed to compute min utxo: minUtxoDepositCoefficient not set",
+		)
+	}
+
+	size, err := txOutSize(out)
+	if err != nil {
+		return num.Int{}, fmt.Errorf("failed to compute min utxo: %w", err)
+	}
+
+	constant, ok := p.MinUtxoDepositConstant.AdaLovelace().SafeUint64()
+	if !ok {
+		return num.Int{}, errors.New(
+			"failed to compute min utxo: invalid minUtxoDepositConstant",
+		)
+	}
+
+	// size grows with the lovelace held, so iterate to the smallest fixed point
+	var coin uint64
+	for {
+		want := constant +
+			(utxoEntryOverhead+size+cborUintSize(coin))*p.MinUtxoDepositCoefficient
+		if want <= coin {
+			return num.Uint64(coin), nil
+		}
+		coin = want
+	}
+}
+
+// txOutSize returns the size in bytes of the cbor encoded output excluding
+// the lovelace, which is accounted for by the caller
+func txOutSize(out chainsync.TxOut) (uint64, error) {
+	addr, err := address.Parse(out.Address)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := uint64(2)
+	size := 1 + cborBytesSize(uint64(len(addr.Bytes())))
+
+	assets, err := multiAssetSize(out.Value)
+	if err != nil {
+		return 0, err
+	}
+	size += 1 + assets
+
+	switch {
+	case out.Datum != "":
+		datum, err := hexLen(out.Datum, "datum")
+		if err != nil {
+			return 0, err
+		}
+		entries++
+		size += 1 + 2 + 2 + cborBytesSize(datum) // [1, #6.24(bytes)]
+	case out.DatumHash != "":
+		hash, err := hexLen(out.DatumHash, "datum hash")
+		if err != nil {
+			return 0, err
+		}
+		entries++
+		size += 1 + 2 + cborBytesSize(hash) // [0, bytes]
+	}
+
+	if out.Script != nil {
+		script, err := scriptSize(out.Script)
+		if err != nil {
+			return 0, err
+		}
+		entries++
+		size += 1 + 2 + cborBytesSize(script) // #6.24(bytes)
+	}
+
+	return cborUintSize(entries) + size, nil
+}
+
+// multiAssetSize returns the size of the value excluding the lovelace; outputs
+// holding only ada encode as a bare coin
+func multiAssetSize(value shared.Value) (uint64, error) {
+	assets := value.AssetsExceptAda()
+	if len(assets) == 0 {
+		return 0, nil
+	}
+
+	size := 1 + cborUintSize(uint64(len(assets))) // [coin, multiasset]
+	for policyID, tokens := range assets {
+		policy, err := hexLen(policyID, "policy id")
+		if err != nil {
+			return 0, err
+		}
+		size += cborBytesSize(policy) + cborUintSize(uint64(len(tokens)))
+		for assetName, quantity := range tokens {
+			name, err := hexLen(assetName, "asset name")
+			if err != nil {
+				return 0, err
+			}
+			amount, ok := quantity.SafeUint64()
+			if !ok {
+				return 0, fmt.Errorf(
+					"invalid quantity of %v.%v, %v",
+					policyID,
+					assetName,
+					quantity,
+				)
+			}
+			size += cborBytesSize(name) + cborUintSize(amount)
+		}
+	}
+	return size, nil
+}
+
+// scriptSize returns the size of the cbor encoded [language, script]
+func scriptSize(script *chainsync.Script) (uint64, error) {
+	switch {
+	case script.IsNative() && script.Native != nil:
+		return 2 + nativeScriptSize(*script.Native), nil
+	case script.IsNative():
+		n, err := hexLen(script.CBOR, "script")
+		if err != nil {
+			return 0, err
+		}
+		return 2 + n, nil
+	case script.IsPlutus():
+		n, err := hexLen(script.CBOR, "script")
+		if err != nil {
+			return 0, err
+		}
+		return 2 + cborBytesSize(n), nil
+	default:
+		return 0, fmt.Errorf("unsupported script language, %v", script.Language)
+	}
+}
+
+// nativeScriptSize returns the size of the cbor encoded native script
+func nativeScriptSize(script chainsync.NativeScript) uint64 {
+	switch script.Clause {
+	case chainsync.NativeScriptSignature:
+		return 2 + cborBytesSize(28)
+	case chainsync.NativeScriptAll, chainsync.NativeScriptAny:
+		return 2 + nativeScriptsSize(script.Scripts)
+	case chainsync.NativeScriptSome:
+		return 2 + cborUintSize(script.AtLeast) + nativeScriptsSize(script.Scripts)
+	default: // before, after
+		return 2 + cborUintSize(script.Slot)
+	}
+}
+
+func nativeScriptsSize(scripts []chainsync.NativeScript) uint64 {
+	size := cborUintSize(uint64(len(scripts)))
+	for _, script := range scripts {
+		size += nativeScriptSize(script)
+	}
+	return size
+}
+
+// cborUintSize returns the size of a cbor head encoding v, which is also the
+// size of an unsigned integer
+func cborUintSize(v uint64) uint64 {
+	switch {
+	case v < 24:
+		return 1
+	case v <= 0xff:
+		return 2
+	case v <= 0xffff:
+		return 3
+	case v <= 0xffffffff:
+		return 5
+	default:
+		return 9
+	}
+}
+
+func cborBytesSize(n uint64) uint64 {
+	return cborUintSize(n) + n
+}
+
+func hexLen(s, field string) (uint64, error) {
+	if _, err := hex.DecodeString(s); err != nil {
+		return 0, fmt.Errorf("invalid %v, %v: %w", field, s, err)
+	}
+	return uint64(len(s) / 2), nil
+}
@@ -0,0 +1,129 @@
+package statequery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/tj/assert"
+)
+
+const (
+	baseAddress       = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	enterpriseAddress = "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	policyID          = "da8c30857834c6ae7203935b89278c532b3995245295456f993e1d24"
+	keyHash           = "9e1199a988ba72ffd6e9c269cadb3b53b5f360ff99f112d9b2ee30c4"
+)
+
+func TestProtocolParameters_UnmarshalJSON(t *testing.T) {
+	data := `{
+		"minFeeCoefficient": 44,
+		"minFeeConstant": {"ada": {"lovelace": 155381}},
+		"maxTransactionSize": {"bytes": 16384},
+		"maxValueSize": {"bytes": 5000},
+		"stakeCredentialDeposit": {"ada": {"lovelace": 2000000}},
+		"minUtxoDepositCoefficient": 4310,
+		"minUtxoDepositConstant": {"ada": {"lovelace": 0}},
+		"collateralPercentage": 150,
+		"maxCollateralInputs": 3
+	}`
+
+	var params ProtocolParameters
+	err := json.Unmarshal([]byte(data), &params)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 44, params.MinFeeCoefficient)
+	assert.EqualValues(t, 155381, params.MinFeeConstant.AdaLovelace().Int64())
+	assert.EqualValues(t, 16384, params.MaxTransactionSize.Bytes)
+	assert.EqualValues(t, 4310, params.MinUtxoDepositCoefficient)
+	assert.EqualValues(t, 3, params.MaxCollateralInputs)
+}
+
+func TestProtocolParameters_MinUTxO(t *testing.T) {
+	var (
+		params = ProtocolParameters{MinUtxoDepositCoefficient: 4310}
+		token  = shared.Value{policyID: {"4c51": num.Uint64(1)}}
+	)
+
+	tests := map[string]struct {
+		Out  chainsync.TxOut
+		Want int64
+	}{
+		"ada only": {
+			Out:  chainsync.TxOut{Address: baseAddress},
+			Want: (160 + 67) * 4310,
+		},
+		"enterprise": {
+			Out:  chainsync.TxOut{Address: enterpriseAddress},
+			Want: (160 + 39) * 4310,
+		},
+		"lovelace held is ignored": {
+			Out: chainsync.TxOut{
+				Address: baseAddress,
+				Value:   shared.CreateAdaValue(100_000_000_000),
+			},
+			Want: (160 + 67) * 4310,
+		},
+		"token": {
+			Out:  chainsync.TxOut{Address: baseAddress, Value: token},
+			Want: (160 + 104) * 4310,
+		},
+		"datum hash": {
+			Out: chainsync.TxOut{
+				Address:   baseAddress,
+				DatumHash: keyHash + "00000000",
+			},
+			Want: (160 + 104) * 4310,
+		},
+		"inline datum": {
+			Out:  chainsync.TxOut{Address: baseAddress, Datum: "d87980"},
+			Want: (160 + 76) * 4310,
+		},
+		"native script": {
+			Out: chainsync.TxOut{
+				Address: baseAddress,
+				Script: &chainsync.Script{
+					Language: chainsync.ScriptLanguageNative,
+					Native: &chainsync.NativeScript{
+						Clause:  chainsync.NativeScriptSignature,
+						KeyHash: keyHash,
+					},
+				},
+			},
+			Want: (160 + 106) * 4310,
+		},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			got, err := params.MinUTxO(tc.Out)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got.Int64())
+		})
+	}
+}
+
+func TestProtocolParameters_MinUTxOFixedPoint(t *testing.T) {
+	// with a coefficient of 1 the coin grows from 1 to 2 bytes, which in turn
+	// raises the minimum by 1
+	params := ProtocolParameters{MinUtxoDepositCoefficient: 1}
+	got, err := params.MinUTxO(chainsync.TxOut{Address: baseAddress})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 160+62+2, got.Int64())
+}
+
+func TestProtocolParameters_MinUTxOErrors(t *testing.T) {
+	_, err := ProtocolParameters{}.MinUTxO(chainsync.TxOut{Address: baseAddress})
+	assert.NotNil(t, err)
+
+	params := ProtocolParameters{MinUtxoDepositCoefficient: 4310}
+	_, err = params.MinUTxO(chainsync.TxOut{Address: "bogus"})
+	assert.NotNil(t, err)
+
+	_, err = params.MinUTxO(chainsync.TxOut{
+		Address: baseAddress,
+		Value:   shared.Value{"zz": {"": num.Uint64(1)}},
+	})
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package statequery
 
 import (
+	"fmt"
 	"math/big"
 )
 
@@ -17,3 +18,21 @@ type EraSeconds struct {
 type EraMilliseconds struct {
 	Milliseconds big.Int `json:"milliseconds"`
 }
+
+// PoolDistribution describes a single pool's share of the live stake
+type PoolDistribution struct {
+	Stake string `json:"stake"` // ratio, e.g. "1/4"
+	VRF   string `json:"vrf,omitempty"`
+}
+
+// Fraction parses the pool's stake ratio
+func (p PoolDistribution) Fraction() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(p.Stake)
+	if !ok {
+		return nil, fmt.Errorf("invalid stake ratio, %v", p.Stake)
+	}
+	return r, nil
+}
+
+// StakeDistribution maps bech32 pool ids to their share of the live stake
+type StakeDistribution map[string]PoolDistribution
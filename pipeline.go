@@ -0,0 +1,88 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// WithAdaptivePipeline replaces the fixed WithPipeline depth with one that
+// adapts between minDepth and maxDepth nextBlock requests in flight.  The
+// depth grows while ChainSync waits on ogmios and shrinks while blocks queue
+// behind a slow callback, or while every WithCallbackConcurrency slot is
+// busy, bounding the memory held by responses awaiting delivery.
+func WithAdaptivePipeline(minDepth, maxDepth int) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.adaptive = &adaptivePipeline{min: minDepth, max: maxDepth}
+	}
+}
+
+// adaptivePipeline tracks the nextBlock requests in flight, adjusting the
+// target depth after each response based on how long the reader waited for
+// it versus how long the callback took to accept the prior response
+type adaptivePipeline struct {
+	min      int // minimum depth
+	max      int // maximum depth
+	depth    int // target number of requests in flight
+	inflight int // requests sent and not yet answered
+}
+
+// reset returns a pipeline for a new connection along with the number of
+// requests to prime it with
+func (p *adaptivePipeline) reset() (*adaptivePipeline, int) {
+	minDepth, maxDepth := max(p.min, 1), p.max
+	maxDepth = max(maxDepth, minDepth)
+
+	next := &adaptivePipeline{
+		min:      minDepth,
+		max:      maxDepth,
+		depth:    minDepth,
+		inflight: minDepth,
+	}
+	return next, minDepth
+}
+
+// next records a nextBlock response and returns the number of additional
+// requests to send.  wait is the time spent awaiting the response, work the
+// time spent delivering the prior response, and saturated indicates whether
+// all callback slots are busy
+func (p *adaptivePipeline) next(
+	wait, work time.Duration,
+	saturated bool,
+) int {
+	if p.inflight > 0 {
+		p.inflight--
+	}
+
+	switch {
+	case saturated || work > 2*wait:
+		p.depth = max(p.depth-1, p.min) // callback bound
+	case wait > 2*work:
+		p.depth = min(p.depth+1, p.max) // network bound
+	}
+
+	n := max(p.depth-p.inflight, 0)
+	p.inflight += n
+	return n
+}
+
+// isNextBlockResponse returns true if data is a response to nextBlock
+func isNextBlockResponse(data []byte) bool {
+	method, _ := jsonparser.GetString(data, "method")
+	return method == chainsync.NextBlockMethod
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestAdaptivePipeline(t *testing.T) {
+	p, n := (&adaptivePipeline{min: 2, max: 4}).reset()
+	assert.Equal(t, 2, n)
+
+	// network bound; grows to max
+	assert.Equal(t, 2, p.next(time.Millisecond, 0, false))
+	assert.Equal(t, 2, p.next(time.Millisecond, 0, false))
+	assert.Equal(t, 1, p.next(time.Millisecond, 0, false))
+	assert.Equal(t, 4, p.depth)
+
+	// callback bound; shrinks to min
+	for range 4 {
+		p.next(0, time.Millisecond, false)
+	}
+	assert.Equal(t, 2, p.depth)
+	assert.Equal(t, 2, p.inflight)
+
+	// saturated callbacks shrink regardless of latency
+	p.depth = 3
+	p.next(time.Second, 0, true)
+	assert.Equal(t, 2, p.depth)
+
+	p, n = (&adaptivePipeline{min: 0, max: -1}).reset()
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, p.max)
+}
+
+func TestClient_ChainSyncAdaptivePipeline(t *testing.T) {
+	endpoint := chainSyncServer(t, 100)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var blocks int
+	callback := func(_ context.Context, data []byte) error {
+		if isRollForward(data) {
+			blocks++
+		}
+		return nil
+	}
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithAdaptivePipeline(1, 10),
+		WithStopWhenTipReached(),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+	assert.Equal(t, 100, blocks)
+}
@@ -0,0 +1,202 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Progress reports how far ChainSync has synced toward the tip, as reported
+// by the most recent nextBlock response
+type Progress struct {
+	Slot            uint64        // slot of the most recent block or rollback
+	Tip             uint64        // slot of the tip
+	Blocks          uint64        // blocks rolled forward since ChainSync began
+	BlocksPerSecond float64       // recent rate of blocks rolled forward
+	ETA             time.Duration // estimated time to catch the tip; 0 if unknown
+	Percent         float64       // percentage of the chain synced, 0 to 100
+}
+
+// String implements fmt.Stringer
+func (p Progress) String() string {
+	return fmt.Sprintf(
+		"slot=%v tip=%v blocks/s=%.1f eta=%v synced=%.2f%%",
+		p.Slot,
+		p.Tip,
+		p.BlocksPerSecond,
+		p.ETA.Round(time.Second),
+		p.Percent,
+	)
+}
+
+// ProgressFunc receives periodic progress reports
+type ProgressFunc func(ctx context.Context, progress Progress)
+
+type progressReport struct {
+	interval time.Duration
+	fn       ProgressFunc
+}
+
+// WithProgressReport invokes fn with the current Progress every interval
+// while ChainSync runs
+func WithProgressReport(
+	interval time.Duration,
+	fn ProgressFunc,
+) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.report = &progressReport{interval: interval, fn: fn}
+	}
+}
+
+// Progress returns the current sync progress
+func (c *ChainSync) Progress() Progress {
+	return c.meter.progress()
+}
+
+// progressSample is the minimum interval over which rates are measured
+const progressSample = time.Second
+
+// progressMeter measures sync progress; rates are exponentially weighted
+// moving averages of samples taken at least progressSample apart
+type progressMeter struct {
+	now func() time.Time
+
+	mutex         sync.Mutex
+	slot          uint64
+	tip           uint64
+	blocks        uint64
+	updated       time.Time // when the most recent response was observed
+	sampled       time.Time // when the current sample began
+	sampledSlot   uint64
+	sampledBlocks uint64
+	blockRate     float64 // blocks per second
+	slotRate      float64 // slots per second
+
+	// the tip advances a block at a time, so its rate is averaged over the
+	// whole sync rather than per sample
+	tipSince time.Time // when tipBase was observed
+	tipBase  uint64
+	tipRate  float64 // tip slots per second
+}
+
+func newProgressMeter() *progressMeter {
+	return &progressMeter{now: time.Now}
+}
+
+// observe records a nextBlock response
+func (m *progressMeter) observe(slot, tip uint64, forward bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.slot, m.tip, m.updated = slot, tip, now
+	if forward {
+		m.blocks++
+	}
+	if m.tipSince.IsZero() || tip < m.tipBase {
+		m.tipSince, m.tipBase, m.tipRate = now, tip, 0
+	} else if elapsed := now.Sub(m.tipSince); elapsed >= progressSample {
+		m.tipRate = float64(tip-m.tipBase) / elapsed.Seconds()
+	}
+	if m.sampled.IsZero() || slot < m.sampledSlot {
+		m.sample(now) // first block or rollback
+		return
+	}
+
+	elapsed := now.Sub(m.sampled)
+	if elapsed < progressSample {
+		return
+	}
+	seconds := elapsed.Seconds()
+	blockRate := float64(m.blocks-m.sampledBlocks) / seconds
+	slotRate := float64(slot-m.sampledSlot) / seconds
+	if m.blockRate == 0 && m.slotRate == 0 {
+		m.blockRate, m.slotRate = blockRate, slotRate
+	} else {
+		m.blockRate = (m.blockRate + blockRate) / 2
+		m.slotRate = (m.slotRate + slotRate) / 2
+	}
+	m.sample(now)
+}
+
+// sample assumes the caller holds the mutex
+func (m *progressMeter) sample(now time.Time) {
+	m.sampled = now
+	m.sampledSlot = m.slot
+	m.sampledBlocks = m.blocks
+}
+
+// status returns the most recent slot and tip and when they were observed
+func (m *progressMeter) status() (slot, tip uint64, updated time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.slot, m.tip, m.updated
+}
+
+func (m *progressMeter) progress() Progress {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	progress := Progress{
+		Slot:            m.slot,
+		Tip:             m.tip,
+		Blocks:          m.blocks,
+		BlocksPerSecond: m.blockRate,
+	}
+	switch {
+	case m.tip == 0:
+	case m.slot >= m.tip:
+		progress.Percent = 100
+	default:
+		progress.Percent = 100 * float64(m.slot) / float64(m.tip)
+		// the tip keeps advancing, so only the rate at which the gap closes
+		// counts toward the ETA; it is unknown should the gap not be closing
+		if rate := m.slotRate - m.tipRate; rate > 0 {
+			seconds := float64(m.tip-m.slot) / rate
+			progress.ETA = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return progress
+}
+
+// report invokes fn every interval until ctx or done is closed
+func (m *progressMeter) report(
+	ctx context.Context,
+	done <-chan struct{},
+	interval time.Duration,
+	fn ProgressFunc,
+) {
+	if interval <= 0 || fn == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			fn(ctx, m.progress())
+		}
+	}
+}
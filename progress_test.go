@@ -0,0 +1,114 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestProgressMeter(t *testing.T) {
+	var (
+		now   = time.Unix(0, 0)
+		meter = newProgressMeter()
+	)
+	meter.now = func() time.Time { return now }
+
+	assert.Equal(t, Progress{}, meter.progress())
+
+	meter.observe(100, 1100, true)
+	for i := 1; i <= 10; i++ {
+		now = now.Add(100 * time.Millisecond)
+		meter.observe(uint64(100+10*i), 1100, true)
+	}
+
+	progress := meter.progress()
+	assert.EqualValues(t, 200, progress.Slot)
+	assert.EqualValues(t, 1100, progress.Tip)
+	assert.EqualValues(t, 11, progress.Blocks)
+	assert.Equal(t, 10.0, progress.BlocksPerSecond)
+	assert.Equal(t, 9*time.Second, progress.ETA)
+	assert.InDelta(t, 18.18, progress.Percent, 0.01)
+
+	// rollbacks are not counted as blocks and restart the sample
+	meter.observe(150, 1100, false)
+	assert.EqualValues(t, 11, meter.progress().Blocks)
+
+	meter.observe(1100, 1100, true)
+	progress = meter.progress()
+	assert.Equal(t, 100.0, progress.Percent)
+	assert.Equal(t, time.Duration(0), progress.ETA)
+}
+
+func TestProgressMeter_TipGrowth(t *testing.T) {
+	var (
+		now   = time.Unix(0, 0)
+		meter = newProgressMeter()
+	)
+	meter.now = func() time.Time { return now }
+
+	// syncing at 11 slots/s while the tip grows at 1 slot/s closes the gap at
+	// 10 slots/s
+	meter.observe(100, 1100, true)
+	for i := 1; i <= 5; i++ {
+		now = now.Add(time.Second)
+		meter.observe(uint64(100+11*i), uint64(1100+i), true)
+	}
+
+	progress := meter.progress()
+	assert.EqualValues(t, 155, progress.Slot)
+	assert.EqualValues(t, 1105, progress.Tip)
+	assert.Equal(t, 95*time.Second, progress.ETA)
+
+	// the gap is not closing, so the ETA is unknown
+	now = now.Add(time.Second)
+	meter.observe(155, 1200, true)
+	assert.Equal(t, time.Duration(0), meter.progress().ETA)
+}
+
+func TestChainSync_Progress(t *testing.T) {
+	endpoint := chainSyncServer(t, 5)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	synced := make(chan Progress, 1)
+	report := func(_ context.Context, progress Progress) {
+		if progress.Percent == 100 {
+			select {
+			case synced <- progress:
+			default:
+			}
+		}
+	}
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithProgressReport(time.Millisecond, report),
+	)
+	assert.Nil(t, err)
+
+	select {
+	case progress := <-synced:
+		assert.EqualValues(t, 5, progress.Slot)
+		assert.EqualValues(t, 5, progress.Tip)
+	case <-ctx.Done():
+		t.Fatalf("got %v; want synced", chainSync.Progress())
+	}
+	assert.Nil(t, chainSync.Close())
+	assert.EqualValues(t, 5, chainSync.Progress().Blocks)
+}
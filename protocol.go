@@ -0,0 +1,168 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	v5 "github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/v5"
+	"github.com/buger/jsonparser"
+)
+
+// Protocol identifies the request encoding spoken by the ogmios server
+type Protocol int32
+
+const (
+	// ProtocolV6 is the JSON-RPC 2.0 protocol of ogmios v6.  This is the
+	// default.
+	ProtocolV6 Protocol = iota
+
+	// ProtocolV5 is the jsonwsp protocol of ogmios v5
+	ProtocolV5
+
+	// ProtocolAuto detects the protocol of the server on first use, via the
+	// health endpoint or, failing that, a probe request
+	ProtocolAuto
+)
+
+// String implements fmt.Stringer
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolV6:
+		return "v6"
+	case ProtocolV5:
+		return "v5"
+	case ProtocolAuto:
+		return "auto"
+	default:
+		return "unknown"
+	}
+}
+
+// Protocol returns the protocol spoken by the server.  With ProtocolAuto the
+// server is queried once and the result cached until the client fails over
+// to another endpoint.
+func (c *Client) Protocol(ctx context.Context) (Protocol, error) {
+	if c.options.protocol != ProtocolAuto {
+		return c.options.protocol, nil
+	}
+	if v := atomic.LoadInt32(&c.protocol); v > 0 {
+		return Protocol(v - 1), nil
+	}
+
+	protocol, err := c.detectProtocol(ctx)
+	if err != nil {
+		return ProtocolV6, err
+	}
+	c.options.logger.Info("detected ogmios protocol",
+		KV("protocol", protocol.String()),
+	)
+	atomic.StoreInt32(&c.protocol, int32(protocol)+1)
+	return protocol, nil
+}
+
+func (c *Client) detectProtocol(ctx context.Context) (Protocol, error) {
+	if health, err := c.Health(ctx); err == nil {
+		version := strings.TrimPrefix(health.Version, "v")
+		switch {
+		case strings.HasPrefix(version, "5."):
+			return ProtocolV5, nil
+		case strings.HasPrefix(version, "6."):
+			return ProtocolV6, nil
+		}
+	}
+
+	// v5 answers a JSON-RPC request with a jsonwsp fault
+	var (
+		payload = makePayload("queryNetwork/tip", Map{}, Map{})
+		raw     json.RawMessage
+	)
+	err := c.query(ctx, payload, &raw)
+	var fault Error
+	switch {
+	case errors.As(err, &fault):
+		return ProtocolV5, nil
+	case err != nil:
+		return ProtocolV6, fmt.Errorf("failed to detect ogmios protocol: %w", err)
+	}
+	if _, _, _, err := jsonparser.Get(raw, "jsonrpc"); err != nil {
+		return ProtocolV6, fmt.Errorf(
+			"failed to detect ogmios protocol: unrecognized response, %s",
+			raw,
+		)
+	}
+	return ProtocolV6, nil
+}
+
+// resetProtocol forgets the detected protocol, e.g. on failover
+func (c *Client) resetProtocol() {
+	atomic.StoreInt32(&c.protocol, 0)
+}
+
+// requestNextV5 is the v5 equivalent of a nextBlock request
+var requestNextV5 = mustMarshal(makePayloadV5(chainsync.RequestNextMethod, Map{}))
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// encodeInitV5 converts a findIntersection request built by getInit into its
+// v5 equivalent
+func encodeInitV5(init []byte) ([]byte, error) {
+	var request struct {
+		Params struct {
+			Points chainsync.Points `json:"points"`
+		} `json:"params"`
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(init, &request); err != nil {
+		return nil, fmt.Errorf("failed to decode findIntersection: %w", err)
+	}
+
+	var points []*v5.PointV5
+	for _, point := range request.Params.Points {
+		points = append(points, v5.PointFromV6(point))
+	}
+	payload := makePayloadV5(chainsync.FindIntersectMethod, Map{"points": points})
+	payload["mirror"] = request.ID
+	return json.Marshal(payload)
+}
+
+// decodeV5 converts a v5 chainsync response into its v6 equivalent so that
+// callbacks, checkpoints, and stop conditions need only understand v6
+func decodeV5(data []byte) ([]byte, error) {
+	var response v5.ResponseV5
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode v5 response: %w", err)
+	}
+	if response.Result == nil {
+		var e Error
+		if err := json.Unmarshal(data, &e); err == nil && e.Fault.Code != "" {
+			return nil, e
+		}
+		return nil, fmt.Errorf("failed to decode v5 response: %s", data)
+	}
+	return json.Marshal(response.ConvertToV6())
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+const faultV5 = `{"type":"jsonwsp/fault","version":"1.0","servicename":"ogmios","fault":{"code":"client","string":"unsupported request"}}`
+
+func TestProtocol_String(t *testing.T) {
+	assert.Equal(t, "v6", ProtocolV6.String())
+	assert.Equal(t, "v5", ProtocolV5.String())
+	assert.Equal(t, "auto", ProtocolAuto.String())
+	assert.Equal(t, "unknown", Protocol(-1).String())
+}
+
+func TestClient_Protocol(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("explicit", func(t *testing.T) {
+		client := New(WithEndpoint(deadEndpoint()), WithProtocol(ProtocolV5))
+		protocol, err := client.Protocol(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, ProtocolV5, protocol)
+	})
+
+	t.Run("v5", func(t *testing.T) {
+		endpoint, requests := scripted(t, faultV5)
+		client := New(
+			WithEndpoint(endpoint),
+			WithProtocol(ProtocolAuto),
+			WithLogger(NopLogger),
+		)
+		for range 2 {
+			protocol, err := client.Protocol(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, ProtocolV5, protocol)
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt64(requests))
+	})
+
+	t.Run("v6", func(t *testing.T) {
+		endpoint, _ := scripted(t,
+			`{"jsonrpc":"2.0","method":"queryNetwork/tip","result":"origin"}`,
+		)
+		client := New(
+			WithEndpoint(endpoint),
+			WithProtocol(ProtocolAuto),
+			WithLogger(NopLogger),
+		)
+		protocol, err := client.Protocol(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, ProtocolV6, protocol)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"version":"v5.6.0","connectionStatus":"connected"}`))
+			},
+		))
+		defer server.Close()
+
+		client := New(
+			WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+			WithProtocol(ProtocolAuto),
+			WithLogger(NopLogger),
+		)
+		protocol, err := client.Protocol(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, ProtocolV5, protocol)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		client := New(
+			WithEndpoint(deadEndpoint()),
+			WithProtocol(ProtocolAuto),
+			WithLogger(NopLogger),
+		)
+		_, err := client.Protocol(ctx)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestClient_protocolV5(t *testing.T) {
+	ctx := context.Background()
+	endpoint, _ := scripted(t,
+		faultV5,
+		`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"Query","result":{"slot":12,"hash":"abc"}}`,
+		`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"SubmitTx","result":{"SubmitSuccess":{"txId":"tx"}}}`,
+		`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"SubmitTx","result":{"SubmitFail":[{"badInputs":[]}]}}`,
+	)
+	client := New(
+		WithEndpoint(endpoint),
+		WithProtocol(ProtocolAuto),
+		WithLogger(NopLogger),
+	)
+
+	point, err := client.ChainTip(ctx)
+	assert.Nil(t, err)
+	ps, ok := point.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 12, ps.Slot)
+	assert.Equal(t, "abc", ps.ID)
+
+	response, err := client.SubmitTx(ctx, "cbor")
+	assert.Nil(t, err)
+	assert.Equal(t, "tx", response.ID)
+	assert.Nil(t, response.Error)
+
+	response, err = client.SubmitTx(ctx, "cbor")
+	assert.Nil(t, err)
+	assert.NotNil(t, response.Error)
+	assert.JSONEq(t, `[{"badInputs":[]}]`, string(response.Error.Data))
+}
+
+// chainSyncV5Server serves a v5 chainsync that rolls backward to each slot in
+// turn.  Each findIntersection request is sent to init and, if sent is not
+// nil, every response is recorded there before it is written.
+func chainSyncV5Server(t *testing.T, init chan<- Map, sent io.Writer) string {
+	upgrader := websocket.Upgrader{}
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		for slot := 1; ; slot++ {
+			var request Map
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			var response string
+			switch request["methodname"] {
+			case chainsync.FindIntersectMethod:
+				init <- request
+				response = `{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"FindIntersect","result":{"IntersectionFound":{"point":"origin","tip":{"slot":100,"hash":"tip","blockNo":3}}},"reflection":{"step":"INIT"}}`
+			case chainsync.RequestNextMethod:
+				response = `{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"RequestNext","result":{"RollBackward":{"point":{"slot":` +
+					strconv.Itoa(slot) +
+					`,"hash":"a"},"tip":{"slot":100,"hash":"tip","blockNo":3}}}}`
+			default:
+				return
+			}
+			if sent != nil {
+				_, _ = io.WriteString(sent, response+"\n")
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(response)); err != nil {
+				return
+			}
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestClient_chainSyncV5(t *testing.T) {
+	init := make(chan Map, 1)
+	endpoint := chainSyncV5Server(t, init, nil)
+
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		received    = make(chan chainsync.ResponsePraos, 16)
+	)
+	defer cancel()
+
+	client := New(
+		WithEndpoint(endpoint),
+		WithProtocol(ProtocolV5),
+		WithLogger(NopLogger),
+	)
+	var callback ChainSyncFunc = func(_ context.Context, data []byte) error {
+		var response chainsync.ResponsePraos
+		if err := json.Unmarshal(data, &response); err != nil {
+			return err
+		}
+		received <- response
+		return nil
+	}
+	chainSync, err := client.ChainSync(ctx, callback)
+	assert.Nil(t, err)
+	defer chainSync.Close()
+
+	request := <-init
+	assert.Equal(t, map[string]any{"points": []any{"origin"}}, request["args"])
+	assert.Equal(t, map[string]any{"step": "INIT"}, request["mirror"])
+
+	intersection, err := (<-received).FindIntersectResult()
+	assert.Nil(t, err)
+	assert.Equal(t, chainsync.Origin.String(), intersection.Intersection.String())
+
+	for _, want := range []uint64{2, 3} {
+		result, err := (<-received).NextBlockResult()
+		assert.Nil(t, err)
+		assert.Equal(t, chainsync.RollBackwardString, result.Direction)
+		ps, ok := result.Point.PointStruct()
+		assert.True(t, ok)
+		assert.Equal(t, want, ps.Slot)
+	}
+}
+
+func TestClient_chainSyncV5Archive(t *testing.T) {
+	var (
+		archive lockedBuffer
+		sent    lockedBuffer
+		init    = make(chan Map, 1)
+	)
+	endpoint := chainSyncV5Server(t, init, &sent)
+	client := New(
+		WithEndpoint(endpoint),
+		WithProtocol(ProtocolV5),
+		WithLogger(NopLogger),
+		WithFrameArchive(&archive),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc, WithStopAtSlot(3))
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+
+	// the archive holds the v5 frames exactly as the server sent them
+	got := strings.Split(strings.TrimSpace(archive.String()), "\n")
+	want := strings.Split(strings.TrimSpace(sent.String()), "\n")
+	assert.True(t, len(got) >= 3)
+	assert.True(t, len(got) <= len(want))
+	assert.Equal(t, want[:len(got)], got)
+	assert.Contains(t, got[0], `"methodname":"FindIntersect"`)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	response := `{"jsonrpc":"2.0","method":"queryLedgerState/epoch","result":1}`
+	endpoint, _ := scripted(t, response, response, response)
+	client := New(
+		WithEndpoint(endpoint),
+		WithLogger(NopLogger),
+		WithRateLimit(10, 2),
+	)
+	ctx := context.Background()
+
+	started := time.Now()
+	for range 3 {
+		_, err := client.Request(ctx, "queryLedgerState/epoch", nil)
+		assert.Nil(t, err)
+	}
+	// the burst admits two at once; the third waits ~100ms for a token
+	assert.True(t, time.Since(started) >= 80*time.Millisecond)
+
+	// a deadline that cannot be met fails without waiting
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := client.Request(ctx, "queryLedgerState/epoch", nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+
+	options := buildOptions(WithRateLimit(1, 0))
+	assert.Equal(t, 1, options.rateBurst)
+	assert.Nil(t, New().limiter)
+}
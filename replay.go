@@ -0,0 +1,161 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ReplaySource provides previously captured nextBlock responses to Replay
+type ReplaySource interface {
+	// Next returns the next json encoded chainsync response, or io.EOF once the
+	// source is exhausted
+	Next(ctx context.Context) ([]byte, error)
+}
+
+// ReplaySourceFunc adapts a func to ReplaySource
+type ReplaySourceFunc func(ctx context.Context) ([]byte, error)
+
+// Next implements ReplaySource
+func (fn ReplaySourceFunc) Next(ctx context.Context) ([]byte, error) {
+	return fn(ctx)
+}
+
+// NewJSONReplaySource reads newline delimited json chainsync responses, as
+// written by sink.File or WithFrameArchive
+func NewJSONReplaySource(r io.Reader) ReplaySource {
+	br := bufio.NewReader(r)
+	return ReplaySourceFunc(func(context.Context) ([]byte, error) {
+		for {
+			line, err := br.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				return line, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	})
+}
+
+// NewCBORReplaySource reads a sequence of cbor encoded nextBlock results, as
+// published by the kafka sink with kafka.FormatCBOR, re-encoding each as a json
+// nextBlock response
+func NewCBORReplaySource(r io.Reader) ReplaySource {
+	decoder := cbor.NewDecoder(r)
+	return ReplaySourceFunc(func(context.Context) ([]byte, error) {
+		var result chainsync.ResultNextBlockPraos
+		if err := decoder.Decode(&result); err != nil {
+			return nil, err
+		}
+		return json.Marshal(chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result:  result,
+		})
+	})
+}
+
+// Replay feeds captured nextBlock responses through callback without a node
+// connection, e.g. to re-run reducers offline after a logic change.  Delivery
+// matches ChainSync: interceptors, WithHeadersOnly, WithBlockCBOR, ordering,
+// WithMinSlot and the stop conditions apply, and points are saved to the store
+// every WithInterval messages and once the replay ends.  WithStopWhenTipReached
+// uses the tip as captured.  Other messages, e.g. the findIntersection
+// responses of a frame archive, are skipped.  Replay returns nil once source
+// is exhausted, or ctx.Err() if ctx is cancelled first
+func (c *Client) Replay(
+	ctx context.Context,
+	source ReplaySource,
+	callback ChainSyncFunc,
+	opts ...ChainSyncOption,
+) (err error) {
+	options := buildChainSyncOptions(opts...)
+	dispatcher := newDispatcher(wrapCallback(callback, options), options)
+
+	var delivered []byte // most recent message dispatched
+	checkpoint := func() error {
+		if err := dispatcher.barrier(); err != nil {
+			return fmt.Errorf("replay stopped: callback failed: %w", err)
+		}
+		if point, ok := getPoint(delivered); ok {
+			if err := options.store.Save(context.Background(), point); err != nil {
+				return fmt.Errorf("replay failed: %w", err)
+			}
+		}
+		return nil
+	}
+	defer func() {
+		if closeErr := dispatcher.close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("replay stopped: callback failed: %w", closeErr)
+		}
+	}()
+
+	checkSlot := options.minSlot > 0
+	for n := uint64(0); ; {
+		if ctx.Err() != nil {
+			if err := checkpoint(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+
+		data, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return checkpoint()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read replay source: %w", err)
+		}
+
+		_, slot, tip, ok := getBackfillPoint(data)
+		if !ok {
+			continue
+		}
+		if checkSlot {
+			if slot < options.minSlot {
+				continue
+			}
+			checkSlot = false
+		}
+		if options.stopAtSlot > 0 && slot > options.stopAtSlot {
+			return checkpoint()
+		}
+
+		if err := dispatcher.dispatch(ctx, data); err != nil {
+			return fmt.Errorf("replay stopped: callback failed: %w", err)
+		}
+		delivered = data
+
+		if (options.stopAtSlot > 0 && slot >= options.stopAtSlot) ||
+			(options.stopAtTip && tip > 0 && slot >= tip) {
+			return checkpoint()
+		}
+		if n++; n%c.options.saveInterval == 0 {
+			if err := checkpoint(); err != nil {
+				return err
+			}
+		}
+	}
+}
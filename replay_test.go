@@ -0,0 +1,133 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/tj/assert"
+)
+
+func replayResult(slot uint64) chainsync.ResultNextBlockPraos {
+	return chainsync.ResultNextBlockPraos{
+		Direction: chainsync.RollForwardString,
+		Tip:       &chainsync.PointStruct{ID: "block5", Slot: 5},
+		Block: &chainsync.Block{
+			Type:   "praos",
+			Era:    "babbage",
+			ID:     fmt.Sprintf("block%v", slot),
+			Height: slot,
+			Slot:   slot,
+		},
+	}
+}
+
+// replayArchive returns a frame archive holding a findIntersection response
+// followed by roll forwards to slots 1 through 5
+func replayArchive(t *testing.T) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"jsonrpc":"2.0","method":"findIntersection","result":{"intersection":"origin"}}` + "\n\n")
+	for slot := uint64(1); slot <= 5; slot++ {
+		data, err := json.Marshal(chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result:  replayResult(slot),
+		})
+		assert.Nil(t, err)
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func replaySlots(t *testing.T, source ReplaySource, opts ...ChainSyncOption) ([]uint64, *pointStore, error) {
+	var (
+		client = New(WithLogger(NopLogger))
+		store  = &pointStore{}
+		slots  []uint64
+	)
+	callback := func(_ context.Context, data []byte) error {
+		event, ok, err := chainsync.NewBlockEvent(data)
+		if ok && event.Type == chainsync.RollForwardEvent {
+			slots = append(slots, event.Block.Slot)
+		}
+		return err
+	}
+	opts = append(opts, WithStore(store))
+	err := client.Replay(context.Background(), source, callback, opts...)
+	return slots, store, err
+}
+
+func TestClient_Replay(t *testing.T) {
+	archive := replayArchive(t)
+
+	slots, store, err := replaySlots(t, NewJSONReplaySource(bytes.NewReader(archive)))
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, slots)
+	ps, ok := store.point.PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 5, ps.Slot)
+
+	slots, store, err = replaySlots(t,
+		NewJSONReplaySource(bytes.NewReader(archive)),
+		WithMinSlot(2),
+		WithStopAtSlot(4),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{2, 3, 4}, slots)
+	ps, _ = store.point.PointStruct()
+	assert.EqualValues(t, 4, ps.Slot)
+}
+
+func TestClient_ReplayCBOR(t *testing.T) {
+	var buf bytes.Buffer
+	for slot := uint64(1); slot <= 3; slot++ {
+		data, err := cbor.Marshal(replayResult(slot))
+		assert.Nil(t, err)
+		buf.Write(data)
+	}
+
+	slots, _, err := replaySlots(t, NewCBORReplaySource(&buf))
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, slots)
+}
+
+func TestClient_ReplayErrors(t *testing.T) {
+	boom := errors.New("boom")
+	var source ReplaySource = ReplaySourceFunc(func(context.Context) ([]byte, error) {
+		return nil, boom
+	})
+	_, _, err := replaySlots(t, source)
+	assert.True(t, errors.Is(err, boom))
+
+	client := New(WithLogger(NopLogger))
+	callback := func(context.Context, []byte) error { return boom }
+	source = NewJSONReplaySource(bytes.NewReader(replayArchive(t)))
+	err = client.Replay(context.Background(), source, callback)
+	assert.True(t, errors.Is(err, boom))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source = NewJSONReplaySource(bytes.NewReader(replayArchive(t)))
+	err = client.Replay(ctx, source, func(context.Context, []byte) error { return nil })
+	assert.True(t, errors.Is(err, context.Canceled))
+}
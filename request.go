@@ -0,0 +1,96 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+)
+
+// RPCError is a json-rpc error returned by ogmios in response to Request
+type RPCError struct {
+	Method  string          `json:"-"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements error interface
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("ogmios %v failed: %v (%v)", e.Method, e.Message, e.Code)
+}
+
+// Request sends an arbitrary ogmios v6 json-rpc request and returns the raw
+// result, allowing methods without a typed wrapper to be called.  Requests
+// share the connection pool, retry policy, and timeout of typed queries.
+// Params may be nil for methods that take none; a json-rpc error response is
+// returned as *RPCError
+func (c *Client) Request(
+	ctx context.Context,
+	method string,
+	params any,
+) (json.RawMessage, error) {
+	payload := Map{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"id":      Map{},
+	}
+	if params != nil {
+		payload["params"] = params
+	}
+
+	var raw json.RawMessage
+	if err := c.query(ctx, payload, &raw); err != nil {
+		return nil, fmt.Errorf("ogmios %v failed: %w", method, err)
+	}
+
+	if value, _, _, err := jsonparser.Get(raw, "error"); err == nil {
+		e := RPCError{Method: method}
+		if err := json.Unmarshal(value, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode %v error: %w", method, err)
+		}
+		return nil, &e
+	}
+
+	result, _, _, err := jsonparser.Get(raw, "result")
+	if err != nil {
+		return nil, fmt.Errorf("ogmios %v returned no result: %w", method, err)
+	}
+	return result, nil
+}
+
+// Query sends an ogmios v6 json-rpc request via Request and decodes the result
+// into T e.g.
+//
+//	height, err := ogmigo.Query[uint64](ctx, client, "queryNetwork/blockHeight", nil)
+func Query[T any](
+	ctx context.Context,
+	client *Client,
+	method string,
+	params any,
+) (T, error) {
+	var v T
+	result, err := client.Request(ctx, method, params)
+	if err != nil {
+		return v, err
+	}
+	if err := client.options.codec.Unmarshal(result, &v); err != nil {
+		return v, fmt.Errorf("failed to decode %v result: %w", method, err)
+	}
+	return v, nil
+}
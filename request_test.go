@@ -0,0 +1,86 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestClient_Request(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("result", func(t *testing.T) {
+		endpoint, _ := scripted(t,
+			`{"jsonrpc":"2.0","method":"queryNetwork/blockHeight","result":{"height":42}}`,
+		)
+		client := New(WithEndpoint(endpoint))
+
+		result, err := client.Request(ctx, "queryNetwork/blockHeight", nil)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"height":42}`, string(result))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		endpoint, _ := scripted(t,
+			`{"jsonrpc":"2.0","method":"bogus","error":{"code":-32601,"message":"method not found"}}`,
+		)
+		client := New(WithEndpoint(endpoint))
+
+		_, err := client.Request(ctx, "bogus", Map{"a": 1})
+		var rpcErr *RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, -32601, rpcErr.Code)
+		assert.Equal(t, "bogus", rpcErr.Method)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		endpoint, requests := scripted(t,
+			`{"jsonrpc":"2.0","method":"queryLedgerState/epoch","error":{"code":2003,"message":"acquired expired"}}`,
+			`{"jsonrpc":"2.0","method":"queryLedgerState/epoch","result":123}`,
+		)
+		client := New(WithEndpoint(endpoint), WithRetry(1, ConstantBackoff(0)))
+
+		result, err := client.Request(ctx, "queryLedgerState/epoch", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "123", string(result))
+		assert.EqualValues(t, 2, *requests)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		endpoint, _ = scripted(t,
+			`{"jsonrpc":"2.0","method":"queryNetwork/tip","result":{"slot":10,"id":"abc"}}`,
+			`{"jsonrpc":"2.0","method":"queryLedgerState/epoch","result":"bogus"}`,
+		)
+		client = New(WithEndpoint(endpoint))
+	)
+
+	type tip struct {
+		Slot uint64 `json:"slot"`
+		ID   string `json:"id"`
+	}
+	got, err := Query[tip](ctx, client, "queryNetwork/tip", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, tip{Slot: 10, ID: "abc"}, got)
+
+	_, err = Query[uint64](ctx, client, "queryLedgerState/epoch", nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Backoff returns the delay before the given retry attempt, starting from 1
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits the same delay before every retry
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff doubles the delay after each retry, starting at base and
+// never exceeding max
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// isRetryable returns true for transport failures and transient ogmios errors
+func isRetryable(err error) bool {
+	var (
+		acquireErr *AcquireError
+		timeoutErr *QueryTimeoutError
+		faultErr   Error
+		closeErr   *websocket.CloseError
+		opErr      *net.OpError
+	)
+	switch {
+	case errors.As(err, &acquireErr):
+		return true
+	case errors.As(err, &faultErr):
+		return isAcquireFault(faultErr)
+	case errors.As(err, &timeoutErr):
+		return true
+	case errors.As(err, &closeErr):
+		return true
+	case errors.As(err, &opErr):
+		return true
+	case errors.Is(err, websocket.ErrBadHandshake):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	default:
+		return false
+	}
+}
+
+// unsentError marks a failure that occurred before the request was written,
+// e.g. while dialing, so retrying cannot repeat the request
+type unsentError struct {
+	err error
+}
+
+func (e *unsentError) Error() string { return e.err.Error() }
+func (e *unsentError) Unwrap() error { return e.err }
+
+// unsent wraps err, if any, as an unsentError
+func unsent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &unsentError{err: err}
+}
+
+// isSubmitRetryable returns true for transient failures that occurred before
+// the transaction was written.  Once written, the node may have accepted the
+// transaction; resubmitting it would be rejected, e.g. as spending inputs
+// already spent, masking the earlier success
+func isSubmitRetryable(err error) bool {
+	var unsentErr *unsentError
+	if !errors.As(err, &unsentErr) {
+		return false
+	}
+	return errors.Is(err, ErrCircuitOpen) || isRetryable(err)
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+// scripted replies to the nth request with responses[n]; a blank response
+// drops the connection without replying
+func scripted(t *testing.T, responses ...string) (string, *int64) {
+	var (
+		upgrader = websocket.Upgrader{}
+		requests int64
+	)
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		n := atomic.AddInt64(&requests, 1) - 1
+		if int(n) >= len(responses) || responses[n] == "" {
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(responses[n]))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), &requests
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, ConstantBackoff(time.Second)(3))
+
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	assert.Equal(t, time.Second, backoff(1))
+	assert.Equal(t, 2*time.Second, backoff(2))
+	assert.Equal(t, 4*time.Second, backoff(3))
+	assert.Equal(t, 5*time.Second, backoff(4))
+	assert.Equal(t, 5*time.Second, backoff(10))
+}
+
+func TestClient_queryRetry(t *testing.T) {
+	const (
+		acquire = `{"jsonrpc":"2.0","method":"queryLedgerState/epoch","error":{"code":2003,"message":"acquired expired"}}`
+		success = `{"jsonrpc":"2.0","method":"queryLedgerState/epoch","result":123}`
+	)
+
+	t.Run("transient", func(t *testing.T) {
+		endpoint, requests := scripted(t, "", acquire, success)
+		client := New(
+			WithEndpoint(endpoint),
+			WithLogger(NopLogger),
+			WithRetry(2, ConstantBackoff(0)),
+		)
+
+		epoch, err := client.CurrentEpoch(context.Background())
+		assert.Nil(t, err)
+		assert.EqualValues(t, 123, epoch)
+		assert.EqualValues(t, 3, atomic.LoadInt64(requests))
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		endpoint, requests := scripted(t, acquire, acquire, success)
+		client := New(
+			WithEndpoint(endpoint),
+			WithLogger(NopLogger),
+			WithRetry(1, ConstantBackoff(0)),
+		)
+
+		_, err := client.CurrentEpoch(context.Background())
+		var acquireErr *AcquireError
+		if ok := errors.As(err, &acquireErr); !ok {
+			t.Fatalf("got %v; want *AcquireError", err)
+		}
+		assert.Equal(t, AcquiredExpiredCode, acquireErr.Code)
+		assert.EqualValues(t, 2, atomic.LoadInt64(requests))
+	})
+
+	t.Run("ledger rejection", func(t *testing.T) {
+		rejected := `{"jsonrpc":"2.0","method":"submitTransaction","error":{"code":3117,"message":"unknown inputs"}}`
+		endpoint, requests := scripted(t, rejected, rejected)
+		client := New(
+			WithEndpoint(endpoint),
+			WithLogger(NopLogger),
+			WithRetry(3, ConstantBackoff(0)),
+		)
+
+		resp, err := client.SubmitTx(context.Background(), "fffefdfc")
+		assert.Nil(t, err)
+		assert.Equal(t, 3117, resp.Error.Code)
+		assert.EqualValues(t, 1, atomic.LoadInt64(requests))
+	})
+
+	t.Run("submitted", func(t *testing.T) {
+		// the node accepts the tx, but the connection drops before the reply;
+		// a resubmission would be rejected as spending already spent inputs
+		spent := `{"jsonrpc":"2.0","method":"submitTransaction","error":{"code":3117,"message":"unknown inputs"}}`
+		endpoint, requests := scripted(t, "", spent)
+		client := New(
+			WithEndpoint(endpoint),
+			WithLogger(NopLogger),
+			WithRetry(3, ConstantBackoff(0)),
+		)
+
+		resp, err := client.SubmitTx(context.Background(), "fffefdfc")
+		assert.NotNil(t, err)
+		assert.Nil(t, resp)
+		assert.EqualValues(t, 1, atomic.LoadInt64(requests))
+	})
+
+	t.Run("unsent", func(t *testing.T) {
+		var (
+			upgrader = websocket.Upgrader{}
+			attempts int64
+		)
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt64(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable) // failed handshake
+				return
+			}
+			conn, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				return
+			}
+			//nolint:errcheck
+			defer conn.Close()
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(
+				`{"jsonrpc":"2.0","method":"submitTransaction","result":{"transaction":{"id":"tx"}}}`,
+			))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		defer server.Close()
+
+		client := New(
+			WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+			WithLogger(NopLogger),
+			WithRetry(3, ConstantBackoff(0)),
+		)
+
+		resp, err := client.SubmitTx(context.Background(), "fffefdfc")
+		assert.Nil(t, err)
+		assert.Equal(t, "tx", resp.ID)
+		assert.EqualValues(t, 2, atomic.LoadInt64(&attempts))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		endpoint, requests := scripted(t, "", success)
+		client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+		_, err := client.CurrentEpoch(context.Background())
+		assert.NotNil(t, err)
+		assert.EqualValues(t, 1, atomic.LoadInt64(requests))
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// starvationThreshold is how long a pooled request may wait to be sent before
+// it is counted as starved
+const starvationThreshold = 5 * time.Second
+
+// TrafficClass groups pooled requests for scheduling.  When a connection has
+// WithPipeline requests in flight, further requests are queued per class and
+// sent round-robin across classes, so a burst of heavy queries cannot delay
+// submissions indefinitely.  ChainSync and MonitorMempool use dedicated
+// connections and are never queued behind pooled requests
+type TrafficClass int
+
+const (
+	// TrafficQuery are ledger state and network queries
+	TrafficQuery TrafficClass = iota
+	// TrafficSubmission are transaction submissions and evaluations
+	TrafficSubmission
+	// TrafficOther is any request not otherwise classified
+	TrafficOther
+
+	numTrafficClasses = iota
+)
+
+// String implements fmt.Stringer
+func (t TrafficClass) String() string {
+	switch t {
+	case TrafficQuery:
+		return "query"
+	case TrafficSubmission:
+		return "submission"
+	case TrafficOther:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
+
+// TrafficStats summarizes scheduling of pooled requests for a single class
+type TrafficStats struct {
+	Class   TrafficClass  `json:"class"`
+	Queued  int           `json:"queued"`  // requests currently waiting to be sent
+	Sent    uint64        `json:"sent"`    // requests sent
+	MaxWait time.Duration `json:"maxWait"` // longest time a request waited to be sent
+	Starved uint64        `json:"starved"` // requests that waited longer than 5s
+}
+
+// classify returns the traffic class of the payload
+func classify(payload any) TrafficClass {
+	method := methodName(payload)
+	switch {
+	case strings.HasPrefix(method, "queryLedgerState"),
+		strings.HasPrefix(method, "queryNetwork"),
+		method == "Query":
+		return TrafficQuery
+	case method == "submitTransaction",
+		method == "evaluateTransaction",
+		method == "SubmitTx",
+		method == "EvaluateTx":
+		return TrafficSubmission
+	default:
+		return TrafficOther
+	}
+}
+
+// trafficStats records scheduling statistics shared by all pooled connections
+type trafficStats struct {
+	mutex sync.Mutex
+	stats [numTrafficClasses]TrafficStats
+}
+
+func newTrafficStats() *trafficStats {
+	t := &trafficStats{}
+	for i := range t.stats {
+		t.stats[i].Class = TrafficClass(i)
+	}
+	return t
+}
+
+func (t *trafficStats) queued(class TrafficClass, delta int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stats[class].Queued += delta
+}
+
+// sent records a request dequeued after waiting d; returns true if starved
+func (t *trafficStats) sent(class TrafficClass, d time.Duration) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats := &t.stats[class]
+	stats.Queued--
+	stats.Sent++
+	if d > stats.MaxWait {
+		stats.MaxWait = d
+	}
+	if d > starvationThreshold {
+		stats.Starved++
+		return true
+	}
+	return false
+}
+
+func (t *trafficStats) snapshot() []TrafficStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]TrafficStats(nil), t.stats[:]...)
+}
+
+// TrafficStats returns scheduling statistics for pooled requests, by class.
+// Returns nil unless WithConnectionPool or WithPersistentConnection is set
+func (c *Client) TrafficStats() []TrafficStats {
+	if c.session == nil {
+		return nil
+	}
+	return c.session.stats.snapshot()
+}
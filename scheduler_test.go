@@ -0,0 +1,130 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := map[string]TrafficClass{
+		"queryLedgerState/utxo":  TrafficQuery,
+		"queryNetwork/tip":       TrafficQuery,
+		"submitTransaction":      TrafficSubmission,
+		"evaluateTransaction":    TrafficSubmission,
+		"acquireLedgerState":     TrafficOther,
+		"releaseLedgerState":     TrafficOther,
+		"queryLedgerState/epoch": TrafficQuery,
+	}
+	for method, want := range testCases {
+		t.Run(method, func(t *testing.T) {
+			assert.Equal(t, want, classify(makePayload(method, Map{}, nil)))
+		})
+	}
+	assert.Equal(t, TrafficQuery, classify(makePayloadV5("Query", Map{})))
+	assert.Equal(t, TrafficSubmission, classify(makePayloadV5("SubmitTx", Map{})))
+}
+
+func TestClient_TrafficFairness(t *testing.T) {
+	var (
+		upgrader = websocket.Upgrader{}
+		mutex    sync.Mutex
+		methods  []string
+	)
+	// the server answers requests one at a time, recording the order of arrival
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		for {
+			var request struct {
+				Method string          `json:"method"`
+				ID     json.RawMessage `json:"id"`
+			}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			mutex.Lock()
+			methods = append(methods, request.Method)
+			mutex.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+			_ = conn.WriteJSON(Map{"jsonrpc": "2.0", "id": request.ID, "result": 1})
+		}
+	}))
+	defer server.Close()
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithLogger(NopLogger),
+		WithPersistentConnection(true),
+		WithPipeline(1),
+	)
+	//nolint:errcheck
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload := makePayload("queryLedgerState/utxo", Map{}, nil)
+			assert.Nil(t, client.query(ctx, payload, nil))
+		}()
+	}
+
+	// wait for the queries to back up
+	for client.TrafficStats()[TrafficQuery].Queued < 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	payload := makePayload("submitTransaction", Map{}, nil)
+	assert.Nil(t, client.query(ctx, payload, nil))
+	wg.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.Len(t, methods, 21)
+	for i, method := range methods {
+		if method == "submitTransaction" {
+			if i > 12 {
+				t.Fatalf("got submission at %v; want it ahead of queued queries", i)
+			}
+		}
+	}
+
+	stats := client.TrafficStats()
+	assert.EqualValues(t, 20, stats[TrafficQuery].Sent)
+	assert.EqualValues(t, 1, stats[TrafficSubmission].Sent)
+	assert.Equal(t, 0, stats[TrafficQuery].Queued)
+}
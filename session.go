@@ -0,0 +1,537 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/gorilla/websocket"
+)
+
+// ErrClientClosed is returned by requests made after Client.Close
+var ErrClientClosed = errors.New("ogmigo: client closed")
+
+type sessionResult struct {
+	raw json.RawMessage
+	err error
+}
+
+// sessionRequest is a request waiting to be written to a sessionConn
+type sessionRequest struct {
+	ctx     context.Context
+	class   TrafficClass
+	payload any
+	queued  time.Time
+	popped  bool       // popped is true once the writer has dequeued it
+	written chan error // written receives the result of the write
+}
+
+// sessionConn is a single websocket connection shared by many requests.
+// Responses are matched to requests via the JSON-RPC id, or the mirror for
+// v5 jsonwsp requests.  At most limit requests are in flight at once; the
+// remainder are queued by TrafficClass and written round-robin
+type sessionConn struct {
+	conn   *websocket.Conn
+	write  sync.Mutex // serializes writes to conn
+	limit  int
+	logger Logger
+	stats  *trafficStats
+	wake   chan struct{}
+
+	mutex    sync.Mutex
+	pending  map[uint64]chan sessionResult
+	queues   [numTrafficClasses][]*sessionRequest
+	inflight int
+	next     int // next class to be considered by the writer
+	done     chan struct{}
+	err      error
+
+	lastPong int64 // atomic; unix nanos of the last pong or creation
+}
+
+func newSessionConn(
+	conn *websocket.Conn,
+	options Options,
+	stats *trafficStats,
+) *sessionConn {
+	sc := &sessionConn{
+		conn:     conn,
+		limit:    options.pipeline,
+		logger:   options.logger,
+		stats:    stats,
+		wake:     make(chan struct{}, 1),
+		pending:  map[uint64]chan sessionResult{},
+		done:     make(chan struct{}),
+		lastPong: time.Now().UnixNano(),
+	}
+	conn.SetPongHandler(func(string) error {
+		atomic.StoreInt64(&sc.lastPong, time.Now().UnixNano())
+		return nil
+	})
+	go sc.readLoop(options.archive)
+	go sc.writeLoop()
+	return sc
+}
+
+// ping sends a ping, failing the connection if no pong has been received
+// within timeout
+func (sc *sessionConn) ping(timeout time.Duration) {
+	last := time.Unix(0, atomic.LoadInt64(&sc.lastPong))
+	if time.Since(last) > timeout {
+		sc.fail(&PongTimeoutError{LastPong: last, Timeout: timeout})
+		return
+	}
+
+	sc.write.Lock()
+	err := sc.conn.WriteControl(
+		websocket.PingMessage,
+		nil,
+		time.Now().Add(timeout),
+	)
+	sc.write.Unlock()
+	if err != nil {
+		sc.fail(fmt.Errorf("failed to ping ogmios: %w", err))
+	}
+}
+
+func (sc *sessionConn) readLoop(archive *frameArchive) {
+	for {
+		_, raw, err := sc.conn.ReadMessage()
+		if err != nil {
+			sc.fail(fmt.Errorf("failed to read json response: %w", err))
+			return
+		}
+		archive.write(raw)
+
+		id, ok := responseID(raw)
+		if !ok {
+			sc.logger.Warn("skipping response without request id")
+			continue
+		}
+
+		sc.mutex.Lock()
+		ch, ok := sc.pending[id]
+		delete(sc.pending, id)
+		sc.mutex.Unlock()
+
+		if !ok {
+			sc.logger.Debug("skipping response for abandoned request",
+				Uint64("id", id),
+			)
+			continue
+		}
+		ch <- sessionResult{raw: raw}
+	}
+}
+
+// writeLoop writes queued requests, round-robin across traffic classes,
+// whenever fewer than limit requests are in flight
+func (sc *sessionConn) writeLoop() {
+	for {
+		req, ok := sc.dequeue()
+		if !ok {
+			return
+		}
+
+		if err := req.ctx.Err(); err != nil {
+			req.written <- fmt.Errorf("failed to submit request: %w", err)
+			continue
+		}
+
+		sc.write.Lock()
+		deadline, _ := req.ctx.Deadline()
+		_ = sc.conn.SetWriteDeadline(deadline)
+		err := sc.conn.WriteJSON(req.payload)
+		sc.write.Unlock()
+		if err != nil {
+			err = fmt.Errorf("failed to submit request: %w", ioErr(req.ctx, err))
+			req.written <- err
+			sc.fail(err)
+			return
+		}
+		req.written <- nil
+	}
+}
+
+// dequeue blocks until a request may be written; ok is false once the
+// connection has failed
+func (sc *sessionConn) dequeue() (*sessionRequest, bool) {
+	for {
+		sc.mutex.Lock()
+		if sc.err != nil {
+			sc.mutex.Unlock()
+			return nil, false
+		}
+		if sc.inflight < sc.limit {
+			for i := 0; i < numTrafficClasses; i++ {
+				class := (sc.next + i) % numTrafficClasses
+				queue := sc.queues[class]
+				if len(queue) == 0 {
+					continue
+				}
+
+				req := queue[0]
+				sc.queues[class] = queue[1:]
+				sc.next = class + 1
+				sc.inflight++
+				req.popped = true
+				sc.mutex.Unlock()
+
+				wait := time.Since(req.queued)
+				if sc.stats.sent(req.class, wait) {
+					sc.logger.Warn("pooled request starved",
+						KV("class", req.class.String()),
+						Duration("wait", wait),
+					)
+				}
+				return req, true
+			}
+		}
+		sc.mutex.Unlock()
+
+		select {
+		case <-sc.wake:
+		case <-sc.done:
+			return nil, false
+		}
+	}
+}
+
+// signal wakes the writer
+func (sc *sessionConn) signal() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// fail closes the connection and releases all pending requests with err
+func (sc *sessionConn) fail(err error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if sc.err != nil {
+		return
+	}
+	sc.err = err
+	close(sc.done)
+	_ = sc.conn.Close()
+	for id, ch := range sc.pending {
+		ch <- sessionResult{err: err}
+		delete(sc.pending, id)
+	}
+	for class, queue := range sc.queues {
+		for _, req := range queue {
+			req.written <- err
+		}
+		sc.stats.queued(TrafficClass(class), -len(queue))
+		sc.queues[class] = nil
+	}
+}
+
+func (sc *sessionConn) alive() bool {
+	select {
+	case <-sc.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (sc *sessionConn) roundTrip(
+	ctx context.Context,
+	id uint64,
+	payload any,
+) (json.RawMessage, error) {
+	var (
+		ch  = make(chan sessionResult, 1)
+		req = &sessionRequest{
+			ctx:     ctx,
+			class:   classify(payload),
+			payload: payload,
+			queued:  time.Now(),
+			written: make(chan error, 1),
+		}
+	)
+
+	sc.mutex.Lock()
+	if sc.err != nil {
+		sc.mutex.Unlock()
+		return nil, unsent(sc.err)
+	}
+	sc.pending[id] = ch
+	sc.queues[req.class] = append(sc.queues[req.class], req)
+	sc.stats.queued(req.class, 1)
+	sc.mutex.Unlock()
+	sc.signal()
+
+	defer func() {
+		sc.mutex.Lock()
+		delete(sc.pending, id)
+		if req.popped {
+			sc.inflight--
+		} else if sc.remove(req) {
+			sc.stats.queued(req.class, -1)
+		}
+		sc.mutex.Unlock()
+		sc.signal()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to submit request: %w", ctx.Err())
+	case err := <-req.written:
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to read json response: %w", ctx.Err())
+	case result := <-ch:
+		return result.raw, result.err
+	}
+}
+
+// remove drops req from its queue; assumes the caller holds the mutex
+func (sc *sessionConn) remove(req *sessionRequest) bool {
+	queue := sc.queues[req.class]
+	for i, r := range queue {
+		if r == req {
+			sc.queues[req.class] = append(queue[:i:i], queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// healthInterval is how frequently pooled connections are pinged unless set
+// via WithKeepAlive; connections that fail to respond within two intervals are
+// replaced
+const healthInterval = 15 * time.Second
+
+// session maintains a pool of long-lived connections for state queries and
+// submissions.  Requests are dispatched round-robin and dead connections
+// are replaced
+type session struct {
+	client   *Client
+	interval time.Duration // health check interval
+	timeout  time.Duration // pong timeout; 0 for twice the interval
+	nextID   uint64        // atomic
+	next     uint64        // atomic; round-robin index
+
+	mutex   sync.Mutex
+	conns   []*sessionConn
+	dialing []chan struct{} // closed once the dial of the slot completes
+	closed  bool
+	health  chan struct{} // closed to stop the health check
+	stats   *trafficStats
+}
+
+func newSession(client *Client, size int) *session {
+	interval, timeout := healthInterval, time.Duration(0)
+	if client.options.keepAlive > 0 {
+		interval, timeout = client.options.keepAlive, client.options.keepTimeout
+	}
+	return &session{
+		client:   client,
+		interval: interval,
+		timeout:  timeout,
+		conns:    make([]*sessionConn, size),
+		dialing:  make([]chan struct{}, size),
+		stats:    newTrafficStats(),
+	}
+}
+
+// get returns the live connection in slot i, dialing a new one if required.
+// The lock is released while dialing so a stalled dial blocks neither the
+// other slots, the health check nor close; concurrent callers for the same
+// slot wait on the dial in progress
+func (s *session) get(ctx context.Context, i int) (*sessionConn, error) {
+	for {
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			return nil, unsent(ErrClientClosed)
+		}
+		if s.health == nil {
+			s.health = make(chan struct{})
+			go s.healthCheck(s.health)
+		}
+		if sc := s.conns[i]; sc != nil && sc.alive() {
+			s.mutex.Unlock()
+			return sc, nil
+		}
+		dialing := s.dialing[i]
+		if dialing == nil {
+			break // still locked
+		}
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, unsent(fmt.Errorf(
+				"failed to connect to ogmios, %v: %w",
+				s.client.endpoint(),
+				ctx.Err(),
+			))
+		case <-dialing:
+		}
+	}
+
+	dialing := make(chan struct{})
+	s.dialing[i] = dialing
+	s.mutex.Unlock()
+
+	conn, err := s.client.dial(ctx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dialing[i] = nil
+	close(dialing)
+
+	if err != nil {
+		return nil, unsent(fmt.Errorf(
+			"failed to connect to ogmios, %v: %w",
+			s.client.endpoint(),
+			err,
+		))
+	}
+	if s.closed {
+		_ = conn.Close()
+		return nil, unsent(ErrClientClosed)
+	}
+	s.conns[i] = newSessionConn(conn, s.client.options, s.stats)
+	return s.conns[i], nil
+}
+
+func (s *session) roundTrip(
+	ctx context.Context,
+	payload any,
+) (json.RawMessage, error) {
+	i := int(atomic.AddUint64(&s.next, 1) % uint64(len(s.conns)))
+	sc, err := s.get(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	id := atomic.AddUint64(&s.nextID, 1)
+	return sc.roundTrip(ctx, id, withRequestID(payload, id))
+}
+
+// healthCheck pings each live connection, failing those that stop responding
+// and replacing any that have died
+func (s *session) healthCheck(stop chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		for i := range s.conns {
+			s.mutex.Lock()
+			sc := s.conns[i]
+			s.mutex.Unlock()
+
+			switch {
+			case sc == nil:
+				continue // never used
+			case !sc.alive():
+				ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+				if _, err := s.get(ctx, i); err != nil {
+					s.client.options.logger.Error("failed to replace connection",
+						Err(err),
+					)
+				}
+				cancel()
+			default:
+				timeout := s.timeout
+				if timeout <= 0 {
+					timeout = 2 * s.interval
+				}
+				sc.ping(timeout)
+			}
+		}
+	}
+}
+
+func (s *session) close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	if s.health != nil {
+		close(s.health)
+		s.health = nil
+	}
+
+	for i, sc := range s.conns {
+		if sc == nil {
+			continue
+		}
+		s.conns[i] = nil
+
+		// the close frame is a courtesy; the connection is closed regardless
+		sc.write.Lock()
+		_ = sc.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second),
+		)
+		sc.write.Unlock()
+		sc.fail(ErrClientClosed)
+	}
+	return nil
+}
+
+// withRequestID returns a copy of the payload tagged with id; v5 jsonwsp
+// requests carry the id in the mirror which ogmios returns as the reflection
+func withRequestID(payload any, id uint64) any {
+	m, ok := payload.(Map)
+	if !ok {
+		return payload
+	}
+
+	tagged := maps.Clone(m)
+	if _, ok := m["methodname"]; ok {
+		tagged["mirror"] = id
+	} else {
+		tagged["id"] = id
+	}
+	return tagged
+}
+
+// responseID returns the request id of a response produced by withRequestID
+func responseID(raw []byte) (uint64, bool) {
+	for _, key := range []string{"id", "reflection"} {
+		if id, err := jsonparser.GetInt(raw, key); err == nil && id > 0 {
+			return uint64(id), true
+		}
+	}
+	return 0, false
+}
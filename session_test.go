@@ -0,0 +1,331 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tj/assert"
+)
+
+// multiplexServer replies to each request concurrently, in reverse order of
+// arrival, echoing params.n as the result.  After limit requests on a
+// connection, the connection is dropped; 0 for no limit
+func multiplexServer(t *testing.T, limit int) (string, *int64) {
+	var (
+		upgrader    = websocket.Upgrader{}
+		connections int64
+	)
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+		atomic.AddInt64(&connections, 1)
+
+		var (
+			wg    sync.WaitGroup
+			write sync.Mutex
+		)
+		defer wg.Wait()
+
+		for n := 1; ; n++ {
+			var request struct {
+				Method string          `json:"method"`
+				Params struct{ N int } `json:"params"`
+				Args   struct{ N int } `json:"args"`
+				ID     json.RawMessage `json:"id"`
+				Mirror json.RawMessage `json:"mirror"`
+			}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				time.Sleep(time.Duration(10-n%10) * time.Millisecond)
+
+				response := Map{"jsonrpc": "2.0", "id": request.ID, "result": request.Params.N}
+				if request.Mirror != nil {
+					response = Map{"type": "jsonwsp/response", "reflection": request.Mirror, "result": request.Args.N}
+				}
+
+				write.Lock()
+				defer write.Unlock()
+				_ = conn.WriteJSON(response)
+			}(n)
+
+			if limit > 0 && n >= limit {
+				wg.Wait()
+				return
+			}
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), &connections
+}
+
+func TestClient_persistentConnection(t *testing.T) {
+	endpoint, connections := multiplexServer(t, 0)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithPersistentConnection(true))
+	//nolint:errcheck
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var (
+				payload = makePayload("queryLedgerState/epoch", Map{"n": i}, nil)
+				content struct{ Result int }
+			)
+			if i%2 == 0 {
+				payload = makePayloadV5("Query", Map{"n": i})
+			}
+			err := client.query(ctx, payload, &content)
+			assert.Nil(t, err)
+			assert.Equal(t, i, content.Result)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt64(connections))
+}
+
+func TestClient_persistentConnectionReplaced(t *testing.T) {
+	endpoint, connections := multiplexServer(t, 1)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithPersistentConnection(true))
+	//nolint:errcheck
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for i := 1; i <= 3; i++ {
+		var content struct{ Result int }
+		payload := makePayload("queryLedgerState/epoch", Map{"n": i}, nil)
+
+		// the server drops the connection after each request, so allow the
+		// client to observe the failure before the next request
+		var err error
+		for attempt := 0; attempt < 10; attempt++ {
+			if err = client.query(ctx, payload, &content); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, i, content.Result)
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt64(connections))
+
+	assert.Nil(t, client.Close())
+	err := client.query(ctx, makePayload("queryLedgerState/epoch", Map{}, nil), nil)
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("got %v; want %v", err, ErrClientClosed)
+	}
+}
+
+func TestClient_persistentConnectionTimeout(t *testing.T) {
+	server := httptest.NewServer(timeout(time.Minute))
+	defer server.Close()
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithLogger(NopLogger),
+		WithPersistentConnection(true),
+	)
+	//nolint:errcheck
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CurrentEpoch(ctx)
+	if ok := errors.Is(err, context.DeadlineExceeded); !ok {
+		t.Fatalf("got %v; want context.DeadlineExceeded", err)
+	}
+}
+
+func TestClient_connectionPool(t *testing.T) {
+	endpoint, connections := multiplexServer(t, 0)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithConnectionPool(4))
+	//nolint:errcheck
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var content struct{ Result int }
+			payload := makePayload("queryLedgerState/epoch", Map{"n": i}, nil)
+			err := client.query(ctx, payload, &content)
+			assert.Nil(t, err)
+			assert.Equal(t, i, content.Result)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 4, atomic.LoadInt64(connections))
+}
+
+func TestClient_connectionPoolHealthCheck(t *testing.T) {
+	var (
+		upgrader    = websocket.Upgrader{}
+		connections int64
+	)
+	// the server answers the first request then stops reading, so pings go
+	// unanswered
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+		atomic.AddInt64(&connections, 1)
+
+		var request struct{ ID json.RawMessage }
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+		_ = conn.WriteJSON(Map{"jsonrpc": "2.0", "id": request.ID, "result": 1})
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithLogger(NopLogger),
+		WithConnectionPool(1),
+	)
+	//nolint:errcheck
+	defer client.Close()
+	client.session.interval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := client.CurrentEpoch(ctx)
+	assert.Nil(t, err)
+
+	for atomic.LoadInt64(&connections) < 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("got %v connections; want 2", atomic.LoadInt64(&connections))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestClient_connectionPoolStalledDial(t *testing.T) {
+	var (
+		upgrader = websocket.Upgrader{}
+		dials    int64
+		stalled  = make(chan struct{})
+		release  = make(chan struct{})
+	)
+	// the first handshake stalls until released; the rest answer each request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt64(&dials, 1) == 1 {
+			close(stalled)
+			select {
+			case <-release:
+			case <-req.Context().Done():
+			}
+		}
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		for {
+			var request struct{ ID json.RawMessage }
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			_ = conn.WriteJSON(Map{"jsonrpc": "2.0", "id": request.ID, "result": 1})
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithLogger(NopLogger),
+		WithConnectionPool(2),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stuck := make(chan error, 1)
+	go func() {
+		stuck <- client.query(ctx, makePayload("queryLedgerState/epoch", Map{}, nil), nil)
+	}()
+	<-stalled
+
+	// the other slot is unaffected by the stalled dial
+	timeout, cancelTimeout := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelTimeout()
+	var content struct{ Result int }
+	err := client.query(timeout, makePayload("queryLedgerState/epoch", Map{}, nil), &content)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, content.Result)
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+	select {
+	case err := <-closed:
+		assert.Nil(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("close blocked by stalled dial")
+	}
+
+	// once the stalled dial completes, the connection is discarded
+	release <- struct{}{}
+	err = <-stuck
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("got %v; want %v", err, ErrClientClosed)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type file struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+// File writes newline delimited json to one file per key within a directory.
+// Files are appended to so a resumed backfill continues where it left off
+type File struct {
+	mutex sync.Mutex
+	dir   string
+	files map[string]*file
+}
+
+// NewFile returns a Sink that writes to dir, creating it if required
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sink dir, %v: %w", dir, err)
+	}
+	return &File{
+		dir:   dir,
+		files: map[string]*file{},
+	}, nil
+}
+
+// Write implements Sink
+func (f *File) Write(_ context.Context, key string, data []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	v, ok := f.files[key]
+	if !ok {
+		path := filepath.Join(f.dir, filepath.Base(key)+".jsonl")
+		fh, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open sink file, %v: %w", path, err)
+		}
+		v = &file{f: fh, w: bufio.NewWriter(fh)}
+		f.files[key] = v
+	}
+
+	if _, err := v.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write to sink file: %w", err)
+	}
+	if err := v.w.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to write to sink file: %w", err)
+	}
+	return nil
+}
+
+// Flush implements Sink; buffered data is written and synced to disk
+func (f *File) Flush(_ context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.flush()
+}
+
+func (f *File) flush() error {
+	for key, v := range f.files {
+		if err := v.w.Flush(); err != nil {
+			return fmt.Errorf("failed to flush sink file, %v: %w", key, err)
+		}
+		if err := v.f.Sync(); err != nil {
+			return fmt.Errorf("failed to sync sink file, %v: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink
+func (f *File) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	err := f.flush()
+	for key, v := range f.files {
+		err = errors.Join(err, v.f.Close())
+		delete(f.files, key)
+	}
+	return err
+}
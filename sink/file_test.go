@@ -0,0 +1,64 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestFile(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+	)
+
+	s, err := NewFile(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Write(ctx, "0", []byte(`{"a":1}`)))
+	assert.Nil(t, s.Flush(ctx))
+	assert.Nil(t, s.Close())
+
+	// reopening appends rather than truncates
+	s, err = NewFile(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Write(ctx, "0", []byte(`{"a":2}`)))
+	assert.Nil(t, s.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "0.jsonl"))
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(data))
+}
+
+func TestSlot(t *testing.T) {
+	slot, ok := Slot([]byte(`{"result":{"block":{"slot":123}}}`))
+	assert.True(t, ok)
+	assert.EqualValues(t, 123, slot)
+
+	slot, ok = Slot([]byte(`{"result":{"point":{"slot":456,"id":"abc"}}}`))
+	assert.True(t, ok)
+	assert.EqualValues(t, 456, slot)
+
+	slot, ok = Slot([]byte(`{"result":{"point":"origin"}}`))
+	assert.True(t, ok)
+	assert.EqualValues(t, 0, slot)
+
+	_, ok = Slot([]byte(`{"result":{}}`))
+	assert.False(t, ok)
+}
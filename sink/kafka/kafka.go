@@ -0,0 +1,325 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kafka publishes chainsync events to a Kafka topic.  The package is
+// client agnostic; callers adapt the Kafka client of their choice to Producer
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Message is a single record destined for a Kafka topic
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// Producer publishes messages to Kafka.  Produce must not return until every
+// message has been acknowledged by the broker e.g. a kafka-go Writer with
+// RequiredAcks set to RequireAll
+type Producer interface {
+	Produce(ctx context.Context, messages ...Message) error
+}
+
+// ProducerFunc adapts a func to Producer
+type ProducerFunc func(ctx context.Context, messages ...Message) error
+
+// Produce implements Producer
+func (fn ProducerFunc) Produce(ctx context.Context, messages ...Message) error {
+	return fn(ctx, messages...)
+}
+
+// KeyMode determines the key of each message
+type KeyMode int
+
+const (
+	// KeyBlockHash keys messages by the id of the block rolled forward to, or
+	// of the point rolled backward to.  This is the default.
+	KeyBlockHash KeyMode = iota
+	// KeySlot keys messages by the decimal slot of the block or point
+	KeySlot
+)
+
+// Format determines the encoding of each message value
+type Format int
+
+const (
+	// FormatJSON publishes the ogmios nextBlock response as received.  This is
+	// the default.
+	FormatJSON Format = iota
+	// FormatCBOR publishes the nextBlock result encoded as CBOR
+	FormatCBOR
+)
+
+// Options configures the Kafka sink
+type Options struct {
+	key       KeyMode
+	format    Format
+	batchSize int
+	store     ogmigo.Store
+}
+
+// Option provides functional options for the Kafka sink
+type Option func(*Options)
+
+// WithKey sets how message keys are derived; defaults to KeyBlockHash
+func WithKey(mode KeyMode) Option {
+	return func(opts *Options) {
+		opts.key = mode
+	}
+}
+
+// WithFormat sets the message encoding; defaults to FormatJSON
+func WithFormat(format Format) Option {
+	return func(opts *Options) {
+		opts.format = format
+	}
+}
+
+// WithBatchSize sets the number of messages buffered before they are
+// produced; defaults to 1
+func WithBatchSize(n int) Option {
+	return func(opts *Options) {
+		opts.batchSize = n
+	}
+}
+
+// WithStore checkpoints the point of the last acknowledged message to store.
+// The store should not also be passed to ChainSync, which would otherwise
+// save points ahead of the producer acks
+func WithStore(store ogmigo.Store) Option {
+	return func(opts *Options) {
+		opts.store = store
+	}
+}
+
+type pending struct {
+	message Message
+	point   chainsync.Point
+}
+
+// Sink publishes chainsync events to a Kafka topic.  Messages are buffered
+// until the batch is full or the sync reaches the tip, then produced; the
+// checkpoint advances only once the producer acknowledges the batch, so
+// delivery is at least once.  Sink also implements sink.Sink so it may be
+// used with Backfill.
+type Sink struct {
+	mutex    sync.Mutex
+	producer Producer
+	topic    string
+	options  Options
+	pending  []pending
+}
+
+// New returns a Sink that publishes to topic
+func New(producer Producer, topic string, opts ...Option) *Sink {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.batchSize <= 0 {
+		options.batchSize = 1
+	}
+	return &Sink{
+		producer: producer,
+		topic:    topic,
+		options:  options,
+	}
+}
+
+// Points returns the checkpointed points, suitable for ogmigo.WithPoints, or
+// nil if no store was provided
+func (s *Sink) Points(ctx context.Context) (chainsync.Points, error) {
+	if s.options.store == nil {
+		return nil, nil
+	}
+	points, err := s.options.store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kafka sink points: %w", err)
+	}
+	return points, nil
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that publishes
+// each nextBlock response
+func (s *Sink) ChainSyncFunc() ogmigo.ChainSyncFunc {
+	return func(ctx context.Context, data []byte) error {
+		return s.Write(ctx, "", data)
+	}
+}
+
+// Write implements sink.Sink; messages other than nextBlock responses are
+// ignored as is the shard key
+func (s *Sink) Write(ctx context.Context, _ string, data []byte) error {
+	e, ok := parseEvent(data)
+	if !ok {
+		return nil
+	}
+
+	value, err := s.encode(data)
+	if err != nil {
+		return err
+	}
+
+	key := e.id
+	if s.options.key == KeySlot {
+		key = strconv.FormatUint(e.slot, 10)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = append(s.pending, pending{
+		message: Message{
+			Topic: s.topic,
+			Key:   []byte(key),
+			Value: value,
+		},
+		point: e.point,
+	})
+	atTip := e.tip > 0 && e.slot >= e.tip
+	if atTip || len(s.pending) >= s.options.batchSize {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// Flush implements sink.Sink; buffered messages are produced and, once
+// acknowledged, the checkpoint is saved
+func (s *Sink) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush(ctx)
+}
+
+func (s *Sink) flush(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	messages := make([]Message, 0, len(s.pending))
+	for _, p := range s.pending {
+		messages = append(messages, p.message)
+	}
+	// pending messages are retained on failure so a later flush retries them
+	if err := s.producer.Produce(ctx, messages...); err != nil {
+		return fmt.Errorf(
+			"failed to produce %v kafka messages: %w",
+			len(messages),
+			err,
+		)
+	}
+
+	point := s.pending[len(s.pending)-1].point
+	s.pending = s.pending[:0]
+	if s.options.store != nil {
+		if err := s.options.store.Save(ctx, point); err != nil {
+			return fmt.Errorf("failed to save kafka sink checkpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close implements sink.Sink; the producer is not closed
+func (s *Sink) Close() error {
+	return s.Flush(context.Background())
+}
+
+func (s *Sink) encode(data []byte) ([]byte, error) {
+	if s.options.format != FormatCBOR {
+		return append([]byte(nil), data...), nil
+	}
+
+	raw, _, _, err := jsonparser.Get(data, "result")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nextBlock result: %w", err)
+	}
+	var result chainsync.ResultNextBlockPraos
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode nextBlock result: %w", err)
+	}
+	value, err := cbor.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode nextBlock result: %w", err)
+	}
+	return value, nil
+}
+
+type event struct {
+	point chainsync.Point
+	id    string
+	slot  uint64
+	tip   uint64
+}
+
+// parseEvent extracts the point of a nextBlock response along with the slot
+// of the current tip
+func parseEvent(data []byte) (event, bool) {
+	method, _ := jsonparser.GetString(data, "method")
+	if method != chainsync.NextBlockMethod {
+		return event{}, false
+	}
+
+	tip, _ := jsonparser.GetInt(data, "result", "tip", "slot")
+	direction, _ := jsonparser.GetString(data, "result", "direction")
+	switch direction {
+	case chainsync.RollForwardString:
+		slot, err := jsonparser.GetInt(data, "result", "block", "slot")
+		if err != nil {
+			return event{}, false
+		}
+		id, _ := jsonparser.GetString(data, "result", "block", "id")
+		return event{
+			point: chainsync.PointStruct{ID: id, Slot: uint64(slot)}.Point(),
+			id:    id,
+			slot:  uint64(slot),
+			tip:   uint64(tip),
+		}, true
+
+	case chainsync.RollBackwardString:
+		raw, dataType, _, err := jsonparser.Get(data, "result", "point")
+		if err != nil {
+			return event{}, false
+		}
+		if dataType == jsonparser.String {
+			return event{
+				point: chainsync.Origin,
+				id:    chainsync.Origin.String(),
+				tip:   uint64(tip),
+			}, true
+		}
+		var point chainsync.Point
+		if err := json.Unmarshal(raw, &point); err != nil {
+			return event{}, false
+		}
+		e := event{point: point, tip: uint64(tip)}
+		if ps, ok := point.PointStruct(); ok {
+			e.id, e.slot = ps.ID, ps.Slot
+		}
+		return e, true
+	}
+	return event{}, false
+}
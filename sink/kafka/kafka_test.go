@@ -0,0 +1,157 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/tj/assert"
+)
+
+func forward(t *testing.T, slot, tip uint64) []byte {
+	data, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollForwardString,
+			Tip:       &chainsync.PointStruct{Slot: tip, ID: "tip"},
+			Block:     &chainsync.Block{Slot: slot, ID: "block"},
+		},
+	})
+	assert.Nil(t, err)
+	return data
+}
+
+func backward(t *testing.T, point chainsync.Point) []byte {
+	data, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Tip:       &chainsync.PointStruct{Slot: 100, ID: "tip"},
+			Point:     &point,
+		},
+	})
+	assert.Nil(t, err)
+	return data
+}
+
+type recorder struct {
+	batches [][]Message
+	err     error
+}
+
+func (r *recorder) Produce(_ context.Context, messages ...Message) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.batches = append(r.batches, messages)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		producer = &recorder{}
+		s        = New(producer, "blocks")
+		fn       = s.ChainSyncFunc()
+	)
+
+	intersection := []byte(`{"jsonrpc":"2.0","method":"findIntersection"}`)
+	assert.Nil(t, fn(ctx, intersection))
+	assert.Len(t, producer.batches, 0)
+
+	data := forward(t, 1, 100)
+	assert.Nil(t, fn(ctx, data))
+	assert.Len(t, producer.batches, 1)
+	assert.Equal(t, "blocks", producer.batches[0][0].Topic)
+	assert.Equal(t, "block", string(producer.batches[0][0].Key))
+	assert.Equal(t, data, producer.batches[0][0].Value)
+
+	point := chainsync.PointStruct{Slot: 5, ID: "abc"}.Point()
+	assert.Nil(t, fn(ctx, backward(t, point)))
+	assert.Len(t, producer.batches, 2)
+	assert.Equal(t, "abc", string(producer.batches[1][0].Key))
+
+	assert.Nil(t, fn(ctx, backward(t, chainsync.Origin)))
+	assert.Equal(t, "origin", string(producer.batches[2][0].Key))
+}
+
+func TestSink_Checkpoint(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		producer = &recorder{}
+		store    = ogmigo.NewKVStore(kv.NewMemory(), "points")
+		s        = New(producer, "blocks",
+			WithKey(KeySlot),
+			WithBatchSize(2),
+			WithStore(store),
+		)
+	)
+
+	assert.Nil(t, s.Write(ctx, "", forward(t, 1, 100)))
+	points, err := s.Points(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, points, 0)
+
+	// checkpoint waits for the producer to ack the batch
+	producer.err = errors.New("boom")
+	err = s.Write(ctx, "", forward(t, 2, 100))
+	assert.True(t, errors.Is(err, producer.err))
+	points, err = s.Points(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, points, 0)
+
+	producer.err = nil
+	assert.Nil(t, s.Flush(ctx))
+	assert.Len(t, producer.batches, 1)
+	assert.Len(t, producer.batches[0], 2)
+	assert.Equal(t, "1", string(producer.batches[0][0].Key))
+	assert.Equal(t, "2", string(producer.batches[0][1].Key))
+
+	points, err = s.Points(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, points, 1)
+	ps, ok := points[0].PointStruct()
+	assert.True(t, ok)
+	assert.EqualValues(t, 2, ps.Slot)
+
+	// reaching the tip flushes a partial batch
+	assert.Nil(t, s.Write(ctx, "", forward(t, 100, 100)))
+	assert.Len(t, producer.batches, 2)
+
+	assert.Nil(t, s.Write(ctx, "", forward(t, 101, 200)))
+	assert.Nil(t, s.Close())
+	assert.Len(t, producer.batches, 3)
+}
+
+func TestSink_CBOR(t *testing.T) {
+	producer := &recorder{}
+	s := New(producer, "blocks", WithFormat(FormatCBOR))
+	assert.Nil(t, s.Write(context.Background(), "", forward(t, 7, 100)))
+
+	var result chainsync.ResultNextBlockPraos
+	err := cbor.Unmarshal(producer.batches[0][0].Value, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, chainsync.RollForwardString, result.Direction)
+	assert.EqualValues(t, 7, result.Block.Slot)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink provides destinations for json encoded chainsync responses
+package sink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// Sink receives json encoded chainsync responses grouped by key, e.g. the
+// backfill shard id
+type Sink interface {
+	// Write the message; the write need not be durable until Flush returns
+	Write(ctx context.Context, key string, data []byte) error
+	// Flush makes all prior writes durable
+	Flush(ctx context.Context) error
+	// Close flushes and releases any resources held by the sink
+	Close() error
+}
+
+// Slot returns the slot of the block rolled forward to, or of the point
+// rolled backward to, by a nextBlock response
+func Slot(data []byte) (uint64, bool) {
+	if slot, err := jsonparser.GetInt(data, "result", "block", "slot"); err == nil {
+		return uint64(slot), true
+	}
+
+	raw, dataType, _, err := jsonparser.Get(data, "result", "point")
+	if err != nil {
+		return 0, false
+	}
+	if dataType == jsonparser.String {
+		return 0, true // origin
+	}
+	var point chainsync.Point
+	if err := json.Unmarshal(raw, &point); err != nil {
+		return 0, false
+	}
+	if ps, ok := point.PointStruct(); ok {
+		return ps.Slot, true
+	}
+	return 0, false
+}
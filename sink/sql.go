@@ -0,0 +1,153 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+type row struct {
+	key  string
+	slot uint64
+	data []byte
+}
+
+// SQLOptions configures the SQL sink
+type SQLOptions struct {
+	placeholder func(i int) string
+}
+
+// SQLOption provides functional options for the SQL sink
+type SQLOption func(*SQLOptions)
+
+// WithPlaceholder sets the bind parameter style; defaults to postgres style
+// $1, $2, ...
+func WithPlaceholder(fn func(i int) string) SQLOption {
+	return func(opts *SQLOptions) {
+		opts.placeholder = fn
+	}
+}
+
+// SQL writes messages as rows of (shard, slot, data) to a database table.
+// Rows are buffered and inserted within a single transaction on Flush.  SQL
+// is driver agnostic; callers register and open the driver of their choice
+type SQL struct {
+	mutex   sync.Mutex
+	db      *sql.DB
+	table   string
+	insert  string
+	pending []row
+}
+
+// NewSQL returns a Sink that writes to table
+func NewSQL(db *sql.DB, table string, opts ...SQLOption) *SQL {
+	options := SQLOptions{
+		placeholder: func(i int) string { return fmt.Sprintf("$%d", i) },
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	insert := fmt.Sprintf(
+		"INSERT INTO %v (shard, slot, data) VALUES (%v, %v, %v)",
+		table,
+		options.placeholder(1),
+		options.placeholder(2),
+		options.placeholder(3),
+	)
+	return &SQL{
+		db:     db,
+		table:  table,
+		insert: insert,
+	}
+}
+
+// CreateTable creates the sink table if it does not already exist
+func (s *SQL) CreateTable(ctx context.Context) error {
+	stmt := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %v (shard TEXT NOT NULL, slot BIGINT NOT NULL, data TEXT NOT NULL)",
+		s.table,
+	)
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to create table, %v: %w", s.table, err)
+	}
+	return nil
+}
+
+// Write implements Sink
+func (s *SQL) Write(_ context.Context, key string, data []byte) error {
+	slot, _ := Slot(data)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = append(s.pending, row{
+		key:  key,
+		slot: slot,
+		data: append([]byte(nil), data...),
+	})
+	return nil
+}
+
+// Flush implements Sink
+func (s *SQL) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush(ctx)
+}
+
+func (s *SQL) flush(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, s.insert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	//nolint:errcheck
+	defer stmt.Close()
+
+	for _, r := range s.pending {
+		if _, err := stmt.ExecContext(ctx, r.key, r.slot, string(r.data)); err != nil {
+			return fmt.Errorf("failed to insert row: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// Close implements Sink; the underlying *sql.DB is not closed
+func (s *SQL) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush(context.Background())
+}
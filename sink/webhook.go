@@ -0,0 +1,318 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body when
+// WithSecret is specified
+const SignatureHeader = "X-Ogmigo-Signature"
+
+// Webhook event types
+const (
+	EventBlock    = "block"
+	EventTx       = "tx"
+	EventRollback = "rollback"
+)
+
+// WebhookEvent is the json body POSTed by Webhook
+type WebhookEvent struct {
+	Type    string           `json:"type"`
+	Slot    uint64           `json:"slot"`
+	BlockID string           `json:"blockId,omitempty"`
+	Block   *chainsync.Block `json:"block,omitempty"` // EventBlock
+	Tx      *chainsync.Tx    `json:"tx,omitempty"`    // EventTx
+	Point   *chainsync.Point `json:"point,omitempty"` // EventRollback
+}
+
+// DeadLetterFunc receives an event that could not be delivered.  Returning
+// nil skips the event; returning an error stops the sync
+type DeadLetterFunc func(ctx context.Context, body []byte, err error) error
+
+// WebhookOptions configures the Webhook sink
+type WebhookOptions struct {
+	client     *http.Client
+	secret     []byte
+	retries    int
+	backoff    ogmigo.Backoff
+	deadLetter DeadLetterFunc
+	filter     func(tx chainsync.Tx) bool
+}
+
+// WebhookOption provides functional options for the Webhook sink
+type WebhookOption func(*WebhookOptions)
+
+// WithHTTPClient sets the client used to POST events; defaults to a client
+// with a 30s timeout
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.client = client
+	}
+}
+
+// WithSecret signs each request body with HMAC-SHA256 using secret
+func WithSecret(secret []byte) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.secret = secret
+	}
+}
+
+// WithRetries sets the number of retries after a failed POST; defaults to 3
+func WithRetries(n int) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.retries = n
+	}
+}
+
+// WithBackoff sets the delay between retries; defaults to exponential backoff
+// from 1s to 30s
+func WithBackoff(backoff ogmigo.Backoff) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.backoff = backoff
+	}
+}
+
+// WithDeadLetter receives events that could not be delivered once retries are
+// exhausted; without it, the undelivered event fails the write.  Events
+// interrupted by ctx being cancelled are never dead lettered; the write fails
+// instead so the event is redelivered on restart
+func WithDeadLetter(fn DeadLetterFunc) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.deadLetter = fn
+	}
+}
+
+// WithTxFilter POSTs each transaction matching fn rather than each block
+func WithTxFilter(fn func(tx chainsync.Tx) bool) WebhookOption {
+	return func(opts *WebhookOptions) {
+		opts.filter = fn
+	}
+}
+
+// WebhookError is returned when the endpoint responds with a non-2xx status
+type WebhookError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements error
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf(
+		"webhook responded with status %v: %v",
+		e.StatusCode,
+		e.Body,
+	)
+}
+
+// retryable returns true for rate limiting and server errors
+func (e *WebhookError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Webhook POSTs each block, or each filtered transaction, as json to an http
+// endpoint.  Rollbacks are POSTed as well so consumers can discard orphaned
+// events.  Each event is delivered before Write returns
+type Webhook struct {
+	endpoint string
+	options  WebhookOptions
+}
+
+// NewWebhook returns a Sink that POSTs events to endpoint
+func NewWebhook(endpoint string, opts ...WebhookOption) *Webhook {
+	options := WebhookOptions{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		retries: 3,
+		backoff: ogmigo.ExponentialBackoff(time.Second, 30*time.Second),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Webhook{
+		endpoint: endpoint,
+		options:  options,
+	}
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that POSTs each
+// nextBlock response
+func (w *Webhook) ChainSyncFunc() ogmigo.ChainSyncFunc {
+	return func(ctx context.Context, data []byte) error {
+		return w.Write(ctx, "", data)
+	}
+}
+
+// Write implements Sink; messages other than nextBlock responses are ignored
+// as is the key
+func (w *Webhook) Write(ctx context.Context, _ string, data []byte) error {
+	events, err := w.events(data)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook event: %w", err)
+		}
+		if err := w.deliver(ctx, body); err != nil {
+			if w.options.deadLetter == nil || ctx.Err() != nil {
+				return err // cancelled events are still deliverable; don't dead letter
+			}
+			if err := w.options.deadLetter(ctx, body, err); err != nil {
+				return fmt.Errorf("webhook dead letter failed: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Flush implements Sink; events are delivered by Write so there is nothing to
+// flush
+func (w *Webhook) Flush(context.Context) error {
+	return nil
+}
+
+// Close implements Sink
+func (w *Webhook) Close() error {
+	return nil
+}
+
+func (w *Webhook) events(data []byte) ([]WebhookEvent, error) {
+	method, _ := jsonparser.GetString(data, "method")
+	if method != chainsync.NextBlockMethod {
+		return nil, nil
+	}
+	raw, _, _, err := jsonparser.Get(data, "result")
+	if err != nil {
+		return nil, nil
+	}
+	var result chainsync.ResultNextBlockPraos
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode nextBlock result: %w", err)
+	}
+
+	switch {
+	case result.Direction == chainsync.RollBackwardString && result.Point != nil:
+		event := WebhookEvent{Type: EventRollback, Point: result.Point}
+		if ps, ok := result.Point.PointStruct(); ok {
+			event.Slot, event.BlockID = ps.Slot, ps.ID
+		}
+		return []WebhookEvent{event}, nil
+
+	case result.Direction == chainsync.RollForwardString && result.Block != nil:
+		block := result.Block
+		if w.options.filter == nil {
+			return []WebhookEvent{{
+				Type:    EventBlock,
+				Slot:    block.Slot,
+				BlockID: block.ID,
+				Block:   block,
+			}}, nil
+		}
+		var events []WebhookEvent
+		for i := range block.Transactions {
+			tx := &block.Transactions[i]
+			if !w.options.filter(*tx) {
+				continue
+			}
+			events = append(events, WebhookEvent{
+				Type:    EventTx,
+				Slot:    block.Slot,
+				BlockID: block.ID,
+				Tx:      tx,
+			})
+		}
+		return events, nil
+	}
+	return nil, nil
+}
+
+// deliver POSTs body, retrying transient failures up to the configured limit
+func (w *Webhook) deliver(ctx context.Context, body []byte) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = w.post(ctx, body); err == nil {
+			return nil
+		}
+		var webhookErr *WebhookError
+		if errors.As(err, &webhookErr) && !webhookErr.retryable() {
+			break
+		}
+		if attempt >= w.options.retries {
+			break
+		}
+
+		timer := time.NewTimer(w.options.backoff(attempt + 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return fmt.Errorf("failed to deliver webhook, %v: %w", w.endpoint, err)
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		w.endpoint,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.options.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.options.secret, body))
+	}
+
+	resp, err := w.options.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &WebhookError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body; receivers compare it to
+// the SignatureHeader, ideally using hmac.Equal
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
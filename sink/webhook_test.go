@@ -0,0 +1,203 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+type webhookServer struct {
+	mutex    sync.Mutex
+	statuses []int // statuses returned in turn; 200 once exhausted
+	events   []WebhookEvent
+	attempts int
+	sigs     []string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.attempts++
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	data, _ := io.ReadAll(req.Body)
+	var event WebhookEvent
+	_ = json.Unmarshal(data, &event)
+	s.events = append(s.events, event)
+	s.sigs = append(s.sigs, req.Header.Get(SignatureHeader))
+}
+
+func blockMessage(t *testing.T, slot uint64, txs ...string) []byte {
+	block := &chainsync.Block{Slot: slot, ID: "block"}
+	for _, id := range txs {
+		block.Transactions = append(block.Transactions, chainsync.Tx{ID: id})
+	}
+	data, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollForwardString,
+			Block:     block,
+		},
+	})
+	assert.Nil(t, err)
+	return data
+}
+
+func TestWebhook(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		secret = []byte("secret")
+		server = &webhookServer{}
+		ts     = httptest.NewTLSServer(server)
+	)
+	defer ts.Close()
+
+	w := NewWebhook(ts.URL,
+		WithHTTPClient(ts.Client()),
+		WithSecret(secret),
+	)
+	fn := w.ChainSyncFunc()
+	assert.Nil(t, fn(ctx, []byte(`{"method":"findIntersection"}`)))
+	assert.Nil(t, fn(ctx, blockMessage(t, 10, "a")))
+
+	point := chainsync.PointStruct{Slot: 5, ID: "abc"}.Point()
+	data, err := json.Marshal(chainsync.ResponsePraos{
+		Method: chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &point,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, fn(ctx, data))
+	assert.Nil(t, w.Close())
+
+	assert.Len(t, server.events, 2)
+	assert.Equal(t, EventBlock, server.events[0].Type)
+	assert.EqualValues(t, 10, server.events[0].Slot)
+	assert.Equal(t, "a", server.events[0].Block.Transactions[0].ID)
+	assert.Equal(t, EventRollback, server.events[1].Type)
+	assert.Equal(t, "abc", server.events[1].BlockID)
+
+	body, err := json.Marshal(server.events[1])
+	assert.Nil(t, err)
+	assert.Equal(t, Sign(secret, body), server.sigs[1])
+}
+
+func TestWebhook_TxFilter(t *testing.T) {
+	server := &webhookServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	w := NewWebhook(ts.URL, WithTxFilter(func(tx chainsync.Tx) bool {
+		return tx.ID != "skip"
+	}))
+	err := w.Write(context.Background(), "", blockMessage(t, 1, "a", "skip", "b"))
+	assert.Nil(t, err)
+
+	assert.Len(t, server.events, 2)
+	assert.Equal(t, EventTx, server.events[0].Type)
+	assert.Equal(t, "a", server.events[0].Tx.ID)
+	assert.Equal(t, "b", server.events[1].Tx.ID)
+	assert.Equal(t, "", server.sigs[0])
+}
+
+func TestWebhook_Retry(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		server = &webhookServer{}
+		ts     = httptest.NewServer(server)
+	)
+	defer ts.Close()
+
+	t.Run("transient", func(t *testing.T) {
+		server.statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		server.attempts = 0
+		w := NewWebhook(ts.URL, WithBackoff(ogmigo.ConstantBackoff(0)))
+		assert.Nil(t, w.Write(ctx, "", blockMessage(t, 1)))
+		assert.Equal(t, 3, server.attempts)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		server.statuses = []int{500, 500, 500}
+		server.attempts = 0
+		w := NewWebhook(ts.URL,
+			WithRetries(2),
+			WithBackoff(ogmigo.ConstantBackoff(0)),
+		)
+		err := w.Write(ctx, "", blockMessage(t, 1))
+		var webhookErr *WebhookError
+		assert.True(t, errors.As(err, &webhookErr))
+		assert.Equal(t, 500, webhookErr.StatusCode)
+		assert.Equal(t, 3, server.attempts)
+	})
+
+	t.Run("dead letter", func(t *testing.T) {
+		server.statuses = []int{http.StatusBadRequest}
+		server.attempts = 0
+		var dead [][]byte
+		w := NewWebhook(ts.URL,
+			WithDeadLetter(func(_ context.Context, body []byte, err error) error {
+				assert.NotNil(t, err)
+				dead = append(dead, body)
+				return nil
+			}),
+		)
+		assert.Nil(t, w.Write(ctx, "", blockMessage(t, 1)))
+		assert.Equal(t, 1, server.attempts) // client errors are not retried
+		assert.Len(t, dead, 1)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		server.statuses = []int{http.StatusServiceUnavailable}
+		server.attempts = 0
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		w := NewWebhook(ts.URL,
+			WithBackoff(func(int) time.Duration {
+				cancel() // shutdown while backing off
+				return time.Minute
+			}),
+			WithDeadLetter(func(context.Context, []byte, error) error {
+				t.Fatal("cancelled event was dead lettered")
+				return nil
+			}),
+		)
+		err := w.Write(ctx, "", blockMessage(t, 1))
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, server.attempts)
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrTimeBeforeStart is returned when converting a time prior to the start of
+// the network into a slot
+var ErrTimeBeforeStart = errors.New("ogmigo: time before network start")
+
+// slotClockEra is an era expressed as offsets from the network start
+type slotClockEra struct {
+	slot       uint64        // slot is the first slot of the era
+	elapsed    time.Duration // elapsed is the time from network start to slot
+	slotLength time.Duration
+}
+
+// SlotClock converts between slots and wall clock time.  Conversions honor the
+// slot length of each era so slots on either side of an era boundary, e.g.
+// byron to shelley, are placed correctly.  Slots beyond the final era summary
+// are assumed to continue at the slot length of the final era.
+type SlotClock struct {
+	start time.Time
+	eras  []slotClockEra // eras are ordered by slot
+	now   func() time.Time
+}
+
+// NewSlotClock returns a SlotClock for the given era history and network start
+// time, as returned by EraSummaries and StartTime respectively
+func NewSlotClock(history *EraHistory, start time.Time) (*SlotClock, error) {
+	if history == nil || len(history.Summaries) == 0 {
+		return nil, fmt.Errorf("failed to create slot clock: no era summaries")
+	}
+
+	eras := make([]slotClockEra, 0, len(history.Summaries))
+	for i, summary := range history.Summaries {
+		var (
+			seconds    = summary.Start.Time.Seconds.Int64()
+			slotLength = summary.Parameters.SlotLength.Milliseconds.Int64()
+		)
+		if slotLength <= 0 {
+			return nil, fmt.Errorf(
+				"failed to create slot clock: invalid slot length in era %v, %v",
+				i,
+				slotLength,
+			)
+		}
+		eras = append(eras, slotClockEra{
+			slot:       summary.Start.Slot,
+			elapsed:    time.Duration(seconds) * time.Second,
+			slotLength: time.Duration(slotLength) * time.Millisecond,
+		})
+	}
+	sort.SliceStable(eras, func(i, j int) bool {
+		return eras[i].slot < eras[j].slot
+	})
+
+	return &SlotClock{
+		start: start,
+		eras:  eras,
+		now:   time.Now,
+	}, nil
+}
+
+// SlotClock queries the era summaries and network start time and returns a
+// SlotClock built from them
+func (c *Client) SlotClock(ctx context.Context) (*SlotClock, error) {
+	_, clock, err := c.eraClock(ctx)
+	return clock, err
+}
+
+// eraClock returns the era history along with the SlotClock built from it
+func (c *Client) eraClock(ctx context.Context) (*EraHistory, *SlotClock, error) {
+	history, err := c.EraSummaries(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query era summaries: %w", err)
+	}
+
+	startTime, err := c.StartTime(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query start time: %w", err)
+	}
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to parse start time, %v: %w",
+			startTime,
+			err,
+		)
+	}
+
+	clock, err := NewSlotClock(history, start)
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, clock, nil
+}
+
+// Start returns the network start time i.e. the time of slot 0
+func (s *SlotClock) Start() time.Time {
+	return s.start
+}
+
+// SlotToTime returns the time at which the slot begins
+func (s *SlotClock) SlotToTime(slot uint64) time.Time {
+	i := sort.Search(len(s.eras), func(i int) bool {
+		return s.eras[i].slot > slot
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	era := s.eras[i]
+	var offset time.Duration
+	if slot > era.slot {
+		offset = time.Duration(slot-era.slot) * era.slotLength
+	}
+	return s.start.Add(era.elapsed + offset)
+}
+
+// TimeToSlot returns the slot in progress at the given time.  If t precedes
+// the network start, ErrTimeBeforeStart is returned.
+func (s *SlotClock) TimeToSlot(t time.Time) (uint64, error) {
+	elapsed := t.Sub(s.start)
+	if elapsed < 0 {
+		return 0, ErrTimeBeforeStart
+	}
+
+	i := sort.Search(len(s.eras), func(i int) bool {
+		return s.eras[i].elapsed > elapsed
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	era := s.eras[i]
+	var slots uint64
+	if elapsed > era.elapsed {
+		slots = uint64((elapsed - era.elapsed) / era.slotLength)
+	}
+	return era.slot + slots, nil
+}
+
+// CurrentSlot returns the slot in progress now
+func (s *SlotClock) CurrentSlot() (uint64, error) {
+	return s.TimeToSlot(s.now())
+}
+
+// SlotsUntil returns the number of slots from the current slot until the slot
+// in progress at t, or 0 if t has already passed
+func (s *SlotClock) SlotsUntil(t time.Time) (uint64, error) {
+	target, err := s.TimeToSlot(t)
+	if err != nil {
+		return 0, err
+	}
+	current, err := s.CurrentSlot()
+	if err != nil {
+		return 0, err
+	}
+	if target <= current {
+		return 0, nil
+	}
+	return target - current, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+// mainnetEraSummaries is an abbreviated mainnet era history covering the
+// byron to shelley boundary
+const mainnetEraSummaries = `[
+  {
+    "start": {"time": {"seconds": 0}, "slot": 0, "epoch": 0},
+    "end": {"time": {"seconds": 89856000}, "slot": 4492800, "epoch": 208},
+    "parameters": {
+      "epochLength": 21600,
+      "slotLength": {"milliseconds": 20000},
+      "safeZone": 4320
+    }
+  },
+  {
+    "start": {"time": {"seconds": 89856000}, "slot": 4492800, "epoch": 208},
+    "end": {"time": {"seconds": 101952000}, "slot": 16588800, "epoch": 236},
+    "parameters": {
+      "epochLength": 432000,
+      "slotLength": {"milliseconds": 1000},
+      "safeZone": 129600
+    }
+  }
+]`
+
+func mainnetSlotClock(t *testing.T) *SlotClock {
+	var summaries []EraSummary
+	err := json.Unmarshal([]byte(mainnetEraSummaries), &summaries)
+	assert.Nil(t, err)
+
+	start := time.Date(2017, 9, 23, 21, 44, 51, 0, time.UTC)
+	clock, err := NewSlotClock(&EraHistory{Summaries: summaries}, start)
+	assert.Nil(t, err)
+	return clock
+}
+
+func TestSlotClock(t *testing.T) {
+	clock := mainnetSlotClock(t)
+	shelley := time.Date(2020, 7, 29, 21, 44, 51, 0, time.UTC)
+
+	tests := map[string]struct {
+		Slot uint64
+		Time time.Time
+	}{
+		"origin": {
+			Slot: 0,
+			Time: clock.Start(),
+		},
+		"byron": {
+			Slot: 3,
+			Time: clock.Start().Add(time.Minute),
+		},
+		"boundary": {
+			Slot: 4492800,
+			Time: shelley,
+		},
+		"shelley": {
+			Slot: 4492860,
+			Time: shelley.Add(time.Minute),
+		},
+		"beyond final era": {
+			Slot: 16588800 + 3600,
+			Time: shelley.Add(12096000*time.Second + time.Hour),
+		},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			assert.Equal(t, tc.Time, clock.SlotToTime(tc.Slot))
+
+			slot, err := clock.TimeToSlot(tc.Time)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Slot, slot)
+		})
+	}
+
+	// times within a slot round down to the start of the slot
+	slot, err := clock.TimeToSlot(clock.Start().Add(39 * time.Second))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, slot)
+
+	_, err = clock.TimeToSlot(clock.Start().Add(-time.Second))
+	assert.True(t, errors.Is(err, ErrTimeBeforeStart))
+}
+
+func TestSlotClock_SlotsUntil(t *testing.T) {
+	var (
+		clock = mainnetSlotClock(t)
+		now   = time.Date(2020, 7, 29, 21, 0, 51, 0, time.UTC)
+	)
+	clock.now = func() time.Time { return now }
+
+	current, err := clock.CurrentSlot()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4492800-132, current)
+
+	// 44 minutes of 20s byron slots followed by 1 minute of 1s shelley slots
+	slots, err := clock.SlotsUntil(now.Add(45 * time.Minute))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 132+60, slots)
+
+	slots, err = clock.SlotsUntil(now.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, slots)
+}
+
+func TestNewSlotClock(t *testing.T) {
+	_, err := NewSlotClock(&EraHistory{}, time.Now())
+	assert.NotNil(t, err)
+
+	_, err = NewSlotClock(&EraHistory{
+		Summaries: []EraSummary{{}},
+	}, time.Now())
+	assert.NotNil(t, err)
+}
@@ -28,7 +28,20 @@ import (
 	"github.com/btcsuite/btcutil/bech32"
 )
 
+// ChainTip returns the tip of the ledger state i.e. queryLedgerState/tip
 func (c *Client) ChainTip(ctx context.Context) (chainsync.Point, error) {
+	protocol, err := c.Protocol(ctx)
+	if err != nil {
+		return chainsync.Point{}, err
+	}
+	if protocol == ProtocolV5 {
+		point, err := c.ChainTipV5(ctx)
+		if err != nil {
+			return chainsync.Point{}, err
+		}
+		return point.ConvertToV6(), nil
+	}
+
 	var (
 		payload = makePayload("queryLedgerState/tip", Map{}, nil)
 		content struct{ Result chainsync.Point }
@@ -147,6 +160,7 @@ type EraHistory struct {
 }
 
 type EraSummary struct {
+	Era        string        `json:"era,omitempty"` // e.g. byron; see EraNames
 	Start      EraBound      `json:"start"`
 	End        EraBound      `json:"end"`
 	Parameters EraParameters `json:"parameters"`
@@ -179,9 +193,7 @@ func (c *Client) EraSummaries(ctx context.Context) (*EraHistory, error) {
 		return nil, err
 	}
 
-	return &EraHistory{
-		Summaries: summaries,
-	}, nil
+	return newEraHistory(summaries), nil
 }
 
 func SlotToElapsedMilliseconds(history *EraHistory, slot uint64) uint64 {
@@ -220,6 +232,29 @@ func (c *Client) EraStart(ctx context.Context) (statequery.EraStart, error) {
 	return content.Result, nil
 }
 
+// LiveStakeDistribution returns the current stake distribution keyed by pool id
+func (c *Client) LiveStakeDistribution(
+	ctx context.Context,
+) (statequery.StakeDistribution, error) {
+	var (
+		payload = makePayload(
+			"queryLedgerState/liveStakeDistribution",
+			Map{},
+			nil,
+		)
+		content struct{ Result statequery.StakeDistribution }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf(
+			"failed to query live stake distribution: %w",
+			err,
+		)
+	}
+
+	return content.Result, nil
+}
+
 func (c *Client) UtxosByAddress(
 	ctx context.Context,
 	addresses ...string,
@@ -260,6 +295,69 @@ func (c *Client) UtxosByTxIn(
 	return content.Result, nil
 }
 
+// ResolvedTxIn pairs an output reference with the output it references
+type ResolvedTxIn struct {
+	TxIn  chainsync.TxIn
+	TxOut chainsync.TxOut
+}
+
+// ResolveTxIns looks up the outputs referenced by txIns, e.g. the inputs of a
+// transaction.  Results are in the order of txIns; references absent from the
+// utxo set, such as those already spent, are omitted.
+func (c *Client) ResolveTxIns(
+	ctx context.Context,
+	txIns ...chainsync.TxIn,
+) ([]ResolvedTxIn, error) {
+	utxos, err := c.UtxosByTxIn(ctx, chainsync.TxIns(txIns).Queries()...)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]chainsync.TxOut, len(utxos))
+	for _, utxo := range utxos {
+		txOut, err := toTxOut(utxo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tx ins: %w", err)
+		}
+		txIn := chainsync.TxIn{
+			Transaction: chainsync.TxInID{ID: utxo.Transaction.ID},
+			Index:       int(utxo.Index),
+		}
+		outputs[txIn.String()] = txOut
+	}
+
+	var resolved []ResolvedTxIn
+	for _, txIn := range txIns {
+		if txOut, ok := outputs[txIn.String()]; ok {
+			resolved = append(resolved, ResolvedTxIn{TxIn: txIn, TxOut: txOut})
+		}
+	}
+	return resolved, nil
+}
+
+// toTxOut converts the output fields of utxo to a TxOut
+func toTxOut(utxo shared.Utxo) (chainsync.TxOut, error) {
+	txOut := chainsync.TxOut{
+		Address:   utxo.Address,
+		Datum:     utxo.Datum,
+		DatumHash: utxo.DatumHash,
+		Value:     utxo.Value,
+	}
+	if len(utxo.Script) > 0 && string(utxo.Script) != "null" {
+		var script chainsync.Script
+		if err := json.Unmarshal(utxo.Script, &script); err != nil {
+			return chainsync.TxOut{}, fmt.Errorf(
+				"failed to decode script of %v#%v: %w",
+				utxo.Transaction.ID,
+				utxo.Index,
+				err,
+			)
+		}
+		txOut.Script = &script
+	}
+	return txOut, nil
+}
+
 type Delegation struct {
 	PoolID  string  `json:"poolId"`
 	Rewards num.Int `json:"rewards"`
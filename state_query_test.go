@@ -204,3 +204,38 @@ func TestClient_UtxosByTxIn(t *testing.T) {
 	encoder.SetIndent("", "  ")
 	_ = encoder.Encode(utxos)
 }
+
+func TestClient_ResolveTxIns(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","result":[`+
+			`{"transaction":{"id":"b"},"index":1,"address":"addr2","value":{"ada":{"lovelace":2}},`+
+			`"script":{"language":"native","json":{"clause":"signature","from":"abc"}}},`+
+			`{"transaction":{"id":"a"},"index":0,"address":"addr1","value":{"ada":{"lovelace":1}}}]}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	txIns := chainsync.TxIns{
+		{Transaction: chainsync.TxInID{ID: "a"}, Index: 0},
+		{Transaction: chainsync.TxInID{ID: "spent"}, Index: 0},
+		{Transaction: chainsync.TxInID{ID: "b"}, Index: 1},
+	}
+	resolved, err := client.ResolveTxIns(context.Background(), txIns...)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(resolved), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := resolved[0].TxIn.String(), "a#0"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := resolved[0].TxOut.Address, "addr1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := resolved[1].TxIn.String(), "b#1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if resolved[1].TxOut.Script == nil {
+		t.Fatalf("got nil script; want native script")
+	}
+}
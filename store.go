@@ -16,8 +16,12 @@ package ogmigo
 
 import (
 	"context"
-	"strconv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
 
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
 )
 
@@ -31,6 +35,13 @@ type Store interface {
 	Load(ctx context.Context) (chainsync.Points, error)
 }
 
+// Pruner is implemented by stores able to discard points no longer needed,
+// e.g. once a downstream system has durably processed the chain up to before
+type Pruner interface {
+	// Prune removes every point older than before
+	Prune(ctx context.Context, before chainsync.Point) error
+}
+
 type loggingStore struct {
 	logger Logger
 }
@@ -45,9 +56,9 @@ func NewLoggingStore(logger Logger) Store {
 func (l *loggingStore) Save(_ context.Context, point chainsync.Point) error {
 	var kvs []KeyValue
 	if ps, ok := point.PointStruct(); ok {
-		kvs = append(kvs, KV("slot", strconv.FormatUint(ps.Slot, 10)))
+		kvs = append(kvs, Uint64("slot", ps.Slot))
 		if ps.Height != nil {
-			kvs = append(kvs, KV("block", strconv.FormatUint(*ps.Height, 10)))
+			kvs = append(kvs, Uint64("block", *ps.Height))
 		}
 		kvs = append(kvs, KV("id", ps.ID))
 	}
@@ -59,6 +70,136 @@ func (l *loggingStore) Load(_ context.Context) (chainsync.Points, error) {
 	return nil, nil
 }
 
+// kvStoreDepth is the number of recent points retained by a kv backed Store
+// by default
+const kvStoreDepth = 10
+
+type kvStore struct {
+	mutex sync.Mutex
+	store kv.Store
+	key   string
+	depth int
+}
+
+// KVStoreOption provides functional options for NewKVStore
+type KVStoreOption func(*kvStore)
+
+// WithKVStoreRetention keeps the n most recent points; defaults to 10
+func WithKVStoreRetention(n int) KVStoreOption {
+	return func(k *kvStore) {
+		k.depth = n
+	}
+}
+
+// NewKVStore returns a Store that keeps the most recent points under key in
+// store.  Combined with kv.NewEncrypted, checkpoints are encrypted at rest e.g.
+//
+//	store := ogmigo.NewKVStore(kv.NewEncrypted(db, kv.StaticKey(key)), "points")
+//
+// The returned Store also implements Pruner.
+func NewKVStore(store kv.Store, key string, opts ...KVStoreOption) Store {
+	k := &kvStore{
+		store: store,
+		key:   key,
+		depth: kvStoreDepth,
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	if k.depth <= 0 {
+		k.depth = kvStoreDepth
+	}
+	return k
+}
+
+func (k *kvStore) Save(ctx context.Context, point chainsync.Point) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	points, err := k.load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save point: %w", err)
+	}
+	points = append(points, point)
+	sort.Sort(points)
+	if len(points) > k.depth {
+		points = points[:k.depth]
+	}
+	if err := k.save(ctx, points); err != nil {
+		return fmt.Errorf("failed to save point: %w", err)
+	}
+	return nil
+}
+
+// Prune implements Pruner
+func (k *kvStore) Prune(ctx context.Context, before chainsync.Point) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	points, err := k.load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to prune points: %w", err)
+	}
+	kept := points[:0]
+	for _, point := range points {
+		if !isBefore(point, before) {
+			kept = append(kept, point)
+		}
+	}
+	if len(kept) == len(points) {
+		return nil
+	}
+	if err := k.save(ctx, kept); err != nil {
+		return fmt.Errorf("failed to prune points: %w", err)
+	}
+	return nil
+}
+
+func (k *kvStore) save(ctx context.Context, points chainsync.Points) error {
+	data, err := json.Marshal(points)
+	if err != nil {
+		return err
+	}
+	return k.store.Set(ctx, k.key, data)
+}
+
+func (k *kvStore) Load(ctx context.Context) (chainsync.Points, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	points, err := k.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load points: %w", err)
+	}
+	return points, nil
+}
+
+func (k *kvStore) load(ctx context.Context) (chainsync.Points, error) {
+	data, ok, err := k.store.Get(ctx, k.key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var points chainsync.Points
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// isBefore returns true if point precedes before; origin precedes every
+// other point
+func isBefore(point, before chainsync.Point) bool {
+	ps, ok := before.PointStruct()
+	if !ok {
+		return false
+	}
+	if p, ok := point.PointStruct(); ok {
+		return p.Slot < ps.Slot
+	}
+	return true
+}
+
 type nopStore struct{}
 
 func (n nopStore) Save(context.Context, chainsync.Point) error { return nil }
@@ -68,3 +209,24 @@ func (n nopStore) Load(
 ) (chainsync.Points, error) {
 	return nil, nil
 }
+
+// CopyStore copies the points held by src to dst, e.g. to migrate the
+// checkpoints of a deployment between Store implementations without losing
+// its cursor.  Points are saved oldest first so a dst with limited retention
+// keeps the most recent.  Returns the number of points copied.
+func CopyStore(ctx context.Context, src, dst Store) (int, error) {
+	points, err := src.Load(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy points: %w", err)
+	}
+	sort.Sort(points)
+
+	var copied int
+	for i := len(points) - 1; i >= 0; i-- {
+		if err := dst.Save(ctx, points[i]); err != nil {
+			return copied, fmt.Errorf("failed to copy points: %w", err)
+		}
+		copied++
+	}
+	return copied, nil
+}
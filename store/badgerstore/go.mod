@@ -1,8 +1,6 @@
 module github.com/SundaeSwap-finance/ogmigo/store/badgerstore
 
-go 1.23.0
-
-toolchain go1.23.7
+go 1.24.0
 
 require (
 	github.com/SundaeSwap-finance/ogmigo/v6 v6.0.0
@@ -10,8 +8,9 @@ require (
 )
 
 require (
-	github.com/SundaeSwap-finance/ogmigo v0.0.0-00010101000000-000000000000 // indirect
 	github.com/aws/aws-sdk-go v1.44.197 // indirect
+	github.com/btcsuite/btcutil v1.0.2 // indirect
+	github.com/buger/jsonparser v1.1.2 // indirect
 	github.com/cespare/xxhash v1.1.0 // indirect
 	github.com/cespare/xxhash/v2 v2.1.1 // indirect
 	github.com/dgraph-io/ristretto v0.1.0 // indirect
@@ -28,8 +27,11 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	go.opencensus.io v0.22.5 // indirect
+	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/net v0.38.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 )
 
 replace github.com/SundaeSwap-finance/ogmigo => ../..
+
+replace github.com/SundaeSwap-finance/ogmigo/v6 => ../..
@@ -0,0 +1,88 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badgerstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/badger/v3"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
+)
+
+var _ kv.Store = (*KV)(nil)
+
+// KV is a kv.Store backed by badger
+type KV struct {
+	db     *badger.DB
+	prefix string
+}
+
+// NewKV returns a kv.Store that holds its keys beneath prefix
+func NewKV(db *badger.DB, prefix string) *KV {
+	return &KV{
+		db:     db,
+		prefix: strings.TrimRight(prefix, "/") + "/",
+	}
+}
+
+func (k *KV) key(key string) []byte {
+	return []byte(k.prefix + key)
+}
+
+// Get implements kv.Store
+func (k *KV) Get(_ context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	err := k.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(k.key(key))
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get key, %v: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set implements kv.Store
+func (k *KV) Set(_ context.Context, key string, value []byte) error {
+	err := k.db.Update(func(tx *badger.Txn) error {
+		return tx.Set(k.key(key), value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set key, %v: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements kv.Store
+func (k *KV) Delete(_ context.Context, key string) error {
+	err := k.db.Update(func(tx *badger.Txn) error {
+		return tx.Delete(k.key(key))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete key, %v: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badgerstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+func TestKV(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		store = NewKV(db, "cache")
+	)
+
+	if _, ok, err := store.Get(ctx, "a"); err != nil || ok {
+		t.Fatalf("got %v, %v; want false, nil", ok, err)
+	}
+	if err := store.Set(ctx, "a", []byte("1")); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	value, ok, err := store.Get(ctx, "a")
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v; want true, nil", ok, err)
+	}
+	if got, want := string(value), "1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if _, ok, _ := store.Get(ctx, "a"); ok {
+		t.Fatalf("got %v; want false", ok)
+	}
+}
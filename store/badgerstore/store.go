@@ -19,69 +19,329 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
-	"sync/atomic"
+	"sync"
+	"time"
 
 	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
 
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
 )
 
+// DefaultRetention is the number of recent points kept by default
+const DefaultRetention = 10
+
+// gcDiscardRatio is the fraction of a value log file that must be stale
+// before periodic GC rewrites it
+const gcDiscardRatio = 0.5
+
 type Store struct {
-	db      *badger.DB
-	counter int64
-	prefix  []byte
+	db        *badger.DB
+	prefix    []byte
+	retention int
+	envelope  kv.Envelope // nil unless WithEncryption was specified
+
+	owned       bool // db was opened by Open and is closed by Close
+	inMemory    bool
+	compression *options.CompressionType
+	gcInterval  time.Duration
+	done        chan struct{}
+	closeOnce   sync.Once
+	wg          sync.WaitGroup
+}
+
+// Option provides functional options for New
+type Option func(*Store)
+
+// WithCompression sets the block compression of a db opened by Open;
+// defaults to the badger default
+func WithCompression(compression options.CompressionType) Option {
+	return func(s *Store) {
+		s.compression = &compression
+	}
+}
+
+// WithEncryption seals each point with AES-GCM using keys supplied by
+// envelope, e.g. kv.StaticKey or kv.NewKMSEnvelope.  Points saved without
+// encryption can no longer be loaded.
+func WithEncryption(envelope kv.Envelope) Option {
+	return func(s *Store) {
+		s.envelope = envelope
+	}
 }
 
-func New(db *badger.DB, prefix string) *Store {
-	return &Store{
-		db:     db,
-		prefix: []byte(strings.TrimRight(prefix, "/") + "/"),
+// WithGCInterval runs value log GC every interval until Close is called, so
+// the db does not grow without bound on long syncs; defaults to never
+func WithGCInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		s.gcInterval = interval
+	}
+}
+
+// WithInMemory holds a db opened by Open entirely in memory; the path passed
+// to Open is ignored
+func WithInMemory(enabled bool) Option {
+	return func(s *Store) {
+		s.inMemory = enabled
+	}
+}
+
+// WithRetention keeps the n most recent points; defaults to DefaultRetention
+func WithRetention(n int) Option {
+	return func(s *Store) {
+		s.retention = n
+	}
+}
+
+// New returns a Store holding its points beneath prefix in db.  Call Close to
+// stop any periodic GC; db remains open.
+func New(db *badger.DB, prefix string, opts ...Option) *Store {
+	s := newStore(prefix, opts...)
+	s.db = db
+	s.start()
+	return s
+}
+
+// Open opens the badger db at path, applying WithCompression and
+// WithInMemory, and returns a Store holding its points beneath prefix.  Close
+// closes the db.
+func Open(path, prefix string, opts ...Option) (*Store, error) {
+	s := newStore(prefix, opts...)
+
+	dbOptions := badger.DefaultOptions(path).WithLogger(nil)
+	if s.inMemory {
+		dbOptions = dbOptions.WithDir("").WithValueDir("").WithInMemory(true)
+	}
+	if s.compression != nil {
+		dbOptions = dbOptions.WithCompression(*s.compression)
 	}
+	db, err := badger.Open(dbOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badger db, %v: %w", path, err)
+	}
+
+	s.db, s.owned = db, true
+	s.start()
+	return s, nil
+}
+
+func newStore(prefix string, opts ...Option) *Store {
+	s := &Store{
+		prefix:    []byte(strings.TrimRight(prefix, "/") + "/"),
+		retention: DefaultRetention,
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.retention <= 0 {
+		s.retention = DefaultRetention
+	}
+	return s
+}
+
+// start begins periodic GC, if configured; in-memory dbs have no value log
+func (s *Store) start() {
+	if s.gcInterval <= 0 || s.db.Opts().InMemory {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.gcInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.gc()
+			}
+		}
+	}()
+}
+
+// gc rewrites value log files until no more can be reclaimed
+func (s *Store) gc() {
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		if err := s.db.RunValueLogGC(gcDiscardRatio); err != nil {
+			return // badger.ErrNoRewrite once nothing remains to reclaim
+		}
+	}
+}
+
+// Close stops periodic GC and, if the db was opened by Open, closes the db
+func (s *Store) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		if s.owned {
+			err = s.db.Close()
+		}
+	})
+	return err
 }
 
 // Save the point; save will be called multiple times and should only
 // keep track of the most recent points
-func (s *Store) Save(_ context.Context, point chainsync.Point) error {
+func (s *Store) Save(ctx context.Context, point chainsync.Point) error {
 	data, err := json.Marshal(point)
 	if err != nil {
 		return fmt.Errorf("failed to save point: %w", err)
 	}
 
-	v := atomic.AddInt64(&s.counter, 1) % 10
-	key := append(s.prefix, []byte(strconv.FormatInt(v, 10))...)
-
-	tx := s.db.NewTransaction(true)
-	if err := tx.Set(key, data); err != nil {
-		return fmt.Errorf("failed to save point: set failed: %w", err)
+	key := s.key(point)
+	if s.envelope != nil {
+		data, err = kv.Seal(ctx, s.envelope, string(key), data)
+		if err != nil {
+			return fmt.Errorf("failed to save point: %w", err)
+		}
 	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to save point: commit failed: %w", err)
+
+	err = s.db.Update(func(txn *badger.Txn) error {
+		entries, err := s.entries(ctx, txn)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(key, data); err != nil {
+			return fmt.Errorf("set failed: %w", err)
+		}
+
+		// drop the oldest points beyond the retention
+		entries = append(entries, entry{key: string(key), point: point})
+		entries = dedup(entries)
+		for _, e := range entries[min(s.retention, len(entries)):] {
+			if err := txn.Delete([]byte(e.key)); err != nil {
+				return fmt.Errorf("delete failed: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save point: %w", err)
 	}
 
 	return s.db.Sync()
 }
 
 // Load saved points
-func (s *Store) Load(context.Context) (chainsync.Points, error) {
-	tx := s.db.NewTransaction(false)
-	iter := tx.NewIterator(badger.DefaultIteratorOptions)
+func (s *Store) Load(ctx context.Context) (chainsync.Points, error) {
+	var pp chainsync.Points
+	err := s.db.View(func(txn *badger.Txn) error {
+		entries, err := s.entries(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			pp = append(pp, e.point)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load points: %w", err)
+	}
+
+	sort.Sort(pp)
+
+	return pp, nil
+}
+
+// Prune removes every point older than before; origin is older than every
+// other point
+func (s *Store) Prune(ctx context.Context, before chainsync.Point) error {
+	ps, ok := before.PointStruct()
+	if !ok {
+		return nil // nothing precedes origin
+	}
+
+	err := s.db.Update(func(txn *badger.Txn) error {
+		entries, err := s.entries(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if p, ok := e.point.PointStruct(); ok && p.Slot >= ps.Slot {
+				continue
+			}
+			if err := txn.Delete([]byte(e.key)); err != nil {
+				return fmt.Errorf("delete failed: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune points: %w", err)
+	}
+
+	return s.db.Sync()
+}
+
+type entry struct {
+	key   string
+	point chainsync.Point
+}
+
+// entries returns every point saved beneath the prefix
+func (s *Store) entries(ctx context.Context, txn *badger.Txn) ([]entry, error) {
+	iter := txn.NewIterator(badger.DefaultIteratorOptions)
 	defer iter.Close()
 
-	var pp chainsync.Points
+	var entries []entry
 	for iter.Seek(s.prefix); iter.ValidForPrefix(s.prefix); iter.Next() {
-		var p chainsync.Point
-		unmarshal := func(val []byte) error { return json.Unmarshal(val, &p) }
+		item := iter.Item()
+		key := string(item.KeyCopy(nil))
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		if s.envelope != nil {
+			if data, err = kv.Open(ctx, s.envelope, key, data); err != nil {
+				return nil, fmt.Errorf("failed to decrypt %v: %w", key, err)
+			}
+		}
 
-		if err := iter.Item().Value(unmarshal); err != nil {
-			return nil, fmt.Errorf("failed to load points: %w", err)
+		var p chainsync.Point
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
 		}
+		entries = append(entries, entry{key: key, point: p})
+	}
+	return entries, nil
+}
 
-		pp = append(pp, p)
+// key returns the key of the point; saving a point again replaces it
+func (s *Store) key(point chainsync.Point) []byte {
+	name := point.String()
+	if ps, ok := point.PointStruct(); ok {
+		name = fmt.Sprintf("%020d/%v", ps.Slot, ps.ID)
 	}
+	return append(append([]byte(nil), s.prefix...), name...)
+}
 
-	sort.Sort(pp)
+// dedup orders entries most recent first keeping only the first of each key
+func dedup(entries []entry) []entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return chainsync.Points{entries[i].point, entries[j].point}.Less(0, 1)
+	})
 
-	return pp, nil
+	seen := map[string]struct{}{}
+	deduped := entries[:0]
+	for _, e := range entries {
+		if _, ok := seen[e.key]; !ok {
+			seen[e.key] = struct{}{}
+			deduped = append(deduped, e)
+		}
+	}
+	return deduped
 }
@@ -15,12 +15,17 @@
 package badgerstore
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
 	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
 )
 
 func TestStore_Load(t *testing.T) {
@@ -65,3 +70,177 @@ func TestStore_Load(t *testing.T) {
 		t.Fatalf("got %#v; want %#v", got, want)
 	}
 }
+
+func TestStore_Retention(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		store = New(db, "points", WithRetention(2))
+		a     = chainsync.PointStruct{ID: "a", Slot: 10}.Point()
+		b     = chainsync.PointStruct{ID: "b", Slot: 20}.Point()
+		c     = chainsync.PointStruct{ID: "c", Slot: 30}.Point()
+	)
+	for _, point := range []chainsync.Point{b, c, a, c} {
+		if err := store.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := chainsync.Points{c, b}
+	if got := points; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		store = New(db, "points")
+		a     = chainsync.PointStruct{ID: "a", Slot: 10}.Point()
+		b     = chainsync.PointStruct{ID: "b", Slot: 20}.Point()
+	)
+	for _, point := range []chainsync.Point{chainsync.Origin, a, b} {
+		if err := store.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	if err := store.Prune(ctx, b); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := chainsync.Points{b}
+	if got := points; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open("ignored", "points",
+		WithInMemory(true),
+		WithCompression(options.ZSTD),
+		WithGCInterval(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := store.db.Opts().Compression, options.ZSTD; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	ctx := context.Background()
+	point := chainsync.PointStruct{ID: "a", Slot: 10}.Point()
+	if err := store.Save(ctx, point); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := points, (chainsync.Points{point}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !store.db.IsClosed() {
+		t.Fatalf("got open; want closed")
+	}
+}
+
+func TestStore_GC(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions(t.TempDir()).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	store := New(db, "points", WithRetention(1), WithGCInterval(time.Millisecond))
+	ctx := context.Background()
+	for slot := uint64(1); slot <= 100; slot++ {
+		point := chainsync.PointStruct{ID: "id", Slot: slot}.Point()
+		if err := store.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond) // allow a gc pass
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if db.IsClosed() {
+		t.Fatalf("got closed; want db left open")
+	}
+	if got, want := store.Close(), error(nil); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestStore_Encryption(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		key   = bytes.Repeat([]byte{1}, 32)
+		store = New(db, "points", WithEncryption(kv.StaticKey(key)))
+		point = chainsync.PointStruct{ID: "secret", Slot: 10}.Point()
+	)
+	if err := store.Save(ctx, point); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	err = db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(store.key(point))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			if bytes.Contains(val, []byte("secret")) {
+				t.Fatalf("got plaintext %s; want ciphertext", val)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := points, (chainsync.Points{point}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	other := New(db, "points", WithEncryption(kv.StaticKey(make([]byte, 32))))
+	if _, err := other.Load(ctx); !errors.Is(err, kv.ErrDecrypt) {
+		t.Fatalf("got %v; want %v", err, kv.ErrDecrypt)
+	}
+}
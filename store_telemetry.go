@@ -0,0 +1,182 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// StoreOp identifies the Store operation reported to a StoreHook
+type StoreOp int
+
+const (
+	// StoreSave is a call to Store.Save
+	StoreSave StoreOp = iota
+	// StoreLoad is a call to Store.Load
+	StoreLoad
+	// StorePrune is a call to Pruner.Prune
+	StorePrune
+
+	numStoreOps = iota
+)
+
+// String implements fmt.Stringer
+func (op StoreOp) String() string {
+	switch op {
+	case StoreSave:
+		return "save"
+	case StoreLoad:
+		return "load"
+	case StorePrune:
+		return "prune"
+	default:
+		return "unknown"
+	}
+}
+
+// StoreEvent describes a single completed Store operation
+type StoreEvent struct {
+	Op       StoreOp
+	Point    chainsync.Point // Point saved, or pruned before; zero for load
+	Duration time.Duration
+	Err      error
+}
+
+// StoreHook is invoked after every Store operation, e.g. to export latencies
+// and errors as metrics.  The hook is called synchronously and should return
+// quickly.
+type StoreHook func(ctx context.Context, event StoreEvent)
+
+// StoreStats summarizes the calls of a single Store operation
+type StoreStats struct {
+	Op      StoreOp       `json:"op"`
+	Calls   uint64        `json:"calls"`
+	Errors  uint64        `json:"errors"`
+	Last    time.Duration `json:"last"` // duration of the most recent call
+	Max     time.Duration `json:"max"`  // longest call
+	Total   time.Duration `json:"total"`
+	LastErr string        `json:"lastErr,omitempty"`
+}
+
+// InstrumentedStore is a Store decorator that times every operation and
+// counts errors so checkpointing problems, such as a throttled backend, are
+// visible before the sync falls behind
+type InstrumentedStore struct {
+	store Store
+	hook  StoreHook
+
+	mutex sync.Mutex
+	stats [numStoreOps]StoreStats
+}
+
+var (
+	_ Store  = (*InstrumentedStore)(nil)
+	_ Pruner = (*InstrumentedStore)(nil)
+)
+
+// NewInstrumentedStore wraps store, invoking hook, if not nil, after each
+// operation
+func NewInstrumentedStore(store Store, hook StoreHook) *InstrumentedStore {
+	s := &InstrumentedStore{
+		store: store,
+		hook:  hook,
+	}
+	for i := range s.stats {
+		s.stats[i].Op = StoreOp(i)
+	}
+	return s
+}
+
+// Save implements Store
+func (s *InstrumentedStore) Save(
+	ctx context.Context,
+	point chainsync.Point,
+) error {
+	started := time.Now()
+	err := s.store.Save(ctx, point)
+	s.record(ctx, StoreEvent{
+		Op:       StoreSave,
+		Point:    point,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+	return err
+}
+
+// Load implements Store
+func (s *InstrumentedStore) Load(ctx context.Context) (chainsync.Points, error) {
+	started := time.Now()
+	points, err := s.store.Load(ctx)
+	s.record(ctx, StoreEvent{
+		Op:       StoreLoad,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+	return points, err
+}
+
+// Prune implements Pruner; Prune is a no-op if the wrapped store does not
+// implement Pruner
+func (s *InstrumentedStore) Prune(
+	ctx context.Context,
+	before chainsync.Point,
+) error {
+	pruner, ok := s.store.(Pruner)
+	if !ok {
+		return nil
+	}
+
+	started := time.Now()
+	err := pruner.Prune(ctx, before)
+	s.record(ctx, StoreEvent{
+		Op:       StorePrune,
+		Point:    before,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+	return err
+}
+
+// Stats returns the statistics of each operation
+func (s *InstrumentedStore) Stats() []StoreStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]StoreStats(nil), s.stats[:]...)
+}
+
+func (s *InstrumentedStore) record(ctx context.Context, event StoreEvent) {
+	s.mutex.Lock()
+	stats := &s.stats[event.Op]
+	stats.Calls++
+	stats.Last = event.Duration
+	stats.Total += event.Duration
+	if event.Duration > stats.Max {
+		stats.Max = event.Duration
+	}
+	if event.Err != nil {
+		stats.Errors++
+		stats.LastErr = event.Err.Error()
+	}
+	s.mutex.Unlock()
+
+	if s.hook != nil {
+		s.hook(ctx, event)
+	}
+}
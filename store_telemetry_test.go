@@ -0,0 +1,119 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+type failingStore struct {
+	err error
+}
+
+func (f failingStore) Save(context.Context, chainsync.Point) error {
+	return f.err
+}
+
+func (f failingStore) Load(context.Context) (chainsync.Points, error) {
+	return nil, f.err
+}
+
+func TestInstrumentedStore(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		events []StoreEvent
+		store  = NewInstrumentedStore(
+			NewKVStore(kv.NewMemory(), "points"),
+			func(_ context.Context, event StoreEvent) {
+				events = append(events, event)
+			},
+		)
+		a = chainsync.PointStruct{ID: "a", Slot: 10}.Point()
+		b = chainsync.PointStruct{ID: "b", Slot: 20}.Point()
+	)
+	for _, point := range []chainsync.Point{a, b} {
+		if err := store.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+	if err := store.Prune(ctx, b); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(points), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var ops []StoreOp
+	for _, event := range events {
+		ops = append(ops, event.Op)
+	}
+	want := []StoreOp{StoreSave, StoreSave, StorePrune, StoreLoad}
+	if got := ops; len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got := ops[i]; got != want[i] {
+			t.Fatalf("got %v; want %v", got, want[i])
+		}
+	}
+	if got, want := events[1].Point, b; got.String() != want.String() {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	stats := store.Stats()
+	if got, want := stats[StoreSave].Calls, uint64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats[StoreSave].Errors, uint64(0); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestInstrumentedStore_Errors(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		boom  = errors.New("throttled")
+		store = NewInstrumentedStore(failingStore{err: boom}, nil)
+	)
+	if err := store.Save(ctx, chainsync.Origin); !errors.Is(err, boom) {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+	if _, err := store.Load(ctx); !errors.Is(err, boom) {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+	if err := store.Prune(ctx, chainsync.Origin); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	stats := store.Stats()
+	if got, want := stats[StoreSave].Errors, uint64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats[StoreLoad].LastErr, boom.Error(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats[StorePrune].Calls, uint64(0); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -1,9 +1,11 @@
 package ogmigo
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
+	"github.com/SundaeSwap-finance/ogmigo/v6/kv"
 	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
 )
 
@@ -27,3 +29,141 @@ func TestNewLoggingStore(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestNewKVStore(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		memory = kv.NewMemory()
+		key    = bytes.Repeat([]byte{1}, 32)
+		store  = NewKVStore(kv.NewEncrypted(memory, kv.StaticKey(key)), "points")
+		atSlot = func(slot uint64) chainsync.Point {
+			return chainsync.PointStruct{ID: "id", Slot: slot}.Point()
+		}
+	)
+
+	pp, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(pp), 0; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	for slot := uint64(1); slot <= 12; slot++ {
+		if err := store.Save(ctx, atSlot(slot)); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	pp, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(pp), 10; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := pp[0].String(), atSlot(12).String(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	raw, _, _ := memory.Get(ctx, "points")
+	if bytes.Contains(raw, []byte("slot")) {
+		t.Fatalf("got plaintext; want encrypted points")
+	}
+}
+
+func TestNewKVStore_Retention(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		store  = NewKVStore(kv.NewMemory(), "points", WithKVStoreRetention(3))
+		atSlot = func(slot uint64) chainsync.Point {
+			return chainsync.PointStruct{ID: "id", Slot: slot}.Point()
+		}
+	)
+	for slot := uint64(1); slot <= 5; slot++ {
+		if err := store.Save(ctx, atSlot(slot)); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	pp, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := pp.String(), "slot=5 id=id, slot=4 id=id, slot=3 id=id"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestNewKVStore_Prune(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		store  = NewKVStore(kv.NewMemory(), "points")
+		atSlot = func(slot uint64) chainsync.Point {
+			return chainsync.PointStruct{ID: "id", Slot: slot}.Point()
+		}
+	)
+	points := []chainsync.Point{chainsync.Origin, atSlot(1), atSlot(2), atSlot(3)}
+	for _, point := range points {
+		if err := store.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	pruner, ok := store.(Pruner)
+	if !ok {
+		t.Fatalf("got %T; want Pruner", store)
+	}
+	if err := pruner.Prune(ctx, chainsync.Origin); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	pp, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(pp), 4; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := pruner.Prune(ctx, atSlot(2)); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	pp, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := pp.String(), "slot=3 id=id, slot=2 id=id"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestCopyStore(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		src    = NewKVStore(kv.NewMemory(), "points")
+		dst    = NewKVStore(kv.NewMemory(), "points", WithKVStoreRetention(2))
+		atSlot = func(slot uint64) chainsync.Point {
+			return chainsync.PointStruct{ID: "id", Slot: slot}.Point()
+		}
+	)
+	for _, point := range []chainsync.Point{atSlot(1), atSlot(3), atSlot(2)} {
+		if err := src.Save(ctx, point); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	n, err := CopyStore(ctx, src, dst)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := n, 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	pp, err := dst.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := pp.String(), "slot=3 id=id, slot=2 id=id"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errStopStream signals the consumer of a streamed result stopped early
+var errStopStream = errors.New("stream stopped")
+
+// streamResult is query for responses too large to buffer e.g. the utxos of
+// an exchange address.  Each element of the result array is passed to fn as
+// it is read from the socket, so memory use is bounded by the largest element
+// rather than the whole frame.  Transient failures are retried per WithRetry
+// until the first element is received.  The request is sent over a newly
+// dialed connection, regardless of WithConnectionPool, and is bounded by ctx
+// rather than WithQueryTimeout as large results may legitimately take a while
+func (c *Client) streamResult(
+	ctx context.Context,
+	payload any,
+	fn func(decoder *json.Decoder) error,
+) error {
+	var started bool
+	once := func() error {
+		return c.streamOnce(ctx, payload, func(decoder *json.Decoder) error {
+			started = true
+			return fn(decoder)
+		})
+	}
+	retryable := func(err error) bool {
+		return !started && isRetryable(err)
+	}
+	return c.retry(ctx, payload, once, retryable)
+}
+
+func (c *Client) streamOnce(
+	ctx context.Context,
+	payload any,
+	fn func(decoder *json.Decoder) error,
+) (err error) {
+	breaker := c.breaker()
+	if err := breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { breaker.record(ctx, err) }()
+
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to connect to ogmios, %v: %w",
+			c.endpoint(),
+			err,
+		)
+	}
+	//nolint:errcheck
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+		_ = conn.SetReadDeadline(deadline)
+	}
+
+	if err := conn.WriteJSON(payload); err != nil {
+		return fmt.Errorf("failed to submit request: %w", ioErr(ctx, err))
+	}
+
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return fmt.Errorf("failed to read json response: %w", ioErr(ctx, err))
+	}
+
+	if err := decodeStream(json.NewDecoder(r), methodName(payload), fn); err != nil {
+		if errors.Is(err, errStopStream) {
+			return err
+		}
+		return ioErr(ctx, err)
+	}
+	return nil
+}
+
+// decodeStream walks a json-rpc response, passing each element of the result
+// array to fn.  An error response is returned as *AcquireError for transient
+// acquire failures and *RPCError otherwise
+func decodeStream(
+	decoder *json.Decoder,
+	method string,
+	fn func(decoder *json.Decoder) error,
+) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	var found bool
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read json response: %w", err)
+		}
+
+		switch token {
+		case "result":
+			found = true
+			if err := decodeElements(decoder, fn); err != nil {
+				return err
+			}
+
+		case "error":
+			var e RPCError
+			if err := decoder.Decode(&e); err != nil {
+				return fmt.Errorf("failed to decode %v error: %w", method, err)
+			}
+			if e.Code == AcquireLedgerStateFailureCode || e.Code == AcquiredExpiredCode {
+				return &AcquireError{Code: e.Code, Message: e.Message, Data: e.Data}
+			}
+			e.Method = method
+			return &e
+
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to read json response: %w", err)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("ogmios %v returned no result", method)
+	}
+	return nil
+}
+
+// decodeElements passes each element of the json array at the head of the
+// decoder to fn; a null array has no elements
+func decodeElements(decoder *json.Decoder, fn func(*json.Decoder) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read json response: %w", err)
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("failed to read json response: want array, got %v", token)
+	}
+
+	for decoder.More() {
+		if err := fn(decoder); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read json response: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("failed to read json response: want %v, got %v", delim, token)
+	}
+	return nil
+}
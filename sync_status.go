@@ -0,0 +1,109 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// NetworkTip returns the tip of the node's chain i.e. queryNetwork/tip.  It
+// may be ahead of ChainTip, the tip of the ledger state, while the node is
+// replaying its ledger e.g. after a restart.
+func (c *Client) NetworkTip(ctx context.Context) (chainsync.Point, error) {
+	var (
+		payload = makePayload("queryNetwork/tip", Map{}, nil)
+		content struct{ Result chainsync.Point }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return chainsync.Point{}, err
+	}
+
+	return content.Result, nil
+}
+
+// LedgerTip returns the tip of the ledger state i.e. queryLedgerState/tip.
+// LedgerTip is ChainTip, named to distinguish it from NetworkTip.
+func (c *Client) LedgerTip(ctx context.Context) (chainsync.Point, error) {
+	return c.ChainTip(ctx)
+}
+
+// SyncStatus compares the network tip with the ledger tip
+type SyncStatus struct {
+	Network chainsync.Point // Network tip, from queryNetwork/tip
+	Ledger  chainsync.Point // Ledger tip, from queryLedgerState/tip
+}
+
+// SyncStatus queries both the network and ledger tips, e.g. to delay
+// starting services until the node has finished replaying its ledger
+func (c *Client) SyncStatus(ctx context.Context) (SyncStatus, error) {
+	network, err := c.NetworkTip(ctx)
+	if err != nil {
+		return SyncStatus{}, fmt.Errorf("failed to query network tip: %w", err)
+	}
+	ledger, err := c.LedgerTip(ctx)
+	if err != nil {
+		return SyncStatus{}, fmt.Errorf("failed to query ledger tip: %w", err)
+	}
+	return SyncStatus{Network: network, Ledger: ledger}, nil
+}
+
+// Lag returns the number of slots the ledger tip is behind the network tip
+func (s SyncStatus) Lag() uint64 {
+	network, ledger := slotOf(s.Network), slotOf(s.Ledger)
+	if ledger >= network {
+		return 0
+	}
+	return network - ledger
+}
+
+// Diverged returns true if the ledger tip differs from the network tip,
+// typically because the node is still replaying its ledger
+func (s SyncStatus) Diverged() bool {
+	network, ok1 := s.Network.PointStruct()
+	ledger, ok2 := s.Ledger.PointStruct()
+	if !ok1 || !ok2 {
+		return ok1 != ok2
+	}
+	// the ledger tip carries no height, so only the slot and id are compared
+	return network.Slot != ledger.Slot || network.ID != ledger.ID
+}
+
+// Ready returns true if the ledger tip is no more than maxLag slots behind
+// the network tip
+func (s SyncStatus) Ready(maxLag uint64) bool {
+	return s.Lag() <= maxLag
+}
+
+// String implements fmt.Stringer
+func (s SyncStatus) String() string {
+	return fmt.Sprintf(
+		"network=[%v] ledger=[%v] lag=%v",
+		s.Network,
+		s.Ledger,
+		s.Lag(),
+	)
+}
+
+// slotOf returns the slot of the point; origin is slot 0
+func slotOf(point chainsync.Point) uint64 {
+	if ps, ok := point.PointStruct(); ok {
+		return ps.Slot
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestClient_SyncStatus(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","method":"queryNetwork/tip","result":{"slot":120,"id":"b","height":7}}`,
+		`{"jsonrpc":"2.0","method":"queryLedgerState/tip","result":{"slot":100,"id":"a"}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	status, err := client.SyncStatus(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, status.Diverged())
+	assert.EqualValues(t, 20, status.Lag())
+	assert.True(t, status.Ready(20))
+	assert.False(t, status.Ready(19))
+	assert.Equal(t, "network=[slot=120 id=b block=7] ledger=[slot=100 id=a] lag=20", status.String())
+}
+
+func TestClient_LedgerTip(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","method":"queryLedgerState/tip","result":{"slot":100,"id":"a"}}`,
+		`{"jsonrpc":"2.0","method":"queryNetwork/tip","result":{"slot":120,"id":"b","height":7}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ledger, err := client.LedgerTip(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "slot=100 id=a", ledger.String())
+
+	network, err := client.NetworkTip(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "slot=120 id=b block=7", network.String())
+}
+
+func TestSyncStatus(t *testing.T) {
+	height := uint64(7)
+	var (
+		network = chainsync.PointStruct{Slot: 100, ID: "a", Height: &height}.Point()
+		ledger  = chainsync.PointStruct{Slot: 100, ID: "a"}.Point()
+	)
+
+	status := SyncStatus{Network: network, Ledger: ledger}
+	assert.False(t, status.Diverged())
+	assert.EqualValues(t, 0, status.Lag())
+
+	status = SyncStatus{Network: network, Ledger: chainsync.Origin}
+	assert.True(t, status.Diverged())
+	assert.EqualValues(t, 100, status.Lag())
+
+	status = SyncStatus{Network: chainsync.Origin, Ledger: chainsync.Origin}
+	assert.False(t, status.Diverged())
+	assert.True(t, status.Ready(0))
+}
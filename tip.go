@@ -0,0 +1,84 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/buger/jsonparser"
+)
+
+// TipUpdates returns a channel that receives the node's tip each time it
+// changes, including when the tip rolls back.  Updates are driven by a chain
+// sync from the current tip; blocks are not decoded.  Only the latest tip is
+// buffered, so a slow reader skips intermediate tips rather than delaying the
+// sync.  The channel is closed once ctx is cancelled or chain sync fails.
+func (c *Client) TipUpdates(
+	ctx context.Context,
+) (<-chan chainsync.PointStruct, error) {
+	tip, err := c.ChainTip(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ch   = make(chan chainsync.PointStruct, 1)
+		last chainsync.PointStruct
+	)
+	var callback ChainSyncFunc = func(_ context.Context, data []byte) error {
+		raw, _, _, err := jsonparser.Get(data, "result", "tip")
+		if err != nil {
+			return nil // findIntersection
+		}
+		var ps chainsync.PointStruct
+		if err := json.Unmarshal(raw, &ps); err != nil {
+			return nil // origin
+		}
+		if ps.ID == last.ID && ps.Slot == last.Slot {
+			return nil
+		}
+		last = ps
+
+		select {
+		case ch <- ps:
+		default:
+			select {
+			case <-ch: // discard the stale tip
+			default:
+			}
+			ch <- ps
+		}
+		return nil
+	}
+
+	chainSync, err := c.ChainSync(ctx, callback,
+		WithPoints(tip),
+		WithReconnect(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(ch)
+
+		<-chainSync.Done()
+		_ = chainSync.Close()
+	}()
+
+	return ch, nil
+}
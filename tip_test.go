@@ -0,0 +1,63 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestClient_TipUpdates(t *testing.T) {
+	server := fallbackServer(t)
+	client := New(WithEndpoint(server.URL), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tips, err := client.TipUpdates(ctx)
+	assert.Nil(t, err)
+
+	next := func() chainsync.PointStruct {
+		select {
+		case tip, ok := <-tips:
+			assert.True(t, ok)
+			return tip
+		case <-ctx.Done():
+			t.Fatalf("got timeout; want tip")
+			return chainsync.PointStruct{}
+		}
+	}
+	assert.EqualValues(t, 5, next().Slot)
+
+	server.AddBlocks(chainsync.Block{
+		Type:   "praos",
+		Era:    "babbage",
+		ID:     "block6",
+		Height: 6,
+		Slot:   6,
+	})
+	tip := next()
+	assert.EqualValues(t, 6, tip.Slot)
+	assert.Equal(t, "block6", tip.ID)
+
+	cancel()
+	for range tips {
+		// wait for the channel to close
+	}
+}
@@ -48,12 +48,32 @@ type SubmitTx struct {
 // 	Result      json.RawMessage
 // }
 
-// SubmitTx submits the transaction via ogmios
+// SubmitTx submits the transaction via ogmios and returns the transaction id
+// reported by ogmios.  A transaction rejected by the node is not an error;
+// the rejection is reported via SubmitTxResponse.Error, see Err.  With
+// WithTxValidation or WithMaxTxSize, transactions failing ValidateTx are
+// rejected locally with an error wrapping ErrInvalidTx.
 // https://ogmios.dev/mini-protocols/local-tx-submission/
 func (c *Client) SubmitTx(
 	ctx context.Context,
 	data string,
+	opts ...SubmitTxOption,
 ) (s *SubmitTxResponse, err error) {
+	options := buildSubmitTxOptions(opts...)
+	if options.validate {
+		if err := ValidateTx(data, options.maxTxSize); err != nil {
+			return nil, fmt.Errorf("failed to submit TX: %w", err)
+		}
+	}
+
+	protocol, err := c.Protocol(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if protocol == ProtocolV5 {
+		return c.submitTxV5(ctx, data)
+	}
+
 	tx := SubmitTx{
 		Cbor: data,
 	}
@@ -65,7 +85,7 @@ func (c *Client) SubmitTx(
 		)
 		raw json.RawMessage
 	)
-	if err := c.query(ctx, payload, &raw); err != nil {
+	if err := c.submit(ctx, payload, &raw); err != nil {
 		return nil, fmt.Errorf("failed to submit TX: %w", err)
 	}
 
@@ -94,17 +114,33 @@ func readSubmitTx(data []byte) (r *SubmitTxResponse, err error) {
 	)
 }
 
+// SubmitTxResponse is the result of SubmitTx; exactly one of ID or Error is
+// set
 type SubmitTxResponse struct {
-	ID    string
-	Error *SubmitTxError
+	ID    string         // ID of the submitted transaction
+	Error *SubmitTxError // Error is set if the transaction was rejected
+}
+
+// Err returns the rejection, if any, as an error
+func (r *SubmitTxResponse) Err() error {
+	if r == nil || r.Error == nil {
+		return nil
+	}
+	return r.Error
 }
 
+// SubmitTxError is the json-rpc error returned when a transaction is rejected
 type SubmitTxError struct {
 	Code    int
 	Message string
 	Data    json.RawMessage
 }
 
+// Error implements the error interface
+func (e *SubmitTxError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: %v (%v)", e.Message, e.Code)
+}
+
 func readSubmitTxError(data []byte) (*SubmitTxError, error) {
 	value, _, _, err := jsonparser.Get(data, "error")
 	if err != nil {
@@ -162,6 +198,38 @@ func (c *Client) SubmitTxV5(ctx context.Context, data string) (err error) {
 	return readSubmitTxV5(raw)
 }
 
+// submitTxV5 submits the transaction via SubmitTxV5, reporting the result as
+// SubmitTx does
+func (c *Client) submitTxV5(
+	ctx context.Context,
+	data string,
+) (*SubmitTxResponse, error) {
+	var (
+		payload = makePayloadV5("SubmitTx", Map{"submit": data})
+		raw     json.RawMessage
+	)
+	if err := c.submit(ctx, payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to submit TX: %w", err)
+	}
+
+	var e SubmitTxErrorV5
+	switch err := readSubmitTxV5(raw); {
+	case errors.As(err, &e):
+		messages, err := json.Marshal(e.Messages())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SubmitTx response: %w", err)
+		}
+		return &SubmitTxResponse{
+			Error: &SubmitTxError{Message: e.Error(), Data: messages},
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	id, _ := jsonparser.GetString(raw, "result", "SubmitSuccess", "txId")
+	return &SubmitTxResponse{ID: id}, nil
+}
+
 // SubmitTxError encapsulates the SubmitTx errors and allows the results to be parsed
 type SubmitTxErrorV5 struct {
 	messages []json.RawMessage
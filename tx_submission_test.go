@@ -16,6 +16,7 @@ package ogmigo
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -46,6 +47,35 @@ func TestSubmitTxResult(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestReadSubmitTx(t *testing.T) {
+	const id = "ee155ace9c40292074cb6aff8c9ccdd273c81648ff1149ef36bcea6ebb8a3e25"
+
+	resp, err := readSubmitTx([]byte(`{
+		"jsonrpc": "2.0",
+		"method": "submitTransaction",
+		"result": {"transaction": {"id": "` + id + `"}}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, id, resp.ID)
+	assert.Nil(t, resp.Err())
+
+	resp, err = readSubmitTx([]byte(`{
+		"jsonrpc": "2.0",
+		"method": "submitTransaction",
+		"error": {"code": 3117, "message": "missing signatures", "data": {}}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.ID)
+
+	var e *SubmitTxError
+	assert.True(t, errors.As(resp.Err(), &e))
+	assert.Equal(t, 3117, e.Code)
+	assert.Contains(t, resp.Err().Error(), "missing signatures")
+
+	_, err = readSubmitTx([]byte(`{}`))
+	assert.NotNil(t, err)
+}
+
 func testSubmitTxResult(t *testing.T) filepath.WalkFunc {
 	return func(path string, info fs.FileInfo, err error) error {
 		t.Run(filepath.Base(path), func(t *testing.T) {
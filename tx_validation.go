@@ -0,0 +1,134 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ErrInvalidTx is returned when a transaction fails local validation prior to
+// submission
+var ErrInvalidTx = errors.New("ogmigo: invalid transaction")
+
+// cborTagSet is the tag conway uses to mark sets, including the tx inputs
+const cborTagSet = 258
+
+// SubmitTxOptions configures SubmitTx
+type SubmitTxOptions struct {
+	validate  bool   // validate the tx locally prior to submission
+	maxTxSize uint64 // maxTxSize in bytes; 0 skips the size check
+}
+
+// SubmitTxOption provides functional options for SubmitTx
+type SubmitTxOption func(opts *SubmitTxOptions)
+
+// WithTxValidation validates the transaction locally via ValidateTx before
+// submitting it, saving a round trip for transactions the node would reject
+func WithTxValidation() SubmitTxOption {
+	return func(opts *SubmitTxOptions) {
+		opts.validate = true
+	}
+}
+
+// WithMaxTxSize validates the transaction locally, as WithTxValidation, and
+// additionally rejects transactions larger than n bytes; typically n is the
+// maxTransactionSize protocol parameter
+func WithMaxTxSize(n uint64) SubmitTxOption {
+	return func(opts *SubmitTxOptions) {
+		opts.validate = true
+		opts.maxTxSize = n
+	}
+}
+
+func buildSubmitTxOptions(opts ...SubmitTxOption) SubmitTxOptions {
+	var options SubmitTxOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// ValidateTx performs a cheap sanity check of the hex encoded transaction
+// cbor, returning an error wrapping ErrInvalidTx if the transaction is not
+// valid cbor, is larger than maxTxSize bytes, or spends no inputs.  A
+// maxTxSize of 0 skips the size check.  ValidateTx does not check scripts,
+// signatures, or balance; a transaction that passes may still be rejected.
+func ValidateTx(data string, maxTxSize uint64) error {
+	raw, err := hex.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("%w: not hex encoded: %w", ErrInvalidTx, err)
+	}
+	if maxTxSize > 0 && uint64(len(raw)) > maxTxSize {
+		return fmt.Errorf(
+			"%w: size %v bytes exceeds max tx size of %v bytes",
+			ErrInvalidTx,
+			len(raw),
+			maxTxSize,
+		)
+	}
+
+	var tx []cbor.RawMessage
+	if err := cbor.Unmarshal(raw, &tx); err != nil {
+		return fmt.Errorf("%w: not a valid cbor tx: %w", ErrInvalidTx, err)
+	}
+	if len(tx) < 3 || len(tx) > 4 {
+		return fmt.Errorf(
+			"%w: expected tx of 3 or 4 elements; got %v",
+			ErrInvalidTx,
+			len(tx),
+		)
+	}
+
+	var body map[uint64]cbor.RawMessage
+	if err := cbor.Unmarshal(tx[0], &body); err != nil {
+		return fmt.Errorf("%w: invalid tx body: %w", ErrInvalidTx, err)
+	}
+
+	n, err := countInputs(body[0])
+	if err != nil {
+		return fmt.Errorf("%w: invalid tx inputs: %w", ErrInvalidTx, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: tx has no inputs", ErrInvalidTx)
+	}
+
+	return nil
+}
+
+// countInputs returns the number of inputs in the cbor encoded set, which may
+// be a plain array or, from conway, an array tagged 258
+func countInputs(data cbor.RawMessage) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(data, &tag); err == nil {
+		if tag.Number != cborTagSet {
+			return 0, fmt.Errorf("unexpected tag, %v", tag.Number)
+		}
+		data = tag.Content
+	}
+
+	var inputs []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &inputs); err != nil {
+		return 0, err
+	}
+	return len(inputs), nil
+}
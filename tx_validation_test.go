@@ -0,0 +1,103 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/tj/assert"
+)
+
+func encodeTx(t *testing.T, inputs any) string {
+	body := map[uint64]any{
+		1: []any{},
+		2: 170000,
+	}
+	if inputs != nil {
+		body[0] = inputs
+	}
+	data, err := cbor.Marshal([]any{body, map[uint64]any{}, true, nil})
+	assert.Nil(t, err)
+	return hex.EncodeToString(data)
+}
+
+func TestValidateTx(t *testing.T) {
+	input := []any{make([]byte, 32), 0}
+
+	tests := map[string]struct {
+		Data      string
+		MaxTxSize uint64
+		Valid     bool
+	}{
+		"valid": {
+			Data:  encodeTx(t, []any{input}),
+			Valid: true,
+		},
+		"valid set": {
+			Data:  encodeTx(t, cbor.Tag{Number: 258, Content: []any{input}}),
+			Valid: true,
+		},
+		"within max size": {
+			Data:      encodeTx(t, []any{input}),
+			MaxTxSize: 16384,
+			Valid:     true,
+		},
+		"exceeds max size": {
+			Data:      encodeTx(t, []any{input}),
+			MaxTxSize: 10,
+		},
+		"no inputs": {
+			Data: encodeTx(t, []any{}),
+		},
+		"missing inputs": {
+			Data: encodeTx(t, nil),
+		},
+		"not hex": {
+			Data: "zz",
+		},
+		"not cbor": {
+			Data: "ff",
+		},
+		"not a tx": {
+			Data: "820102",
+		},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			err := ValidateTx(tc.Data, tc.MaxTxSize)
+			if tc.Valid {
+				assert.Nil(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidTx))
+		})
+	}
+}
+
+func TestClient_SubmitTxValidation(t *testing.T) {
+	client := New(WithEndpoint("ws://127.0.0.1:0"))
+	_, err := client.SubmitTx(
+		context.Background(),
+		encodeTx(t, []any{}),
+		WithTxValidation(),
+	)
+	assert.True(t, errors.Is(err, ErrInvalidTx))
+	assert.Contains(t, err.Error(), "no inputs")
+}
@@ -14,6 +14,46 @@
 
 package ogmigo
 
+import (
+	"bytes"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxPooledBuffer bounds the capacity of buffers returned to readBuffers so an
+// unusually large message does not pin memory for the life of the process
+const maxPooledBuffer = 4 << 20
+
+// readBuffers pools the buffers used to read websocket messages
+var readBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// readMessage reads the next message from conn.  Unlike conn.ReadMessage,
+// which grows a fresh buffer for every message, the message is read into a
+// pooled buffer and only the returned copy, sized exactly to the message, is
+// allocated.  The copy is owned by the caller and may be retained
+func readMessage(conn *websocket.Conn) (messageType int, data []byte, err error) {
+	messageType, r, err := conn.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+
+	buf := readBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			readBuffers.Put(buf)
+		}
+	}()
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return messageType, nil, err
+	}
+	return messageType, bytes.Clone(buf.Bytes()), nil
+}
+
 // Map provides a simple type alias
 type Map map[string]any
 
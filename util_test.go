@@ -15,8 +15,14 @@
 package ogmigo
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/gorilla/websocket"
 )
 
 func Test_circular_list(t *testing.T) {
@@ -69,3 +75,60 @@ func Test_circular_list(t *testing.T) {
 		})
 	}
 }
+
+func Test_readMessage(t *testing.T) {
+	messages := [][]byte{
+		bytes.Repeat([]byte("a"), 64<<10),
+		[]byte("b"),
+		{},
+		bytes.Repeat([]byte("c"), 1<<10),
+	}
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+
+		for _, message := range messages {
+			if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	endpoint := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	//nolint:errcheck
+	defer conn.Close()
+
+	var got [][]byte
+	for range messages {
+		messageType, data, err := readMessage(conn)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if messageType != websocket.TextMessage {
+			t.Fatalf("got %v; want %v", messageType, websocket.TextMessage)
+		}
+		got = append(got, data)
+	}
+
+	// earlier messages must survive the reuse of the read buffer
+	for i, want := range messages {
+		if !bytes.Equal(got[i], want) {
+			t.Fatalf("message %v: got %v bytes; want %v bytes", i, len(got[i]), len(want))
+		}
+	}
+
+	if _, _, err := readMessage(conn); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
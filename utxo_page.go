@@ -0,0 +1,95 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"iter"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// DefaultUtxoPageSize is the number of utxos per page if none is specified
+const DefaultUtxoPageSize = 1000
+
+// UtxoPage is a page of utxos delivered to a UtxoPageFunc
+type UtxoPage struct {
+	Utxos  []shared.Utxo
+	Cursor int // Cursor is the number of utxos delivered in earlier pages
+}
+
+// UtxoPageFunc receives each page of utxos in turn.  Returning an error stops
+// paging, and the error is returned to the caller.  Utxos must not be retained
+// after UtxoPageFunc returns; the slice is reused for the next page.
+type UtxoPageFunc func(ctx context.Context, page UtxoPage) error
+
+// UtxosByAddressPaged delivers the utxos held by the addresses to fn in pages
+// of up to pageSize; 0 uses DefaultUtxoPageSize.  Utxos are decoded as they
+// are read from the socket, so at most one page is held in memory
+func (c *Client) UtxosByAddressPaged(
+	ctx context.Context,
+	pageSize int,
+	fn UtxoPageFunc,
+	addresses ...string,
+) error {
+	return utxoPages(ctx, c.UtxosByAddressSeq(ctx, addresses...), pageSize, fn)
+}
+
+// UtxosByTxInPaged is UtxosByAddressPaged for the given references
+func (c *Client) UtxosByTxInPaged(
+	ctx context.Context,
+	pageSize int,
+	fn UtxoPageFunc,
+	txIns ...chainsync.TxInQuery,
+) error {
+	return utxoPages(ctx, c.UtxosByTxInSeq(ctx, txIns...), pageSize, fn)
+}
+
+func utxoPages(
+	ctx context.Context,
+	seq iter.Seq2[shared.Utxo, error],
+	pageSize int,
+	fn UtxoPageFunc,
+) error {
+	if pageSize <= 0 {
+		pageSize = DefaultUtxoPageSize
+	}
+
+	page := UtxoPage{Utxos: make([]shared.Utxo, 0, pageSize)}
+	flush := func() error {
+		if len(page.Utxos) == 0 {
+			return nil
+		}
+		if err := fn(ctx, page); err != nil {
+			return err
+		}
+		page.Cursor += len(page.Utxos)
+		page.Utxos = page.Utxos[:0]
+		return nil
+	}
+
+	for utxo, err := range seq {
+		if err != nil {
+			return err
+		}
+		if page.Utxos = append(page.Utxos, utxo); len(page.Utxos) == pageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
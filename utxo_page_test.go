@@ -0,0 +1,99 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/tj/assert"
+)
+
+func TestClient_UtxosByAddressPaged(t *testing.T) {
+	var utxos []string
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		utxos = append(utxos, `{"transaction":{"id":"`+id+`"},"index":0,`+
+			`"address":"addr1","value":{"ada":{"lovelace":1}}}`)
+	}
+	response := `{"jsonrpc":"2.0","result":[` + strings.Join(utxos, ",") + `]}`
+	endpoint, _ := scripted(t, response, response, response)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	ctx := context.Background()
+
+	var (
+		pages   [][]string
+		cursors []int
+	)
+	err := client.UtxosByAddressPaged(ctx, 2,
+		func(_ context.Context, page UtxoPage) error {
+			var ids []string
+			for _, utxo := range page.Utxos {
+				ids = append(ids, utxo.Transaction.ID)
+			}
+			pages = append(pages, ids)
+			cursors = append(cursors, page.Cursor)
+			return nil
+		},
+		"addr1",
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+	assert.Equal(t, []int{0, 2, 4}, cursors)
+
+	boom := errors.New("boom")
+	var calls int
+	err = client.UtxosByAddressPaged(ctx, 2,
+		func(context.Context, UtxoPage) error {
+			calls++
+			return boom
+		},
+		"addr1",
+	)
+	assert.True(t, errors.Is(err, boom))
+	assert.Equal(t, 1, calls)
+
+	var n int
+	err = client.UtxosByTxInPaged(ctx, 0,
+		func(_ context.Context, page UtxoPage) error {
+			n += len(page.Utxos)
+			return nil
+		},
+		chainsync.TxInQuery{Transaction: shared.UtxoTxID{ID: "a"}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+}
+
+func TestClient_UtxosByAddressPaged_Error(t *testing.T) {
+	endpoint, _ := scripted(t,
+		`{"jsonrpc":"2.0","error":{"code":2001,"message":"boom"}}`,
+	)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	err := client.UtxosByAddressPaged(context.Background(), 10,
+		func(context.Context, UtxoPage) error {
+			t.Fatalf("got page; want none")
+			return nil
+		},
+		"addr1",
+	)
+	var rpcErr *RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, 2001, rpcErr.Code)
+}
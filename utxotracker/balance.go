@@ -0,0 +1,241 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utxotracker
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// DefaultBuffer is the default capacity of the BalanceTracker event channel
+const DefaultBuffer = 64
+
+// EventType distinguishes value paid to an address from value spent by it
+type EventType int
+
+const (
+	// Credit indicates value paid to the address
+	Credit EventType = iota + 1
+	// Debit indicates value spent by the address
+	Debit
+)
+
+// String implements fmt.Stringer
+func (e EventType) String() string {
+	switch e {
+	case Credit:
+		return "credit"
+	case Debit:
+		return "debit"
+	default:
+		return "unknown"
+	}
+}
+
+// BalanceEvent records a change to the balance of an address by a single
+// transaction.  When the block containing the transaction is rolled back, the
+// event is emitted again with Reversal set and should be undone.
+type BalanceEvent struct {
+	Type     EventType    `json:"type"`
+	Address  string       `json:"address"`
+	TxID     string       `json:"txId"`
+	Slot     uint64       `json:"slot"`
+	Value    shared.Value `json:"value"`
+	Reversal bool         `json:"reversal,omitempty"`
+}
+
+type balanceBlock struct {
+	slot   uint64
+	events []BalanceEvent
+}
+
+// BalanceTracker tracks the balances of the watched addresses and emits a
+// BalanceEvent for every credit and debit.  Events are delivered in chain
+// order; Observe and Rollback block while the channel is full.
+type BalanceTracker struct {
+	mutex   sync.Mutex
+	tracker *Tracker
+	events  chan BalanceEvent
+	journal []balanceBlock
+}
+
+// NewBalanceTracker returns a new BalanceTracker
+func NewBalanceTracker(opts ...Option) *BalanceTracker {
+	options := buildOptions(opts...)
+	return &BalanceTracker{
+		tracker: newTracker(options),
+		events:  make(chan BalanceEvent, options.buffer),
+	}
+}
+
+// Events returns the channel on which events are emitted
+func (b *BalanceTracker) Events() <-chan BalanceEvent {
+	return b.events
+}
+
+// Close closes the event channel; neither Observe nor Rollback may be called
+// afterwards
+func (b *BalanceTracker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	close(b.events)
+}
+
+// Observe applies a block rolled forward and emits its events
+func (b *BalanceTracker) Observe(
+	ctx context.Context,
+	block *chainsync.Block,
+) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var events []BalanceEvent
+	visit := func(txID string, spent, created []shared.Utxo) {
+		events = appendEvents(events, Debit, txID, block.Slot, spent)
+		events = appendEvents(events, Credit, txID, block.Slot, created)
+	}
+	if err := b.tracker.observe(block, visit); err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		b.journal = append(b.journal, balanceBlock{
+			slot:   block.Slot,
+			events: events,
+		})
+	}
+	floor := b.tracker.floorSlot()
+	i := 0
+	for i < len(b.journal) && b.journal[i].slot <= floor {
+		i++
+	}
+	b.journal = append(b.journal[:0], b.journal[i:]...)
+
+	return b.emit(ctx, events)
+}
+
+// Rollback reverts every block after the point, emitting a reversal for each
+// of their events in the reverse of the order they were emitted
+func (b *BalanceTracker) Rollback(
+	ctx context.Context,
+	point chainsync.Point,
+) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := b.tracker.Rollback(point); err != nil {
+		return err
+	}
+
+	ps, ok := point.PointStruct()
+	var reversals []BalanceEvent
+	i := len(b.journal)
+	for i > 0 && (!ok || b.journal[i-1].slot > ps.Slot) {
+		i--
+		events := b.journal[i].events
+		for j := len(events) - 1; j >= 0; j-- {
+			event := events[j]
+			event.Reversal = true
+			reversals = append(reversals, event)
+		}
+	}
+	b.journal = b.journal[:i]
+
+	return b.emit(ctx, reversals)
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that applies each
+// block before passing the data along to next, if provided
+func (b *BalanceTracker) ChainSyncFunc(
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	return chainSyncFunc(b.Observe, b.Rollback, next)
+}
+
+// BalanceOf returns the balance of the address
+func (b *BalanceTracker) BalanceOf(address string) shared.Value {
+	return b.tracker.BalanceOf(address)
+}
+
+// UtxosAt returns the unspent outputs held by the address
+func (b *BalanceTracker) UtxosAt(address string) []shared.Utxo {
+	return b.tracker.UtxosAt(address)
+}
+
+// Point returns the point of the most recently applied block
+func (b *BalanceTracker) Point() chainsync.Point {
+	return b.tracker.Point()
+}
+
+// emit assumes the caller holds the mutex
+func (b *BalanceTracker) emit(
+	ctx context.Context,
+	events []BalanceEvent,
+) error {
+	for _, event := range events {
+		select {
+		case b.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// appendEvents appends an event for each address holding any of the utxos,
+// ordered by address
+func appendEvents(
+	events []BalanceEvent,
+	eventType EventType,
+	txID string,
+	slot uint64,
+	utxos []shared.Utxo,
+) []BalanceEvent {
+	if len(utxos) == 0 {
+		return events
+	}
+
+	builders := map[string]*shared.ValueBuilder{}
+	for _, utxo := range utxos {
+		builder, ok := builders[utxo.Address]
+		if !ok {
+			builder = &shared.ValueBuilder{}
+			builders[utxo.Address] = builder
+		}
+		builder.Add(utxo.Value)
+	}
+
+	addresses := make([]string, 0, len(builders))
+	for address := range builders {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		events = append(events, BalanceEvent{
+			Type:    eventType,
+			Address: address,
+			TxID:    txID,
+			Slot:    slot,
+			Value:   builders[address].Value(),
+		})
+	}
+	return events
+}
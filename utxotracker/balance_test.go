@@ -0,0 +1,117 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utxotracker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func drain(b *BalanceTracker) []BalanceEvent {
+	var events []BalanceEvent
+	for {
+		select {
+		case event := <-b.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestBalanceTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewBalanceTracker(WithAddresses(alice, bob))
+	assert.Nil(t, tracker.Observe(ctx, block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10), out(alice, 5)},
+	})))
+	assert.Nil(t, tracker.Observe(ctx, block(2, chainsync.Tx{
+		ID:      "b",
+		Inputs:  []chainsync.TxIn{in("a", 0)},
+		Outputs: chainsync.TxOuts{out(bob, 7), out(alice, 3)},
+	})))
+
+	events := drain(tracker)
+	assert.Len(t, events, 4)
+	assert.Equal(t, Credit, events[0].Type)
+	assert.Equal(t, alice, events[0].Address)
+	assert.Equal(t, int64(15), events[0].Value.AdaLovelace().Int64())
+	assert.Equal(t, Debit, events[1].Type)
+	assert.Equal(t, "b", events[1].TxID)
+	assert.EqualValues(t, 2, events[1].Slot)
+	assert.Equal(t, int64(10), events[1].Value.AdaLovelace().Int64())
+	assert.Equal(t, Credit, events[2].Type)
+	assert.Equal(t, alice, events[2].Address)
+	assert.Equal(t, Credit, events[3].Type)
+	assert.Equal(t, bob, events[3].Address)
+
+	assert.Nil(t, tracker.Rollback(ctx, point(1)))
+	reversals := drain(tracker)
+	assert.Len(t, reversals, 3)
+	for i, event := range reversals {
+		assert.True(t, event.Reversal)
+		assert.Equal(t, events[3-i].Type, event.Type)
+		assert.Equal(t, events[3-i].Address, event.Address)
+	}
+	assert.Equal(t, int64(15), tracker.BalanceOf(alice).AdaLovelace().Int64())
+	assert.Len(t, tracker.UtxosAt(bob), 0)
+
+	// nothing left to reverse after slot 1
+	assert.Nil(t, tracker.Rollback(ctx, point(1)))
+	assert.Len(t, drain(tracker), 0)
+}
+
+func TestBalanceTracker_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tracker := NewBalanceTracker(WithBuffer(0))
+	err := tracker.Observe(ctx, block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10)},
+	}))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestBalanceTracker_ChainSyncFunc(t *testing.T) {
+	tracker := NewBalanceTracker()
+	defer tracker.Close()
+
+	data, err := json.Marshal(chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollForwardString,
+			Block: block(10, chainsync.Tx{
+				ID:      "a",
+				Outputs: chainsync.TxOuts{out(alice, 10)},
+			}),
+		},
+	})
+	assert.Nil(t, err)
+
+	fn := tracker.ChainSyncFunc(nil)
+	assert.Nil(t, fn(context.Background(), data))
+
+	event := <-tracker.Events()
+	assert.Equal(t, Credit, event.Type)
+	assert.Equal(t, "a", event.TxID)
+	assert.Equal(t, "credit", event.Type.String())
+}
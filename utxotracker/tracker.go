@@ -0,0 +1,481 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package utxotracker maintains an in-memory set of the unspent outputs held
+// by a set of watched addresses or policies as the chain is streamed via
+// ChainSync.  Recent blocks are journaled so rollbacks restore the set exactly.
+package utxotracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+)
+
+// DefaultDepth is the number of recent blocks journaled by default; it matches
+// the security parameter, k, of mainnet
+const DefaultDepth = 2160
+
+// ErrRollbackTooDeep indicates a rollback past the oldest journaled block
+var ErrRollbackTooDeep = errors.New("rollback exceeds journal depth")
+
+// Options configures a Tracker
+type Options struct {
+	addresses map[string]struct{}
+	policies  map[string]struct{}
+	depth     int
+	buffer    int
+}
+
+// Option provides the functional options pattern for Tracker
+type Option func(*Options)
+
+// WithAddresses tracks outputs paid to any of the addresses
+func WithAddresses(addresses ...string) Option {
+	return func(opts *Options) {
+		for _, address := range addresses {
+			opts.addresses[address] = struct{}{}
+		}
+	}
+}
+
+// WithPolicies tracks outputs holding an asset of any of the policies
+func WithPolicies(policies ...string) Option {
+	return func(opts *Options) {
+		for _, policy := range policies {
+			opts.policies[policy] = struct{}{}
+		}
+	}
+}
+
+// WithDepth sets the number of recent blocks that may be rolled back
+func WithDepth(n int) Option {
+	return func(opts *Options) {
+		opts.depth = n
+	}
+}
+
+// WithBuffer sets the capacity of the BalanceTracker event channel
+func WithBuffer(n int) Option {
+	return func(opts *Options) {
+		opts.buffer = n
+	}
+}
+
+// Change records the outputs created and spent by a single block
+type Change struct {
+	Point   chainsync.PointStruct `json:"point"`
+	Created []chainsync.TxID      `json:"created,omitempty"`
+	Spent   []shared.Utxo         `json:"spent,omitempty"`
+}
+
+// Snapshot captures the state of a Tracker so it may be restored later, e.g.
+// after a restart.  Snapshot is json serializable.
+type Snapshot struct {
+	Point   chainsync.Point `json:"point"`
+	Floor   uint64          `json:"floor"` // oldest slot a rollback may target
+	Utxos   []shared.Utxo   `json:"utxos"`
+	Journal []Change        `json:"journal,omitempty"`
+}
+
+// Tracker maintains the unspent outputs of the watched addresses and
+// policies.  With neither WithAddresses nor WithPolicies, every output is
+// tracked.  Tracker is safe for concurrent use.
+type Tracker struct {
+	mutex     sync.Mutex
+	addresses map[string]struct{}
+	policies  map[string]struct{}
+	depth     int
+
+	point     chainsync.Point
+	floor     uint64
+	utxos     map[chainsync.TxID]shared.Utxo
+	byAddress map[string]map[chainsync.TxID]struct{}
+	journal   []Change
+}
+
+// New returns a new Tracker
+func New(opts ...Option) *Tracker {
+	return newTracker(buildOptions(opts...))
+}
+
+func buildOptions(opts ...Option) Options {
+	options := Options{
+		addresses: map[string]struct{}{},
+		policies:  map[string]struct{}{},
+		depth:     DefaultDepth,
+		buffer:    DefaultBuffer,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.depth <= 0 {
+		options.depth = DefaultDepth
+	}
+	if options.buffer < 0 {
+		options.buffer = 0
+	}
+	return options
+}
+
+func newTracker(options Options) *Tracker {
+	t := &Tracker{
+		addresses: options.addresses,
+		policies:  options.policies,
+		depth:     options.depth,
+	}
+	t.reset()
+	return t
+}
+
+// Observe applies a block rolled forward
+func (t *Tracker) Observe(block *chainsync.Block) error {
+	return t.observe(block, nil)
+}
+
+// observe applies the block, invoking visit, if provided, with the tracked
+// outputs spent and created by each transaction
+func (t *Tracker) observe(
+	block *chainsync.Block,
+	visit func(txID string, spent, created []shared.Utxo),
+) error {
+	if block == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	change := Change{
+		Point: chainsync.PointStruct{ID: block.ID, Slot: block.Slot},
+	}
+	for _, tx := range block.Transactions {
+		inputs, outputs, offset := tx.Inputs, tx.Outputs, 0
+		if tx.Spends == "collaterals" {
+			// a failed script consumes its collateral; any collateral return
+			// follows the regular outputs
+			inputs, outputs, offset = tx.Collaterals, nil, len(tx.Outputs)
+			if tx.CollateralReturn != nil {
+				outputs = chainsync.TxOuts{*tx.CollateralReturn}
+			}
+		}
+
+		var spent, created []shared.Utxo
+		for _, in := range inputs {
+			id := chainsync.NewTxID(in.Transaction.ID, in.Index)
+			if utxo, ok := t.utxos[id]; ok {
+				t.remove(id)
+				change.Spent = append(change.Spent, utxo)
+				spent = append(spent, utxo)
+			}
+		}
+		for i, out := range outputs {
+			if !t.watched(out) {
+				continue
+			}
+			utxo, err := newUtxo(tx.ID, offset+i, out)
+			if err != nil {
+				return fmt.Errorf("failed to observe block %v: %w", block.ID, err)
+			}
+			id := chainsync.NewTxID(tx.ID, offset+i)
+			t.add(id, utxo)
+			change.Created = append(change.Created, id)
+			created = append(created, utxo)
+		}
+		if visit != nil && (len(spent) > 0 || len(created) > 0) {
+			visit(tx.ID, spent, created)
+		}
+	}
+
+	t.point = change.Point.Point()
+	t.journal = append(t.journal, change)
+	if n := len(t.journal) - t.depth; n > 0 {
+		t.floor = t.journal[n-1].Point.Slot
+		t.journal = append(t.journal[:0], t.journal[n:]...)
+	}
+	return nil
+}
+
+// Rollback reverts every block after the point.  Rolling back to the origin
+// discards all tracked outputs.  An ErrRollbackTooDeep is returned if blocks
+// after the point are no longer journaled, in which case the tracker should be
+// rebuilt or restored from an earlier Snapshot.
+func (t *Tracker) Rollback(point chainsync.Point) error {
+	ps, ok := point.PointStruct()
+	if !ok {
+		t.Reset()
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if ps.Slot < t.floor {
+		return fmt.Errorf("failed to rollback to slot %v: %w",
+			ps.Slot, ErrRollbackTooDeep)
+	}
+
+	i := len(t.journal)
+	for i > 0 && t.journal[i-1].Point.Slot > ps.Slot {
+		i--
+		change := t.journal[i]
+		for _, utxo := range change.Spent {
+			t.add(chainsync.NewTxID(utxo.Transaction.ID, int(utxo.Index)), utxo)
+		}
+		for _, id := range change.Created {
+			t.remove(id)
+		}
+	}
+	if i < len(t.journal) {
+		t.point = point
+	}
+	t.journal = t.journal[:i]
+	return nil
+}
+
+// Reset discards all tracked outputs
+func (t *Tracker) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.reset()
+}
+
+func (t *Tracker) reset() {
+	t.point = chainsync.Origin
+	t.floor = 0
+	t.utxos = map[chainsync.TxID]shared.Utxo{}
+	t.byAddress = map[string]map[chainsync.TxID]struct{}{}
+	t.journal = nil
+}
+
+// ChainSyncFunc returns a callback suitable for ChainSync that applies each
+// block before passing the data along to next, if provided
+func (t *Tracker) ChainSyncFunc(
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	return chainSyncFunc(
+		func(_ context.Context, block *chainsync.Block) error {
+			return t.Observe(block)
+		},
+		func(_ context.Context, point chainsync.Point) error {
+			return t.Rollback(point)
+		},
+		next,
+	)
+}
+
+// chainSyncFunc returns a ChainSync callback that applies roll forwards and
+// roll backwards before passing the data along to next
+func chainSyncFunc(
+	observe func(ctx context.Context, block *chainsync.Block) error,
+	rollback func(ctx context.Context, point chainsync.Point) error,
+	next func(ctx context.Context, data []byte) error,
+) func(ctx context.Context, data []byte) error {
+	return func(ctx context.Context, data []byte) error {
+		var response chainsync.ResponsePraos
+		if err := json.Unmarshal(data, &response); err != nil {
+			return fmt.Errorf("failed to decode chainsync response: %w", err)
+		}
+
+		if response.Method == chainsync.NextBlockMethod {
+			result, err := response.NextBlockResult()
+			if err != nil {
+				return fmt.Errorf("failed to decode chainsync response: %w", err)
+			}
+			switch result.Direction {
+			case chainsync.RollForwardString:
+				if err := observe(ctx, result.Block); err != nil {
+					return err
+				}
+			case chainsync.RollBackwardString:
+				if result.Point != nil {
+					if err := rollback(ctx, *result.Point); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if next != nil {
+			return next(ctx, data)
+		}
+		return nil
+	}
+}
+
+// Point returns the point of the most recently applied block; ChainSync may
+// resume from it via WithPoints
+func (t *Tracker) Point() chainsync.Point {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.point
+}
+
+// Len returns the number of tracked outputs
+func (t *Tracker) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.utxos)
+}
+
+// floorSlot returns the oldest slot a rollback may target
+func (t *Tracker) floorSlot() uint64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.floor
+}
+
+// UtxosAt returns the unspent outputs held by the address, ordered by
+// transaction id and index
+func (t *Tracker) UtxosAt(address string) []shared.Utxo {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var utxos []shared.Utxo
+	for id := range t.byAddress[address] {
+		utxos = append(utxos, t.utxos[id])
+	}
+	sortUtxos(utxos)
+	return utxos
+}
+
+// BalanceOf returns the total value of the unspent outputs held by the address
+func (t *Tracker) BalanceOf(address string) shared.Value {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var builder shared.ValueBuilder
+	for id := range t.byAddress[address] {
+		builder.Add(t.utxos[id].Value)
+	}
+	return builder.Value()
+}
+
+// Snapshot returns a copy of the tracker state
+func (t *Tracker) Snapshot() Snapshot {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	utxos := make([]shared.Utxo, 0, len(t.utxos))
+	for _, utxo := range t.utxos {
+		utxos = append(utxos, utxo)
+	}
+	sortUtxos(utxos)
+
+	return Snapshot{
+		Point:   t.point,
+		Floor:   t.floor,
+		Utxos:   utxos,
+		Journal: append([]Change(nil), t.journal...),
+	}
+}
+
+// Restore replaces the tracker state with the snapshot
+func (t *Tracker) Restore(snapshot Snapshot) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.reset()
+	if snapshot.Point.PointType() != 0 {
+		t.point = snapshot.Point
+	}
+	t.floor = snapshot.Floor
+	for _, utxo := range snapshot.Utxos {
+		t.add(chainsync.NewTxID(utxo.Transaction.ID, int(utxo.Index)), utxo)
+	}
+	t.journal = append(t.journal, snapshot.Journal...)
+	if n := len(t.journal) - t.depth; n > 0 {
+		t.floor = t.journal[n-1].Point.Slot
+		t.journal = t.journal[n:]
+	}
+}
+
+// watched assumes the caller holds the mutex
+func (t *Tracker) watched(out chainsync.TxOut) bool {
+	if len(t.addresses) == 0 && len(t.policies) == 0 {
+		return true
+	}
+	if _, ok := t.addresses[out.Address]; ok {
+		return true
+	}
+	for policy := range out.Value {
+		if _, ok := t.policies[policy]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// add assumes the caller holds the mutex
+func (t *Tracker) add(id chainsync.TxID, utxo shared.Utxo) {
+	t.utxos[id] = utxo
+	ids, ok := t.byAddress[utxo.Address]
+	if !ok {
+		ids = map[chainsync.TxID]struct{}{}
+		t.byAddress[utxo.Address] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// remove assumes the caller holds the mutex
+func (t *Tracker) remove(id chainsync.TxID) {
+	utxo, ok := t.utxos[id]
+	if !ok {
+		return
+	}
+	delete(t.utxos, id)
+	if ids := t.byAddress[utxo.Address]; ids != nil {
+		if delete(ids, id); len(ids) == 0 {
+			delete(t.byAddress, utxo.Address)
+		}
+	}
+}
+
+func newUtxo(txID string, index int, out chainsync.TxOut) (shared.Utxo, error) {
+	utxo := shared.Utxo{
+		Transaction: shared.UtxoTxID{ID: txID},
+		Index:       uint32(index),
+		Address:     out.Address,
+		Value:       out.Value.Clone(),
+		DatumHash:   out.DatumHash,
+		Datum:       out.Datum,
+	}
+	if out.Script != nil {
+		script, err := json.Marshal(out.Script)
+		if err != nil {
+			return shared.Utxo{}, fmt.Errorf("failed to encode script: %w", err)
+		}
+		utxo.Script = script
+	}
+	return utxo, nil
+}
+
+func sortUtxos(utxos []shared.Utxo) {
+	sort.Slice(utxos, func(i, j int) bool {
+		if utxos[i].Transaction.ID != utxos[j].Transaction.ID {
+			return utxos[i].Transaction.ID < utxos[j].Transaction.ID
+		}
+		return utxos[i].Index < utxos[j].Index
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utxotracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync/num"
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/shared"
+	"github.com/tj/assert"
+)
+
+const (
+	alice  = "addr_alice"
+	bob    = "addr_bob"
+	policy = "b09dc7fd2a2c5c1d9424877209b2cb299d0e2b6d7b2b0e4a6e0b4241"
+)
+
+func in(id string, index int) chainsync.TxIn {
+	return chainsync.TxIn{Transaction: chainsync.TxInID{ID: id}, Index: index}
+}
+
+func out(address string, lovelace int64) chainsync.TxOut {
+	return chainsync.TxOut{
+		Address: address,
+		Value:   shared.CreateAdaValue(lovelace),
+	}
+}
+
+func block(slot uint64, txs ...chainsync.Tx) *chainsync.Block {
+	return &chainsync.Block{
+		ID:           "block",
+		Slot:         slot,
+		Transactions: txs,
+	}
+}
+
+func point(slot uint64) chainsync.Point {
+	return chainsync.PointStruct{ID: "block", Slot: slot}.Point()
+}
+
+func TestTracker_Observe(t *testing.T) {
+	tracker := New(WithAddresses(alice))
+	assert.Nil(t, tracker.Observe(block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10), out(bob, 20), out(alice, 5)},
+	})))
+	assert.Equal(t, 2, tracker.Len())
+	assert.Equal(t, int64(15), tracker.BalanceOf(alice).AdaLovelace().Int64())
+	assert.Len(t, tracker.UtxosAt(bob), 0)
+
+	assert.Nil(t, tracker.Observe(block(2, chainsync.Tx{
+		ID:      "b",
+		Inputs:  []chainsync.TxIn{in("a", 0)},
+		Outputs: chainsync.TxOuts{out(bob, 10)},
+	})))
+	utxos := tracker.UtxosAt(alice)
+	assert.Len(t, utxos, 1)
+	assert.Equal(t, "a", utxos[0].Transaction.ID)
+	assert.EqualValues(t, 2, utxos[0].Index)
+	assert.Equal(t, point(2), tracker.Point())
+}
+
+func TestTracker_Policies(t *testing.T) {
+	tracker := New(WithPolicies(policy))
+	token := out(bob, 2)
+	token.Value.AddAsset(shared.Coin{
+		AssetId: shared.FromSeparate(policy, "74"),
+		Amount:  num.Int64(1),
+	})
+	assert.Nil(t, tracker.Observe(block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(bob, 1), token},
+	})))
+
+	utxos := tracker.UtxosAt(bob)
+	assert.Len(t, utxos, 1)
+	assert.EqualValues(t, 1, utxos[0].Index)
+}
+
+func TestTracker_Collaterals(t *testing.T) {
+	tracker := New()
+	assert.Nil(t, tracker.Observe(block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10), out(alice, 5)},
+	})))
+
+	ret := out(alice, 4)
+	assert.Nil(t, tracker.Observe(block(2, chainsync.Tx{
+		ID:               "b",
+		Spends:           "collaterals",
+		Inputs:           []chainsync.TxIn{in("a", 0)},
+		Collaterals:      []chainsync.TxIn{in("a", 1)},
+		Outputs:          chainsync.TxOuts{out(bob, 10)},
+		CollateralReturn: &ret,
+	})))
+
+	utxos := tracker.UtxosAt(alice)
+	assert.Len(t, utxos, 2)
+	assert.Equal(t, "a", utxos[0].Transaction.ID)
+	assert.EqualValues(t, 0, utxos[0].Index)
+	assert.Equal(t, "b", utxos[1].Transaction.ID)
+	assert.EqualValues(t, 1, utxos[1].Index)
+	assert.Len(t, tracker.UtxosAt(bob), 0)
+}
+
+func TestTracker_Rollback(t *testing.T) {
+	tracker := New(WithDepth(2))
+	assert.Nil(t, tracker.Observe(block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10)},
+	})))
+	assert.Nil(t, tracker.Observe(block(2, chainsync.Tx{
+		ID:      "b",
+		Inputs:  []chainsync.TxIn{in("a", 0)},
+		Outputs: chainsync.TxOuts{out(bob, 10)},
+	})))
+	assert.Nil(t, tracker.Observe(block(3,
+		chainsync.Tx{
+			ID:      "c",
+			Inputs:  []chainsync.TxIn{in("b", 0)},
+			Outputs: chainsync.TxOuts{out(alice, 9)},
+		},
+		chainsync.Tx{
+			ID:      "d",
+			Inputs:  []chainsync.TxIn{in("c", 0)},
+			Outputs: chainsync.TxOuts{out(bob, 8)},
+		},
+	)))
+	assert.Equal(t, int64(8), tracker.BalanceOf(bob).AdaLovelace().Int64())
+
+	assert.Nil(t, tracker.Rollback(point(2)))
+	assert.Equal(t, int64(10), tracker.BalanceOf(bob).AdaLovelace().Int64())
+	assert.Len(t, tracker.UtxosAt(alice), 0)
+	assert.Equal(t, point(2), tracker.Point())
+
+	assert.Nil(t, tracker.Rollback(point(1)))
+	assert.Equal(t, int64(10), tracker.BalanceOf(alice).AdaLovelace().Int64())
+	assert.Len(t, tracker.UtxosAt(bob), 0)
+
+	// block 1 has fallen out of the journal
+	err := tracker.Rollback(point(0))
+	assert.True(t, errors.Is(err, ErrRollbackTooDeep))
+
+	assert.Nil(t, tracker.Rollback(chainsync.Origin))
+	assert.Equal(t, 0, tracker.Len())
+	assert.Equal(t, chainsync.Origin, tracker.Point())
+}
+
+func TestTracker_Snapshot(t *testing.T) {
+	tracker := New()
+	assert.Nil(t, tracker.Observe(block(1, chainsync.Tx{
+		ID:      "a",
+		Outputs: chainsync.TxOuts{out(alice, 10)},
+	})))
+	assert.Nil(t, tracker.Observe(block(2, chainsync.Tx{
+		ID:      "b",
+		Inputs:  []chainsync.TxIn{in("a", 0)},
+		Outputs: chainsync.TxOuts{out(bob, 10)},
+	})))
+
+	data, err := json.Marshal(tracker.Snapshot())
+	assert.Nil(t, err)
+
+	var snapshot Snapshot
+	assert.Nil(t, json.Unmarshal(data, &snapshot))
+
+	restored := New()
+	restored.Restore(snapshot)
+	assert.Equal(t, point(2), restored.Point())
+	assert.Len(t, restored.UtxosAt(bob), 1)
+
+	assert.Nil(t, restored.Rollback(point(1)))
+	assert.Len(t, restored.UtxosAt(alice), 1)
+	assert.Len(t, restored.UtxosAt(bob), 0)
+}
+
+func TestTracker_ChainSyncFunc(t *testing.T) {
+	var (
+		tracker = New()
+		called  int
+		fn      = tracker.ChainSyncFunc(func(context.Context, []byte) error {
+			called++
+			return nil
+		})
+		forward = chainsync.ResponsePraos{
+			JsonRpc: "2.0",
+			Method:  chainsync.NextBlockMethod,
+			Result: chainsync.ResultNextBlockPraos{
+				Direction: chainsync.RollForwardString,
+				Block: block(10, chainsync.Tx{
+					ID:      "a",
+					Outputs: chainsync.TxOuts{out(alice, 10)},
+				}),
+			},
+		}
+	)
+
+	data, err := json.Marshal(forward)
+	assert.Nil(t, err)
+	assert.Nil(t, fn(context.Background(), data))
+	assert.Equal(t, 1, called)
+	assert.Equal(t, 1, tracker.Len())
+
+	p := point(5)
+	backward := chainsync.ResponsePraos{
+		JsonRpc: "2.0",
+		Method:  chainsync.NextBlockMethod,
+		Result: chainsync.ResultNextBlockPraos{
+			Direction: chainsync.RollBackwardString,
+			Point:     &p,
+		},
+	}
+	data, err = json.Marshal(backward)
+	assert.Nil(t, err)
+	assert.Nil(t, fn(context.Background(), data))
+	assert.Equal(t, 2, called)
+	assert.Equal(t, 0, tracker.Len())
+}
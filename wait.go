@@ -0,0 +1,172 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// TxConfirmation describes the block that includes a transaction
+type TxConfirmation struct {
+	Point         chainsync.Point // Point of the block including the tx
+	Height        uint64          // Height of the block including the tx
+	Confirmations int             // Confirmations includes the block itself
+}
+
+// WaitForTxOptions configures WaitForTx
+type WaitForTxOptions struct {
+	points []chainsync.Point
+}
+
+// WaitForTxOption provides functional options for WaitForTx
+type WaitForTxOption func(opts *WaitForTxOptions)
+
+// WithWaitFrom specifies the points from which to search for the transaction;
+// defaults to the current tip
+func WithWaitFrom(points ...chainsync.Point) WaitForTxOption {
+	return func(opts *WaitForTxOptions) {
+		opts.points = points
+	}
+}
+
+// WaitForTx follows the chain until the transaction is included in a block
+// with at least the given number of confirmations, or ctx expires.  The block
+// including the transaction counts as the first confirmation.  If a rollback
+// orphans the block, WaitForTx continues waiting for the transaction to be
+// included again.
+//
+// By default, the search starts at the current tip, so a transaction included
+// before WaitForTx is called will not be found; see WithWaitFrom.
+func (c *Client) WaitForTx(
+	ctx context.Context,
+	txID string,
+	confirmations int,
+	opts ...WaitForTxOption,
+) (TxConfirmation, error) {
+	var options WaitForTxOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	points := options.points
+	if len(points) == 0 {
+		tip, err := c.ChainTip(ctx)
+		if err != nil {
+			return TxConfirmation{}, fmt.Errorf(
+				"failed to wait for tx %v: %w",
+				txID,
+				err,
+			)
+		}
+		points = []chainsync.Point{tip}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return waitForTx(ctx, c.Blocks(ctx, points...), txID, confirmations)
+}
+
+// waitForTx consumes events until txID has the given number of confirmations
+func waitForTx(
+	ctx context.Context,
+	events iter.Seq2[chainsync.BlockEvent, error],
+	txID string,
+	confirmations int,
+) (TxConfirmation, error) {
+	if confirmations < 1 {
+		confirmations = 1
+	}
+
+	var (
+		found *TxConfirmation
+		slots []uint64 // slots of the including block and those after it
+	)
+	for event, err := range events {
+		if err != nil {
+			return TxConfirmation{}, fmt.Errorf(
+				"failed to wait for tx %v: %w",
+				txID,
+				err,
+			)
+		}
+
+		switch event.Type {
+		case chainsync.RollForwardEvent:
+			block := event.Block
+			switch {
+			case found != nil:
+				slots = append(slots, block.Slot)
+			case includesTx(block, txID):
+				found = &TxConfirmation{
+					Point:  event.Point,
+					Height: block.Height,
+				}
+				slots = []uint64{block.Slot}
+			default:
+				continue
+			}
+
+		case chainsync.RollBackwardEvent:
+			if found == nil {
+				continue
+			}
+			ps, ok := event.Point.PointStruct()
+			if !ok {
+				slots = nil // rolled back to origin
+			}
+			for len(slots) > 0 && slots[len(slots)-1] > ps.Slot {
+				slots = slots[:len(slots)-1]
+			}
+			if len(slots) == 0 {
+				found = nil // orphaned; wait for the tx to be included again
+				continue
+			}
+		}
+
+		if found != nil && len(slots) >= confirmations {
+			found.Confirmations = len(slots)
+			return *found, nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return TxConfirmation{}, fmt.Errorf(
+			"failed to wait for tx %v: %w",
+			txID,
+			err,
+		)
+	}
+	return TxConfirmation{}, fmt.Errorf(
+		"failed to wait for tx %v: chain sync stopped",
+		txID,
+	)
+}
+
+func includesTx(block *chainsync.Block, txID string) bool {
+	if block == nil {
+		return false
+	}
+	for _, tx := range block.Transactions {
+		if tx.ID == txID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func forward(slot uint64, txIDs ...string) chainsync.BlockEvent {
+	block := &chainsync.Block{ID: "block", Slot: slot, Height: slot}
+	for _, id := range txIDs {
+		block.Transactions = append(block.Transactions, chainsync.Tx{ID: id})
+	}
+	return chainsync.BlockEvent{
+		Type:  chainsync.RollForwardEvent,
+		Block: block,
+		Point: block.PointStruct().Point(),
+	}
+}
+
+func backward(point chainsync.Point) chainsync.BlockEvent {
+	return chainsync.BlockEvent{
+		Type:  chainsync.RollBackwardEvent,
+		Point: point,
+	}
+}
+
+func eventSeq(
+	events ...chainsync.BlockEvent,
+) iter.Seq2[chainsync.BlockEvent, error] {
+	return func(yield func(chainsync.BlockEvent, error) bool) {
+		for _, event := range events {
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestWaitForTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("confirmed", func(t *testing.T) {
+		events := eventSeq(
+			forward(1),
+			forward(2, "a", "tx"),
+			forward(3),
+			forward(4),
+			forward(5),
+		)
+		got, err := waitForTx(ctx, events, "tx", 3)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, got.Height)
+		assert.Equal(t, 3, got.Confirmations)
+		assert.Equal(t, forward(2).Point, got.Point)
+	})
+
+	t.Run("included", func(t *testing.T) {
+		got, err := waitForTx(ctx, eventSeq(forward(1, "tx")), "tx", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got.Confirmations)
+	})
+
+	t.Run("rollback reduces confirmations", func(t *testing.T) {
+		events := eventSeq(
+			forward(2, "tx"),
+			forward(3),
+			backward(forward(2).Point),
+			forward(4),
+			forward(5),
+		)
+		got, err := waitForTx(ctx, events, "tx", 3)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, got.Height)
+		assert.Equal(t, 3, got.Confirmations)
+	})
+
+	t.Run("orphaned", func(t *testing.T) {
+		events := eventSeq(
+			forward(2, "tx"),
+			forward(3),
+			backward(forward(1).Point),
+			forward(4),
+			forward(5, "tx"),
+			forward(6),
+			forward(7),
+		)
+		got, err := waitForTx(ctx, events, "tx", 3)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 5, got.Height)
+		assert.Equal(t, 3, got.Confirmations)
+	})
+
+	t.Run("rollback to origin", func(t *testing.T) {
+		events := eventSeq(
+			forward(2, "tx"),
+			backward(chainsync.Origin),
+			forward(3),
+		)
+		_, err := waitForTx(ctx, events, "tx", 2)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := waitForTx(ctx, eventSeq(forward(1)), "tx", 1)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("chain sync failed", func(t *testing.T) {
+		boom := errors.New("boom")
+		events := func(yield func(chainsync.BlockEvent, error) bool) {
+			yield(chainsync.BlockEvent{}, boom)
+		}
+		_, err := waitForTx(ctx, events, "tx", 1)
+		assert.True(t, errors.Is(err, boom))
+	})
+}
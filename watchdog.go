@@ -0,0 +1,141 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// watchdogInterval is how frequently the watchdog checks ChainSync by default
+const watchdogInterval = time.Second
+
+// WatchdogAlert describes a ChainSync the watchdog found lagging or stalled
+type WatchdogAlert struct {
+	Slot uint64        // slot of the most recent block processed
+	Tip  uint64        // slot of the tip as last reported by ogmios
+	Lag  uint64        // slots between the tip and the most recent block
+	Idle time.Duration // time since the most recent block arrived
+}
+
+// WatchdogFunc is notified, once per episode, when ChainSync lags or stalls
+type WatchdogFunc func(ctx context.Context, alert WatchdogAlert)
+
+// WatchdogError is returned when the watchdog forces a reconnect
+type WatchdogError struct {
+	Alert WatchdogAlert
+}
+
+// Error implements error interface
+func (e *WatchdogError) Error() string {
+	return fmt.Sprintf(
+		"chainsync unhealthy: slot=%v tip=%v lag=%v idle=%v",
+		e.Alert.Slot,
+		e.Alert.Tip,
+		e.Alert.Lag,
+		e.Alert.Idle.Round(time.Millisecond),
+	)
+}
+
+// Temporary returns true so ChainSync reconnects
+func (e *WatchdogError) Temporary() bool {
+	return true
+}
+
+type watchdog struct {
+	maxLag    uint64
+	stall     time.Duration
+	reconnect bool
+	hook      WatchdogFunc
+	interval  time.Duration
+}
+
+// WithWatchdog monitors ChainSync, invoking hook, if provided, once the last
+// processed slot trails the tip reported by ogmios by more than maxLag slots
+// or no block has arrived for stall, e.g. while waiting at the tip.  Either
+// check is disabled by passing 0.  If reconnect is true, ChainSync also drops
+// the connection and reconnects immediately.
+func WithWatchdog(
+	maxLag uint64,
+	stall time.Duration,
+	reconnect bool,
+	hook WatchdogFunc,
+) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.watchdog = &watchdog{
+			maxLag:    maxLag,
+			stall:     stall,
+			reconnect: reconnect,
+			hook:      hook,
+			interval:  watchdogInterval,
+		}
+	}
+}
+
+// watch checks the meter every interval until ctx is done.  Idle time is
+// measured from connected should no block have arrived since.
+func (w *watchdog) watch(
+	ctx context.Context,
+	meter *progressMeter,
+	connected time.Time,
+) error {
+	if w.maxLag == 0 && w.stall == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var alerted bool
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		slot, tip, updated := meter.status()
+		if updated.Before(connected) {
+			updated = connected
+		}
+		alert := WatchdogAlert{
+			Slot: slot,
+			Tip:  tip,
+			Idle: time.Since(updated),
+		}
+		if tip > slot {
+			alert.Lag = tip - slot
+		}
+
+		unhealthy := (w.maxLag > 0 && alert.Lag > w.maxLag) ||
+			(w.stall > 0 && alert.Idle > w.stall)
+		if !unhealthy {
+			alerted = false
+			continue
+		}
+		if alerted {
+			continue
+		}
+		alerted = true
+
+		if w.hook != nil {
+			w.hook(ctx, alert)
+		}
+		if w.reconnect {
+			return &WatchdogError{Alert: alert}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+// withWatchdogInterval shortens the interval of a prior WithWatchdog
+func withWatchdogInterval(d time.Duration) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.watchdog.interval = d
+	}
+}
+
+func TestWithWatchdog_Stall(t *testing.T) {
+	endpoint := chainSyncServer(t, 3)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alerts := make(chan WatchdogAlert, 1)
+	hook := func(_ context.Context, alert WatchdogAlert) {
+		alerts <- alert
+	}
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithWatchdog(0, 50*time.Millisecond, false, hook),
+		withWatchdogInterval(10*time.Millisecond),
+	)
+	assert.Nil(t, err)
+
+	select {
+	case alert := <-alerts:
+		assert.EqualValues(t, 3, alert.Slot)
+		assert.EqualValues(t, 0, alert.Lag)
+		assert.True(t, alert.Idle > 50*time.Millisecond)
+	case <-ctx.Done():
+		t.Fatalf("got no alert; want one")
+	}
+
+	// alerts once per episode
+	select {
+	case alert := <-alerts:
+		t.Fatalf("got %v; want no further alerts", alert)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Nil(t, chainSync.Close())
+}
+
+func TestWithWatchdog_Lag(t *testing.T) {
+	endpoint := chainSyncServer(t, 50)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		alerts   = make(chan WatchdogAlert, 1)
+		released = make(chan struct{})
+	)
+	hook := func(_ context.Context, alert WatchdogAlert) {
+		alerts <- alert
+		close(released)
+	}
+	var n int
+	callback := func(context.Context, []byte) error {
+		if n++; n == 3 {
+			<-released // a slow callback at the first block
+		}
+		return nil
+	}
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithWatchdog(10, 0, false, hook),
+		withWatchdogInterval(10*time.Millisecond),
+	)
+	assert.Nil(t, err)
+
+	select {
+	case alert := <-alerts:
+		assert.EqualValues(t, 0, alert.Slot) // rolled back to origin
+		assert.EqualValues(t, 50, alert.Tip)
+		assert.EqualValues(t, 50, alert.Lag)
+	case <-ctx.Done():
+		t.Fatalf("got no alert; want one")
+	}
+	assert.Nil(t, chainSync.Close())
+}
+
+func TestWithWatchdog_Reconnect(t *testing.T) {
+	endpoint := chainSyncServer(t, 3)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alerts := make(chan WatchdogAlert, 2)
+	hook := func(_ context.Context, alert WatchdogAlert) {
+		select {
+		case alerts <- alert:
+		default:
+		}
+	}
+	chainSync, err := client.ChainSync(ctx, nopChainSyncFunc,
+		WithWatchdog(0, 50*time.Millisecond, true, hook),
+		withWatchdogInterval(10*time.Millisecond),
+	)
+	assert.Nil(t, err)
+
+	// each reconnect begins a new episode
+	for range 2 {
+		select {
+		case <-alerts:
+		case <-ctx.Done():
+			t.Fatalf("got no alert; want one per connection")
+		}
+	}
+	assert.Nil(t, chainSync.Close())
+}
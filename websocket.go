@@ -18,64 +18,168 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sync/atomic"
+	"net"
+	"os"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/buger/jsonparser"
 )
 
 var fault = []byte(`jsonwsp/fault`)
 
-func (c *Client) query(
+// query sends the payload to ogmios, retrying transient failures per the
+// policy set via WithRetry
+func (c *Client) query(ctx context.Context, payload any, v any) error {
+	once := func() error { return c.queryOnce(ctx, payload, v) }
+	return c.retry(ctx, payload, once, isRetryable)
+}
+
+// submit is query for transaction submissions.  Only failures before the
+// request is written are retried, see isSubmitRetryable
+func (c *Client) submit(ctx context.Context, payload any, v any) error {
+	once := func() error { return c.queryOnce(ctx, payload, v) }
+	return c.retry(ctx, payload, once, isSubmitRetryable)
+}
+
+// retry invokes once until it succeeds, retryable returns false, or the
+// policy set via WithRetry is exhausted
+func (c *Client) retry(
+	ctx context.Context,
+	payload any,
+	once func() error,
+	retryable func(err error) bool,
+) error {
+	for attempt := 1; ; attempt++ {
+		err := once()
+		if err == nil || attempt > c.options.retryMax || !retryable(err) ||
+			ctx.Err() != nil {
+			return err
+		}
+
+		delay := c.options.retryBackoff(attempt)
+		c.options.logger.Warn("ogmios request failed: will retry",
+			KV("method", methodName(payload)),
+			Int64("attempt", int64(attempt)),
+			Duration("delay", delay.Round(time.Millisecond)),
+			Err(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) queryOnce(
 	ctx context.Context,
 	payload any,
 	v any,
 ) (err error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var (
-		ch     = make(chan error, 1)
-		conn   *websocket.Conn
-		closed int64 // ensures close is only called once
-	)
-	go func() {
-		<-ctx.Done()
-		if conn != nil {
-			ch <- ctx.Err()
-			if v := atomic.AddInt64(&closed, 1); v == 1 {
-				_ = conn.Close()
-			}
+	breaker := c.breaker()
+	if err := breaker.allow(); err != nil {
+		return unsent(err)
+	}
+	defer func(parent context.Context) { breaker.record(parent, err) }(ctx)
+
+	if err := c.wait(ctx); err != nil {
+		return unsent(err)
+	}
+
+	if timeout := c.options.queryTimeout; timeout > 0 {
+		parent := ctx
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// the socket deadline may expire moments before the context does, so
+		// compare deadlines to tell whether the timeout or the parent expired
+		expires, _ := ctx.Deadline()
+		inherited := func() bool {
+			deadline, ok := parent.Deadline()
+			return ok && !deadline.After(expires)
 		}
-	}()
+		defer func() {
+			if err != nil && parent.Err() == nil && !inherited() &&
+				errors.Is(err, context.DeadlineExceeded) {
+				err = &QueryTimeoutError{
+					Method:   methodName(payload),
+					Duration: timeout,
+				}
+			}
+		}()
+	}
 
-	conn, _, err = websocket.DefaultDialer.DialContext(
-		ctx,
-		c.options.endpoint,
-		nil,
-	)
+	var raw json.RawMessage
+	if c.session != nil {
+		raw, err = c.session.roundTrip(ctx, payload)
+	} else {
+		raw, err = c.roundTrip(ctx, payload)
+	}
 	if err != nil {
-		return fmt.Errorf(
+		return err
+	}
+
+	return decodeResponse(c.options.codec, raw, v)
+}
+
+// roundTrip sends the payload over a newly dialed connection and returns the
+// response
+func (c *Client) roundTrip(
+	ctx context.Context,
+	payload any,
+) (raw json.RawMessage, err error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, unsent(fmt.Errorf(
 			"failed to connect to ogmios, %v: %w",
-			c.options.endpoint,
+			c.endpoint(),
 			err,
-		)
+		))
+	}
+	//nolint:errcheck
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+		_ = conn.SetReadDeadline(deadline)
 	}
-	defer func() {
-		if v := atomic.AddInt64(&closed, 1); v == 1 {
-			_ = conn.Close()
-		} else {
-			err = <-ch
-		}
-	}()
 
 	if err := conn.WriteJSON(payload); err != nil {
-		return fmt.Errorf("failed to submit request: %w", err)
+		return nil, fmt.Errorf("failed to submit request: %w", ioErr(ctx, err))
 	}
 
-	var raw json.RawMessage
-	if err := conn.ReadJSON(&raw); err != nil {
-		return fmt.Errorf("failed to read json response: %w", err)
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read json response: %w",
+			ioErr(ctx, err),
+		)
+	}
+	c.options.archive.write(data)
+
+	return data, nil
+}
+
+// decodeResponse surfaces ogmios errors and otherwise unmarshals raw into v
+// via codec
+func decodeResponse(codec JSONCodec, raw json.RawMessage, v any) error {
+	if code, err := jsonparser.GetInt(raw, "error", "code"); err == nil {
+		if code == AcquireLedgerStateFailureCode || code == AcquiredExpiredCode {
+			value, _, _, _ := jsonparser.Get(raw, "error")
+			var e AcquireError
+			if err := json.Unmarshal(value, &e); err != nil {
+				return fmt.Errorf("failed to decode error: %w", err)
+			}
+			return &e
+		}
 	}
 
 	if bytes.Contains(raw, fault) {
@@ -87,10 +191,39 @@ func (c *Client) query(
 	}
 
 	if v != nil {
-		if err := json.Unmarshal(raw, v); err != nil {
+		if err := codec.Unmarshal(raw, v); err != nil {
 			return fmt.Errorf("failed to unmarshal contents: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// ioErr maps websocket i/o failures caused by an expired or cancelled
+// context back onto the context error, so callers can rely on errors.Is
+func ioErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	// websocket reports read timeouts as a net.Error that no longer wraps
+	// os.ErrDeadlineExceeded
+	var ne net.Error
+	if errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &ne) && ne.Timeout()) {
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// methodName returns the ogmios method of a payload built by makePayload or
+// makePayloadV5
+func methodName(payload any) string {
+	if m, ok := payload.(Map); ok {
+		for _, key := range []string{"method", "methodname"} {
+			if s, ok := m[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return "query"
+}
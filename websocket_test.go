@@ -2,11 +2,14 @@ package ogmigo
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -77,3 +80,96 @@ func TestClient_query(t *testing.T) {
 		t.Fatalf("expected context.Canceled; got %v", err)
 	}
 }
+
+func TestClient_queryV5Deadline(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	//nolint:errcheck
+	defer listener.Close()
+
+	go func() {
+		_ = http.Serve(listener, timeout(time.Minute))
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	client := New(WithEndpoint(fmt.Sprintf("ws://127.0.0.1:%v", port)))
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		250*time.Millisecond,
+	)
+	defer cancel()
+
+	begin := time.Now()
+	err = client.SubmitTxV5(ctx, "fffefdfc")
+	if ok := errors.Is(err, context.DeadlineExceeded); !ok {
+		t.Fatalf("got %v; want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 5*time.Second {
+		t.Fatalf("got %v; want prompt return after deadline", elapsed)
+	}
+}
+
+func TestClient_queryTLS(t *testing.T) {
+	server := httptest.NewTLSServer(timeout(0))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client := New(
+		WithEndpoint("wss"+strings.TrimPrefix(server.URL, "https")),
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got Map
+	if err := client.query(ctx, Map{"hello": "world"}, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := got["hello"], "world"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	insecure := New(WithEndpoint("wss" + strings.TrimPrefix(server.URL, "https")))
+	if err := insecure.query(ctx, Map{"hello": "world"}, nil); err == nil {
+		t.Fatalf("got nil; want certificate error")
+	}
+}
+
+func TestClient_queryTimeout(t *testing.T) {
+	server := httptest.NewServer(timeout(time.Minute))
+	defer server.Close()
+
+	client := New(
+		WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")),
+		WithQueryTimeout(250*time.Millisecond),
+	)
+
+	_, err := client.UtxosByAddress(context.Background(), "addr")
+	var qte *QueryTimeoutError
+	if ok := errors.As(err, &qte); !ok {
+		t.Fatalf("got %v; want *QueryTimeoutError", err)
+	}
+	if got, want := qte.Method, "queryLedgerState/utxo"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if ok := errors.Is(err, context.DeadlineExceeded); !ok {
+		t.Fatalf("got %v; want context.DeadlineExceeded", err)
+	}
+
+	// cancellation of the parent context is not reported as a query timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.UtxosByAddress(ctx, "addr")
+	if ok := errors.As(err, &qte); ok {
+		t.Fatalf("got %v; want parent context error", err)
+	}
+	if ok := errors.Is(err, context.DeadlineExceeded); !ok {
+		t.Fatalf("got %v; want context.DeadlineExceeded", err)
+	}
+}
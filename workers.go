@@ -0,0 +1,145 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+)
+
+// PrepareFunc decodes or otherwise pre-processes a json encoded
+// chainsync.Response ahead of delivery.  PrepareFunc must be safe for
+// concurrent use
+type PrepareFunc func(ctx context.Context, data []byte) (any, error)
+
+// WithWorkers runs prepare across n workers so that CPU bound decoding of
+// upcoming messages proceeds concurrently, while the callback itself is still
+// invoked one message at a time in strict chain order.  The callback
+// retrieves the value produced for its message via Prepared.  If prepare is
+// nil, DecodeResponse is used.  WithWorkers takes precedence over
+// WithOrdering.
+func WithWorkers(n int, prepare PrepareFunc) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.workers = n
+		opts.prepare = prepare
+	}
+}
+
+// DecodeResponse is a PrepareFunc that decodes data into a
+// *chainsync.ResponsePraos
+func DecodeResponse(_ context.Context, data []byte) (any, error) {
+	var response chainsync.ResponsePraos
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &response, nil
+}
+
+type preparedKey struct{}
+
+// Prepared returns the value the PrepareFunc provided via WithWorkers
+// produced for the message being delivered to the callback
+func Prepared[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(preparedKey{}).(T)
+	return v, ok
+}
+
+// preparedMessage is a message along with the result of its PrepareFunc
+type preparedMessage struct {
+	ctx   context.Context
+	data  []byte
+	value any
+	err   error
+	ready chan struct{} // closed once prepared
+}
+
+// workers prepares messages concurrently and commits them to the callback in
+// the order they were dispatched
+type workers struct {
+	d       *dispatcher
+	prepare PrepareFunc
+	queue   chan *preparedMessage // messages in chain order
+	done    chan struct{}
+}
+
+func newWorkers(d *dispatcher, n int, prepare PrepareFunc) *workers {
+	if prepare == nil {
+		prepare = DecodeResponse
+	}
+	w := &workers{
+		d:       d,
+		prepare: prepare,
+		queue:   make(chan *preparedMessage, n),
+		done:    make(chan struct{}),
+	}
+	go w.commit()
+	return w
+}
+
+// dispatch starts preparing data and queues it for commit; dispatch blocks
+// once n messages are awaiting commit
+func (w *workers) dispatch(ctx context.Context, data []byte) error {
+	if err := w.d.failed(); err != nil {
+		return err
+	}
+
+	message := &preparedMessage{
+		ctx:   ctx,
+		data:  data,
+		ready: make(chan struct{}),
+	}
+	w.d.wg.Add(1)
+	w.d.sem <- struct{}{}
+	go func() {
+		defer func() { <-w.d.sem }()
+		defer close(message.ready)
+		message.value, message.err = w.prepare(ctx, data)
+	}()
+	w.queue <- message
+	return nil
+}
+
+// commit invokes the callback for each message in order, once prepared
+func (w *workers) commit() {
+	defer close(w.done)
+
+	for message := range w.queue {
+		<-message.ready
+
+		err := message.err
+		if err == nil && w.d.failed() == nil {
+			ctx := context.WithValue(message.ctx, preparedKey{}, message.value)
+			err = w.d.callback(ctx, message.data)
+		}
+		if err != nil {
+			w.d.fail(err)
+		}
+		w.d.wg.Done()
+	}
+}
+
+// saturated returns true if the queue awaiting commit is full
+func (w *workers) saturated() bool {
+	return len(w.queue) == cap(w.queue)
+}
+
+// close stops the committer once the queue has drained
+func (w *workers) close() {
+	close(w.queue)
+	<-w.done
+}
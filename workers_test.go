@@ -0,0 +1,101 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SundaeSwap-finance/ogmigo/v6/ouroboros/chainsync"
+	"github.com/tj/assert"
+)
+
+func TestClient_ChainSyncWorkers(t *testing.T) {
+	const n = 50
+
+	endpoint := chainSyncServer(t, n)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		running, peak int64
+		slots         []uint64
+	)
+	prepare := func(ctx context.Context, data []byte) (any, error) {
+		current := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			v := atomic.LoadInt64(&peak)
+			if current <= v || atomic.CompareAndSwapInt64(&peak, v, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return DecodeResponse(ctx, data)
+	}
+	callback := func(ctx context.Context, _ []byte) error {
+		response, ok := Prepared[*chainsync.ResponsePraos](ctx)
+		if !ok {
+			return errors.New("no prepared value")
+		}
+		if result, err := response.NextBlockResult(); err == nil &&
+			result.Direction == chainsync.RollForwardString {
+			slots = append(slots, result.Block.Slot)
+		}
+		return nil
+	}
+
+	chainSync, err := client.ChainSync(ctx, callback,
+		WithWorkers(4, prepare),
+		WithStopWhenTipReached(),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.Nil(t, chainSync.Close())
+
+	assert.Len(t, slots, n)
+	for i, slot := range slots {
+		assert.EqualValues(t, i+1, slot) // strict chain order
+	}
+	assert.True(t, atomic.LoadInt64(&peak) > 1)
+}
+
+func TestClient_ChainSyncWorkersPrepareError(t *testing.T) {
+	endpoint := chainSyncServer(t, 10)
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	want := errors.New("boom")
+	prepare := func(_ context.Context, data []byte) (any, error) {
+		if isRollForward(data) {
+			return nil, want
+		}
+		return nil, nil
+	}
+	chainSync, err := client.ChainSync(ctx,
+		func(context.Context, []byte) error { return nil },
+		WithWorkers(2, prepare),
+	)
+	assert.Nil(t, err)
+	<-chainSync.Done()
+	assert.True(t, errors.Is(chainSync.Close(), want))
+}